@@ -0,0 +1,34 @@
+package bareknews
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// BodyFormat is a value object that represents the markup of a body. An
+// empty format is taken as plain text.
+type BodyFormat string
+
+const (
+	Markdown BodyFormat = "markdown"
+	HTML     BodyFormat = "html"
+	Plain    BodyFormat = "plain"
+	// Blocks is a JSON document of structured blocks, see news.Document.
+	Blocks BodyFormat = "blocks"
+)
+
+// Validate performs validating to the body format.
+func (f BodyFormat) Validate() error {
+	return validation.Validate(
+		f.String(),
+		validation.In(
+			Markdown.String(),
+			HTML.String(),
+			Plain.String(),
+			Blocks.String(),
+		).Error("body format must be one of 'markdown', 'html', 'plain', 'blocks'"),
+	)
+}
+
+func (f BodyFormat) String() string {
+	return string(f)
+}
@@ -0,0 +1,86 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package changes
+
+import (
+	"context"
+	"sync"
+)
+
+// Ensure, that RepositoryMock does implement Repository.
+// If this is not the case, regenerate this file with moq.
+var _ Repository = &RepositoryMock{}
+
+// RepositoryMock is a mock implementation of Repository.
+//
+// 	func TestSomethingThatUsesRepository(t *testing.T) {
+//
+// 		// make and configure a mocked Repository
+// 		mockedRepository := &RepositoryMock{
+// 			GetSinceFunc: func(ctx context.Context, since int64, limit int) ([]Entry, error) {
+// 				panic("mock out the GetSince method")
+// 			},
+// 		}
+//
+// 		// use mockedRepository in code that requires Repository
+// 		// and then make assertions.
+//
+// 	}
+type RepositoryMock struct {
+	// GetSinceFunc mocks the GetSince method.
+	GetSinceFunc func(ctx context.Context, since int64, limit int) ([]Entry, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetSince holds details about calls to the GetSince method.
+		GetSince []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Since is the since argument value.
+			Since int64
+			// Limit is the limit argument value.
+			Limit int
+		}
+	}
+	lockGetSince sync.RWMutex
+}
+
+// GetSince calls GetSinceFunc.
+func (mock *RepositoryMock) GetSince(ctx context.Context, since int64, limit int) ([]Entry, error) {
+	if mock.GetSinceFunc == nil {
+		panic("RepositoryMock.GetSinceFunc: method is nil but Repository.GetSince was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Since int64
+		Limit int
+	}{
+		Ctx:   ctx,
+		Since: since,
+		Limit: limit,
+	}
+	mock.lockGetSince.Lock()
+	mock.calls.GetSince = append(mock.calls.GetSince, callInfo)
+	mock.lockGetSince.Unlock()
+	return mock.GetSinceFunc(ctx, since, limit)
+}
+
+// GetSinceCalls gets all the calls that were made to GetSince.
+// Check the length with:
+//     len(mockedRepository.GetSinceCalls())
+func (mock *RepositoryMock) GetSinceCalls() []struct {
+	Ctx   context.Context
+	Since int64
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Since int64
+		Limit int
+	}
+	mock.lockGetSince.RLock()
+	calls = mock.calls.GetSince
+	mock.lockGetSince.RUnlock()
+	return calls
+}
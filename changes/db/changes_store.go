@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/changes"
+	"github.com/google/uuid"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/changes/db")
+
+type Store struct {
+	conn *sql.DB
+}
+
+// Ensure Store does implement changes.Repository.
+var _ changes.Repository = Store{}
+
+func CreateStore(conn *sql.DB) Store {
+	return Store{conn: conn}
+}
+
+// Record writes a change of the entities in the transaction of the
+// change, so the feed has every change that is committed and nothing
+// else. REPLACE drops the previous entry of an entity and AUTOINCREMENT
+// gives the new one a sequence that is never handed out again. SQLite
+// has one writer at a time, so the sequences are committed in order and
+// a reader can't skip one that is committed later.
+func Record(ctx context.Context, tx *sql.Tx, kind string, deleted bool, ids ...uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+
+	builder := sqlbuilder.NewInsertBuilder()
+	builder.ReplaceInto("changes")
+	builder.Cols("kind", "entityID", "deleted", "date_created")
+	for _, id := range ids {
+		builder.Values(kind, id, deleted, now)
+	}
+
+	query, args := builder.Build()
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "record the changes")
+	}
+
+	return nil
+}
+
+func (s Store) GetSince(ctx context.Context, since int64, limit int) ([]changes.Entry, error) {
+	ctx, span := tracer.Start(ctx, "changes.db.GetSince")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("seq", "kind", "entityID", "deleted", "date_created")
+	builder.From("changes")
+	builder.Where(builder.GreaterThan("seq", since))
+	builder.OrderBy("seq")
+	builder.Limit(limit)
+	query, args := builder.Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []changes.Entry{}, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	entries := make([]changes.Entry, 0)
+
+	for rows.Next() {
+		e := changes.Entry{}
+
+		err = rows.Scan(&e.Seq, &e.Kind, &e.ID, &e.Deleted, &e.DateCreated)
+		if err != nil {
+			return []changes.Entry{}, errors.Wrap(err, "scan a change")
+		}
+
+		entries = append(entries, e)
+	}
+
+	if rows.Err() != nil {
+		return []changes.Entry{}, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,121 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/changes"
+	"github.com/Iiqbal2000/bareknews/changes/db"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsdb "github.com/Iiqbal2000/bareknews/news/db"
+	"github.com/Iiqbal2000/bareknews/pkg/sqlite3"
+	"github.com/Iiqbal2000/bareknews/tags"
+	tagsdb "github.com/Iiqbal2000/bareknews/tags/db"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+// entry is the part of a change that the tests compare.
+type entry struct {
+	Kind    string
+	ID      uuid.UUID
+	Deleted bool
+}
+
+func entriesOf(got []changes.Entry) []entry {
+	r := make([]entry, 0, len(got))
+	for _, e := range got {
+		r = append(r, entry{Kind: e.Kind, ID: e.ID, Deleted: e.Deleted})
+	}
+	return r
+}
+
+func TestChanges(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	tagsStore := tagsdb.CreateStore(conn)
+	is := is.New(t)
+
+	tag := tags.Create("golang")
+	is.NoErr(tagsStore.Save(context.TODO(), *tag))
+
+	first := news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{tag.Label.ID}, time.Now().Unix())
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	got, err := store.GetSince(context.TODO(), 0, 10)
+	is.NoErr(err)
+	is.Equal(got[0].Seq, int64(1))
+	is.Equal(got[2].Seq, int64(3))
+	is.Equal(entriesOf(got), []entry{
+		{Kind: changes.KindTag, ID: tag.Label.ID},
+		{Kind: changes.KindNews, ID: first.Post.ID},
+		{Kind: changes.KindNews, ID: second.Post.ID},
+	})
+
+	// an entity has only its last change.
+	second.ChangeStatus(bareknews.Publish)
+	is.NoErr(newsStore.Update(context.TODO(), *second))
+	is.NoErr(newsStore.Delete(context.TODO(), second.Post.ID))
+
+	got, err = store.GetSince(context.TODO(), 3, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Seq, int64(5))
+	is.Equal(entriesOf(got), []entry{{Kind: changes.KindNews, ID: second.Post.ID, Deleted: true}})
+
+	// the news of a deleted tag are changed too.
+	is.NoErr(tagsStore.Delete(context.TODO(), tag.Label.ID))
+
+	got, err = store.GetSince(context.TODO(), 5, 10)
+	is.NoErr(err)
+	is.Equal(entriesOf(got), []entry{
+		{Kind: changes.KindNews, ID: first.Post.ID},
+		{Kind: changes.KindTag, ID: tag.Label.ID, Deleted: true},
+	})
+
+	got, err = store.GetSince(context.TODO(), 0, 1)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+}
+
+func TestChangesRollBack(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	// the second item takes the title of the first one.
+	apply := func(n news.News) (news.Change, error) {
+		n.ChangeTitle("news 1")
+		return news.Change{News: n}, nil
+	}
+	ids := []uuid.UUID{first.Post.ID, second.Post.ID}
+
+	// the failed change is rolled back with its entry.
+	errs, err := newsStore.Bulk(context.TODO(), ids, apply, false)
+	is.NoErr(err)
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+
+	got, err := store.GetSince(context.TODO(), 2, 10)
+	is.NoErr(err)
+	is.Equal(entriesOf(got), []entry{{Kind: changes.KindNews, ID: first.Post.ID}})
+
+	// nothing is recorded when the whole bulk is rolled back.
+	errs, err = newsStore.Bulk(context.TODO(), ids, apply, true)
+	is.NoErr(err)
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+
+	got, err = store.GetSince(context.TODO(), 2, 10)
+	is.NoErr(err)
+	is.Equal(got[0].Seq, int64(3))
+}
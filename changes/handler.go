@@ -0,0 +1,66 @@
+package changes
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	service Service
+	log     *zap.SugaredLogger
+}
+
+func CreateHandler(svc Service, log *zap.SugaredLogger) handler {
+	return handler{service: svc, log: log}
+}
+
+// GetChanges godoc
+// @Summary      Get the changes of the news and the tags
+// @Description  Get the news and the tags that changed after a sequence, the oldest change first. An entity is listed once, at its last change; a deleted one is a tombstone. Pass next_since as the since of the next request.
+// @Tags         changes
+// @Accept       json
+// @Produce      json
+// @Param        since   query     int     false  "sequence of the last change the client has"	minimum(0) default(0)
+// @Param        limit   query     int     false  "number of changes"	minimum(1) maximum(500) default(100)
+// @Success      200  {object}  web.RespBody{data=Page} "A page of the changes"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /changes [get]
+func (h handler) GetChanges(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var err error
+	var since int64
+
+	if rawSince := strings.TrimSpace(r.URL.Query().Get("since")); rawSince != "" {
+		since, err = strconv.ParseInt(rawSince, 10, 64)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the since"), http.StatusBadRequest)
+		}
+	}
+
+	limit := 0
+
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the limit"), http.StatusBadRequest)
+		}
+	}
+
+	page, err := h.service.Since(ctx, since, limit)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting the changes",
+		Data:    page,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/changes"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/changes/memory")
+
+type key struct {
+	kind string
+	id   uuid.UUID
+}
+
+// Store is an in-memory implementation of changes.Repository. The news
+// and the tags stores record their changes in it while they hold their
+// own lock, which stands in for the transaction of the SQLite store.
+type Store struct {
+	mu      *sync.RWMutex
+	lastSeq *int64
+	items   map[key]changes.Entry
+}
+
+// Ensure Store does implement changes.Repository.
+var _ changes.Repository = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:      &sync.RWMutex{},
+		lastSeq: new(int64),
+		items:   make(map[key]changes.Entry),
+	}
+}
+
+// Record replaces the entry of every entity with a new one.
+func (s Store) Record(kind string, deleted bool, ids ...uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().Unix()
+
+	for _, id := range ids {
+		*s.lastSeq++
+		s.items[key{kind: kind, id: id}] = changes.Entry{
+			Seq:         *s.lastSeq,
+			Kind:        kind,
+			ID:          id,
+			Deleted:     deleted,
+			DateCreated: now,
+		}
+	}
+}
+
+func (s Store) GetSince(ctx context.Context, since int64, limit int) ([]changes.Entry, error) {
+	_, span := tracer.Start(ctx, "changes.memory.GetSince")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r := make([]changes.Entry, 0)
+
+	for _, e := range s.items {
+		if e.Seq > since {
+			r = append(r, e)
+		}
+	}
+
+	sort.Slice(r, func(i, j int) bool { return r[i].Seq < r[j].Seq })
+
+	if len(r) > limit {
+		r = r[:limit]
+	}
+
+	return r, nil
+}
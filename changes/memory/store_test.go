@@ -0,0 +1,81 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/changes"
+	"github.com/Iiqbal2000/bareknews/changes/memory"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsmemory "github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/Iiqbal2000/bareknews/tags"
+	tagsmemory "github.com/Iiqbal2000/bareknews/tags/memory"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestChanges(t *testing.T) {
+	store := memory.CreateStore()
+	newsStore := newsmemory.CreateStore().WithChanges(store)
+	tagsStore := tagsmemory.CreateStore().WithNews(newsStore).WithChanges(store)
+	is := is.New(t)
+
+	tag := tags.Create("golang")
+	is.NoErr(tagsStore.Save(context.TODO(), *tag))
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	got, err := store.GetSince(context.TODO(), 0, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 3)
+	is.Equal(got[0].Kind, changes.KindTag)
+	is.Equal(got[2].ID, second.Post.ID)
+	is.Equal(got[2].Seq, int64(3))
+
+	// an entity has only its last change.
+	second.ChangeStatus(bareknews.Publish)
+	is.NoErr(newsStore.Update(context.TODO(), *second))
+	is.NoErr(newsStore.Delete(context.TODO(), second.Post.ID))
+
+	got, err = store.GetSince(context.TODO(), 3, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Seq, int64(5))
+	is.Equal(got[0].ID, second.Post.ID)
+	is.True(got[0].Deleted)
+
+	// the failed change of a bulk isn't recorded.
+	third := news.Create("news 3", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *third))
+
+	// the third item takes the title of the first one.
+	_, err = newsStore.Bulk(context.TODO(), []uuid.UUID{first.Post.ID, third.Post.ID}, func(n news.News) (news.Change, error) {
+		n.ChangeTitle("news 1")
+		return news.Change{News: n}, nil
+	}, false)
+	is.NoErr(err)
+
+	got, err = store.GetSince(context.TODO(), 6, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].ID, first.Post.ID)
+
+	is.NoErr(tagsStore.Delete(context.TODO(), tag.Label.ID))
+
+	got, err = store.GetSince(context.TODO(), 7, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].ID, tag.Label.ID)
+	is.True(got[0].Deleted)
+
+	got, err = store.GetSince(context.TODO(), 0, 2)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].ID, second.Post.ID)
+	is.Equal(got[1].ID, third.Post.ID)
+}
@@ -0,0 +1,30 @@
+package changes
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// The kinds of the entities in the feed.
+const (
+	KindNews = "news"
+	KindTag  = "tag"
+)
+
+// Entry is the last change of an entity. Seq grows with every change and
+// an entity has only its last entry, so the entry of a deleted entity is
+// its tombstone.
+type Entry struct {
+	Seq         int64
+	Kind        string
+	ID          uuid.UUID
+	Deleted     bool
+	DateCreated int64
+}
+
+//go:generate moq -out changesRepo_moq.go . Repository
+type Repository interface {
+	// GetSince returns up to limit entries after the sequence, in order.
+	GetSince(ctx context.Context, since int64, limit int) ([]Entry, error)
+}
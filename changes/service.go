@@ -0,0 +1,128 @@
+// Package changes is the feed of the changes of the news and the tags,
+// for the clients that keep a copy of them in sync.
+package changes
+
+import (
+	"context"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/tags"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/changes")
+
+const (
+	// DefaultLimit is the number of changes of a page when no limit is
+	// given.
+	DefaultLimit = 100
+	// MaxLimit is the maximum number of changes of a page.
+	MaxLimit = 500
+)
+
+// ChangeOut is a change of the feed. News or Tag is the entity as it is
+// now; both are null when it is deleted.
+type ChangeOut struct {
+	Seq     int64         `json:"seq"`
+	Type    string        `json:"type" enums:"news,tag"`
+	ID      uuid.UUID     `json:"id"`
+	Deleted bool          `json:"deleted"`
+	News    *news.NewsOut `json:"news,omitempty"`
+	Tag     *tags.TagsOut `json:"tag,omitempty"`
+}
+
+// Page is a page of the feed. NextSince is the since of the next page;
+// it stays the same until there are new changes.
+type Page struct {
+	Changes   []ChangeOut `json:"changes"`
+	NextSince int64       `json:"next_since"`
+	HasMore   bool        `json:"has_more"`
+}
+
+type Service struct {
+	store   Repository
+	news    news.Service
+	tagging tags.Service
+}
+
+func CreateSvc(store Repository, nws news.Service, tagging tags.Service) Service {
+	return Service{store: store, news: nws, tagging: tagging}
+}
+
+// Since returns the changes after the sequence with the entities as they
+// are now. An entity is listed once, at its last change.
+func (s Service) Since(ctx context.Context, since int64, limit int) (Page, error) {
+	ctx, span := tracer.Start(ctx, "changes.Since")
+	defer span.End()
+
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	err := validation.Errors{
+		"since": validation.Validate(since, validation.Min(int64(0))),
+		"limit": validation.Validate(limit, validation.Min(1), validation.Max(MaxLimit)),
+	}.Filter()
+	if err != nil {
+		return Page{}, err
+	}
+
+	entries, err := s.store.GetSince(ctx, since, limit+1)
+	if err != nil {
+		return Page{}, errors.Wrap(err, "get the changes")
+	}
+
+	page := Page{Changes: make([]ChangeOut, 0, len(entries)), NextSince: since}
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.HasMore = true
+	}
+
+	for _, e := range entries {
+		page.NextSince = e.Seq
+
+		c, err := s.changeOut(ctx, e)
+		if errors.Is(err, bareknews.ErrDataNotFound) {
+			// The entity is deleted since the page is read; its
+			// tombstone comes later in the feed.
+			continue
+		}
+		if err != nil {
+			return Page{}, err
+		}
+
+		page.Changes = append(page.Changes, c)
+	}
+
+	return page, nil
+}
+
+func (s Service) changeOut(ctx context.Context, e Entry) (ChangeOut, error) {
+	c := ChangeOut{Seq: e.Seq, Type: e.Kind, ID: e.ID, Deleted: e.Deleted}
+
+	if e.Deleted {
+		return c, nil
+	}
+
+	switch e.Kind {
+	case KindNews:
+		nw, err := s.news.GetById(ctx, e.ID)
+		if err != nil {
+			return ChangeOut{}, errors.Wrap(err, "get a news item")
+		}
+		c.News = &nw
+	case KindTag:
+		tg, err := s.tagging.GetById(ctx, e.ID)
+		if err != nil {
+			return ChangeOut{}, errors.Wrap(err, "get a tag")
+		}
+		c.Tag = &tg
+	}
+
+	return c, nil
+}
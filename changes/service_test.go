@@ -0,0 +1,91 @@
+package changes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/changes"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/tags"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestSince(t *testing.T) {
+	story := news.Create("news 1", "news body", bareknews.Publish, nil, 1)
+	tag := tags.Create("golang")
+	gone := uuid.New()
+	deleted := uuid.New()
+
+	entries := []changes.Entry{
+		{Seq: 3, Kind: changes.KindNews, ID: story.Post.ID},
+		{Seq: 4, Kind: changes.KindTag, ID: tag.Label.ID},
+		{Seq: 6, Kind: changes.KindNews, ID: gone},
+		{Seq: 7, Kind: changes.KindNews, ID: deleted, Deleted: true},
+	}
+
+	store := &changes.RepositoryMock{
+		GetSinceFunc: func(ctx context.Context, since int64, limit int) ([]changes.Entry, error) {
+			r := make([]changes.Entry, 0)
+			for _, e := range entries {
+				if e.Seq > since && len(r) < limit {
+					r = append(r, e)
+				}
+			}
+			return r, nil
+		},
+	}
+	nwsStore := &news.RepositoryMock{
+		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*news.News, error) {
+			if id != story.Post.ID {
+				return nil, bareknews.ErrDataNotFound
+			}
+			return story, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
+			return tag, nil
+		},
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{}, nil
+		},
+	}
+
+	tagging := tags.CreateSvc(tgStore)
+	svc := changes.CreateSvc(store, news.CreateSvc(nwsStore, tagging), tagging)
+	is := is.New(t)
+
+	page, err := svc.Since(context.TODO(), 0, 0)
+	is.NoErr(err)
+	is.Equal(store.GetSinceCalls()[0].Limit, changes.DefaultLimit+1)
+	is.True(!page.HasMore)
+	is.Equal(page.NextSince, int64(7))
+	// the news that is deleted since the page is read is left out.
+	is.Equal(len(page.Changes), 3)
+	is.Equal(page.Changes[0].News.ID, story.Post.ID)
+	is.Equal(page.Changes[1].Tag.Name, "golang")
+	is.Equal(page.Changes[2].ID, deleted)
+	is.True(page.Changes[2].Deleted)
+	is.True(page.Changes[2].News == nil)
+
+	page, err = svc.Since(context.TODO(), 0, 2)
+	is.NoErr(err)
+	is.True(page.HasMore)
+	is.Equal(page.NextSince, int64(4))
+	is.Equal(len(page.Changes), 2)
+
+	// the token stays the same when there is nothing new.
+	page, err = svc.Since(context.TODO(), 7, 2)
+	is.NoErr(err)
+	is.Equal(page.NextSince, int64(7))
+	is.Equal(len(page.Changes), 0)
+
+	_, err = svc.Since(context.TODO(), -1, changes.MaxLimit+1)
+	errs, ok := err.(validation.Errors)
+	is.True(ok)
+	is.True(errs["since"] != nil)
+	is.True(errs["limit"] != nil)
+}
@@ -9,14 +9,36 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Iiqbal2000/bareknews"
 	_ "github.com/Iiqbal2000/bareknews/docs"
+	"github.com/Iiqbal2000/bareknews/changes"
+	changesdb "github.com/Iiqbal2000/bareknews/changes/db"
+	changesmemory "github.com/Iiqbal2000/bareknews/changes/memory"
+	"github.com/Iiqbal2000/bareknews/media"
 	"github.com/Iiqbal2000/bareknews/news"
 	"github.com/Iiqbal2000/bareknews/pkg/logger"
 	"github.com/Iiqbal2000/bareknews/pkg/sqlite3"
 	"github.com/Iiqbal2000/bareknews/pkg/web"
 	"github.com/Iiqbal2000/bareknews/tags"
+	idempotencydb "github.com/Iiqbal2000/bareknews/pkg/idempotency/db"
+	idempotencymemory "github.com/Iiqbal2000/bareknews/pkg/idempotency/memory"
+	mediadb "github.com/Iiqbal2000/bareknews/media/db"
+	medialocal "github.com/Iiqbal2000/bareknews/media/local"
+	mediamemory "github.com/Iiqbal2000/bareknews/media/memory"
+	medias3 "github.com/Iiqbal2000/bareknews/media/s3"
 	newsdb "github.com/Iiqbal2000/bareknews/news/db"
+	newsmemory "github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/Iiqbal2000/bareknews/stream"
+	streamdb "github.com/Iiqbal2000/bareknews/stream/db"
+	streammemory "github.com/Iiqbal2000/bareknews/stream/memory"
 	tagsdb "github.com/Iiqbal2000/bareknews/tags/db"
+	tagsmemory "github.com/Iiqbal2000/bareknews/tags/memory"
+	"github.com/Iiqbal2000/bareknews/views"
+	viewsdb "github.com/Iiqbal2000/bareknews/views/db"
+	viewsmemory "github.com/Iiqbal2000/bareknews/views/memory"
+	"github.com/Iiqbal2000/bareknews/webhooks"
+	webhooksdb "github.com/Iiqbal2000/bareknews/webhooks/db"
+	webhooksmemory "github.com/Iiqbal2000/bareknews/webhooks/memory"
 	"github.com/ardanlabs/conf/v3"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -67,8 +89,45 @@ func run(log *zap.SugaredLogger) error {
 			ShutdownTimeout time.Duration `conf:"default:20s"`
 			APIHost         string        `conf:"default:0.0.0.0:3333"`
 			DebugHost       string        `conf:"default:0.0.0.0:4000"`
+			IdempotencyTTL  time.Duration `conf:"default:24h"`
+			IdempotencyLock time.Duration `conf:"default:1m"`
 		}
-		DB string `conf:"default:./bareknews.db"`
+		Validation struct {
+			TitleMin     int `conf:"default:5,help:minimum characters of a news title"`
+			TitleMax     int `conf:"default:150,help:maximum characters of a news title"`
+			BodyMaxBytes int `conf:"default:200000,help:maximum bytes of a news body"`
+			TagNameMin   int `conf:"default:1,help:minimum characters of a tag name"`
+			TagNameMax   int `conf:"default:50,help:maximum characters of a tag name"`
+		}
+		Media struct {
+			Driver      string `conf:"default:local,help:storage of the media files; local or s3"`
+			Dir         string `conf:"default:./media,help:directory of the media files of the local driver"`
+			CacheDir    string `conf:"default:./media/cache,help:directory of the resized images"`
+			MaxBytes    int64  `conf:"default:10485760,help:maximum bytes of an uploaded media"`
+			S3Endpoint  string `conf:"help:host and port of the S3 storage"`
+			S3Region    string
+			S3Bucket    string `conf:"default:bareknews"`
+			S3AccessKey string
+			S3SecretKey string `conf:"mask"`
+			S3UseSSL    bool   `conf:"default:true"`
+		}
+		Views struct {
+			FlushInterval time.Duration `conf:"default:30s,help:how often the counted views are written"`
+		}
+		Stream struct {
+			Heartbeat time.Duration `conf:"default:5s,help:time between the heartbeats of the news stream"`
+			Retention time.Duration `conf:"default:24h,help:how long the events are kept for the clients that resume"`
+		}
+		Webhooks struct {
+			Interval    time.Duration `conf:"default:5s,help:how often the due webhook deliveries are sent"`
+			Timeout     time.Duration `conf:"default:10s,help:time limit of a webhook delivery"`
+			MaxAttempts int           `conf:"default:8,help:attempts before a webhook delivery is dead"`
+			BackoffBase time.Duration `conf:"default:30s,help:delay before the first retry of a webhook delivery"`
+			BackoffMax  time.Duration `conf:"default:6h,help:maximum delay between the retries of a webhook delivery"`
+			Retention   time.Duration `conf:"default:168h,help:how long the finished webhook deliveries are kept in the log"`
+		}
+		DB      string `conf:"default:./bareknews.db"`
+		Storage string `conf:"default:sqlite,help:storage backend; sqlite or memory"`
 	}{}
 
 	_, err := conf.Parse(prefix, &cfg)
@@ -105,23 +164,99 @@ func run(log *zap.SugaredLogger) error {
 
 	log.Infow("config of app", "config", out)
 
-	// Starting a database support.
-	dbConn, err := sqlite3.Run(sqlite3.Config{
-		URI: cfg.DB,
-		DropTableFirst: true,
-		Log: log,
-	})
+	limits := bareknews.Limits{
+		TitleMin:     cfg.Validation.TitleMin,
+		TitleMax:     cfg.Validation.TitleMax,
+		BodyMaxBytes: cfg.Validation.BodyMaxBytes,
+		LabelMin:     cfg.Validation.TagNameMin,
+		LabelMax:     cfg.Validation.TagNameMax,
+	}
 
+	err = limits.Validate()
 	if err != nil {
-		return errors.Wrap(err, "failed to connect db")
+		return errors.Wrap(err, "setting the validation limits")
 	}
 
-	defer func() {
-		log.Infow("shutdown the database", "host", cfg.DB)
-		if err := dbConn.Close(); err != nil {
-			log.Errorf("Error shutting down database: %v", err)
+	// Starting a storage support.
+	var newsRepo news.Repository
+	var tagsRepo tags.Repository
+	var idempotencyStore web.IdempotencyStore
+	var mediaRepo media.Repository
+	var viewsRepo views.Repository
+	var streamRepo stream.Repository
+	var changesRepo changes.Repository
+	var webhooksRepo webhooks.Repository
+
+	switch cfg.Storage {
+	case "sqlite":
+		dbConn, err := sqlite3.Run(sqlite3.Config{
+			URI:            cfg.DB,
+			DropTableFirst: true,
+			Log:            log,
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "failed to connect db")
 		}
-	}()
+
+		defer func() {
+			log.Infow("shutdown the database", "host", cfg.DB)
+			if err := dbConn.Close(); err != nil {
+				log.Errorf("Error shutting down database: %v", err)
+			}
+		}()
+
+		newsRepo = newsdb.CreateStore(dbConn)
+		tagsRepo = tagsdb.CreateStore(dbConn)
+		idempotencyStore = idempotencydb.CreateStore(dbConn)
+		mediaRepo = mediadb.CreateStore(dbConn)
+		viewsRepo = viewsdb.CreateStore(dbConn)
+		streamRepo = streamdb.CreateStore(dbConn)
+		changesRepo = changesdb.CreateStore(dbConn)
+		webhooksRepo = webhooksdb.CreateStore(dbConn)
+	case "memory":
+		log.Infow("startup", "status", "using the in-memory storage, data is lost on shutdown")
+
+		changesStore := changesmemory.CreateStore()
+		webhooksStore := webhooksmemory.CreateStore()
+		streamStore := streammemory.CreateStore()
+		newsStore := newsmemory.CreateStore().WithChanges(changesStore).WithOutbox(webhooksStore).WithLog(streamStore)
+		newsRepo = newsStore
+		tagsRepo = tagsmemory.CreateStore().WithNews(newsStore).WithChanges(changesStore)
+		changesRepo = changesStore
+		webhooksRepo = webhooksStore
+		idempotencyStore = idempotencymemory.CreateStore()
+		mediaRepo = mediamemory.CreateStore()
+		viewsRepo = viewsmemory.CreateStore().WithNews(newsStore)
+		streamRepo = streamStore
+	default:
+		return errors.Errorf("unknown storage %q", cfg.Storage)
+	}
+
+	// Starting a media storage support.
+	var mediaStorage media.Storage
+
+	switch cfg.Media.Driver {
+	case "local":
+		mediaStorage, err = medialocal.CreateStorage(cfg.Media.Dir)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the media directory")
+		}
+	case "s3":
+		mediaStorage, err = medias3.CreateStorage(context.Background(), medias3.Config{
+			Endpoint:  cfg.Media.S3Endpoint,
+			Region:    cfg.Media.S3Region,
+			Bucket:    cfg.Media.S3Bucket,
+			AccessKey: cfg.Media.S3AccessKey,
+			SecretKey: cfg.Media.S3SecretKey,
+			UseSSL:    cfg.Media.S3UseSSL,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to connect the media bucket")
+		}
+	default:
+		return errors.Errorf("unknown media driver %q", cfg.Media.Driver)
+	}
 
 	// =========================================================================
 	// Start API Service
@@ -137,6 +272,7 @@ func run(log *zap.SugaredLogger) error {
 		shutdown,
 		web.ContentTypeJSON(),
 		web.CORS(),
+		web.Idempotency(idempotencyStore, cfg.Web.IdempotencyTTL, cfg.Web.IdempotencyLock),
 		web.Errors(log),
 		web.Panics(),
 	)
@@ -145,27 +281,118 @@ func run(log *zap.SugaredLogger) error {
 	// 	httpSwagger.URL("http://localhost:3333/swagger/doc.json"),
 	// ))
 
-	newsDB := newsdb.CreateStore(dbConn)
-	tagsDB := tagsdb.CreateStore(dbConn)
+	tagsSvc := tags.CreateSvc(tagsRepo).WithLimits(limits)
+	broker, err := stream.CreateBroker(context.Background(), streamRepo, tagsSvc, log)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the news stream")
+	}
 
-	tagsSvc := tags.CreateSvc(tagsDB)
-	newsSvc := news.CreateSvc(newsDB, tagsSvc)
+	brokerCtx, stopBroker := context.WithCancel(context.Background())
+	defer stopBroker()
+	go broker.Run(brokerCtx, cfg.Stream.Retention)
+
+	newsSvc := news.CreateSvc(newsRepo, tagsSvc).WithEvents(broker).WithLimits(limits)
 
 	tagsHandler := tags.CreateHandler(tagsSvc, log)
 	newsHandler := news.CreateHandler(newsSvc, log)
 
+	mediaCache, err := medialocal.CreateStorage(cfg.Media.CacheDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the media cache directory")
+	}
+
+	mediaSvc := media.CreateSvc(mediaRepo, mediaStorage, cfg.Media.MaxBytes).WithCache(mediaCache)
+	mediaHandler := media.CreateHandler(mediaSvc, log)
+
+	// Starting the view counter. It is flushed a last time once the server
+	// stops taking requests; the defer runs before the database is closed.
+	viewCounter := views.CreateCounter(viewsRepo)
+	counterCtx, stopCounter := context.WithCancel(context.Background())
+	go viewCounter.Run(counterCtx, cfg.Views.FlushInterval, log)
+
+	defer func() {
+		stopCounter()
+		log.Infow("flush the views", "pending", viewCounter.Pending())
+		if err := viewCounter.Flush(context.Background()); err != nil {
+			log.Errorf("Error flushing the views: %v", err)
+		}
+	}()
+
+	// Starting the webhook worker. It is stopped before the database is
+	// closed; a delivery that it cuts off is sent again on the next start.
+	webhookWorker, err := webhooks.CreateWorker(
+		webhooksRepo,
+		&http.Client{Timeout: cfg.Webhooks.Timeout},
+		webhooks.Retry{
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			BaseDelay:   cfg.Webhooks.BackoffBase,
+			MaxDelay:    cfg.Webhooks.BackoffMax,
+		},
+		log,
+	)
+	if err != nil {
+		return errors.Wrap(err, "invalid webhook retry")
+	}
+
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+
+	go func() {
+		defer close(workerDone)
+		webhookWorker.Run(workerCtx, cfg.Webhooks.Interval, cfg.Webhooks.Retention)
+	}()
+
+	defer func() {
+		stopWorker()
+		<-workerDone
+	}()
+
+	webhooksHandler := webhooks.CreateHandler(webhooks.CreateSvc(webhooksRepo), log)
+
+	viewsSvc := views.CreateSvc(viewCounter, viewsRepo, newsSvc)
+	viewsHandler := views.CreateHandler(viewsSvc, log)
+	changesHandler := changes.CreateHandler(changes.CreateSvc(changesRepo, newsSvc, tagsSvc), log)
+	streamHandler := stream.CreateHandler(broker, cfg.Stream.Heartbeat, cfg.Web.WriteTimeout, log)
+
 	app.Handle("POST", "/api/news", newsHandler.Create)
+	app.Handle("POST", "/api/news/bulk", newsHandler.Bulk)
 	app.Handle("GET", "/api/news", newsHandler.GetAll)
 	app.Handle("GET", "/api/news/{newsId}", newsHandler.GetById)
+	app.Handle("GET", "/api/news/trending", viewsHandler.GetTrending)
+	app.Handle("GET", "/api/news/{newsId}/related", newsHandler.GetRelated)
+	app.Handle("POST", "/api/news/{newsId}/views", viewsHandler.Count)
 	app.Handle("PUT", "/api/news/{newsId}", newsHandler.Update)
+	app.Handle("PATCH", "/api/news/{newsId}", newsHandler.Patch)
 	app.Handle("DELETE", "/api/news/{newsId}", newsHandler.Delete)
 
 	app.Handle("POST", "/api/tags", tagsHandler.Create)
 	app.Handle("GET", "/api/tags", tagsHandler.GetAll)
+	app.Handle("GET", "/api/tags/cloud", tagsHandler.GetCloud)
+	app.Handle("GET", "/api/tags/suggest", tagsHandler.Suggest)
+	app.Handle("GET", "/api/tags/by-slug/{slug}", newsHandler.GetTopicPage)
 	app.Handle("GET", "/api/tags/{tagId}", tagsHandler.GetById)
 	app.Handle("PUT", "/api/tags/{tagId}", tagsHandler.Update)
+	app.Handle("PATCH", "/api/tags/{tagId}", tagsHandler.Patch)
+	app.Handle("POST", "/api/tags/{tagId}/merge", tagsHandler.Merge)
 	app.Handle("DELETE", "/api/tags/{tagId}", tagsHandler.Delete)
 
+	app.Handle("GET", "/api/stream/news", streamHandler.Stream)
+	app.Handle("GET", "/api/changes", changesHandler.GetChanges)
+
+	app.Handle("POST", "/api/webhooks", webhooksHandler.Create)
+	app.Handle("GET", "/api/webhooks", webhooksHandler.GetAll)
+	app.Handle("GET", "/api/webhooks/{webhookId}", webhooksHandler.GetById)
+	app.Handle("PUT", "/api/webhooks/{webhookId}", webhooksHandler.Update)
+	app.Handle("DELETE", "/api/webhooks/{webhookId}", webhooksHandler.Delete)
+	app.Handle("GET", "/api/webhooks/{webhookId}/deliveries", webhooksHandler.GetDeliveries)
+
+	app.Handle("POST", "/api/media", mediaHandler.Upload)
+	app.Handle("GET", "/api/media", mediaHandler.GetAll)
+	app.Handle("GET", "/api/media/{mediaId}", mediaHandler.GetById)
+	app.Handle("DELETE", "/api/media/{mediaId}", mediaHandler.Delete)
+	app.Handle("GET", "/media/{mediaId}", mediaHandler.Serve)
+	app.Handle("GET", "/media/{mediaId}/{width}", mediaHandler.ServeSize)
+
 	// Construct a server to service the requests against the mux.
 	api := http.Server{
 		Addr:         cfg.Web.APIHost,
@@ -176,6 +403,10 @@ func run(log *zap.SugaredLogger) error {
 		ErrorLog:     zap.NewStdLog(log.Desugar()),
 	}
 
+	// The open streams never go idle, so they are ended when the shutdown
+	// starts.
+	api.RegisterOnShutdown(broker.Close)
+
 	// Make a channel to listen for errors coming from the listener. Use a
 	// buffered channel so the goroutine can exit if we don't collect this error.
 	serverErrors := make(chan error, 1)
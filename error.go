@@ -5,20 +5,43 @@ import (
 	"github.com/pkg/errors"
 )
 
+// The domain errors below are returned by every repository and passed
+// through the services unchanged, so they can be matched with errors.Is
+// at any layer.
 var (
 	ErrInternalServer   = errors.New("internal server error")
-	ErrDataAlreadyExist = validation.NewError(
+	ErrDataAlreadyExist = newError(
 		"data_already_exist",
 		"the data already exist",
 	)
-	ErrDataNotFound = validation.NewError(
+	ErrDataNotFound = newError(
 		"data_not_found",
 		"the data is not found",
 	)
-	ErrInvalidJSON = validation.NewError(
+	ErrInvalidJSON = newError(
 		"invalid_json",
 		"the JSON syntax is invalid",
 	)
+	ErrRolledBack = newError(
+		"rolled_back",
+		"the change is rolled back because another change failed",
+	)
+	ErrTooLarge = newError(
+		"too_large",
+		"the file is too large",
+	)
+	ErrUnsupportedType = newError(
+		"unsupported_type",
+		"the type of the file is not supported",
+	)
 )
 
 const SubStrUniqueConstraint = "UNIQUE constraint failed:"
+
+// newError creates a validation error that can be matched with errors.Is.
+// A plain validation.ErrorObject holds a map, so it is not comparable and
+// errors.Is never matches it.
+func newError(code, message string) validation.Error {
+	err := validation.NewError(code, message).(validation.ErrorObject)
+	return &err
+}
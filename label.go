@@ -11,8 +11,14 @@ type Label struct {
 	Name string
 }
 
+// Validate checks the label against the default limits.
 func (l Label) Validate() error {
+	return l.ValidateWith(DefaultLimits)
+}
+
+// ValidateWith checks the label against the limits.
+func (l Label) ValidateWith(limits Limits) error {
 	return validation.ValidateStruct(&l,
-		validation.Field(&l.Name, validation.Required, validation.Length(1, 20)),
+		validation.Field(&l.Name, lengthRules(limits.LabelMin, limits.LabelMax)...),
 	)
 }
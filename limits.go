@@ -0,0 +1,47 @@
+package bareknews
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Limits are the sizes that the posts and the labels must fit in. The
+// titles and the names are counted in characters, the bodies in bytes.
+type Limits struct {
+	TitleMin     int
+	TitleMax     int
+	BodyMaxBytes int
+	LabelMin     int
+	LabelMax     int
+}
+
+// DefaultLimits are the limits of the validations that aren't given any.
+var DefaultLimits = Limits{
+	TitleMin:     5,
+	TitleMax:     150,
+	BodyMaxBytes: 200000,
+	LabelMin:     1,
+	LabelMax:     50,
+}
+
+func (l Limits) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.TitleMin, validation.Min(1)),
+		validation.Field(&l.TitleMax, validation.Min(l.TitleMin)),
+		validation.Field(&l.BodyMaxBytes, validation.Min(1)),
+		validation.Field(&l.LabelMin, validation.Min(1)),
+		validation.Field(&l.LabelMax, validation.Min(l.LabelMin)),
+	)
+}
+
+// lengthRules requires a value of min to max characters. Every message
+// carries the limits, so the clients can tell what is accepted.
+func lengthRules(min, max int) []validation.Rule {
+	msg := fmt.Sprintf("must be between %d and %d characters long", min, max)
+
+	return []validation.Rule{
+		validation.Required.Error("cannot be blank; it " + msg),
+		validation.RuneLength(min, max).Error("the length " + msg),
+	}
+}
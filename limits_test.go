@@ -0,0 +1,55 @@
+package bareknews_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestLimits(t *testing.T) {
+	t.Run("applies the limits", func(t *testing.T) {
+		is := is.New(t)
+
+		l := bareknews.Limits{
+			TitleMin:     3,
+			TitleMax:     10,
+			BodyMaxBytes: 8,
+			LabelMin:     2,
+			LabelMax:     4,
+		}
+		is.NoErr(l.Validate())
+
+		is.NoErr(bareknews.Post{ID: uuid.New(), Title: "abc", Body: "12345678"}.ValidateWith(l))
+
+		err := bareknews.Post{ID: uuid.New(), Title: strings.Repeat("a", 11), Body: "123456789"}.ValidateWith(l)
+		errs, ok := err.(validation.Errors)
+		is.True(ok)
+		is.True(strings.Contains(errs["Title"].Error(), "between 3 and 10"))
+		is.True(strings.Contains(errs["Body"].Error(), "at most 8 bytes"))
+
+		err = bareknews.Label{ID: uuid.New(), Name: "a"}.ValidateWith(l)
+		errs, ok = err.(validation.Errors)
+		is.True(ok)
+		is.True(strings.Contains(errs["Name"].Error(), "between 2 and 4"))
+
+		// the limits of one validation don't leak into the others.
+		is.NoErr(bareknews.Label{ID: uuid.New(), Name: "a"}.Validate())
+	})
+
+	t.Run("counts characters, not bytes", func(t *testing.T) {
+		is := is.New(t)
+
+		is.NoErr(bareknews.Label{ID: uuid.New(), Name: strings.Repeat("é", bareknews.DefaultLimits.LabelMax)}.Validate())
+	})
+
+	t.Run("rejects inverted limits", func(t *testing.T) {
+		is := is.New(t)
+
+		err := bareknews.Limits{TitleMin: 10, TitleMax: 5, BodyMaxBytes: 1, LabelMin: 1, LabelMax: 1}.Validate()
+		is.True(err != nil)
+	})
+}
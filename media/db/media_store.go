@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media"
+	"github.com/google/uuid"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/media/db")
+
+// mediaColumns are the columns that scanMedia reads.
+var mediaColumns = []string{
+	"id", "storage_key", "filename", "mime_type", "size",
+	"width", "height", "alt", "credit", "date_created",
+}
+
+type Store struct {
+	conn *sql.DB
+}
+
+// Ensure Store does implement media.Repository.
+var _ media.Repository = Store{}
+
+func CreateStore(conn *sql.DB) Store {
+	return Store{conn: conn}
+}
+
+func (s Store) Save(ctx context.Context, m media.Media) error {
+	ctx, span := tracer.Start(ctx, "media.db.Save")
+	defer span.End()
+
+	builder := sqlbuilder.NewInsertBuilder()
+	builder.InsertInto("media")
+	builder.Cols(mediaColumns...)
+	builder.Values(
+		m.ID,
+		m.Key,
+		m.Filename,
+		m.MimeType,
+		m.Size,
+		m.Width,
+		m.Height,
+		m.Alt,
+		m.Credit,
+		m.DateCreated,
+	)
+	query, args := builder.Build()
+
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		if possibleErr, ok := err.(sqlite3.Error); ok {
+			if possibleErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+				possibleErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+				return bareknews.ErrDataAlreadyExist
+			}
+		}
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
+
+func (s Store) GetById(ctx context.Context, id uuid.UUID) (media.Media, error) {
+	ctx, span := tracer.Start(ctx, "media.db.GetById")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(mediaColumns...)
+	builder.From("media")
+	builder.Where(builder.Equal("id", id))
+
+	query, args := builder.Build()
+
+	m, err := scanMedia(s.conn.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return media.Media{}, bareknews.ErrDataNotFound
+		}
+		return media.Media{}, errors.Wrap(err, "scan a media")
+	}
+
+	return m, nil
+}
+
+func (s Store) GetAll(ctx context.Context, after *media.Cursor, limit int) ([]media.Media, error) {
+	ctx, span := tracer.Start(ctx, "media.db.GetAll")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(mediaColumns...)
+	builder.From("media")
+
+	if after != nil {
+		builder.Where(builder.Or(
+			builder.LessThan("date_created", after.DateCreated),
+			builder.And(
+				builder.Equal("date_created", after.DateCreated),
+				builder.LessThan("id", after.ID),
+			),
+		))
+	}
+
+	builder.OrderBy("date_created DESC", "id DESC")
+	builder.Limit(limit)
+
+	query, args := builder.Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []media.Media{}, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	result := make([]media.Media, 0)
+
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return []media.Media{}, errors.Wrap(err, "scan a media")
+		}
+
+		result = append(result, m)
+	}
+
+	if rows.Err() != nil {
+		return []media.Media{}, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return result, nil
+}
+
+func (s Store) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "media.db.Delete")
+	defer span.End()
+
+	builder := sqlbuilder.NewDeleteBuilder()
+	builder.DeleteFrom("media")
+	builder.Where(builder.Equal("id", id))
+
+	query, args := builder.Build()
+
+	res, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "count the deleted rows")
+	}
+
+	if n == 0 {
+		return bareknews.ErrDataNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMedia scans the mediaColumns of a row.
+func scanMedia(row scanner) (media.Media, error) {
+	m := media.Media{}
+
+	err := row.Scan(
+		&m.ID,
+		&m.Key,
+		&m.Filename,
+		&m.MimeType,
+		&m.Size,
+		&m.Width,
+		&m.Height,
+		&m.Alt,
+		&m.Credit,
+		&m.DateCreated,
+	)
+
+	return m, err
+}
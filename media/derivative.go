@@ -0,0 +1,343 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/image/draw"
+)
+
+// Widths are the widths, in pixels, that an image can be resized to.
+// Any other width is rejected, so the cache can't be filled with every
+// size a client asks for.
+var Widths = []int{160, 320, 640, 1024, 1600}
+
+const (
+	// MaxPixels is the maximum number of pixels of an uploaded image. A
+	// derivative decodes the whole image, so a small file of a huge image
+	// would take a lot of memory.
+	MaxPixels = 50 * 1000 * 1000
+	// JPEGQuality is the quality of the JPEG derivatives.
+	JPEGQuality = 85
+)
+
+// ErrUnknownWidth is returned when a derivative is asked in a width that
+// is not one of Widths.
+var ErrUnknownWidth = validation.NewError(
+	"validation_unknown_width",
+	"must be one of "+joinWidths(),
+)
+
+// SizeOut is a derivative of an image.
+type SizeOut struct {
+	Width int    `json:"width"`
+	URL   string `json:"url"`
+}
+
+// sizesOf returns the derivatives that are smaller than the image. The
+// images are never scaled up, so a wider derivative is the image itself.
+func sizesOf(m Media) []SizeOut {
+	r := make([]SizeOut, 0, len(Widths))
+
+	for _, w := range Widths {
+		if w < m.Width {
+			r = append(r, SizeOut{Width: w, URL: fmt.Sprintf("/media/%s/%d", m.ID, w)})
+		}
+	}
+
+	return r
+}
+
+// Derive returns the image of the media resized to the width, with its
+// MIME type. The JPEG images give JPEG derivatives and the others PNG
+// ones. A derivative is made on the first request and then read from the
+// cache, when the service has one. The caller must close the file.
+func (s Service) Derive(ctx context.Context, id uuid.UUID, width int) (string, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "media.Derive")
+	defer span.End()
+
+	if !containsWidth(width) {
+		return "", nil, validation.Errors{"width": ErrUnknownWidth}
+	}
+
+	m, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	mimeType := derivedType(m.MimeType)
+	key := derivativeKey(m, width)
+
+	if s.cache != nil {
+		f, err := s.cache.Open(ctx, key)
+		if err == nil {
+			return mimeType, f, nil
+		}
+		if !errors.Is(err, bareknews.ErrDataNotFound) {
+			return "", nil, errors.Wrap(err, "open the cached derivative")
+		}
+	}
+
+	data, err := s.derive(ctx, m, width)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if s.cache != nil {
+		// Two first requests may both make the derivative; the storage
+		// replaces the file whole, so either one wins.
+		err = s.cache.Put(ctx, key, bytes.NewReader(data), int64(len(data)), mimeType)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "cache the derivative")
+		}
+	}
+
+	return mimeType, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// derive decodes the image, turns it the way its EXIF orientation says,
+// resizes it and encodes it again. The new file has no metadata, so the
+// EXIF data of the upload, such as the location, are dropped.
+func (s Service) derive(ctx context.Context, m Media, width int) ([]byte, error) {
+	f, err := s.storage.Open(ctx, m.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "read the file")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "decode the image")
+	}
+
+	orientation := 1
+	if m.MimeType == "image/jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	img := orient(resize(src, width, orientation >= 5), orientation)
+
+	var b bytes.Buffer
+
+	if derivedType(m.MimeType) == "image/jpeg" {
+		err = jpeg.Encode(&b, img, &jpeg.Options{Quality: JPEGQuality})
+	} else {
+		err = png.Encode(&b, img)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "encode the derivative")
+	}
+
+	return b.Bytes(), nil
+}
+
+// deleteDerivatives removes the cached derivatives of the media.
+func (s Service) deleteDerivatives(ctx context.Context, m Media) error {
+	if s.cache == nil {
+		return nil
+	}
+
+	for _, w := range Widths {
+		err := s.cache.Delete(ctx, derivativeKey(m, w))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func derivativeKey(m Media, width int) string {
+	return fmt.Sprintf("%s-%d%s", m.ID, width, Types[derivedType(m.MimeType)])
+}
+
+func derivedType(mimeType string) string {
+	if mimeType == "image/jpeg" {
+		return mimeType
+	}
+	return "image/png"
+}
+
+// resize scales src down so that it is width pixels wide once it is
+// turned; swapped tells whether turning it swaps its sides. The ratio is
+// kept and an image narrower than the width is left as is.
+func resize(src image.Image, width int, swapped bool) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if swapped {
+		w, h = h, w
+	}
+
+	if width >= w {
+		return src
+	}
+
+	height := h * width / w
+	if height < 1 {
+		height = 1
+	}
+
+	dw, dh := width, height
+	if swapped {
+		dw, dh = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	return dst
+}
+
+// orient turns src the way an EXIF orientation from 2 to 8 says, so it
+// shows upright without its metadata.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // upside down, mirrored
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // turned clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // turned counterclockwise
+				dx, dy = y, w-1-x
+			}
+
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	return dst
+}
+
+// exifOrientation returns the EXIF orientation of a JPEG file, from 1 to
+// 8, or 1 when it has none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// The segments before the image data are a marker and a length that
+	// counts itself.
+	for i := 2; i+4 <= len(data); {
+		marker := data[i+1]
+		if data[i] != 0xFF || marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+
+		i += 2 + size
+	}
+
+	return 1
+}
+
+// tiffOrientation reads the orientation tag of the first IFD of the TIFF
+// structure of an EXIF segment.
+func tiffOrientation(t []byte) int {
+	if len(t) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+
+	switch string(t[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(t[4:]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 1
+	}
+
+	entries := int(order.Uint16(t[ifd:]))
+
+	for k := 0; k < entries; k++ {
+		e := ifd + 2 + k*12
+		if e+12 > len(t) {
+			return 1
+		}
+
+		if order.Uint16(t[e:]) == 0x0112 {
+			o := int(order.Uint16(t[e+8:]))
+			if o < 1 || o > 8 {
+				return 1
+			}
+			return o
+		}
+	}
+
+	return 1
+}
+
+func containsWidth(width int) bool {
+	for _, w := range Widths {
+		if w == width {
+			return true
+		}
+	}
+	return false
+}
+
+func joinWidths() string {
+	r := make([]string, 0, len(Widths))
+	for _, w := range Widths {
+		r = append(r, strconv.Itoa(w))
+	}
+	return strings.Join(r, ", ")
+}
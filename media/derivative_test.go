@@ -0,0 +1,214 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+// mapStorage returns a storage that keeps the files in a map.
+func mapStorage() *media.StorageMock {
+	files := make(map[string][]byte)
+
+	return &media.StorageMock{
+		PutFunc: func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+			b, err := io.ReadAll(r)
+			files[key] = b
+			return err
+		},
+		OpenFunc: func(ctx context.Context, key string) (io.ReadCloser, error) {
+			b, ok := files[key]
+			if !ok {
+				return nil, bareknews.ErrDataNotFound
+			}
+			return io.NopCloser(bytes.NewReader(b)), nil
+		},
+		DeleteFunc: func(ctx context.Context, key string) error {
+			delete(files, key)
+			return nil
+		},
+	}
+}
+
+// upload stores the file in a service with a cache and returns them.
+func upload(t *testing.T, file []byte) (media.Service, *media.StorageMock, media.MediaOut) {
+	t.Helper()
+
+	saved := make(map[uuid.UUID]media.Media)
+	store := &media.RepositoryMock{
+		SaveFunc: func(ctx context.Context, m media.Media) error {
+			saved[m.ID] = m
+			return nil
+		},
+		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (media.Media, error) {
+			m, ok := saved[id]
+			if !ok {
+				return media.Media{}, bareknews.ErrDataNotFound
+			}
+			return m, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			delete(saved, id)
+			return nil
+		},
+	}
+	cache := mapStorage()
+
+	svc := media.CreateSvc(store, mapStorage(), 0).WithCache(cache)
+	out, err := svc.Upload(context.TODO(), media.UploadIn{File: bytes.NewReader(file), Filename: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return svc, cache, out
+}
+
+func decode(t *testing.T, f io.ReadCloser) (image.Image, []byte) {
+	t.Helper()
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return img, b
+}
+
+func TestDerive(t *testing.T) {
+	t.Run("image should be resized and cached", func(t *testing.T) {
+		svc, cache, out := upload(t, pngOf(800, 400))
+		is := is.New(t)
+
+		is.Equal(out.Sizes, []media.SizeOut{
+			{Width: 160, URL: "/media/" + out.ID.String() + "/160"},
+			{Width: 320, URL: "/media/" + out.ID.String() + "/320"},
+			{Width: 640, URL: "/media/" + out.ID.String() + "/640"},
+		})
+
+		mimeType, f, err := svc.Derive(context.TODO(), out.ID, 320)
+		is.NoErr(err)
+		is.Equal(mimeType, "image/png")
+
+		img, _ := decode(t, f)
+		is.Equal(img.Bounds().Dx(), 320)
+		is.Equal(img.Bounds().Dy(), 160)
+		is.Equal(len(cache.PutCalls()), 1)
+
+		_, f, err = svc.Derive(context.TODO(), out.ID, 320)
+		is.NoErr(err)
+		f.Close()
+		is.Equal(len(cache.PutCalls()), 1)
+
+		is.NoErr(svc.Delete(context.TODO(), out.ID))
+		_, err = cache.Open(context.TODO(), cache.PutCalls()[0].Key)
+		is.Equal(err, bareknews.ErrDataNotFound)
+	})
+
+	t.Run("image should not be scaled up", func(t *testing.T) {
+		svc, _, out := upload(t, pngOf(100, 50))
+		is := is.New(t)
+
+		is.Equal(len(out.Sizes), 0)
+
+		_, f, err := svc.Derive(context.TODO(), out.ID, 1600)
+		is.NoErr(err)
+
+		img, _ := decode(t, f)
+		is.Equal(img.Bounds().Dx(), 100)
+	})
+
+	t.Run("width out of the list should be rejected", func(t *testing.T) {
+		svc, _, out := upload(t, pngOf(100, 50))
+		is := is.New(t)
+
+		_, _, err := svc.Derive(context.TODO(), out.ID, 321)
+		errs, ok := err.(validation.Errors)
+		is.True(ok)
+		is.Equal(errs["width"], media.ErrUnknownWidth)
+	})
+
+	t.Run("unknown media should be not found", func(t *testing.T) {
+		svc, _, _ := upload(t, pngOf(100, 50))
+		is := is.New(t)
+
+		_, _, err := svc.Derive(context.TODO(), uuid.New(), 320)
+		is.Equal(err, bareknews.ErrDataNotFound)
+	})
+
+	t.Run("jpeg should be turned upright without its EXIF data", func(t *testing.T) {
+		// a wide image with a white left half, stored turned: its
+		// orientation tells to turn it clockwise.
+		src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 40; x++ {
+				c := color.RGBA{A: 255}
+				if x < 20 {
+					c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+				}
+				src.Set(x, y, c)
+			}
+		}
+
+		svc, _, out := upload(t, jpegWithOrientation(t, src, 6))
+		is := is.New(t)
+
+		// the sizes are the upright ones.
+		is.Equal(out.Width, 20)
+		is.Equal(out.Height, 40)
+
+		mimeType, f, err := svc.Derive(context.TODO(), out.ID, 160)
+		is.NoErr(err)
+		is.Equal(mimeType, "image/jpeg")
+
+		img, b := decode(t, f)
+		is.Equal(img.Bounds().Dx(), 20)
+		is.Equal(img.Bounds().Dy(), 40)
+		// the white half is now on top.
+		top, _, _, _ := img.At(10, 5).RGBA()
+		bottom, _, _, _ := img.At(10, 35).RGBA()
+		is.True(top > 0xf000)
+		is.True(bottom < 0x1000)
+		is.True(!bytes.Contains(b, []byte("Exif")))
+	})
+}
+
+// jpegWithOrientation encodes img as a JPEG file with an EXIF segment
+// that holds the orientation.
+func jpegWithOrientation(t *testing.T, img image.Image, orientation byte) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // big endian header, first IFD at 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // orientation, a short
+		0, 0, 0, 0, // no next IFD
+	}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(segment) + 2
+
+	file := []byte{0xFF, 0xD8, 0xFF, 0xE1, byte(size >> 8), byte(size)}
+	file = append(file, segment...)
+
+	return append(file, b.Bytes()[2:]...)
+}
@@ -0,0 +1,253 @@
+package media
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// formOverhead is the room for the other parts and the boundaries of
+	// an upload form, on top of the size limit of the file.
+	formOverhead = 1 << 20
+	// formMemory is the part of an upload form that is kept in memory; the
+	// rest goes to temporary files.
+	formMemory = 8 << 20
+)
+
+type handler struct {
+	service Service
+	log     *zap.SugaredLogger
+}
+
+func CreateHandler(svc Service, log *zap.SugaredLogger) handler {
+	return handler{service: svc, log: log}
+}
+
+// UploadMedia godoc
+// @Summary      Upload a media
+// @Description  Add an image to the media library. Its type is sniffed from the content and must be JPEG, PNG, GIF or WebP.
+// @Tags         media
+// @Accept       mpfd
+// @Produce      json
+// @Param        file    formData  file    true   "the image"
+// @Param        alt     formData  string  false  "alternative text"
+// @Param        credit  formData  string  false  "author or source of the image"
+// @Success      201  {object}  web.RespBody{data=MediaOut} "The new media"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      413  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      415  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /media [post]
+func (h handler) Upload(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	limit := h.service.MaxSize() + formOverhead
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+	err := r.ParseMultipartForm(formMemory)
+	if err != nil {
+		if r.ContentLength > limit || strings.Contains(err.Error(), "request body too large") {
+			return bareknews.ErrTooLarge
+		}
+		return web.NewRequestError(errors.New("failed to parse the multipart form"), http.StatusBadRequest)
+	}
+
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return validation.Errors{"file": validation.ErrRequired}
+	}
+
+	defer file.Close()
+
+	m, err := h.service.Upload(ctx, UploadIn{
+		File:     file,
+		Filename: header.Filename,
+		Alt:      r.FormValue("alt"),
+		Credit:   r.FormValue("credit"),
+	})
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully uploading a media",
+		Data:    m,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusCreated)
+}
+
+// GetAllMedia godoc
+// @Summary      Get all media
+// @Description  Get a page of the media library, the newest first
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Param   cursor      query     string     false  "next_cursor of the previous page"
+// @Param   limit      query     int     false  "number of media"	minimum(1) maximum(100) default(20)
+// @Success      200  {object}  web.PageResponse{data=[]MediaOut} "Page of media"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /media [get]
+func (h handler) GetAll(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query()
+	limit := 0
+
+	if rawLimit := strings.TrimSpace(q.Get("limit")); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the limit"), http.StatusBadRequest)
+		}
+	}
+
+	page, err := h.service.List(ctx, strings.TrimSpace(q.Get("cursor")), limit)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.PageResponse{
+		Message: "Successfully getting all media",
+		Data:    page.Items,
+		Paging: web.Paging{
+			Limit:      page.Limit,
+			NextCursor: page.NextCursor,
+		},
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// GetMediaById godoc
+// @Summary      Get a media
+// @Description  Get the metadata of a media by id
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Media ID"  Format(uuid)
+// @Success      200  {object}  web.RespBody{data=MediaOut} "The media"
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /media/{id} [get]
+func (h handler) GetById(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "mediaId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	m, err := h.service.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting a media",
+		Data:    m,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// DeleteMedia godoc
+// @Summary      Delete a media
+// @Description  Delete a media and its file by id
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Media ID"  Format(uuid)
+// @Success      200  {object}  web.RespBody{data=object}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /media/{id} [delete]
+func (h handler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "mediaId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	err = h.service.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully deleting a media",
+		Data:    struct{}{},
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// Serve sends the file of a media. The files never change, so they are
+// cached for a year.
+func (h handler) Serve(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "mediaId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	m, f, err := h.service.Open(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	w.Header().Set("Content-Length", strconv.FormatInt(m.Size, 10))
+	setFileHeaders(w, m.MimeType)
+
+	_, err = io.Copy(w, f)
+	if err != nil {
+		h.log.Errorw("serve a media", "id", id, "ERROR", err)
+	}
+
+	return nil
+}
+
+// ServeSize sends the image of a media resized to a width of Widths. Like
+// the files, the derivatives never change.
+func (h handler) ServeSize(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "mediaId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	width, err := strconv.Atoi(chi.URLParam(r, "width"))
+	if err != nil {
+		return validation.Errors{"width": ErrUnknownWidth}
+	}
+
+	mimeType, f, err := h.service.Derive(ctx, id, width)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	setFileHeaders(w, mimeType)
+
+	_, err = io.Copy(w, f)
+	if err != nil {
+		h.log.Errorw("serve a media size", "id", id, "width", width, "ERROR", err)
+	}
+
+	return nil
+}
+
+func setFileHeaders(w http.ResponseWriter, mimeType string) {
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+}
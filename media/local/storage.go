@@ -0,0 +1,117 @@
+// Package local keeps the media files in a directory of the local disk.
+package local
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/media/local")
+
+// Storage is a media.Storage that writes every file to the directory,
+// named after its key.
+type Storage struct {
+	dir string
+}
+
+// Ensure Storage does implement media.Storage.
+var _ media.Storage = Storage{}
+
+// CreateStorage returns a storage in dir, which is created when it
+// doesn't exist.
+func CreateStorage(dir string) (Storage, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return Storage{}, errors.Wrap(err, "create the media directory")
+	}
+
+	return Storage{dir: dir}, nil
+}
+
+// Put writes the file to a temporary file first, so a file is never
+// seen half written.
+func (s Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, span := tracer.Start(ctx, "media.local.Put")
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return errors.Wrap(err, "create a temporary file")
+	}
+
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrap(err, "write the file")
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return errors.Wrap(err, "move the file")
+	}
+
+	return nil
+}
+
+func (s Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	_, span := tracer.Start(ctx, "media.local.Open")
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, bareknews.ErrDataNotFound
+		}
+		return nil, errors.Wrap(err, "open the file")
+	}
+
+	return f, nil
+}
+
+func (s Storage) Delete(ctx context.Context, key string) error {
+	_, span := tracer.Start(ctx, "media.local.Delete")
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "remove the file")
+	}
+
+	return nil
+}
+
+// path returns the path of the file of the key. A key is a plain file
+// name, so it can't point outside of the directory.
+func (s Storage) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || key == "." || key == ".." {
+		return "", errors.Errorf("invalid key %q", key)
+	}
+
+	return filepath.Join(s.dir, key), nil
+}
@@ -0,0 +1,38 @@
+package local_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media/local"
+	"github.com/matryer/is"
+)
+
+func TestStorage(t *testing.T) {
+	storage, err := local.CreateStorage(t.TempDir())
+	is := is.New(t)
+	is.NoErr(err)
+
+	err = storage.Put(context.TODO(), "a.png", strings.NewReader("content"), 7, "image/png")
+	is.NoErr(err)
+
+	f, err := storage.Open(context.TODO(), "a.png")
+	is.NoErr(err)
+	got, err := io.ReadAll(f)
+	is.NoErr(err)
+	is.NoErr(f.Close())
+	is.Equal(string(got), "content")
+
+	is.NoErr(storage.Delete(context.TODO(), "a.png"))
+	_, err = storage.Open(context.TODO(), "a.png")
+	is.Equal(err, bareknews.ErrDataNotFound)
+	// deleting a missing file is not an error.
+	is.NoErr(storage.Delete(context.TODO(), "a.png"))
+
+	// the keys can't leave the directory.
+	_, err = storage.Open(context.TODO(), "../a.png")
+	is.True(err != nil)
+}
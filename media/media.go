@@ -0,0 +1,75 @@
+package media
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+// The maximum lengths of the texts of a media, in characters.
+const (
+	MaxFilenameLength = 255
+	MaxAltLength      = 300
+	MaxCreditLength   = 200
+)
+
+// Types maps the accepted MIME types to the extension of their files.
+var Types = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Media is an aggregate that represents a file of the media library.
+type Media struct {
+	ID uuid.UUID
+	// Key locates the file in the storage.
+	Key      string
+	Filename string
+	MimeType string
+	// Size is in bytes, Width and Height in pixels.
+	Size        int64
+	Width       int
+	Height      int
+	Alt         string
+	Credit      string
+	DateCreated int64
+}
+
+func Create(filename, mimeType string, size int64, width, height int, alt, credit string, timeNowUnix int64) *Media {
+	id := uuid.New()
+
+	return &Media{
+		ID:          id,
+		Key:         id.String() + Types[mimeType],
+		Filename:    filename,
+		MimeType:    mimeType,
+		Size:        size,
+		Width:       width,
+		Height:      height,
+		Alt:         alt,
+		Credit:      credit,
+		DateCreated: timeNowUnix,
+	}
+}
+
+func (m Media) Validate() error {
+	types := make([]interface{}, 0, len(Types))
+	for t := range Types {
+		types = append(types, t)
+	}
+
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Filename, validation.RuneLength(0, MaxFilenameLength)),
+		validation.Field(&m.MimeType, validation.Required, validation.In(types...)),
+		validation.Field(&m.Size, validation.Required),
+		validation.Field(&m.Alt, validation.RuneLength(0, MaxAltLength).Error(
+			fmt.Sprintf("the length must be at most %d characters", MaxAltLength),
+		)),
+		validation.Field(&m.Credit, validation.RuneLength(0, MaxCreditLength).Error(
+			fmt.Sprintf("the length must be at most %d characters", MaxCreditLength),
+		)),
+	)
+}
@@ -0,0 +1,234 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package media
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sync"
+)
+
+// Ensure, that RepositoryMock does implement Repository.
+// If this is not the case, regenerate this file with moq.
+var _ Repository = &RepositoryMock{}
+
+// RepositoryMock is a mock implementation of Repository.
+//
+// 	func TestSomethingThatUsesRepository(t *testing.T) {
+//
+// 		// make and configure a mocked Repository
+// 		mockedRepository := &RepositoryMock{
+// 			DeleteFunc: func(contextMoqParam context.Context, uUID uuid.UUID) error {
+// 				panic("mock out the Delete method")
+// 			},
+// 			GetAllFunc: func(ctx context.Context, after *Cursor, limit int) ([]Media, error) {
+// 				panic("mock out the GetAll method")
+// 			},
+// 			GetByIdFunc: func(contextMoqParam context.Context, uUID uuid.UUID) (Media, error) {
+// 				panic("mock out the GetById method")
+// 			},
+// 			SaveFunc: func(contextMoqParam context.Context, media Media) error {
+// 				panic("mock out the Save method")
+// 			},
+// 		}
+//
+// 		// use mockedRepository in code that requires Repository
+// 		// and then make assertions.
+//
+// 	}
+type RepositoryMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(contextMoqParam context.Context, uUID uuid.UUID) error
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(ctx context.Context, after *Cursor, limit int) ([]Media, error)
+
+	// GetByIdFunc mocks the GetById method.
+	GetByIdFunc func(contextMoqParam context.Context, uUID uuid.UUID) (Media, error)
+
+	// SaveFunc mocks the Save method.
+	SaveFunc func(contextMoqParam context.Context, media Media) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// UUID is the uUID argument value.
+			UUID uuid.UUID
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// After is the after argument value.
+			After *Cursor
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetById holds details about calls to the GetById method.
+		GetById []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// UUID is the uUID argument value.
+			UUID uuid.UUID
+		}
+		// Save holds details about calls to the Save method.
+		Save []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// Media is the media argument value.
+			Media Media
+		}
+	}
+	lockDelete  sync.RWMutex
+	lockGetAll  sync.RWMutex
+	lockGetById sync.RWMutex
+	lockSave    sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *RepositoryMock) Delete(contextMoqParam context.Context, uUID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("RepositoryMock.DeleteFunc: method is nil but Repository.Delete was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}{
+		ContextMoqParam: contextMoqParam,
+		UUID:            uUID,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(contextMoqParam, uUID)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//     len(mockedRepository.DeleteCalls())
+func (mock *RepositoryMock) DeleteCalls() []struct {
+	ContextMoqParam context.Context
+	UUID            uuid.UUID
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *RepositoryMock) GetAll(ctx context.Context, after *Cursor, limit int) ([]Media, error) {
+	if mock.GetAllFunc == nil {
+		panic("RepositoryMock.GetAllFunc: method is nil but Repository.GetAll was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		After *Cursor
+		Limit int
+	}{
+		Ctx:   ctx,
+		After: after,
+		Limit: limit,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	return mock.GetAllFunc(ctx, after, limit)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//     len(mockedRepository.GetAllCalls())
+func (mock *RepositoryMock) GetAllCalls() []struct {
+	Ctx   context.Context
+	After *Cursor
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		After *Cursor
+		Limit int
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetById calls GetByIdFunc.
+func (mock *RepositoryMock) GetById(contextMoqParam context.Context, uUID uuid.UUID) (Media, error) {
+	if mock.GetByIdFunc == nil {
+		panic("RepositoryMock.GetByIdFunc: method is nil but Repository.GetById was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}{
+		ContextMoqParam: contextMoqParam,
+		UUID:            uUID,
+	}
+	mock.lockGetById.Lock()
+	mock.calls.GetById = append(mock.calls.GetById, callInfo)
+	mock.lockGetById.Unlock()
+	return mock.GetByIdFunc(contextMoqParam, uUID)
+}
+
+// GetByIdCalls gets all the calls that were made to GetById.
+// Check the length with:
+//     len(mockedRepository.GetByIdCalls())
+func (mock *RepositoryMock) GetByIdCalls() []struct {
+	ContextMoqParam context.Context
+	UUID            uuid.UUID
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}
+	mock.lockGetById.RLock()
+	calls = mock.calls.GetById
+	mock.lockGetById.RUnlock()
+	return calls
+}
+
+// Save calls SaveFunc.
+func (mock *RepositoryMock) Save(contextMoqParam context.Context, media Media) error {
+	if mock.SaveFunc == nil {
+		panic("RepositoryMock.SaveFunc: method is nil but Repository.Save was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		Media           Media
+	}{
+		ContextMoqParam: contextMoqParam,
+		Media:           media,
+	}
+	mock.lockSave.Lock()
+	mock.calls.Save = append(mock.calls.Save, callInfo)
+	mock.lockSave.Unlock()
+	return mock.SaveFunc(contextMoqParam, media)
+}
+
+// SaveCalls gets all the calls that were made to Save.
+// Check the length with:
+//     len(mockedRepository.SaveCalls())
+func (mock *RepositoryMock) SaveCalls() []struct {
+	ContextMoqParam context.Context
+	Media           Media
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		Media           Media
+	}
+	mock.lockSave.RLock()
+	calls = mock.calls.Save
+	mock.lockSave.RUnlock()
+	return calls
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/media/memory")
+
+// Store keeps the metadata of the media in memory; the files are kept by
+// the media storage. It is safe for concurrent use.
+type Store struct {
+	mu    *sync.RWMutex
+	items map[uuid.UUID]media.Media
+}
+
+// Ensure Store does implement media.Repository.
+var _ media.Repository = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:    &sync.RWMutex{},
+		items: make(map[uuid.UUID]media.Media),
+	}
+}
+
+func (s Store) Save(ctx context.Context, m media.Media) error {
+	_, span := tracer.Start(ctx, "media.memory.Save")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[m.ID]; ok {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	s.items[m.ID] = m
+
+	return nil
+}
+
+func (s Store) GetById(ctx context.Context, id uuid.UUID) (media.Media, error) {
+	_, span := tracer.Start(ctx, "media.memory.GetById")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.items[id]
+	if !ok {
+		return media.Media{}, bareknews.ErrDataNotFound
+	}
+
+	return m, nil
+}
+
+func (s Store) GetAll(ctx context.Context, after *media.Cursor, limit int) ([]media.Media, error) {
+	_, span := tracer.Start(ctx, "media.memory.GetAll")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]media.Media, 0, len(s.items))
+	for _, m := range s.items {
+		if after == nil || before(m, *after) {
+			items = append(items, m)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return before(items[j], media.Cursor{DateCreated: items[i].DateCreated, ID: items[i].ID})
+	})
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+// before tells whether m comes after the cursor in the newest first
+// order, as the SQLite store compares the ids as text.
+func before(m media.Media, c media.Cursor) bool {
+	if m.DateCreated != c.DateCreated {
+		return m.DateCreated < c.DateCreated
+	}
+
+	return m.ID.String() < c.ID.String()
+}
+
+func (s Store) Delete(ctx context.Context, id uuid.UUID) error {
+	_, span := tracer.Start(ctx, "media.memory.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return bareknews.ErrDataNotFound
+	}
+
+	delete(s.items, id)
+
+	return nil
+}
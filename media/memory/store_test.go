@@ -0,0 +1,49 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media"
+	"github.com/Iiqbal2000/bareknews/media/memory"
+	"github.com/matryer/is"
+)
+
+func TestStore(t *testing.T) {
+	store := memory.CreateStore()
+	is := is.New(t)
+
+	want := media.Create("photo.png", "image/png", 120, 40, 30, "a photo", "someone", 100)
+	is.NoErr(store.Save(context.TODO(), *want))
+	is.Equal(store.Save(context.TODO(), *want), bareknews.ErrDataAlreadyExist)
+
+	got, err := store.GetById(context.TODO(), want.ID)
+	is.NoErr(err)
+	is.Equal(got, *want)
+
+	is.NoErr(store.Delete(context.TODO(), want.ID))
+	_, err = store.GetById(context.TODO(), want.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
+
+func TestGetAll(t *testing.T) {
+	store := memory.CreateStore()
+	is := is.New(t)
+
+	for _, date := range []int64{100, 200, 200, 300} {
+		m := media.Create("a.png", "image/png", 1, 1, 1, "", "", date)
+		is.NoErr(store.Save(context.TODO(), *m))
+	}
+
+	first, err := store.GetAll(context.TODO(), nil, 2)
+	is.NoErr(err)
+	is.Equal(len(first), 2)
+	is.Equal(first[0].DateCreated, int64(300))
+
+	last := first[1]
+	rest, err := store.GetAll(context.TODO(), &media.Cursor{DateCreated: last.DateCreated, ID: last.ID}, 10)
+	is.NoErr(err)
+	is.Equal(len(rest), 2)
+	is.Equal(rest[1].DateCreated, int64(100))
+}
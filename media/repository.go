@@ -0,0 +1,37 @@
+package media
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// Cursor is a position in the media list, which is ordered by the
+// creation date, then by the id, the newest first.
+type Cursor struct {
+	DateCreated int64     `json:"d"`
+	ID          uuid.UUID `json:"i"`
+}
+
+//go:generate moq -out mediaRepo_moq.go . Repository
+type Repository interface {
+	Save(context.Context, Media) error
+	GetById(context.Context, uuid.UUID) (Media, error)
+	// GetAll returns the media after the cursor, the newest first. A nil
+	// cursor starts from the newest.
+	GetAll(ctx context.Context, after *Cursor, limit int) ([]Media, error)
+	Delete(context.Context, uuid.UUID) error
+}
+
+// Storage keeps the files of the media library by key.
+//
+//go:generate moq -out storage_moq.go . Storage
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns bareknews.ErrDataNotFound when there is no file with
+	// the key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete doesn't fail when there is no file with the key.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,114 @@
+// Package s3 keeps the media files in a bucket of an S3-compatible
+// object storage, such as Amazon S3 or MinIO.
+package s3
+
+import (
+	"context"
+	"io"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/media/s3")
+
+// Config locates the bucket. Endpoint is a host with its port, such as
+// "localhost:9000" for a local MinIO.
+type Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// Storage is a media.Storage that puts every file in the bucket as an
+// object named after its key.
+type Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// Ensure Storage does implement media.Storage.
+var _ media.Storage = Storage{}
+
+// CreateStorage connects to the storage and creates the bucket when it
+// doesn't exist.
+func CreateStorage(ctx context.Context, cfg Config) (Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return Storage{}, errors.Wrap(err, "create the client")
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return Storage{}, errors.Wrap(err, "check the bucket")
+	}
+
+	if !exists {
+		err = client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region})
+		if err != nil {
+			return Storage{}, errors.Wrap(err, "create the bucket")
+		}
+	}
+
+	return Storage{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	ctx, span := tracer.Start(ctx, "media.s3.Put")
+	defer span.End()
+
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return errors.Wrap(err, "put the object")
+	}
+
+	return nil
+}
+
+func (s Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "media.s3.Open")
+	defer span.End()
+
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "get the object")
+	}
+
+	// GetObject doesn't send the request; Stat does, and tells whether
+	// the object exists.
+	_, err = obj.Stat()
+	if err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, bareknews.ErrDataNotFound
+		}
+		return nil, errors.Wrap(err, "stat the object")
+	}
+
+	return obj, nil
+}
+
+func (s Storage) Delete(ctx context.Context, key string) error {
+	ctx, span := tracer.Start(ctx, "media.s3.Delete")
+	defer span.End()
+
+	err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return errors.Wrap(err, "remove the object")
+	}
+
+	return nil
+}
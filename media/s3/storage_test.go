@@ -0,0 +1,46 @@
+package s3_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media/s3"
+	"github.com/matryer/is"
+)
+
+// TestStorage runs against the storage of NEWS_TEST_S3_ENDPOINT, such as a
+// local MinIO, and is skipped when it is not set.
+func TestStorage(t *testing.T) {
+	endpoint := os.Getenv("NEWS_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("NEWS_TEST_S3_ENDPOINT is not set")
+	}
+
+	is := is.New(t)
+
+	storage, err := s3.CreateStorage(context.TODO(), s3.Config{
+		Endpoint:  endpoint,
+		Bucket:    "bareknews-test",
+		AccessKey: os.Getenv("NEWS_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("NEWS_TEST_S3_SECRET_KEY"),
+	})
+	is.NoErr(err)
+
+	err = storage.Put(context.TODO(), "a.png", strings.NewReader("content"), 7, "image/png")
+	is.NoErr(err)
+
+	f, err := storage.Open(context.TODO(), "a.png")
+	is.NoErr(err)
+	got, err := io.ReadAll(f)
+	is.NoErr(err)
+	is.NoErr(f.Close())
+	is.Equal(string(got), "content")
+
+	is.NoErr(storage.Delete(context.TODO(), "a.png"))
+	_, err = storage.Open(context.TODO(), "a.png")
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
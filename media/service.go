@@ -0,0 +1,315 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	// The decoders of the accepted images, for their dimensions.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/media")
+
+const (
+	// DefaultMaxSize is the size limit of an upload, in bytes, when none
+	// is given.
+	DefaultMaxSize = 10 << 20
+	// DefaultPageLimit is the number of media of a page when no limit is
+	// given.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the maximum number of media of a page.
+	MaxPageLimit = 100
+)
+
+// UploadIn is a file to add to the library.
+type UploadIn struct {
+	File     io.Reader
+	Filename string
+	Alt      string
+	Credit   string
+}
+
+type MediaOut struct {
+	ID uuid.UUID `json:"id"`
+	// URL serves the file.
+	URL         string `json:"url"`
+	Filename    string `json:"filename"`
+	MimeType    string `json:"mime_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Alt         string `json:"alt"`
+	Credit      string `json:"credit"`
+	DateCreated int64  `json:"date_created"`
+	// Sizes are the smaller widths the image is served in.
+	Sizes []SizeOut `json:"sizes"`
+}
+
+// Page is a page of media. NextCursor is empty on the last page.
+type Page struct {
+	Items      []MediaOut
+	Limit      int
+	NextCursor string
+}
+
+func createMediaOut(m Media) MediaOut {
+	return MediaOut{
+		ID:          m.ID,
+		URL:         "/media/" + m.ID.String(),
+		Filename:    m.Filename,
+		MimeType:    m.MimeType,
+		Size:        m.Size,
+		Width:       m.Width,
+		Height:      m.Height,
+		Alt:         m.Alt,
+		Credit:      m.Credit,
+		DateCreated: m.DateCreated,
+		Sizes:       sizesOf(m),
+	}
+}
+
+type Service struct {
+	store   Repository
+	storage Storage
+	cache   Storage
+	maxSize int64
+}
+
+// CreateSvc returns a service that keeps the metadata in repo and the
+// files in storage. The uploads are limited to maxSize bytes, or to
+// DefaultMaxSize when it is 0.
+func CreateSvc(repo Repository, storage Storage, maxSize int64) Service {
+	if maxSize == 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	return Service{store: repo, storage: storage, maxSize: maxSize}
+}
+
+// WithCache returns the service with a storage for the derivatives of the
+// images, so each one is made once. Without it, they are made on every
+// request.
+func (s Service) WithCache(cache Storage) Service {
+	s.cache = cache
+	return s
+}
+
+// MaxSize returns the size limit of an upload, in bytes.
+func (s Service) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload adds a file to the library. The type is sniffed from the content,
+// whatever the client claims, and must be one of Types.
+func (s Service) Upload(ctx context.Context, in UploadIn) (MediaOut, error) {
+	ctx, span := tracer.Start(ctx, "media.Upload")
+	defer span.End()
+
+	// One more byte tells whether the file is over the limit.
+	data, err := io.ReadAll(io.LimitReader(in.File, s.maxSize+1))
+	if err != nil {
+		return MediaOut{}, errors.Wrap(err, "read the file")
+	}
+
+	if len(data) == 0 {
+		return MediaOut{}, validation.Errors{"file": validation.ErrRequired}
+	}
+
+	if int64(len(data)) > s.maxSize {
+		return MediaOut{}, bareknews.ErrTooLarge
+	}
+
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || Types[mimeType] == "" {
+		return MediaOut{}, bareknews.ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return MediaOut{}, bareknews.ErrUnsupportedType
+	}
+
+	if cfg.Width*cfg.Height > MaxPixels {
+		return MediaOut{}, bareknews.ErrTooLarge
+	}
+
+	// The sizes are the ones the image shows in once it is turned.
+	if mimeType == "image/jpeg" && exifOrientation(data) >= 5 {
+		cfg.Width, cfg.Height = cfg.Height, cfg.Width
+	}
+
+	m := Create(
+		strings.TrimSpace(in.Filename),
+		mimeType,
+		int64(len(data)),
+		cfg.Width,
+		cfg.Height,
+		strings.TrimSpace(in.Alt),
+		strings.TrimSpace(in.Credit),
+		time.Now().Unix(),
+	)
+
+	err = m.Validate()
+	if err != nil {
+		return MediaOut{}, err
+	}
+
+	err = s.storage.Put(ctx, m.Key, bytes.NewReader(data), m.Size, m.MimeType)
+	if err != nil {
+		return MediaOut{}, errors.Wrap(err, "put the file")
+	}
+
+	err = s.store.Save(ctx, *m)
+	if err != nil {
+		// The file is useless without its metadata.
+		_ = s.storage.Delete(ctx, m.Key)
+		return MediaOut{}, err
+	}
+
+	return createMediaOut(*m), nil
+}
+
+func (s Service) GetById(ctx context.Context, id uuid.UUID) (MediaOut, error) {
+	ctx, span := tracer.Start(ctx, "media.GetById")
+	defer span.End()
+
+	m, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return MediaOut{}, err
+	}
+
+	return createMediaOut(m), nil
+}
+
+// Open returns the media with its file, which the caller must close.
+func (s Service) Open(ctx context.Context, id uuid.UUID) (Media, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "media.Open")
+	defer span.End()
+
+	m, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return Media{}, nil, err
+	}
+
+	f, err := s.storage.Open(ctx, m.Key)
+	if err != nil {
+		return Media{}, nil, err
+	}
+
+	return m, f, nil
+}
+
+// List returns a page of media, the newest first. The cursor is the
+// NextCursor of the previous page.
+func (s Service) List(ctx context.Context, cursor string, limit int) (Page, error) {
+	ctx, span := tracer.Start(ctx, "media.List")
+	defer span.End()
+
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+
+	err := validation.Errors{
+		"limit": validation.Validate(limit, validation.Min(1), validation.Max(MaxPageLimit)),
+	}.Filter()
+	if err != nil {
+		return Page{}, err
+	}
+
+	var after *Cursor
+
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return Page{}, validation.Errors{
+				"cursor": validation.NewError("invalid_cursor", "cursor is invalid"),
+			}
+		}
+
+		after = &c
+	}
+
+	// One more media tells whether there is a next page.
+	found, err := s.store.GetAll(ctx, after, limit+1)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{Items: make([]MediaOut, 0, len(found)), Limit: limit}
+
+	if len(found) > limit {
+		found = found[:limit]
+		last := found[len(found)-1]
+		page.NextCursor = encodeCursor(Cursor{DateCreated: last.DateCreated, ID: last.ID})
+	}
+
+	for _, m := range found {
+		page.Items = append(page.Items, createMediaOut(m))
+	}
+
+	return page, nil
+}
+
+// Delete removes the media, its file and its derivatives.
+func (s Service) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "media.Delete")
+	defer span.End()
+
+	m, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.Delete(ctx, m.Key)
+	if err != nil {
+		return errors.Wrap(err, "delete the file")
+	}
+
+	err = s.deleteDerivatives(ctx, m)
+	if err != nil {
+		return errors.Wrap(err, "delete the derivatives")
+	}
+
+	return nil
+}
+
+func encodeCursor(c Cursor) string {
+	// A struct of an int and a UUID always marshals.
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	c := Cursor{}
+	err = json.Unmarshal(raw, &c)
+
+	return c, err
+}
@@ -0,0 +1,219 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/media"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func pngOf(width, height int) []byte {
+	var b bytes.Buffer
+	_ = png.Encode(&b, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return b.Bytes()
+}
+
+func storageMock() *media.StorageMock {
+	return &media.StorageMock{
+		PutFunc: func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, key string) error {
+			return nil
+		},
+	}
+}
+
+func TestUpload(t *testing.T) {
+	t.Run("image should be stored", func(t *testing.T) {
+		store := &media.RepositoryMock{
+			SaveFunc: func(ctx context.Context, m media.Media) error {
+				return nil
+			},
+		}
+		storage := storageMock()
+
+		svc := media.CreateSvc(store, storage, 0)
+		got, err := svc.Upload(context.TODO(), media.UploadIn{
+			File:     bytes.NewReader(pngOf(40, 30)),
+			Filename: "photo.jpg",
+			Alt:      " a photo ",
+		})
+
+		is := is.New(t)
+		is.NoErr(err)
+		// the type comes from the content, not from the name.
+		is.Equal(got.MimeType, "image/png")
+		is.Equal(got.Width, 40)
+		is.Equal(got.Height, 30)
+		is.Equal(got.Alt, "a photo")
+		is.Equal(got.URL, "/media/"+got.ID.String())
+		is.Equal(len(storage.PutCalls()), 1)
+		is.Equal(storage.PutCalls()[0].Key, got.ID.String()+".png")
+		is.Equal(len(store.SaveCalls()), 1)
+	})
+
+	t.Run("not an image should be rejected", func(t *testing.T) {
+		store := &media.RepositoryMock{}
+		storage := storageMock()
+
+		svc := media.CreateSvc(store, storage, 0)
+		_, err := svc.Upload(context.TODO(), media.UploadIn{
+			File:     strings.NewReader("<html><script>alert(1)</script></html>"),
+			Filename: "photo.png",
+		})
+
+		is := is.New(t)
+		is.Equal(err, bareknews.ErrUnsupportedType)
+		is.Equal(len(storage.PutCalls()), 0)
+	})
+
+	t.Run("file over the limit should be rejected", func(t *testing.T) {
+		store := &media.RepositoryMock{}
+		storage := storageMock()
+
+		svc := media.CreateSvc(store, storage, 64)
+		_, err := svc.Upload(context.TODO(), media.UploadIn{
+			File:     bytes.NewReader(pngOf(100, 100)),
+			Filename: "photo.png",
+		})
+
+		is := is.New(t)
+		is.Equal(err, bareknews.ErrTooLarge)
+		is.Equal(len(storage.PutCalls()), 0)
+	})
+
+	t.Run("empty file should be rejected", func(t *testing.T) {
+		svc := media.CreateSvc(&media.RepositoryMock{}, storageMock(), 0)
+		_, err := svc.Upload(context.TODO(), media.UploadIn{
+			File:     strings.NewReader(""),
+			Filename: "photo.png",
+		})
+
+		is := is.New(t)
+		errs, ok := err.(validation.Errors)
+		is.True(ok)
+		is.True(errs["file"] != nil)
+	})
+
+	t.Run("file should be removed when the metadata are not saved", func(t *testing.T) {
+		store := &media.RepositoryMock{
+			SaveFunc: func(ctx context.Context, m media.Media) error {
+				return bareknews.ErrInternalServer
+			},
+		}
+		storage := storageMock()
+
+		svc := media.CreateSvc(store, storage, 0)
+		_, err := svc.Upload(context.TODO(), media.UploadIn{
+			File:     bytes.NewReader(pngOf(1, 1)),
+			Filename: "photo.png",
+		})
+
+		is := is.New(t)
+		is.True(err != nil)
+		is.Equal(len(storage.DeleteCalls()), 1)
+		is.Equal(storage.DeleteCalls()[0].Key, storage.PutCalls()[0].Key)
+	})
+}
+
+func TestList(t *testing.T) {
+	all := make([]media.Media, 0)
+	for i := 0; i < 5; i++ {
+		all = append(all, *media.Create("a.png", "image/png", 1, 1, 1, "", "", int64(100-i)))
+	}
+
+	store := &media.RepositoryMock{
+		GetAllFunc: func(ctx context.Context, after *media.Cursor, limit int) ([]media.Media, error) {
+			start := 0
+			if after != nil {
+				for i, m := range all {
+					if m.ID == after.ID {
+						start = i + 1
+					}
+				}
+			}
+			end := start + limit
+			if end > len(all) {
+				end = len(all)
+			}
+			return all[start:end], nil
+		},
+	}
+
+	svc := media.CreateSvc(store, storageMock(), 0)
+	is := is.New(t)
+
+	page, err := svc.List(context.TODO(), "", 2)
+	is.NoErr(err)
+	is.Equal(len(page.Items), 2)
+	is.True(page.NextCursor != "")
+
+	seen := len(page.Items)
+	for page.NextCursor != "" {
+		page, err = svc.List(context.TODO(), page.NextCursor, 2)
+		is.NoErr(err)
+		seen += len(page.Items)
+	}
+	is.Equal(seen, len(all))
+
+	_, err = svc.List(context.TODO(), "not a cursor", 2)
+	errs, ok := err.(validation.Errors)
+	is.True(ok)
+	is.True(errs["cursor"] != nil)
+
+	_, err = svc.List(context.TODO(), "", media.MaxPageLimit+1)
+	errs, ok = err.(validation.Errors)
+	is.True(ok)
+	is.True(errs["limit"] != nil)
+}
+
+func TestDelete(t *testing.T) {
+	m := media.Create("a.png", "image/png", 1, 1, 1, "", "", 1)
+
+	t.Run("media and its file should be removed", func(t *testing.T) {
+		store := &media.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (media.Media, error) {
+				return *m, nil
+			},
+			DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+				return nil
+			},
+		}
+		storage := storageMock()
+
+		svc := media.CreateSvc(store, storage, 0)
+		err := svc.Delete(context.TODO(), m.ID)
+
+		is := is.New(t)
+		is.NoErr(err)
+		is.Equal(len(store.DeleteCalls()), 1)
+		is.Equal(len(storage.DeleteCalls()), 1)
+		is.Equal(storage.DeleteCalls()[0].Key, m.Key)
+	})
+
+	t.Run("unknown media should be not found", func(t *testing.T) {
+		store := &media.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (media.Media, error) {
+				return media.Media{}, bareknews.ErrDataNotFound
+			},
+		}
+		storage := storageMock()
+
+		svc := media.CreateSvc(store, storage, 0)
+		err := svc.Delete(context.TODO(), uuid.New())
+
+		is := is.New(t)
+		is.Equal(err, bareknews.ErrDataNotFound)
+		is.Equal(len(storage.DeleteCalls()), 0)
+	})
+}
@@ -0,0 +1,197 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package media
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Ensure, that StorageMock does implement Storage.
+// If this is not the case, regenerate this file with moq.
+var _ Storage = &StorageMock{}
+
+// StorageMock is a mock implementation of Storage.
+//
+// 	func TestSomethingThatUsesStorage(t *testing.T) {
+//
+// 		// make and configure a mocked Storage
+// 		mockedStorage := &StorageMock{
+// 			DeleteFunc: func(ctx context.Context, key string) error {
+// 				panic("mock out the Delete method")
+// 			},
+// 			OpenFunc: func(ctx context.Context, key string) (io.ReadCloser, error) {
+// 				panic("mock out the Open method")
+// 			},
+// 			PutFunc: func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+// 				panic("mock out the Put method")
+// 			},
+// 		}
+//
+// 		// use mockedStorage in code that requires Storage
+// 		// and then make assertions.
+//
+// 	}
+type StorageMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, key string) error
+
+	// OpenFunc mocks the Open method.
+	OpenFunc func(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// PutFunc mocks the Put method.
+	PutFunc func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+		// Open holds details about calls to the Open method.
+		Open []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+		// Put holds details about calls to the Put method.
+		Put []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// R is the r argument value.
+			R io.Reader
+			// Size is the size argument value.
+			Size int64
+			// ContentType is the contentType argument value.
+			ContentType string
+		}
+	}
+	lockDelete sync.RWMutex
+	lockOpen   sync.RWMutex
+	lockPut    sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *StorageMock) Delete(ctx context.Context, key string) error {
+	if mock.DeleteFunc == nil {
+		panic("StorageMock.DeleteFunc: method is nil but Storage.Delete was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(ctx, key)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//     len(mockedStorage.DeleteCalls())
+func (mock *StorageMock) DeleteCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Open calls OpenFunc.
+func (mock *StorageMock) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if mock.OpenFunc == nil {
+		panic("StorageMock.OpenFunc: method is nil but Storage.Open was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockOpen.Lock()
+	mock.calls.Open = append(mock.calls.Open, callInfo)
+	mock.lockOpen.Unlock()
+	return mock.OpenFunc(ctx, key)
+}
+
+// OpenCalls gets all the calls that were made to Open.
+// Check the length with:
+//     len(mockedStorage.OpenCalls())
+func (mock *StorageMock) OpenCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockOpen.RLock()
+	calls = mock.calls.Open
+	mock.lockOpen.RUnlock()
+	return calls
+}
+
+// Put calls PutFunc.
+func (mock *StorageMock) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if mock.PutFunc == nil {
+		panic("StorageMock.PutFunc: method is nil but Storage.Put was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Key         string
+		R           io.Reader
+		Size        int64
+		ContentType string
+	}{
+		Ctx:         ctx,
+		Key:         key,
+		R:           r,
+		Size:        size,
+		ContentType: contentType,
+	}
+	mock.lockPut.Lock()
+	mock.calls.Put = append(mock.calls.Put, callInfo)
+	mock.lockPut.Unlock()
+	return mock.PutFunc(ctx, key, r, size, contentType)
+}
+
+// PutCalls gets all the calls that were made to Put.
+// Check the length with:
+//     len(mockedStorage.PutCalls())
+func (mock *StorageMock) PutCalls() []struct {
+	Ctx         context.Context
+	Key         string
+	R           io.Reader
+	Size        int64
+	ContentType string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Key         string
+		R           io.Reader
+		Size        int64
+		ContentType string
+	}
+	mock.lockPut.RLock()
+	calls = mock.calls.Put
+	mock.lockPut.RUnlock()
+	return calls
+}
@@ -0,0 +1,280 @@
+package news
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// BlocksVersion is the version of the block document schema. A document
+// of another version is rejected.
+const BlocksVersion = 1
+
+// The types of the blocks.
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockQuote     = "quote"
+	BlockImage     = "image"
+	BlockEmbed     = "embed"
+	BlockList      = "list"
+	BlockCode      = "code"
+)
+
+var (
+	httpURLRx  = regexp.MustCompile(`^https?://`)
+	languageRx = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	// mdSpecial are the characters that Markdown would read as markup.
+	mdSpecial = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+		`[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `#`, `\#`,
+	)
+)
+
+// ErrInvalidBlocks is returned when a body in the blocks format is not a
+// JSON block document.
+var ErrInvalidBlocks = validation.NewError("validation_invalid_blocks", "must be a JSON block document")
+
+// Document is a body made of structured blocks, as the editor produces
+// it. It is stored as JSON in the body of a post with the blocks format:
+//
+//	{"version": 1, "blocks": [{"type": "paragraph", "text": "..."}]}
+type Document struct {
+	Version int     `json:"version"`
+	Blocks  []Block `json:"blocks"`
+}
+
+// Block is a block of a document. The fields in use depend on the type:
+//
+//	paragraph: text
+//	heading:   text, level (1 to 6)
+//	quote:     text, cite
+//	image:     url, alt, caption
+//	embed:     url, caption
+//	list:      items, ordered
+//	code:      code, language
+//
+// The texts are plain texts; they carry no markup.
+type Block struct {
+	Type     string   `json:"type"`
+	Text     string   `json:"text,omitempty"`
+	Level    int      `json:"level,omitempty"`
+	Cite     string   `json:"cite,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Alt      string   `json:"alt,omitempty"`
+	Caption  string   `json:"caption,omitempty"`
+	Items    []string `json:"items,omitempty"`
+	Ordered  bool     `json:"ordered,omitempty"`
+	Code     string   `json:"code,omitempty"`
+	Language string   `json:"language,omitempty"`
+}
+
+// ParseDocument decodes and validates a block document.
+func ParseDocument(body string) (Document, error) {
+	d := Document{}
+
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&d); err != nil {
+		return Document{}, ErrInvalidBlocks
+	}
+
+	if err := d.Validate(); err != nil {
+		return Document{}, err
+	}
+
+	return d, nil
+}
+
+func (d Document) Validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Version,
+			validation.Required,
+			validation.In(BlocksVersion).Error(fmt.Sprintf("must be %d", BlocksVersion)),
+		),
+		validation.Field(&d.Blocks, validation.Required),
+	)
+}
+
+func (b Block) Validate() error {
+	hasText := b.Type == BlockParagraph || b.Type == BlockHeading || b.Type == BlockQuote
+	hasURL := b.Type == BlockImage || b.Type == BlockEmbed
+
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.Type,
+			validation.Required,
+			validation.In(BlockParagraph, BlockHeading, BlockQuote, BlockImage, BlockEmbed, BlockList, BlockCode),
+		),
+		validation.Field(&b.Text, validation.When(hasText, validation.Required)),
+		validation.Field(&b.Level, validation.When(b.Type == BlockHeading,
+			validation.Required, validation.Min(1), validation.Max(6),
+		)),
+		validation.Field(&b.URL, validation.When(hasURL,
+			validation.Required,
+			is.RequestURL,
+			validation.Match(httpURLRx).Error("must be an http or https URL"),
+		)),
+		validation.Field(&b.Items, validation.When(b.Type == BlockList,
+			validation.Required, validation.Each(validation.Required),
+		)),
+		validation.Field(&b.Code, validation.When(b.Type == BlockCode, validation.Required)),
+		validation.Field(&b.Language, validation.Match(languageRx)),
+	)
+}
+
+// HTML converts the document to HTML. The embeds become links, as the
+// rendered bodies don't keep iframes.
+func (d Document) HTML() string {
+	var b strings.Builder
+
+	for _, blk := range d.Blocks {
+		switch blk.Type {
+		case BlockParagraph:
+			fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(blk.Text))
+		case BlockHeading:
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", blk.Level, html.EscapeString(blk.Text), blk.Level)
+		case BlockQuote:
+			b.WriteString("<blockquote>")
+			fmt.Fprintf(&b, "<p>%s</p>", html.EscapeString(blk.Text))
+			if blk.Cite != "" {
+				fmt.Fprintf(&b, "<cite>%s</cite>", html.EscapeString(blk.Cite))
+			}
+			b.WriteString("</blockquote>\n")
+		case BlockImage:
+			fmt.Fprintf(&b, `<figure><img src="%s" alt="%s">`, html.EscapeString(blk.URL), html.EscapeString(blk.Alt))
+			writeCaption(&b, blk.Caption)
+			b.WriteString("</figure>\n")
+		case BlockEmbed:
+			u := html.EscapeString(blk.URL)
+			fmt.Fprintf(&b, `<figure><a href="%s">%s</a>`, u, u)
+			writeCaption(&b, blk.Caption)
+			b.WriteString("</figure>\n")
+		case BlockList:
+			tag := "ul"
+			if blk.Ordered {
+				tag = "ol"
+			}
+			fmt.Fprintf(&b, "<%s>", tag)
+			for _, item := range blk.Items {
+				fmt.Fprintf(&b, "<li>%s</li>", html.EscapeString(item))
+			}
+			fmt.Fprintf(&b, "</%s>\n", tag)
+		case BlockCode:
+			b.WriteString("<pre><code")
+			if blk.Language != "" {
+				fmt.Fprintf(&b, ` class="language-%s"`, blk.Language)
+			}
+			fmt.Fprintf(&b, ">%s</code></pre>\n", html.EscapeString(blk.Code))
+		}
+	}
+
+	return b.String()
+}
+
+func writeCaption(b *strings.Builder, caption string) {
+	if caption != "" {
+		fmt.Fprintf(b, "<figcaption>%s</figcaption>", html.EscapeString(caption))
+	}
+}
+
+// Markdown converts the document to Markdown. The blocks are separated
+// by a blank line.
+func (d Document) Markdown() string {
+	parts := make([]string, 0, len(d.Blocks))
+
+	for _, blk := range d.Blocks {
+		var b strings.Builder
+
+		switch blk.Type {
+		case BlockParagraph:
+			b.WriteString(mdSpecial.Replace(blk.Text))
+		case BlockHeading:
+			fmt.Fprintf(&b, "%s %s", strings.Repeat("#", blk.Level), mdSpecial.Replace(blk.Text))
+		case BlockQuote:
+			for i, line := range strings.Split(blk.Text, "\n") {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				fmt.Fprintf(&b, "> %s", mdSpecial.Replace(line))
+			}
+			if blk.Cite != "" {
+				fmt.Fprintf(&b, "\n>\n> — %s", mdSpecial.Replace(blk.Cite))
+			}
+		case BlockImage:
+			fmt.Fprintf(&b, "![%s](%s)", mdSpecial.Replace(blk.Alt), blk.URL)
+			if blk.Caption != "" {
+				fmt.Fprintf(&b, "\n\n*%s*", mdSpecial.Replace(blk.Caption))
+			}
+		case BlockEmbed:
+			fmt.Fprintf(&b, "<%s>", blk.URL)
+			if blk.Caption != "" {
+				fmt.Fprintf(&b, "\n\n*%s*", mdSpecial.Replace(blk.Caption))
+			}
+		case BlockList:
+			for i, item := range blk.Items {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				if blk.Ordered {
+					fmt.Fprintf(&b, "%d. %s", i+1, mdSpecial.Replace(item))
+				} else {
+					fmt.Fprintf(&b, "- %s", mdSpecial.Replace(item))
+				}
+			}
+		case BlockCode:
+			fence := "```"
+			for strings.Contains(blk.Code, fence) {
+				fence += "`"
+			}
+			fmt.Fprintf(&b, "%s%s\n%s\n%s", fence, blk.Language, blk.Code, fence)
+		}
+
+		parts = append(parts, b.String())
+	}
+
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// Text converts the document to plain text: the texts of the blocks,
+// separated by a blank line. The images and the embeds give their
+// caption only.
+func (d Document) Text() string {
+	var b strings.Builder
+
+	for _, blk := range d.Blocks {
+		var s string
+
+		switch blk.Type {
+		case BlockParagraph, BlockHeading:
+			s = blk.Text
+		case BlockQuote:
+			s = blk.Text
+			if blk.Cite != "" {
+				s += "\n— " + blk.Cite
+			}
+		case BlockImage, BlockEmbed:
+			s = blk.Caption
+		case BlockList:
+			s = strings.Join(blk.Items, "\n")
+		case BlockCode:
+			s = blk.Code
+		}
+
+		if s == "" {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(s)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package news_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/matryer/is"
+)
+
+const blocksBody = `{"version": 1, "blocks": [
+	{"type": "heading", "level": 2, "text": "Cup <final>"},
+	{"type": "paragraph", "text": "The final ends 2-1. A *late* goal."},
+	{"type": "quote", "text": "We never gave up.", "cite": "The coach"},
+	{"type": "image", "url": "https://example.com/a.jpg", "alt": "fans", "caption": "The fans"},
+	{"type": "embed", "url": "https://example.com/v/1"},
+	{"type": "list", "ordered": true, "items": ["first", "second"]},
+	{"type": "code", "language": "go", "code": "x := 1"}
+]}`
+
+func TestParseDocument(t *testing.T) {
+	t.Run("valid document", func(t *testing.T) {
+		is := is.New(t)
+
+		d, err := news.ParseDocument(blocksBody)
+		is.NoErr(err)
+		is.Equal(d.Version, news.BlocksVersion)
+		is.Equal(len(d.Blocks), 7)
+	})
+
+	payloadTest := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"not JSON", "hello", ""},
+		{"unknown field", `{"version": 1, "blocks": [{"type": "paragraph", "text": "a", "color": "red"}]}`, ""},
+		{"unknown version", `{"version": 2, "blocks": [{"type": "paragraph", "text": "a"}]}`, "version"},
+		{"no blocks", `{"version": 1, "blocks": []}`, "blocks"},
+		{"unknown type", `{"version": 1, "blocks": [{"type": "table"}]}`, "blocks"},
+		{"paragraph without text", `{"version": 1, "blocks": [{"type": "paragraph"}]}`, "blocks"},
+		{"heading level out of range", `{"version": 1, "blocks": [{"type": "heading", "level": 7, "text": "a"}]}`, "blocks"},
+		{"image with a javascript URL", `{"version": 1, "blocks": [{"type": "image", "url": "javascript:alert(1)"}]}`, "blocks"},
+		{"list with a blank item", `{"version": 1, "blocks": [{"type": "list", "items": ["a", ""]}]}`, "blocks"},
+	}
+
+	for _, pt := range payloadTest {
+		t.Run(pt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			_, err := news.ParseDocument(pt.body)
+			is.True(err != nil)
+
+			if pt.field == "" {
+				is.Equal(err, news.ErrInvalidBlocks)
+				return
+			}
+
+			errs, ok := err.(validation.Errors)
+			is.True(ok)
+			is.True(errs[pt.field] != nil)
+		})
+	}
+}
+
+func TestDocumentConverters(t *testing.T) {
+	is := is.New(t)
+
+	d, err := news.ParseDocument(blocksBody)
+	is.NoErr(err)
+
+	t.Run("html", func(t *testing.T) {
+		got := d.HTML()
+		is.True(strings.Contains(got, "<h2>Cup &lt;final&gt;</h2>"))
+		is.True(strings.Contains(got, "<blockquote><p>We never gave up.</p><cite>The coach</cite></blockquote>"))
+		is.True(strings.Contains(got, `<img src="https://example.com/a.jpg" alt="fans"><figcaption>The fans</figcaption>`))
+		is.True(strings.Contains(got, `<a href="https://example.com/v/1">`))
+		is.True(strings.Contains(got, "<ol><li>first</li><li>second</li></ol>"))
+		is.True(strings.Contains(got, `<code class="language-go">x := 1</code>`))
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		got := d.Markdown()
+		is.True(strings.HasPrefix(got, "## Cup \\<final\\>\n\n"))
+		is.True(strings.Contains(got, `A \*late\* goal.`))
+		is.True(strings.Contains(got, "> We never gave up.\n>\n> — The coach"))
+		is.True(strings.Contains(got, "![fans](https://example.com/a.jpg)\n\n*The fans*"))
+		is.True(strings.Contains(got, "1. first\n2. second"))
+		is.True(strings.Contains(got, "```go\nx := 1\n```"))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		is.Equal(d.Text(), "Cup <final>\n\nThe final ends 2-1. A *late* goal.\n\nWe never gave up.\n— The coach\n\nThe fans\n\nfirst\nsecond\n\nx := 1")
+	})
+}
+
+func TestBlocksBody(t *testing.T) {
+	is := is.New(t)
+
+	nw := news.Create("Cup final", blocksBody, bareknews.Draft, nil, time.Now().Unix())
+	nw.ChangeBodyFormat(bareknews.Blocks)
+	is.NoErr(nw.Validate())
+	is.True(strings.Contains(nw.BodyHTML, "<figure>"))
+	is.True(strings.Contains(nw.BodyHTML, `<code class="language-go">`))
+	is.True(strings.HasPrefix(nw.PlainText(), "Cup <final>"))
+
+	nw.ChangeBody(`{"version": 1, "blocks": [{"type": "heading", "text": "a"}]}`)
+	err := nw.Validate()
+	is.True(err != nil)
+
+	errs, ok := err.(validation.Errors)
+	is.True(ok)
+	is.True(errs["Body"] != nil)
+}
@@ -0,0 +1,103 @@
+package news
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// policy is the allow-list of the rendered bodies. It keeps the usual
+// formatting, links and images, and drops the scripts, the styles, the
+// event handlers and the URLs other than http, https and mailto, such as
+// javascript: URLs. The language classes of the code blocks are kept for
+// the syntax highlighters.
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[a-zA-Z0-9]+$`)).OnElements("code")
+
+	return p
+}()
+
+// markdown renders the GitHub flavored Markdown. Raw HTML is kept because
+// the policy cleans it afterwards.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+)
+
+// renderBody returns the sanitized HTML of a body in the format.
+func renderBody(body string, format bareknews.BodyFormat) string {
+	var raw string
+
+	switch format {
+	case bareknews.Markdown:
+		var buf bytes.Buffer
+		// Writing to a bytes.Buffer doesn't fail.
+		_ = markdown.Convert([]byte(body), &buf)
+		raw = buf.String()
+	case bareknews.HTML:
+		raw = body
+	case bareknews.Blocks:
+		// An invalid document renders to nothing; Validate reports it.
+		d, err := ParseDocument(body)
+		if err == nil {
+			raw = d.HTML()
+		}
+	default:
+		raw = renderPlain(body)
+	}
+
+	return policy.Sanitize(raw)
+}
+
+// textPolicy drops every tag and keeps the text.
+var textPolicy = bluemonday.StrictPolicy()
+
+// PlainText returns the body of the news as plain text.
+func (n News) PlainText() string {
+	switch n.Post.Format {
+	case bareknews.Blocks:
+		d, err := ParseDocument(n.Post.Body)
+		if err != nil {
+			return ""
+		}
+		return d.Text()
+	case bareknews.Markdown, bareknews.HTML:
+		return html.UnescapeString(textPolicy.Sanitize(n.BodyHTML))
+	default:
+		return n.Post.Body
+	}
+}
+
+// renderPlain turns the blank-line separated blocks of a plain text into
+// paragraphs and keeps its line breaks.
+func renderPlain(body string) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+
+	var b strings.Builder
+
+	for _, para := range strings.Split(body, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+
+		lines := strings.Split(para, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(lines, "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,304 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxBulkItems is the maximum number of news items a bulk request can
+	// touch, whether they are listed or matched by the filter.
+	MaxBulkItems = 100
+	// MaxBulkOperations is the maximum number of operations in a bulk
+	// request.
+	MaxBulkOperations = 10
+)
+
+// The modes of a bulk request. In the atomic mode nothing is written when
+// one of the items fails; in the partial mode the other items are still
+// written.
+const (
+	BulkAtomic  = "atomic"
+	BulkPartial = "partial"
+)
+
+// The operations of a bulk request.
+const (
+	OpPublish    = "publish"
+	OpUnpublish  = "unpublish"
+	OpDelete     = "delete"
+	OpAddTags    = "add_tags"
+	OpRemoveTags = "remove_tags"
+	OpSetStatus  = "set_status"
+)
+
+// BulkIn is the payload of a bulk request. The operations are applied in
+// order to every news item in IDs and every news item matched by Filter.
+type BulkIn struct {
+	Mode       string          `json:"mode" enums:"atomic,partial" default:"atomic"`
+	IDs        []uuid.UUID     `json:"ids"`
+	Filter     *BulkFilter     `json:"filter"`
+	Operations []BulkOperation `json:"operations" validate:"required"`
+}
+
+// BulkFilter matches the news items by status and topic.
+type BulkFilter struct {
+	Status string `json:"status" enums:"publish,draft"`
+	Topic  string `json:"topic"`
+}
+
+// BulkOperation is a single change that is applied to every target.
+type BulkOperation struct {
+	Op     string   `json:"op" enums:"publish,unpublish,delete,add_tags,remove_tags,set_status"`
+	Tags   []string `json:"tags"`
+	Status string   `json:"status" enums:"publish,draft"`
+}
+
+// BulkOut is the outcome of a bulk request. Applied reports whether any
+// change is written.
+type BulkOut struct {
+	Mode    string
+	Applied bool
+	Results []BulkResult
+}
+
+// BulkResult is the outcome of a single news item. Err is nil when the
+// item is changed.
+type BulkResult struct {
+	ID  uuid.UUID
+	Err error
+}
+
+func (b BulkIn) Validate() error {
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.Mode, validation.In(BulkAtomic, BulkPartial).
+			Error("mode must be one of 'atomic', 'partial'")),
+		validation.Field(&b.IDs,
+			validation.When(b.Filter == nil, validation.Required.Error("ids or filter is required")),
+			validation.Length(0, MaxBulkItems)),
+		validation.Field(&b.Filter),
+		validation.Field(&b.Operations, validation.Required, validation.Length(1, MaxBulkOperations)),
+	)
+}
+
+func (f BulkFilter) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.Status, validation.In(bareknews.Publish.String(), bareknews.Draft.String()).
+			Error("status must be one of 'publish', 'draft'")),
+	)
+}
+
+func (o BulkOperation) Validate() error {
+	hasTags := o.Op == OpAddTags || o.Op == OpRemoveTags
+
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Op, validation.Required, validation.In(
+			OpPublish, OpUnpublish, OpDelete, OpAddTags, OpRemoveTags, OpSetStatus,
+		).Error("op is unknown")),
+		validation.Field(&o.Tags, validation.When(hasTags, validation.Required)),
+		validation.Field(&o.Status, validation.When(o.Op == OpSetStatus,
+			validation.Required,
+			validation.In(bareknews.Publish.String(), bareknews.Draft.String()).
+				Error("status must be one of 'publish', 'draft'"),
+		)),
+	)
+}
+
+// Bulk applies the operations to the targeted news items in one
+// transaction and reports the outcome of every item.
+func (s Service) Bulk(ctx context.Context, input BulkIn) (BulkOut, error) {
+	ctx, span := tracer.Start(ctx, "news.Bulk")
+	defer span.End()
+
+	if input.Mode == "" {
+		input.Mode = BulkAtomic
+	}
+
+	err := input.Validate()
+	if err != nil {
+		return BulkOut{}, err
+	}
+
+	ids, err := s.bulkTargets(ctx, input)
+	if err != nil {
+		return BulkOut{}, err
+	}
+
+	tagsID, err := s.bulkTags(ctx, input.Operations)
+	if err != nil {
+		return BulkOut{}, err
+	}
+
+	atomic := input.Mode == BulkAtomic
+	out := BulkOut{Mode: input.Mode, Results: make([]BulkResult, len(ids))}
+
+	// The items are read and changed in the transaction of the store, so
+	// a write that comes in between isn't lost.
+	errs, err := s.store.Bulk(ctx, ids, func(n News) (Change, error) {
+		return s.bulkChange(n, input.Operations, tagsID)
+	}, atomic)
+	if err != nil {
+		return BulkOut{}, errors.Wrap(err, "apply the bulk changes")
+	}
+
+	failed := false
+
+	for i, err := range errs {
+		out.Results[i].ID = ids[i]
+
+		if err != nil {
+			out.Results[i].Err = err
+			failed = true
+		}
+	}
+
+	if failed && atomic {
+		return rollBack(out), nil
+	}
+
+	for _, r := range out.Results {
+		if r.Err == nil {
+			out.Applied = true
+			break
+		}
+	}
+
+	if out.Applied {
+		s.notify()
+	}
+
+	return out, nil
+}
+
+// bulkTargets returns the unique IDs that are listed or matched by the
+// filter, in that order.
+func (s Service) bulkTargets(ctx context.Context, input BulkIn) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(input.IDs))
+	seen := make(map[uuid.UUID]bool)
+
+	add := func(id uuid.UUID) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range input.IDs {
+		add(id)
+	}
+
+	if input.Filter != nil {
+		f := Filter{Status: bareknews.Status(input.Filter.Status)}
+
+		if topic := strings.TrimSpace(input.Filter.Topic); topic != "" {
+			tg, err := s.tagging.GetByName(ctx, topic)
+			if err != nil {
+				return nil, errors.Wrap(err, "get a tag by name")
+			}
+
+			f.TagID = tg.ID
+		}
+
+		matched, err := s.store.GetIdsByFilter(ctx, f, MaxBulkItems+1)
+		if err != nil {
+			return nil, errors.Wrap(err, "get news ids by filter")
+		}
+
+		for _, id := range matched {
+			add(id)
+		}
+	}
+
+	if len(ids) > MaxBulkItems {
+		return nil, validation.Errors{
+			"ids": validation.NewError(
+				"too_many_items",
+				fmt.Sprintf("a bulk request can touch at most %d news items", MaxBulkItems),
+			),
+		}
+	}
+
+	return ids, nil
+}
+
+// bulkTags resolves the tag names of every operation by the index of the
+// operation. The names can be aliases; unknown names are rejected.
+func (s Service) bulkTags(ctx context.Context, ops []BulkOperation) (map[int][]uuid.UUID, error) {
+	r := make(map[int][]uuid.UUID)
+
+	for i, op := range ops {
+		if op.Op != OpAddTags && op.Op != OpRemoveTags {
+			continue
+		}
+
+		for _, name := range op.Tags {
+			tg, err := s.tagging.GetByName(ctx, name)
+			if errors.Is(err, bareknews.ErrDataNotFound) {
+				return nil, validation.Errors{
+					"operations": validation.Errors{
+						fmt.Sprint(i): validation.NewError(
+							"unknown_tag",
+							fmt.Sprintf("tag %q is not found", name),
+						),
+					},
+				}
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, "get a tag by name")
+			}
+
+			r[i] = append(r[i], tg.ID)
+		}
+	}
+
+	return r, nil
+}
+
+// bulkChange applies the operations to the news item.
+func (s Service) bulkChange(item News, ops []BulkOperation, tagsID map[int][]uuid.UUID) (Change, error) {
+	for i, op := range ops {
+		switch op.Op {
+		case OpDelete:
+			return Change{News: item, Delete: true}, nil
+		case OpPublish:
+			item.ChangeStatus(bareknews.Publish)
+		case OpUnpublish:
+			item.ChangeStatus(bareknews.Draft)
+		case OpSetStatus:
+			item.ChangeStatus(bareknews.Status(op.Status))
+		case OpAddTags:
+			item.AddTags(tagsID[i])
+		case OpRemoveTags:
+			item.RemoveTags(tagsID[i])
+		}
+	}
+
+	item.ChangeDateUpdated(time.Now().Unix())
+
+	err := item.ValidateWith(s.limits)
+	if err != nil {
+		return Change{}, err
+	}
+
+	return Change{News: item}, nil
+}
+
+// rollBack marks the items that didn't fail as rolled back.
+func rollBack(out BulkOut) BulkOut {
+	out.Applied = false
+
+	for i := range out.Results {
+		if out.Results[i].Err == nil {
+			out.Results[i].Err = bareknews.ErrRolledBack
+		}
+	}
+
+	return out
+}
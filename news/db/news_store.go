@@ -5,7 +5,11 @@ import (
 	"database/sql"
 
 	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/changes"
+	changesdb "github.com/Iiqbal2000/bareknews/changes/db"
 	"github.com/Iiqbal2000/bareknews/news"
+	streamdb "github.com/Iiqbal2000/bareknews/stream/db"
+	webhooksdb "github.com/Iiqbal2000/bareknews/webhooks/db"
 	"github.com/google/uuid"
 	"github.com/huandu/go-sqlbuilder"
 	"github.com/mattn/go-sqlite3"
@@ -34,15 +38,31 @@ func (s Store) Save(ctx context.Context, n news.News) error {
 
 	defer tx.Rollback()
 
+	featured := featuredOf(n)
+
 	builder := sqlbuilder.NewInsertBuilder()
 	builder.InsertInto("news")
-	builder.Cols("id", "title", "slug", "status", "body", "date_created", "date_updated")
+	builder.Cols(
+		"id", "title", "slug", "status", "body", "body_format", "body_html",
+		"excerpt", "excerpt_custom", "word_count", "reading_time_minutes",
+		"featured_image_url", "featured_image_alt", "featured_image_credit",
+		"date_created", "date_updated",
+	)
 	builder.Values(
 		n.Post.ID,
 		n.Post.Title,
 		n.Slug,
 		n.Status,
 		n.Post.Body,
+		n.Post.Format,
+		n.BodyHTML,
+		n.Excerpt,
+		n.ExcerptCustom,
+		n.WordCount,
+		n.ReadingTimeMinutes,
+		featured.URL,
+		featured.Alt,
+		featured.Credit,
 		n.DateCreated,
 		n.DateUpdated,
 	)
@@ -63,6 +83,39 @@ func (s Store) Save(ctx context.Context, n news.News) error {
 		return errors.Wrap(err, "could not insert news-tags relation")
 	}
 
+	err = s.insertGallery(ctx, tx, n)
+	if err != nil {
+		return errors.Wrap(err, "could not insert the gallery")
+	}
+
+	err = changesdb.Record(ctx, tx, changes.KindNews, false, n.Post.ID)
+	if err != nil {
+		return err
+	}
+
+	err = webhooksdb.Enqueue(ctx, tx, news.EventCreated, n)
+	if err != nil {
+		return err
+	}
+
+	err = streamdb.Append(ctx, tx, news.EventOf(news.EventCreated, n))
+	if err != nil {
+		return err
+	}
+
+	// a news item that is created as published is published too.
+	if n.Status == bareknews.Publish {
+		err = webhooksdb.Enqueue(ctx, tx, news.EventPublished, n)
+		if err != nil {
+			return err
+		}
+
+		err = streamdb.Append(ctx, tx, news.EventOf(news.EventPublished, n))
+		if err != nil {
+			return err
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return errors.Wrap(err, "commit tx")
 	}
@@ -75,83 +128,89 @@ func (s Store) GetById(ctx context.Context, id uuid.UUID) (*news.News, error) {
 	defer span.End()
 
 	builder := sqlbuilder.NewSelectBuilder()
-	builder.Select("id", "title", "status", "body", "slug", "date_created", "date_updated")
+	builder.Select(newsColumns...)
 	builder.From("news")
 	builder.Where(builder.Equal("id", id))
 
 	query, args := builder.Build()
 	row := s.conn.QueryRowContext(ctx, query, args...)
-	post := bareknews.Post{}
-	slug := new(bareknews.Slug)
-	status := new(bareknews.Status)
-	dateCreated := new(int64)
-	updateCreated := new(int64)
 
-	err := row.Scan(&post.ID, &post.Title, status, &post.Body, slug, dateCreated, updateCreated)
+	result, err := scanNews(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return &news.News{}, sql.ErrNoRows
+			return &news.News{}, bareknews.ErrDataNotFound
 		} else {
 			return &news.News{}, errors.Wrap(err, "scan a news item")
 		}
 	}
 
-	tagIdResults, err := s.getAllTagIds(ctx, post.ID)
+	result.TagsID, err = s.getAllTagIds(ctx, result.Post.ID)
 	if err != nil {
 		return &news.News{}, errors.Wrap(err, "could not get tag ids")
 	}
 
-	result := &news.News{
-		Post:   post,
-		Slug:   *slug,
-		Status: *status,
-		TagsID: tagIdResults,
-		DateCreated: *dateCreated,
-		DateUpdated: *updateCreated,
+	galleries, err := s.getGalleries(ctx, []uuid.UUID{result.Post.ID})
+	if err != nil {
+		return &news.News{}, errors.Wrap(err, "could not get the gallery")
 	}
-	return result, nil
+
+	result.Gallery = galleries[result.Post.ID]
+
+	return &result, nil
 }
 
-func (s Store) Update(ctx context.Context, n news.News) error {
-	ctx, span := tracer.Start(ctx, "news.db.Update")
+func (s Store) GetByIds(ctx context.Context, ids []uuid.UUID, view news.View) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetByIds")
 	defer span.End()
 
-	tx, err := s.conn.BeginTx(ctx, nil)
-	if err != nil {
-		return errors.Wrap(err, "begin tx")
+	if len(ids) == 0 {
+		return []news.News{}, nil
 	}
 
-	defer tx.Rollback()
+	idsStr := make([]string, 0, len(ids))
 
-	builder := sqlbuilder.NewUpdateBuilder()
-	builder.Update("news")
-	builder.Set(
-		builder.Assign("title", n.Post.Title),
-		builder.Assign("body", n.Post.Body),
-		builder.Assign("status", n.Status),
-		builder.Assign("slug", n.Slug),
-		builder.Assign("date_updated", n.DateUpdated),
-	)
+	for _, id := range ids {
+		idsStr = append(idsStr, id.String())
+	}
 
-	builder.Where(builder.Equal("id", n.Post.ID))
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Where(builder.In("id", sqlbuilder.List(idsStr)))
 
-	query, args := builder.Build()
-	_, err = tx.Exec(query, args...)
+	nws, err := s.list(ctx, builder, nil, len(ids), view)
 	if err != nil {
-		return errors.Wrap(err, "exec the query")
+		return []news.News{}, err
 	}
 
-	// deleting relation between news and tags.
-	err = s.deleteNewsTagsRelation(ctx, tx, n.Post.ID)
+	byId := make(map[uuid.UUID]news.News, len(nws))
+	for _, n := range nws {
+		byId[n.Post.ID] = n
+	}
+
+	results := make([]news.News, 0, len(nws))
+
+	for _, id := range ids {
+		if n, ok := byId[id]; ok {
+			results = append(results, n)
+		}
+	}
+
+	return results, nil
+}
+
+func (s Store) Update(ctx context.Context, n news.News) error {
+	ctx, span := tracer.Start(ctx, "news.db.Update")
+	defer span.End()
+
+	tx, err := s.conn.BeginTx(ctx, nil)
 	if err != nil {
-		return errors.Wrap(err, "could not delete news-tags relation")
+		return errors.Wrap(err, "begin tx")
 	}
 
-	// inserting new relation between news and tags if they
-	// exist.
-	err = s.insertNewsTagsRelation(ctx, tx, n)
+	defer tx.Rollback()
+
+	err = s.update(ctx, tx, n)
 	if err != nil {
-		return errors.Wrap(err, "could not insert news-tags relation")
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -172,19 +231,9 @@ func (s Store) Delete(ctx context.Context, id uuid.UUID) error {
 
 	defer tx.Rollback()
 
-	err = s.deleteNewsTagsRelation(ctx, tx, id)
+	err = s.delete(ctx, tx, id)
 	if err != nil {
-		return errors.Wrap(err, "could not delete news-tags relation")
-	}
-
-	d := sqlbuilder.NewDeleteBuilder()
-	d.DeleteFrom("news")
-	d.Where(d.Equal("id", id))
-	sql, args := d.Build()
-
-	_, err = tx.Exec(sql, args...)
-	if err != nil {
-		return errors.Wrap(err, "exec the query")
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -193,329 +242,486 @@ func (s Store) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (s Store) Count(ctx context.Context, id uuid.UUID) (int, error) {
-	ctx, span := tracer.Start(ctx, "news.db.Count")
+// Bulk reads and changes the items in one transaction. Each item runs
+// inside a savepoint, so in the partial mode only the failed changes are
+// rolled back.
+func (s Store) Bulk(ctx context.Context, ids []uuid.UUID, apply news.Apply, atomic bool) ([]error, error) {
+	ctx, span := tracer.Start(ctx, "news.db.Bulk")
 	defer span.End()
 
-	builder := sqlbuilder.NewSelectBuilder()
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "begin tx")
+	}
 
-	builder.Select(builder.As("COUNT(id)", "c"))
-	builder.From("news")
-	builder.Where(builder.Equal("id", id))
+	defer tx.Rollback()
 
-	query, args := builder.Build()
-	row := s.conn.QueryRowContext(ctx, query, args...)
+	errs := make([]error, len(ids))
+	failed := false
 
-	var result int
-	err := row.Scan(&result)
-	if err != nil {
-		return result, errors.Wrap(err, "scan a total amount of news")
+	for i, id := range ids {
+		_, err = tx.ExecContext(ctx, "SAVEPOINT bulk_item")
+		if err != nil {
+			return nil, errors.Wrap(err, "create a savepoint")
+		}
+
+		n, err := s.getTx(ctx, tx, id)
+		switch {
+		case errors.Is(err, bareknews.ErrDataNotFound):
+			errs[i] = err
+		case err != nil:
+			return nil, err
+		default:
+			errs[i] = s.applyTx(ctx, tx, n, apply)
+		}
+
+		if errs[i] != nil {
+			failed = true
+
+			_, err = tx.ExecContext(ctx, "ROLLBACK TO bulk_item")
+			if err != nil {
+				return nil, errors.Wrap(err, "roll back to the savepoint")
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "RELEASE bulk_item")
+		if err != nil {
+			return nil, errors.Wrap(err, "release the savepoint")
+		}
 	}
 
-	if result == 0 {
-		return result, sql.ErrNoRows
+	// every item is tried, so the atomic mode reports all of the failures.
+	if failed && atomic {
+		return errs, nil
 	}
 
-	return result, nil
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit tx")
+	}
+
+	return errs, nil
 }
 
-func (s Store) GetAll(ctx context.Context, cursor int64, limit int) ([]news.News, error) {
-	ctx, span := tracer.Start(ctx, "news.db.GetAll")
+func (s Store) GetIdsByFilter(ctx context.Context, f news.Filter, limit int) ([]uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetIdsByFilter")
 	defer span.End()
 
 	builder := sqlbuilder.NewSelectBuilder()
-
-	builder.Select("id", "title", "status", "body", "slug", "date_created", "date_updated")
+	builder.Select("id")
 	builder.From("news")
-	if cursor != 0 {
-		builder.Where(builder.LessThan("date_created", cursor))
-	}
+	whereFilter(builder, f)
 
 	builder.OrderBy("date_created").Desc()
-
-	if limit == 0 {
-		limit = 2
-	}
-
 	builder.Limit(limit)
 
 	query, args := builder.Build()
 
 	rows, err := s.conn.QueryContext(ctx, query, args...)
 	if err != nil {
-		return []news.News{}, errors.Wrap(err, "exec the query")
+		return []uuid.UUID{}, errors.Wrap(err, "exec the query")
 	}
 
 	defer rows.Close()
 
-	newsResults := make([]news.News, 0)
-	postIds := make([]uuid.UUID, 0)
+	ids := make([]uuid.UUID, 0)
 
 	for rows.Next() {
-		post := bareknews.Post{}
-		slug := new(bareknews.Slug)
-		status := new(bareknews.Status)
-		dateCreated := new(int64)
-		dateUpdted := new(int64)
-
-		err = rows.Scan(
-			&post.ID,
-			&post.Title,
-			status,
-			&post.Body,
-			slug,
-			dateCreated,
-			dateUpdted,
-		)
+		id := uuid.UUID{}
+		err = rows.Scan(&id)
 		if err != nil {
-			return []news.News{}, errors.Wrap(err, "scan a news item")
+			return []uuid.UUID{}, errors.Wrap(err, "scan a news id")
 		}
-
-		postIds = append(postIds, post.ID)
-
-		newsResults = append(newsResults, news.News{
-			Post:   post,
-			Status: *status,
-			Slug:   *slug,
-			DateCreated: *dateCreated,
-			DateUpdated: *dateUpdted,
-		})
+		ids = append(ids, id)
 	}
 
 	if rows.Err() != nil {
-		return []news.News{}, errors.Wrap(err, "failed get items during iteration")
+		return []uuid.UUID{}, errors.Wrap(rows.Err(), "failed get items during iteration")
 	}
 
-	tagIdBucket, err := s.getAllNewsTagsIds(ctx, postIds)
+	return ids, nil
+}
+
+// GetRelatedIds ranks the news in one query: published holds the
+// published news, df the number of them that have each tag, and the
+// shared tags of every other published news are weighted by their IDF
+// and the decay of its age.
+func (s Store) GetRelatedIds(ctx context.Context, id uuid.UUID, limit int, now int64) ([]uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetRelatedIds")
+	defer span.End()
+
+	query, args := sqlbuilder.Buildf(
+		"WITH links AS (SELECT DISTINCT newsID, tagsID FROM news_tags), "+
+			"published AS (SELECT id, date_created FROM news WHERE status = %s), "+
+			"df AS ("+
+			"SELECT links.tagsID AS tag, COUNT(*) AS n FROM links "+
+			"JOIN published ON published.id = links.newsID GROUP BY links.tagsID"+
+			"), "+
+			"total AS (SELECT COUNT(*) AS n FROM published) "+
+			"SELECT published.id FROM links source "+
+			"JOIN links other ON other.tagsID = source.tagsID AND other.newsID <> source.newsID "+
+			"JOIN published ON published.id = other.newsID "+
+			"JOIN df ON df.tag = source.tagsID "+
+			"CROSS JOIN total "+
+			"WHERE source.newsID = %s "+
+			"GROUP BY published.id, published.date_created "+
+			"ORDER BY SUM(ln(1.0 + 1.0 * total.n / df.n)) * "+
+			"exp(-ln(2.0) * max(0, %s - published.date_created) / %s) DESC, "+
+			"published.date_created DESC, published.id "+
+			"LIMIT %s",
+		bareknews.Publish, id, now, news.RelatedHalfLife.Seconds(), limit,
+	).Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
 	if err != nil {
-		return []news.News{}, errors.Wrap(err, "could not get tag ids")
+		return []uuid.UUID{}, errors.Wrap(err, "exec the query")
 	}
 
-	// Reconstruct news with addition TagsID property.
-	for i, elem := range newsResults {
-		newsResults[i] = news.News{
-			Post:   elem.Post,
-			Status: elem.Status,
-			Slug:   elem.Slug,
-			TagsID: tagIdBucket[elem.Post.ID],
-			DateCreated: elem.DateCreated,
-			DateUpdated: elem.DateUpdated,
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+
+	for rows.Next() {
+		relatedId := uuid.UUID{}
+		err = rows.Scan(&relatedId)
+		if err != nil {
+			return []uuid.UUID{}, errors.Wrap(err, "scan a news id")
 		}
+		ids = append(ids, relatedId)
 	}
 
-	return newsResults, nil
+	if rows.Err() != nil {
+		return []uuid.UUID{}, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return ids, nil
 }
 
-func (s Store) GetAllByPagination(ctx context.Context, cursor int64, limit int) ([]news.News, error) {
-	ctx, span := tracer.Start(ctx, "news.db.GetAllByPagination")
-	defer span.End()
+func (s Store) update(ctx context.Context, tx *sql.Tx, n news.News) error {
+	// the previous status tells an update from a publishing.
+	var previous bareknews.Status
 
-	builder := sqlbuilder.NewSelectBuilder()
+	err := tx.QueryRowContext(ctx, "SELECT status FROM news WHERE id = ?", n.Post.ID).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrap(err, "get the previous status")
+	}
 
-	builder.Select(
-		"id",
-		"title",
-		"status",
-		"body",
-		"slug",
-		"date_created",
-		"date_updated",
+	featured := featuredOf(n)
+
+	builder := sqlbuilder.NewUpdateBuilder()
+	builder.Update("news")
+	builder.Set(
+		builder.Assign("title", n.Post.Title),
+		builder.Assign("body", n.Post.Body),
+		builder.Assign("body_format", n.Post.Format),
+		builder.Assign("body_html", n.BodyHTML),
+		builder.Assign("excerpt", n.Excerpt),
+		builder.Assign("excerpt_custom", n.ExcerptCustom),
+		builder.Assign("word_count", n.WordCount),
+		builder.Assign("reading_time_minutes", n.ReadingTimeMinutes),
+		builder.Assign("featured_image_url", featured.URL),
+		builder.Assign("featured_image_alt", featured.Alt),
+		builder.Assign("featured_image_credit", featured.Credit),
+		builder.Assign("status", n.Status),
+		builder.Assign("slug", n.Slug),
+		builder.Assign("date_updated", n.DateUpdated),
 	)
-	builder.From("news")
 
-	if cursor != 0 {
-		builder.Where(builder.LessThan("date_created", cursor))
-	}
+	builder.Where(builder.Equal("id", n.Post.ID))
 
-	builder.OrderBy("date_created").Desc()
+	query, args := builder.Build()
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		if possibleErr, ok := err.(sqlite3.Error); ok {
+			if possibleErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return bareknews.ErrDataAlreadyExist
+			}
+		}
+		return errors.Wrap(err, "exec the query")
+	}
 
-	if limit == 0 {
-		limit = 2
+	// deleting relation between news and tags.
+	err = s.deleteNewsTagsRelation(ctx, tx, n.Post.ID)
+	if err != nil {
+		return errors.Wrap(err, "could not delete news-tags relation")
 	}
 
-	builder.Limit(limit)
+	// inserting new relation between news and tags if they
+	// exist.
+	err = s.insertNewsTagsRelation(ctx, tx, n)
+	if err != nil {
+		return errors.Wrap(err, "could not insert news-tags relation")
+	}
 
-	query, args := builder.Build()
+	// the gallery is replaced whole, like the tags.
+	err = s.deleteGallery(ctx, tx, n.Post.ID)
+	if err != nil {
+		return errors.Wrap(err, "could not delete the gallery")
+	}
 
-	rows, err := s.conn.QueryContext(ctx, query, args...)
+	err = s.insertGallery(ctx, tx, n)
 	if err != nil {
-		return []news.News{}, errors.Wrap(err, "exec the query")
+		return errors.Wrap(err, "could not insert the gallery")
 	}
 
-	defer rows.Close()
+	err = changesdb.Record(ctx, tx, changes.KindNews, false, n.Post.ID)
+	if err != nil {
+		return err
+	}
 
-	newsResults := make([]news.News, 0)
-	postIds := make([]uuid.UUID, 0)
+	kind := news.EventKind(previous, n.Status, false)
 
-	for rows.Next() {
-		post := bareknews.Post{}
-		slug := new(bareknews.Slug)
-		status := new(bareknews.Status)
-		dateCreated := new(int64)
-		dateUpdted := new(int64)
-
-		err = rows.Scan(
-			&post.ID,
-			&post.Title,
-			status,
-			&post.Body,
-			slug,
-			dateCreated,
-			dateUpdted,
-		)
-		if err != nil {
-			return []news.News{}, errors.Wrap(err, "scan a news item")
-		}
+	err = webhooksdb.Enqueue(ctx, tx, kind, n)
+	if err != nil {
+		return err
+	}
 
-		postIds = append(postIds, post.ID)
+	return streamdb.Append(ctx, tx, news.EventOf(kind, n))
+}
 
-		newsResults = append(newsResults, news.News{
-			Post:   post,
-			Status: *status,
-			Slug:   *slug,
-			DateCreated: *dateCreated,
-			DateUpdated: *dateUpdted,
-		})
+func (s Store) delete(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	// the events carry the news item as it was before it is deleted.
+	err := s.enqueueDeleted(ctx, tx, id)
+	if err != nil {
+		return err
 	}
 
-	if rows.Err() != nil {
-		return []news.News{}, errors.Wrap(err, "failed get items during iteration")
+	err = s.deleteNewsTagsRelation(ctx, tx, id)
+	if err != nil {
+		return errors.Wrap(err, "could not delete news-tags relation")
 	}
 
-	tagIdBucket, err := s.getAllNewsTagsIds(ctx, postIds)
+	err = s.deleteGallery(ctx, tx, id)
 	if err != nil {
-		return []news.News{}, errors.Wrap(err, "could not get tag ids")
+		return errors.Wrap(err, "could not delete the gallery")
 	}
 
-	// Reconstruct news with addition TagsID property.
-	for i, elem := range newsResults {
-		newsResults[i] = news.News{
-			Post:   elem.Post,
-			Status: elem.Status,
-			Slug:   elem.Slug,
-			TagsID: tagIdBucket[elem.Post.ID],
-			DateCreated: elem.DateCreated,
-			DateUpdated: elem.DateUpdated,
-		}
+	d := sqlbuilder.NewDeleteBuilder()
+	d.DeleteFrom("news")
+	d.Where(d.Equal("id", id))
+	sql, args := d.Build()
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
 	}
 
-	return newsResults, nil
+	return changesdb.Record(ctx, tx, changes.KindNews, true, id)
 }
 
-func (s Store) GetAllByTopic(ctx context.Context, topic uuid.UUID, cursor int64, limit int) ([]news.News, error) {
-	ctx, span := tracer.Start(ctx, "news.db.GetAllByTopic")
-	defer span.End()
-
-	newsIDs, err := s.getAllNewsIds(ctx, topic)
+// applyTx writes the change that apply returns for the news item.
+func (s Store) applyTx(ctx context.Context, tx *sql.Tx, n news.News, apply news.Apply) error {
+	c, err := apply(n)
 	if err != nil {
-		return []news.News{}, errors.Wrap(err, "could not get news ids")
+		return err
 	}
 
-	newsIdsStr := make([]string, 0)
+	if c.Delete {
+		return s.delete(ctx, tx, n.Post.ID)
+	}
 
-	for _, elem := range newsIDs {
-		newsIdsStr = append(newsIdsStr, elem.String())
+	return s.update(ctx, tx, c.News)
+}
+
+// enqueueDeleted writes the deleted events of the news item as it was.
+func (s Store) enqueueDeleted(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	n, err := s.getTx(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, bareknews.ErrDataNotFound) {
+			return nil
+		}
+		return err
 	}
 
-	newsIdMark := sqlbuilder.List(newsIdsStr)
-	builder := sqlbuilder.NewSelectBuilder()
+	err = webhooksdb.Enqueue(ctx, tx, news.EventDeleted, n)
+	if err != nil {
+		return err
+	}
 
-	builder.Select("id", "title", "status", "body", "slug","date_created", "date_updated",)
+	return streamdb.Append(ctx, tx, news.EventOf(news.EventDeleted, n))
+}
+
+// getTx reads the news item with its tags and its gallery in the
+// transaction, because the rows that another connection sees may be
+// older.
+func (s Store) getTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (news.News, error) {
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(newsColumns...)
 	builder.From("news")
-	builder.Where(builder.In("id", newsIdMark))
-	if cursor != 0 {
-		builder.Where(builder.LessThan("date_created", cursor))
-	}
+	builder.Where(builder.Equal("id", id))
+	query, args := builder.Build()
 
-	builder.OrderBy("date_created").Desc()
+	n, err := scanNews(tx.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return news.News{}, bareknews.ErrDataNotFound
+		}
+		return news.News{}, errors.Wrap(err, "scan a news item")
+	}
 
-	if limit == 0 {
-		limit = 2
+	rows, err := tx.QueryContext(ctx, "SELECT tagsID FROM news_tags WHERE newsID = ?", id)
+	if err != nil {
+		return news.News{}, errors.Wrap(err, "exec the query")
 	}
 
-	builder.Limit(limit)
+	defer rows.Close()
 
-	newsQuery, newsArgs := builder.Build()
+	for rows.Next() {
+		tagId := uuid.UUID{}
+		err = rows.Scan(&tagId)
+		if err != nil {
+			return news.News{}, errors.Wrap(err, "scan a tag id")
+		}
+		n.TagsID = append(n.TagsID, tagId)
+	}
 
-	newsRows, err := s.conn.QueryContext(ctx, newsQuery, newsArgs...)
-	if err != nil {
-		return []news.News{}, errors.Wrap(err, "exec the query")
+	if rows.Err() != nil {
+		return news.News{}, errors.Wrap(rows.Err(), "failed get items during iteration")
 	}
 
-	defer newsRows.Close()
+	gallery, err := tx.QueryContext(ctx, "SELECT url, caption FROM news_gallery WHERE newsID = ? ORDER BY position", id)
+	if err != nil {
+		return news.News{}, errors.Wrap(err, "exec the query")
+	}
 
-	newsResult := make([]news.News, 0)
-	postIds := make([]uuid.UUID, 0)
+	defer gallery.Close()
 
-	for newsRows.Next() {
-		post := bareknews.Post{}
-		slug := new(bareknews.Slug)
-		status := new(bareknews.Status)
-		dateCreated := new(int64)
-		dateUpdted := new(int64)
-
-		err = newsRows.Scan(
-			&post.ID,
-			&post.Title,
-			status,
-			&post.Body,
-			slug,
-			dateCreated,
-			dateUpdted,
-		)
+	for gallery.Next() {
+		g := news.GalleryItem{}
+		err = gallery.Scan(&g.URL, &g.Caption)
 		if err != nil {
-			return []news.News{}, errors.Wrap(err, "scan a news item")
+			return news.News{}, errors.Wrap(err, "scan a gallery item")
 		}
+		n.Gallery = append(n.Gallery, g)
+	}
+
+	if gallery.Err() != nil {
+		return news.News{}, errors.Wrap(gallery.Err(), "failed get items during iteration")
+	}
+
+	return n, nil
+}
+
+func (s Store) Count(ctx context.Context, id uuid.UUID) (int, error) {
+	ctx, span := tracer.Start(ctx, "news.db.Count")
+	defer span.End()
 
-		postIds = append(postIds, post.ID)
+	builder := sqlbuilder.NewSelectBuilder()
+
+	builder.Select(builder.As("COUNT(id)", "c"))
+	builder.From("news")
+	builder.Where(builder.Equal("id", id))
+
+	query, args := builder.Build()
+	row := s.conn.QueryRowContext(ctx, query, args...)
 
-		newsResult = append(newsResult, news.News{
-			Post:   post,
-			Status: *status,
-			Slug:   *slug,
-			DateCreated: *dateCreated,
-			DateUpdated: *dateUpdted,
-		})
+	var result int
+	err := row.Scan(&result)
+	if err != nil {
+		return result, errors.Wrap(err, "scan a total amount of news")
 	}
 
-	if newsRows.Err() != nil {
-		return []news.News{}, errors.Wrap(err, "failed get items during iteration")
+	if result == 0 {
+		return result, bareknews.ErrDataNotFound
 	}
 
-	tagIdsBucket, err := s.getAllNewsTagsIds(ctx, postIds)
+	return result, nil
+}
+
+func (s Store) GetAll(ctx context.Context, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetAll")
+	defer span.End()
+
+	return s.list(ctx, sqlbuilder.NewSelectBuilder(), after, limit, view)
+}
+
+func (s Store) GetAllByPagination(ctx context.Context, after *news.Cursor, limit int) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetAllByPagination")
+	defer span.End()
+
+	return s.list(ctx, sqlbuilder.NewSelectBuilder(), after, limit, news.FullView)
+}
+
+func (s Store) GetAllByTopic(ctx context.Context, topic uuid.UUID, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetAllByTopic")
+	defer span.End()
+
+	return s.GetAllByTopics(ctx, []uuid.UUID{topic}, after, limit, view)
+}
+
+func (s Store) GetAllByTopics(ctx context.Context, topics []uuid.UUID, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetAllByTopics")
+	defer span.End()
+
+	newsIDs, err := s.getAllNewsIds(ctx, topics...)
 	if err != nil {
-		return []news.News{}, errors.Wrap(err, "could not get tag ids in bucket")
+		return []news.News{}, errors.Wrap(err, "could not get news ids")
 	}
 
-	// Reconstruct news with addition TagsID property.
-	for i, elem := range newsResult {
-		newsResult[i] = news.News{
-			Post:   elem.Post,
-			Status: elem.Status,
-			Slug:   elem.Slug,
-			TagsID: tagIdsBucket[elem.Post.ID],
-			DateCreated: elem.DateCreated,
-			DateUpdated: elem.DateUpdated,
-		}
+	newsIdsStr := make([]string, 0)
+
+	for _, elem := range newsIDs {
+		newsIdsStr = append(newsIdsStr, elem.String())
+	}
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Where(builder.In("id", sqlbuilder.List(newsIdsStr)))
+
+	return s.list(ctx, builder, after, limit, view)
+}
+
+func (s Store) GetAllByFilter(ctx context.Context, f news.Filter, limit int, view news.View) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.db.GetAllByFilter")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	whereFilter(builder, f)
+
+	return s.list(ctx, builder, nil, limit, view)
+}
+
+// whereFilter adds the conditions of the filter to the builder.
+func whereFilter(builder *sqlbuilder.SelectBuilder, f news.Filter) {
+	if f.Status != "" {
+		builder.Where(builder.Equal("status", f.Status))
 	}
 
-	return newsResult, nil
+	if f.TagID != uuid.Nil {
+		builder.Where(builder.In("id", sqlbuilder.Buildf("SELECT newsID FROM news_tags WHERE tagsID = %s", f.TagID)))
+	}
 }
 
-func (s Store) GetAllByStatus(ctx context.Context, status bareknews.Status, cursor int64, limit int) ([]news.News, error) {
+func (s Store) GetAllByStatus(ctx context.Context, status bareknews.Status, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
 	ctx, span := tracer.Start(ctx, "news.db.GetAllByStatus")
 	defer span.End()
 
 	builder := sqlbuilder.NewSelectBuilder()
+	builder.Where(builder.Equal("status", status))
+
+	return s.list(ctx, builder, after, limit, view)
+}
+
+// list returns a page of the news that the builder selects, the newest
+// first. Only the columns of the view are read, the tag ids only when the
+// view has the tags and the galleries only when it has them.
+func (s Store) list(ctx context.Context, builder *sqlbuilder.SelectBuilder, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.db.list")
+	defer span.End()
 
-	builder.Select("id", "title", "status", "body", "slug", "date_created", "date_updated")
+	cols := columnsOf(view)
+
+	builder.Select(cols...)
 	builder.From("news")
-	builder.Where(builder.Equal("status", status))
-	if cursor != 0 {
-		builder.Where(builder.LessThan("date_created", cursor))
+	if after != nil {
+		builder.Where(builder.Or(
+			builder.LessThan("date_created", after.DateCreated),
+			builder.And(
+				builder.Equal("date_created", after.DateCreated),
+				builder.LessThan("id", after.ID),
+			),
+		))
 	}
 
-	builder.OrderBy("date_created").Desc()
+	builder.OrderBy("date_created DESC", "id DESC")
 
 	if limit == 0 {
 		limit = 2
@@ -523,82 +729,74 @@ func (s Store) GetAllByStatus(ctx context.Context, status bareknews.Status, curs
 
 	builder.Limit(limit)
 
-	newsQuery, newsArgs := builder.Build()
+	query, args := builder.Build()
 
-	newsRows, err := s.conn.QueryContext(ctx, newsQuery, newsArgs...)
+	rows, err := s.conn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return []news.News{}, errors.Wrap(err, "exec the query")
 	}
 
-	defer newsRows.Close()
+	defer rows.Close()
 
-	newsResult := make([]news.News, 0)
+	newsResults := make([]news.News, 0)
 	postIds := make([]uuid.UUID, 0)
 
-	for newsRows.Next() {
-		post := bareknews.Post{}
-		slug := new(bareknews.Slug)
-		status := new(bareknews.Status)
-		dateCreated := new(int64)
-		dateUpdted := new(int64)
-
-		err = newsRows.Scan(
-			&post.ID,
-			&post.Title,
-			status,
-			&post.Body,
-			slug,
-			dateCreated,
-			dateUpdted,
-		)
+	for rows.Next() {
+		n, err := scanNews(rows, cols...)
 		if err != nil {
 			return []news.News{}, errors.Wrap(err, "scan a news item")
 		}
 
-		postIds = append(postIds, post.ID)
+		postIds = append(postIds, n.Post.ID)
+		newsResults = append(newsResults, n)
+	}
 
-		newsResult = append(newsResult, news.News{
-			Post:   post,
-			Status: *status,
-			Slug:   *slug,
-			DateCreated: *dateCreated,
-			DateUpdated: *dateUpdted,
-		})
+	if rows.Err() != nil {
+		return []news.News{}, errors.Wrap(rows.Err(), "failed get items during iteration")
 	}
 
-	if newsRows.Err() != nil {
-		return []news.News{}, errors.Wrap(err, "failed get items during iteration")
+	if view.Has("gallery") {
+		galleries, err := s.getGalleries(ctx, postIds)
+		if err != nil {
+			return []news.News{}, errors.Wrap(err, "could not get the galleries")
+		}
+
+		for i, elem := range newsResults {
+			newsResults[i].Gallery = galleries[elem.Post.ID]
+		}
 	}
 
-	tagIdsBucket, err := s.getAllNewsTagsIds(ctx, postIds)
+	if !view.Tags {
+		return newsResults, nil
+	}
+
+	tagIdBucket, err := s.getAllNewsTagsIds(ctx, postIds)
 	if err != nil {
-		return []news.News{}, errors.Wrap(err, "could not get tag ids in bucket")
+		return []news.News{}, errors.Wrap(err, "could not get tag ids")
 	}
 
-	// Reconstruct news with addition TagsID property.
-	for i, elem := range newsResult {
-		newsResult[i] = news.News{
-			Post:   elem.Post,
-			Status: elem.Status,
-			Slug:   elem.Slug,
-			TagsID: tagIdsBucket[elem.Post.ID],
-			DateCreated: elem.DateCreated,
-			DateUpdated: elem.DateUpdated,
-		}
+	for i, elem := range newsResults {
+		newsResults[i].TagsID = tagIdBucket[elem.Post.ID]
 	}
 
-	return newsResult, nil
+	return newsResults, nil
 }
 
-func (s Store) getAllNewsIds(ctx context.Context, tagsID uuid.UUID) ([]uuid.UUID, error) {
+func (s Store) getAllNewsIds(ctx context.Context, tagsID ...uuid.UUID) ([]uuid.UUID, error) {
 	ctx, span := tracer.Start(ctx, "news.db.getAllNewsIds")
 	defer span.End()
 
+	tagsIdStr := make([]string, 0, len(tagsID))
+
+	for _, id := range tagsID {
+		tagsIdStr = append(tagsIdStr, id.String())
+	}
+
 	builder := sqlbuilder.NewSelectBuilder()
 	builder.Distinct()
 	builder.Select("newsID")
 	builder.From("news_tags")
-	builder.Where(builder.Equal("tagsID", tagsID))
+	builder.Where(builder.In("tagsID", sqlbuilder.List(tagsIdStr)))
 	query, args := builder.Build()
 
 	rows, err := s.conn.QueryContext(ctx, query, args...)
@@ -758,3 +956,217 @@ func (s Store) getAllTagIds(ctx context.Context, newsId uuid.UUID) ([]uuid.UUID,
 
 	return tagsResult, nil
 }
+
+func (s Store) insertGallery(ctx context.Context, tx *sql.Tx, n news.News) error {
+	_, span := tracer.Start(ctx, "news.db.insertGallery")
+	defer span.End()
+
+	if len(n.Gallery) == 0 {
+		return nil
+	}
+
+	builder := sqlbuilder.NewInsertBuilder()
+	builder.InsertInto("news_gallery")
+	builder.Cols("newsID", "position", "url", "caption")
+
+	for i, g := range n.Gallery {
+		builder.Values(n.Post.ID, i, g.URL, g.Caption)
+	}
+
+	query, args := builder.Build()
+
+	_, err := tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
+
+func (s Store) deleteGallery(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	_, span := tracer.Start(ctx, "news.db.deleteGallery")
+	defer span.End()
+
+	builder := sqlbuilder.NewDeleteBuilder()
+	builder.DeleteFrom("news_gallery")
+	builder.Where(builder.Equal("newsID", id))
+	query, args := builder.Build()
+
+	_, err := tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
+
+// getGalleries returns the galleries of the news, in order, by news id.
+func (s Store) getGalleries(ctx context.Context, newsIds []uuid.UUID) (map[uuid.UUID][]news.GalleryItem, error) {
+	_, span := tracer.Start(ctx, "news.db.getGalleries")
+	defer span.End()
+
+	idstr := make([]string, 0, len(newsIds))
+
+	for _, elem := range newsIds {
+		idstr = append(idstr, elem.String())
+	}
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("newsID", "url", "caption")
+	builder.From("news_gallery")
+	builder.Where(builder.In("newsID", sqlbuilder.List(idstr)))
+	builder.OrderBy("newsID", "position")
+	query, args := builder.Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	galleries := make(map[uuid.UUID][]news.GalleryItem)
+
+	for rows.Next() {
+		newsId := uuid.UUID{}
+		g := news.GalleryItem{}
+
+		err = rows.Scan(&newsId, &g.URL, &g.Caption)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan a gallery item")
+		}
+
+		galleries[newsId] = append(galleries[newsId], g)
+	}
+
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return galleries, nil
+}
+
+// featuredOf returns the featured image of the news, a blank one when it
+// has none. A blank URL is stored for no image.
+func featuredOf(n news.News) news.Image {
+	if n.FeaturedImage == nil {
+		return news.Image{}
+	}
+
+	return *n.FeaturedImage
+}
+
+// newsColumns are the columns of the news.
+var newsColumns = []string{
+	"id", "title", "status", "body", "body_format", "body_html",
+	"excerpt", "excerpt_custom", "word_count", "reading_time_minutes",
+	"slug", "date_created", "date_updated",
+	"featured_image_url", "featured_image_alt", "featured_image_credit",
+}
+
+// fieldColumns are the columns behind the fields of the output that
+// aren't named after their column.
+var fieldColumns = map[string][]string{
+	"excerpt":        {"excerpt", "excerpt_custom"},
+	"tags":           {},
+	"featured_image": {"featured_image_url", "featured_image_alt", "featured_image_credit"},
+	"gallery":        {},
+}
+
+// columnsOf returns the columns that the view needs. The id, the status
+// and the creation date are always read: they key, filter and page the
+// lists.
+func columnsOf(view news.View) []string {
+	if view.Fields == nil {
+		return newsColumns
+	}
+
+	cols := []string{"id", "status", "date_created"}
+
+	for _, f := range view.Fields {
+		fc, ok := fieldColumns[f]
+		if !ok {
+			fc = []string{f}
+		}
+
+		for _, c := range fc {
+			if !containsCol(cols, c) {
+				cols = append(cols, c)
+			}
+		}
+	}
+
+	return cols
+}
+
+func containsCol(cols []string, col string) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanNews scans the columns of a row, newsColumns when none is given.
+func scanNews(row scanner, cols ...string) (news.News, error) {
+	n := news.News{}
+	featured := news.Image{}
+
+	if len(cols) == 0 {
+		cols = newsColumns
+	}
+
+	dest := make([]interface{}, 0, len(cols))
+
+	for _, c := range cols {
+		switch c {
+		case "id":
+			dest = append(dest, &n.Post.ID)
+		case "title":
+			dest = append(dest, &n.Post.Title)
+		case "status":
+			dest = append(dest, &n.Status)
+		case "body":
+			dest = append(dest, &n.Post.Body)
+		case "body_format":
+			dest = append(dest, &n.Post.Format)
+		case "body_html":
+			dest = append(dest, &n.BodyHTML)
+		case "excerpt":
+			dest = append(dest, &n.Excerpt)
+		case "excerpt_custom":
+			dest = append(dest, &n.ExcerptCustom)
+		case "word_count":
+			dest = append(dest, &n.WordCount)
+		case "reading_time_minutes":
+			dest = append(dest, &n.ReadingTimeMinutes)
+		case "slug":
+			dest = append(dest, &n.Slug)
+		case "date_created":
+			dest = append(dest, &n.DateCreated)
+		case "date_updated":
+			dest = append(dest, &n.DateUpdated)
+		case "featured_image_url":
+			dest = append(dest, &featured.URL)
+		case "featured_image_alt":
+			dest = append(dest, &featured.Alt)
+		case "featured_image_credit":
+			dest = append(dest, &featured.Credit)
+		default:
+			return news.News{}, errors.Errorf("unknown column %q", c)
+		}
+	}
+
+	err := row.Scan(dest...)
+
+	if featured.URL != "" {
+		n.FeaturedImage = &featured
+	}
+
+	return n, err
+}
@@ -2,6 +2,7 @@ package db_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -37,6 +38,11 @@ func TestSaveNews(t *testing.T) {
 	is.Equal(len(got.TagsID), len(tgIds))
 	is.Equal(got.DateCreated, want.DateCreated)
 	is.Equal(got.DateUpdated, want.DateUpdated)
+	is.Equal(got.Post.Format, bareknews.Plain)
+	is.Equal(got.BodyHTML, want.BodyHTML)
+	is.Equal(got.Excerpt, want.Excerpt)
+	is.Equal(got.WordCount, 15)
+	is.Equal(got.ReadingTimeMinutes, 1)
 }
 
 func TestUpdateNews(t *testing.T) {
@@ -60,6 +66,8 @@ func TestUpdateNews(t *testing.T) {
 	news.ChangeTitle(wantTitle)
 	news.ChangeStatus(wantStatus)
 	news.ChangeTags(wantTags)
+	news.ChangeBodyFormat(bareknews.Markdown)
+	news.ChangeExcerpt("Written by hand.")
 	news.ChangeDateUpdated(time.Now().Unix())
 
 	err = newsStore.Update(context.TODO(), *news)
@@ -71,6 +79,10 @@ func TestUpdateNews(t *testing.T) {
 	is.Equal(got.Post.Title, wantTitle)
 	is.Equal(got.Status, wantStatus)
 	is.Equal(len(got.TagsID), len(wantTags))
+	is.Equal(got.Post.Format, bareknews.Markdown)
+	is.Equal(got.BodyHTML, news.BodyHTML)
+	is.Equal(got.Excerpt, "Written by hand.")
+	is.True(got.ExcerptCustom)
 }
 
 func TestDeleteNews(t *testing.T) {
@@ -121,6 +133,30 @@ func TestGetByID(t *testing.T) {
 	is.True(got.DateUpdated != 0)
 }
 
+func TestGetByIds(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	tgId := uuid.New()
+	first := news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{tgId}, 1)
+	second := news.Create("news 2", "news body", bareknews.Publish, nil, 2)
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	got, err := newsStore.GetByIds(context.TODO(), []uuid.UUID{first.Post.ID, uuid.New(), second.Post.ID}, news.FullView)
+	is.NoErr(err)
+	// the order of the ids is kept and the unknown id is left out.
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Post.ID, first.Post.ID)
+	is.Equal(got[0].TagsID, []uuid.UUID{tgId})
+	is.Equal(got[1].Post.ID, second.Post.ID)
+
+	got, err = newsStore.GetByIds(context.TODO(), nil, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+}
+
 func TestGetAllNews(t *testing.T) {
 	conn, _ := sqlite3.Run(sqlite3.Config{URI: "./../../bareknews.db", DropTableFirst: true})
 	newsStore := db.CreateStore(conn)
@@ -145,7 +181,13 @@ func TestGetAllNews(t *testing.T) {
 		t.Fatal(err.Error())
 	}
 
-	got, err := newsStore.GetAll(context.TODO(), 0, 2)
+	// the news created in the same second come by their ids, the
+	// greatest first.
+	if wantNews1.Post.ID.String() < wantNews2.Post.ID.String() {
+		wantNews1, wantNews2 = wantNews2, wantNews1
+	}
+
+	got, err := newsStore.GetAll(context.TODO(), nil, 2, news.FullView)
 	is := is.New(t)
 	is.NoErr(err)
 	is.Equal(len(got), 2)
@@ -191,7 +233,7 @@ func TestGetAllByTopic(t *testing.T) {
 		t.Fatal(err.Error())
 	}
 
-	got, err := newsStore.GetAllByTopic(context.TODO(), tgId, 0, 2)
+	got, err := newsStore.GetAllByTopic(context.TODO(), tgId, nil, 2, news.FullView)
 	is := is.New(t)
 	is.NoErr(err)
 	is.Equal(len(got), 2)
@@ -225,7 +267,7 @@ func TestGetAllByStatus(t *testing.T) {
 		t.Fatal(err.Error())
 	}
 
-	got, err := newsStore.GetAllByStatus(context.TODO(), bareknews.Publish, 0, 2)
+	got, err := newsStore.GetAllByStatus(context.TODO(), bareknews.Publish, nil, 2, news.FullView)
 	is := is.New(t)
 	is.NoErr(err)
 	is.Equal(len(got), 1)
@@ -283,7 +325,7 @@ func TestGetAllByPaginationNoCursor(t *testing.T) {
 
 	is := is.New(t)
 
-	got, err := newsStore.GetAllByPagination(context.TODO(), 0, 2)
+	got, err := newsStore.GetAllByPagination(context.TODO(), nil, 2)
 	is.NoErr(err)
 
 	is.Equal(len(got), 2)
@@ -333,8 +375,310 @@ func TestGetAllByPaginationWithCursor(t *testing.T) {
 
 	is := is.New(t)
 
-	got, err := newsStore.GetAllByPagination(context.TODO(), wantNews3.DateCreated, 2)
+	got, err := newsStore.GetAllByPagination(context.TODO(), &news.Cursor{DateCreated: wantNews3.DateCreated, ID: wantNews3.Post.ID}, 2)
 	is.NoErr(err)
 
 	is.Equal(len(got), 2)
-}
\ No newline at end of file
+}
+
+func TestGetAllSameSecond(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	for i := 0; i < 3; i++ {
+		nws := news.Create(fmt.Sprintf("news %d", i+1), "news body", bareknews.Draft, nil, 1)
+		is.NoErr(newsStore.Save(context.TODO(), *nws))
+	}
+
+	// the news created in the same second aren't skipped by the cursor.
+	first, err := newsStore.GetAll(context.TODO(), nil, 2, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(first), 2)
+
+	last := first[1]
+	second, err := newsStore.GetAll(context.TODO(), &news.Cursor{DateCreated: last.DateCreated, ID: last.Post.ID}, 2, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(second), 1)
+	is.True(second[0].Post.ID != first[0].Post.ID && second[0].Post.ID != first[1].Post.ID)
+}
+
+// bulkApply publishes the first news item and gives the title of the
+// first one to the second one, which is a duplicate then; the others are
+// deleted.
+func bulkApply(first, second uuid.UUID) news.Apply {
+	return func(n news.News) (news.Change, error) {
+		switch n.Post.ID {
+		case first:
+			n.ChangeStatus(bareknews.Publish)
+		case second:
+			n.ChangeTitle("news 1")
+		default:
+			return news.Change{News: n, Delete: true}, nil
+		}
+		return news.Change{News: n}, nil
+	}
+}
+
+func TestBulk(t *testing.T) {
+	t.Run("atomic mode rolls back every change", func(t *testing.T) {
+		conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+		newsStore := db.CreateStore(conn)
+		is := is.New(t)
+
+		first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+		second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+		is.NoErr(newsStore.Save(context.TODO(), *first))
+		is.NoErr(newsStore.Save(context.TODO(), *second))
+
+		ids := []uuid.UUID{first.Post.ID, second.Post.ID}
+		errs, err := newsStore.Bulk(context.TODO(), ids, bulkApply(first.Post.ID, second.Post.ID), true)
+		is.NoErr(err)
+		is.NoErr(errs[0])
+		is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+
+		got, err := newsStore.GetById(context.TODO(), first.Post.ID)
+		is.NoErr(err)
+		is.Equal(got.Status, bareknews.Draft)
+	})
+
+	t.Run("partial mode keeps the other changes", func(t *testing.T) {
+		conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+		newsStore := db.CreateStore(conn)
+		is := is.New(t)
+
+		first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+		second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+		third := news.Create("news 3", "news body", bareknews.Draft, nil, time.Now().Unix())
+		is.NoErr(newsStore.Save(context.TODO(), *first))
+		is.NoErr(newsStore.Save(context.TODO(), *second))
+		is.NoErr(newsStore.Save(context.TODO(), *third))
+
+		ids := []uuid.UUID{first.Post.ID, second.Post.ID, third.Post.ID, uuid.New()}
+		errs, err := newsStore.Bulk(context.TODO(), ids, bulkApply(first.Post.ID, second.Post.ID), false)
+		is.NoErr(err)
+		is.NoErr(errs[0])
+		is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+		is.NoErr(errs[2])
+		is.Equal(errs[3], bareknews.ErrDataNotFound)
+
+		got, err := newsStore.GetById(context.TODO(), first.Post.ID)
+		is.NoErr(err)
+		is.Equal(got.Status, bareknews.Publish)
+
+		got, err = newsStore.GetById(context.TODO(), second.Post.ID)
+		is.NoErr(err)
+		is.Equal(got.Post.Title, "news 2")
+
+		_, err = newsStore.GetById(context.TODO(), third.Post.ID)
+		is.Equal(err, bareknews.ErrDataNotFound)
+	})
+
+	t.Run("apply gets the item of the transaction", func(t *testing.T) {
+		conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+		newsStore := db.CreateStore(conn)
+		is := is.New(t)
+
+		tagID := uuid.New()
+		n := news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{tagID}, time.Now().Unix())
+		n.ChangeGallery([]news.GalleryItem{{URL: "https://example.com/a.png", Caption: "a"}})
+		is.NoErr(newsStore.Save(context.TODO(), *n))
+
+		// a write that comes before the bulk isn't lost.
+		n.ChangeTitle("news 2")
+		is.NoErr(newsStore.Update(context.TODO(), *n))
+
+		errs, err := newsStore.Bulk(context.TODO(), []uuid.UUID{n.Post.ID}, func(got news.News) (news.Change, error) {
+			is.Equal(got.Post.Title, "news 2")
+			is.Equal(got.TagsID, []uuid.UUID{tagID})
+			is.Equal(len(got.Gallery), 1)
+
+			got.ChangeStatus(bareknews.Publish)
+			return news.Change{News: got}, nil
+		}, true)
+		is.NoErr(err)
+		is.NoErr(errs[0])
+
+		got, err := newsStore.GetById(context.TODO(), n.Post.ID)
+		is.NoErr(err)
+		is.Equal(got.Post.Title, "news 2")
+		is.Equal(got.Status, bareknews.Publish)
+		is.Equal(len(got.Gallery), 1)
+	})
+}
+
+func TestGetIdsByFilter(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	tgId := uuid.New()
+	first := news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{tgId}, 1)
+	second := news.Create("news 2", "news body", bareknews.Draft, []uuid.UUID{tgId}, 2)
+	third := news.Create("news 3", "news body", bareknews.Publish, nil, 3)
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+	is.NoErr(newsStore.Save(context.TODO(), *third))
+
+	got, err := newsStore.GetIdsByFilter(context.TODO(), news.Filter{Status: bareknews.Publish}, 10)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{third.Post.ID, first.Post.ID})
+
+	got, err = newsStore.GetIdsByFilter(context.TODO(), news.Filter{TagID: tgId}, 10)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{second.Post.ID, first.Post.ID})
+
+	got, err = newsStore.GetIdsByFilter(context.TODO(), news.Filter{}, 1)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{third.Post.ID})
+
+	nws, err := newsStore.GetAllByFilter(context.TODO(), news.Filter{Status: bareknews.Publish, TagID: tgId}, 10, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(nws), 1)
+	is.Equal(nws[0].Post.ID, first.Post.ID)
+	is.Equal(nws[0].TagsID, []uuid.UUID{tgId})
+}
+
+func TestGetAllByTopics(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	tgId1, tgId2 := uuid.New(), uuid.New()
+
+	first := news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{tgId1}, 1)
+	second := news.Create("news 2", "news body", bareknews.Draft, []uuid.UUID{tgId1, tgId2}, 2)
+	third := news.Create("news 3", "news body", bareknews.Draft, nil, 3)
+	for _, nw := range []*news.News{first, second, third} {
+		is.NoErr(newsStore.Save(context.TODO(), *nw))
+	}
+
+	got, err := newsStore.GetAllByTopics(context.TODO(), []uuid.UUID{tgId1, tgId2}, nil, 10, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Post.ID, second.Post.ID)
+	is.Equal(got[1].Post.ID, first.Post.ID)
+}
+
+func TestGetAllSparse(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	tgId := uuid.New()
+	body := "Struct fields can also use tags to more specifically generate data for that field type."
+
+	want := news.Create("news 1", body, bareknews.Publish, []uuid.UUID{tgId}, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *want))
+
+	view := news.View{Fields: []string{"title", "excerpt"}}
+
+	got, err := newsStore.GetAll(context.TODO(), nil, 10, view)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Post.ID, want.Post.ID)
+	is.Equal(got[0].Post.Title, want.Post.Title)
+	is.Equal(got[0].Excerpt, want.Excerpt)
+	is.Equal(got[0].Status, want.Status)
+	is.Equal(got[0].DateCreated, want.DateCreated)
+	// The body and the tags are not read.
+	is.Equal(got[0].Post.Body, "")
+	is.Equal(got[0].BodyHTML, "")
+	is.Equal(len(got[0].TagsID), 0)
+
+	view.Tags = true
+
+	got, err = newsStore.GetAllByStatus(context.TODO(), bareknews.Publish, nil, 10, view)
+	is.NoErr(err)
+	is.Equal(got[0].TagsID, []uuid.UUID{tgId})
+	is.Equal(got[0].Post.Body, "")
+}
+
+func TestImages(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	gallery := []news.GalleryItem{
+		{URL: "https://example.com/1.jpg", Caption: "first"},
+		{URL: "https://example.com/2.jpg", Caption: "second"},
+		{URL: "https://example.com/3.jpg"},
+	}
+
+	want := news.Create("news 1", "news body", bareknews.Publish, nil, time.Now().Unix())
+	want.ChangeFeaturedImage(&news.Image{URL: "/media/a.jpg", Alt: "fans", Credit: "someone"})
+	want.ChangeGallery(gallery)
+	is.NoErr(newsStore.Save(context.TODO(), *want))
+
+	other := news.Create("news 2", "news body", bareknews.Publish, nil, time.Now().Unix()-1)
+	is.NoErr(newsStore.Save(context.TODO(), *other))
+
+	got, err := newsStore.GetById(context.TODO(), want.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.FeaturedImage, want.FeaturedImage)
+	is.Equal(got.Gallery, gallery)
+
+	got, err = newsStore.GetById(context.TODO(), other.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.FeaturedImage, nil)
+	is.Equal(len(got.Gallery), 0)
+
+	list, err := newsStore.GetAll(context.TODO(), nil, 10, news.View{Fields: []string{"featured_image", "gallery"}})
+	is.NoErr(err)
+	is.Equal(list[0].FeaturedImage, want.FeaturedImage)
+	is.Equal(list[0].Gallery, gallery)
+
+	// the gallery is replaced in its new order.
+	want.ChangeFeaturedImage(nil)
+	want.ChangeGallery([]news.GalleryItem{gallery[2], gallery[0]})
+	is.NoErr(newsStore.Update(context.TODO(), *want))
+
+	got, err = newsStore.GetById(context.TODO(), want.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.FeaturedImage, nil)
+	is.Equal(got.Gallery, []news.GalleryItem{gallery[2], gallery[0]})
+
+	is.NoErr(newsStore.Delete(context.TODO(), want.Post.ID))
+
+	var count int
+	is.NoErr(conn.QueryRow("SELECT COUNT(*) FROM news_gallery").Scan(&count))
+	is.Equal(count, 0)
+}
+
+func TestGetRelatedIds(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	const now, day = int64(1700000000), int64(86400)
+	// a is a rare tag and b a common one.
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	add := func(title string, status bareknews.Status, age int64, tgs ...uuid.UUID) uuid.UUID {
+		n := news.Create(title, "news body", status, tgs, now-age*day)
+		is.NoErr(newsStore.Save(context.TODO(), *n))
+		return n.Post.ID
+	}
+
+	source := add("source", bareknews.Publish, 0, a, b)
+	// the duplicated tag counts once.
+	rare := add("rare tag", bareknews.Publish, 1, a, a)
+	common := add("common tag", bareknews.Publish, 1, b)
+	both := add("both tags", bareknews.Publish, 3, a, b)
+	old := add("both tags but old", bareknews.Publish, 60, a, b)
+	for i := 0; i < 3; i++ {
+		add(fmt.Sprintf("filler %d", i), bareknews.Publish, 10, b)
+	}
+	add("draft", bareknews.Draft, 0, a, b)
+	add("unrelated", bareknews.Publish, 0, c)
+
+	got, err := newsStore.GetRelatedIds(context.TODO(), source, 10, now)
+	is.NoErr(err)
+	is.Equal(len(got), 7)
+	is.Equal(got[:3], []uuid.UUID{both, rare, common})
+	is.Equal(got[6], old)
+
+	got, err = newsStore.GetRelatedIds(context.TODO(), source, 2, now)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{both, rare})
+}
@@ -0,0 +1,67 @@
+package news
+
+import (
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/tags"
+)
+
+// The kinds of the events of the news. Published is a news item that goes
+// live: a draft that is published, or a news item that is created as
+// published, which comes as created and then as published.
+const (
+	EventCreated   = "created"
+	EventUpdated   = "updated"
+	EventPublished = "published"
+	EventDeleted   = "deleted"
+)
+
+// Event is a change of a news item. News is the item after the change,
+// or as it was before it is deleted.
+type Event struct {
+	Type string
+	News NewsOut
+}
+
+// EventOf returns the event of the change of a news item. The stores
+// write it in the transaction of the change, where only the IDs of the
+// tags are known, so the tags carry nothing but their ID.
+func EventOf(kind string, n News) Event {
+	tgs := make([]tags.TagsOut, 0, len(n.TagsID))
+	for _, id := range n.TagsID {
+		tgs = append(tgs, tags.TagsOut{ID: id})
+	}
+
+	return Event{Type: kind, News: createNewsOut(&n, tgs)}
+}
+
+// Notifier is told that the events of a change are written. The events
+// are already in the store, so it reads them from there.
+type Notifier interface {
+	Notify()
+}
+
+// WithEvents returns a copy of the service that notifies n of its
+// changes.
+func (s Service) WithEvents(n Notifier) Service {
+	s.events = n
+	return s
+}
+
+func (s Service) notify() {
+	if s.events != nil {
+		s.events.Notify()
+	}
+}
+
+// EventKind returns the kind of the event of a news item that is changed
+// from the status previous to current.
+func EventKind(previous, current bareknews.Status, deleted bool) string {
+	switch {
+	case deleted:
+		return EventDeleted
+	case previous != bareknews.Publish && current == bareknews.Publish:
+		return EventPublished
+	default:
+		return EventUpdated
+	}
+}
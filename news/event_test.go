@@ -0,0 +1,145 @@
+package news_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+// notifier is a news.Notifier that counts the notifications.
+type notifier struct {
+	count int
+}
+
+func (n *notifier) Notify() {
+	n.count++
+}
+
+func TestEvents(t *testing.T) {
+	stored := map[uuid.UUID]*news.News{}
+	store := &news.RepositoryMock{
+		SaveFunc: func(ctx context.Context, n news.News) error {
+			stored[n.Post.ID] = &n
+			return nil
+		},
+		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*news.News, error) {
+			n, ok := stored[id]
+			if !ok {
+				return nil, bareknews.ErrDataNotFound
+			}
+			copied := *n
+			return &copied, nil
+		},
+		UpdateFunc: func(ctx context.Context, n news.News) error {
+			stored[n.Post.ID] = &n
+			return nil
+		},
+		CountFunc: func(ctx context.Context, id uuid.UUID) (int, error) {
+			if _, ok := stored[id]; !ok {
+				return 0, bareknews.ErrDataNotFound
+			}
+			return 1, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			delete(stored, id)
+			return nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByNamesFunc: func(ctx context.Context, names ...string) ([]tags.Tags, error) {
+			return []tags.Tags{}, nil
+		},
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{}, nil
+		},
+	}
+
+	events := &notifier{}
+	svc := news.CreateSvc(store, tags.CreateSvc(tgStore)).WithEvents(events)
+	is := is.New(t)
+
+	input := news.NewsIn{Title: "news title", Body: "news body", Status: "draft"}
+	created, err := svc.Create(context.TODO(), input)
+	is.NoErr(err)
+
+	input.Status = "publish"
+	_, err = svc.Update(context.TODO(), created.ID, input)
+	is.NoErr(err)
+
+	_, err = svc.Patch(context.TODO(), created.ID, []byte(`{"title":"another title"}`))
+	is.NoErr(err)
+
+	is.NoErr(svc.Delete(context.TODO(), created.ID))
+
+	// nothing is notified when the change fails.
+	is.Equal(svc.Delete(context.TODO(), created.ID), bareknews.ErrDataNotFound)
+
+	is.Equal(events.count, 4)
+}
+
+func TestBulkEvents(t *testing.T) {
+	draft := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	published := news.Create("news 2", "news body", bareknews.Publish, nil, time.Now().Unix())
+	existing := map[uuid.UUID]*news.News{draft.Post.ID: draft, published.Post.ID: published}
+
+	store := &news.RepositoryMock{
+		BulkFunc: func(ctx context.Context, ids []uuid.UUID, apply news.Apply, atomic bool) ([]error, error) {
+			errs := make([]error, len(ids))
+			for i, id := range ids {
+				n, ok := existing[id]
+				if !ok {
+					errs[i] = bareknews.ErrDataNotFound
+					continue
+				}
+				_, errs[i] = apply(*n)
+			}
+			return errs, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{}, nil
+		},
+	}
+
+	events := &notifier{}
+	svc := news.CreateSvc(store, tags.CreateSvc(tgStore)).WithEvents(events)
+	is := is.New(t)
+
+	out, err := svc.Bulk(context.TODO(), news.BulkIn{
+		IDs:        []uuid.UUID{draft.Post.ID, published.Post.ID},
+		Operations: []news.BulkOperation{{Op: news.OpPublish}},
+	})
+	is.NoErr(err)
+	is.True(out.Applied)
+
+	// a bulk request is notified once.
+	is.Equal(events.count, 1)
+
+	out, err = svc.Bulk(context.TODO(), news.BulkIn{
+		IDs:        []uuid.UUID{uuid.New()},
+		Operations: []news.BulkOperation{{Op: news.OpPublish}},
+	})
+	is.NoErr(err)
+	is.True(!out.Applied)
+	is.Equal(events.count, 1)
+}
+
+func TestEventOf(t *testing.T) {
+	is := is.New(t)
+
+	tagID := uuid.New()
+	n := news.Create("news title", "news body", bareknews.Publish, []uuid.UUID{tagID}, time.Now().Unix())
+
+	e := news.EventOf(news.EventPublished, *n)
+	is.Equal(e.Type, news.EventPublished)
+	is.Equal(e.News.ID, n.Post.ID)
+	is.Equal(e.News.Status, "publish")
+	is.Equal(e.News.Tags, []tags.TagsOut{{ID: tagID}})
+}
@@ -2,7 +2,6 @@ package news
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -47,9 +46,6 @@ func (n handler) Create(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 	nws, err := n.service.Create(ctx, payloadIn)
 	if err != nil {
-		if errors.Is(err, bareknews.ErrDataAlreadyExist) {
-			return web.NewRequestError(bareknews.ErrDataAlreadyExist, http.StatusConflict)
-		}
 		return err
 	}
 
@@ -77,14 +73,11 @@ func (n handler) GetById(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	id, err := uuid.Parse(rawID)
 	if err != nil {
-		return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
+		return bareknews.ErrDataNotFound
 	}
 
 	nws, err := n.service.GetById(ctx, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
-		}
 		return err
 	}
 
@@ -96,9 +89,75 @@ func (n handler) GetById(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(w, payloadRes, http.StatusOK)
 }
 
+// GetRelatedNews godoc
+// @Summary      Get the related news
+// @Description  Get the published news that share the most tags with a news, the rare tags and the new stories counting more
+// @Tags         news
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "News ID"  Format(uuid)
+// @Param        limit   query     int     false  "number of news"	minimum(1) maximum(20) default(5)
+// @Success      200  {object}  web.RespBody{data=[]NewsOut} "The related news, the most related first"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /news/{id}/related [get]
+func (n handler) GetRelated(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "newsId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	limit := 0
+
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the limit"), http.StatusBadRequest)
+		}
+	}
+
+	nws, err := n.service.GetRelated(ctx, id, limit)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting the related news",
+		Data:    nws,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// GetTopicPage godoc
+// @Summary      Get the landing page of a tag
+// @Description  Get a tag by slug with its metadata and its newest published stories
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        slug   path      string  true  "Tag slug"
+// @Success      200  {object}  web.RespBody{data=TopicPage} "The tag with its stories"
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /tags/by-slug/{slug} [get]
+func (n handler) GetTopicPage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := n.service.GetTopicPage(ctx, chi.URLParam(r, "slug"))
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting a tag page",
+		Data:    page,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
 // UpdateNews godoc
 // @Summary      Update a news
-// @Description  Update a news and return it
+// @Description  Replace every field of a news and return it. The tags that aren't given are removed.
 // @Tags         news
 // @Accept       json
 // @Produce      json
@@ -113,7 +172,7 @@ func (n handler) Update(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	rawID := chi.URLParam(r, "newsId")
 	id, err := uuid.Parse(rawID)
 	if err != nil {
-		return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
+		return bareknews.ErrDataNotFound
 	}
 
 	payloadIn := NewsIn{}
@@ -125,9 +184,6 @@ func (n handler) Update(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 	nws, err := n.service.Update(ctx, id, payloadIn)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
-		}
 		return err
 	}
 
@@ -139,6 +195,45 @@ func (n handler) Update(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	return web.Respond(w, payloadRes, http.StatusOK)
 }
 
+// PatchNews godoc
+// @Summary      Patch a news
+// @Description  Partially update a news with a JSON merge patch (RFC 7396) and return it. A null member clears the field and a missing member keeps it.
+// @Tags         news
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        id   path      string  true  "News ID"  Format(uuid)
+// @Param news body NewsIn true "A merge patch of the news"
+// @Success      200  {object}  web.RespBody{data=posting.Response} "Response body for a news"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      415  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /news/{id} [patch]
+func (n handler) Patch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rawID := chi.URLParam(r, "newsId")
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	patch, err := web.ReadMergePatch(r)
+	if err != nil {
+		return err
+	}
+
+	nws, err := n.service.Patch(ctx, id, patch)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully patching a news",
+		Data:    nws,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
 // DeleteNews godoc
 // @Summary      Delete a news
 // @Description  Delete a news by id
@@ -155,14 +250,11 @@ func (n handler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 	id, err := uuid.Parse(rawID)
 	if err != nil {
-		return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
+		return bareknews.ErrDataNotFound
 	}
 
 	err = n.service.Delete(ctx, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
-		}
 		return err
 	}
 
@@ -182,7 +274,14 @@ func (n handler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Requ
 // @Produce      json
 // @Param   topic      query     string     false  "a topic"
 // @Param   status      query     string     false  "status of the news"	Enums(draft, publish)
-// @Success      200  {object}  web.RespBody{data=[]posting.Response} "Array of news body"
+// @Param   descendants      query     bool     false  "include the news of the topics below the topic"
+// @Param   cursor      query     string     false  "X-Next-Cursor of the previous page, or a Unix time to get the news created before it"
+// @Param   fields      query     string     false  "comma-separated fields to return, e.g. id,title,slug,tags,excerpt"
+// @Param   include      query     string     false  "relations to load; an empty value skips the tags"	Enums(tags)
+// @Success      200  {object}  web.RespBody{data=[]NewsOut} "Array of news body"
+// @Header       200  {string}  X-Next-Cursor  "cursor of the next page, left out on the last page"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
 // @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
 // @Router       /news [get]
 func (n handler) GetAll(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -191,56 +290,209 @@ func (n handler) GetAll(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	status := strings.TrimSpace(q.Get("status"))
 	rawCursor := strings.TrimSpace(q.Get("cursor"))
 
-	if rawCursor == "" {
-		rawCursor = "0"
+	after, err := parseCursor(rawCursor)
+	if err != nil {
+		return web.NewRequestError(errors.New("failed to convert the cursor"), http.StatusBadRequest)
 	}
 
-	cursor, err := strconv.ParseInt(rawCursor, 10, 64)
+	var include *string
+	if _, ok := q["include"]; ok {
+		i := q.Get("include")
+		include = &i
+	}
+
+	view, err := ParseView(q.Get("fields"), include)
 	if err != nil {
-		return web.NewRequestError(errors.New("failed to convert the cursor"), http.StatusBadRequest)
+		return err
+	}
+
+	getAllByTopic := n.service.GetAllByTopic
+
+	if rawDescendants := strings.TrimSpace(q.Get("descendants")); rawDescendants != "" {
+		descendants, err := strconv.ParseBool(rawDescendants)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the descendants"), http.StatusBadRequest)
+		}
+
+		if descendants {
+			getAllByTopic = n.service.GetAllBySection
+		}
 	}
 
 	newsRes := make([]NewsOut, 0)
+	// page is the page before the status filter, which moves the cursor.
+	var page []NewsOut
 
 	switch {
 	case topic != "" && status != "":
-		nws, err := n.service.GetAllByTopic(ctx, topic, cursor)
+		page, err = getAllByTopic(ctx, topic, after, view)
 		if err != nil {
 			return err
 		}
 
-		for _, n := range nws {
+		for _, n := range page {
 			if n.Status == status {
 				newsRes = append(newsRes, n)
 			}
 		}
 	case topic == "" && status != "":
-		nws, err := n.service.GetAllByStatus(ctx, status, cursor)
+		page, err = n.service.GetAllByStatus(ctx, status, after, view)
 		if err != nil {
 			return err
 		}
 
-		newsRes = append(newsRes, nws...)
+		newsRes = append(newsRes, page...)
 	case topic != "" && status == "":
-		nws, err := n.service.GetAllByTopic(ctx, topic, cursor)
+		page, err = getAllByTopic(ctx, topic, after, view)
 		if err != nil {
 			return err
 		}
 
-		newsRes = append(newsRes, nws...)
+		newsRes = append(newsRes, page...)
 	default:
-		nws, err := n.service.GetAll(ctx, cursor)
+		page, err = n.service.GetAll(ctx, after, view)
 		if err != nil {
 			return err
 		}
 
-		newsRes = append(newsRes, nws...)
+		newsRes = append(newsRes, page...)
+	}
+
+	// A full page may be followed by another one. The cursor goes in a
+	// header, so the body keeps the shape that the clients know.
+	if len(page) == PageSize {
+		last := page[len(page)-1]
+		w.Header().Set("X-Next-Cursor", encodeCursor(Cursor{DateCreated: last.DateCreated, ID: last.ID}))
+	}
+
+	data := make([]interface{}, 0, len(newsRes))
+
+	for _, nw := range newsRes {
+		item, err := view.Project(nw)
+		if err != nil {
+			return errors.Wrap(err, "project a news item")
+		}
+
+		data = append(data, item)
 	}
 
 	payloadRes := web.GeneralResponse{
 		Message: "Successfuly getting all news",
-		Data:    newsRes,
+		Data:    data,
 	}
 
 	return web.Respond(w, payloadRes, http.StatusOK)
 }
+
+type bulkItemOut struct {
+	ID     uuid.UUID `json:"id"`
+	Status int       `json:"status"`
+	Code   string    `json:"code,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+type bulkOut struct {
+	Mode    string        `json:"mode"`
+	Applied bool          `json:"applied"`
+	Results []bulkItemOut `json:"results"`
+}
+
+// BulkNews godoc
+// @Summary      Change many news at once
+// @Description  Apply the operations to the listed news or the news matched by the filter in one transaction.
+// @Description  The atomic mode writes nothing when an item fails, the partial mode writes the other items.
+// @Description  Every item has its own status in the results; the items that are rolled back because another one failed have 409.
+// @Tags         news
+// @Accept       json
+// @Produce      json
+// @Param bulk body BulkIn true "A payload of the bulk operations"
+// @Success      200  {object}  web.RespBody{data=bulkOut} "Every item is changed"
+// @Success      207  {object}  web.RespBody{data=bulkOut} "Some items are changed"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      422  {object}  web.RespBody{data=bulkOut} "Nothing is changed; the results tell the failed items and the rolled back ones (409)"
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /news/bulk [post]
+func (n handler) Bulk(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	payloadIn := BulkIn{}
+
+	err := json.NewDecoder(r.Body).Decode(&payloadIn)
+	if err != nil {
+		return bareknews.ErrInvalidJSON
+	}
+
+	res, err := n.service.Bulk(ctx, payloadIn)
+	if err != nil {
+		return err
+	}
+
+	out := bulkOut{
+		Mode:    res.Mode,
+		Applied: res.Applied,
+		Results: make([]bulkItemOut, 0, len(res.Results)),
+	}
+
+	failed := 0
+
+	for _, item := range res.Results {
+		if item.Err == nil {
+			out.Results = append(out.Results, bulkItemOut{ID: item.ID, Status: http.StatusOK})
+			continue
+		}
+
+		failed++
+		status := web.StatusOf(item.Err)
+
+		out.Results = append(out.Results, bulkItemOut{
+			ID:     item.ID,
+			Status: status,
+			Code:   web.CodeOf(item.Err, status),
+			Error:  web.DetailOf(item.Err, status),
+		})
+	}
+
+	status := http.StatusOK
+	message := "Successfully changing the news"
+
+	switch {
+	case failed > 0 && !res.Applied:
+		status = http.StatusUnprocessableEntity
+		message = "No news is changed"
+	case failed > 0:
+		status = http.StatusMultiStatus
+		message = "Some news are changed"
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: message,
+		Data:    out,
+	}
+
+	return web.Respond(w, payloadRes, status)
+}
+
+// parseCursor reads the cursor of the news list: the X-Next-Cursor of the
+// previous page, or the Unix time that the older clients send, which gets
+// the news created before it. Zero and an empty cursor start from the
+// newest.
+func parseCursor(raw string) (*Cursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if unix == 0 {
+			return nil, nil
+		}
+
+		// The nil UUID is below every ID, so only the date is compared.
+		return &Cursor{DateCreated: unix, ID: uuid.Nil}, nil
+	}
+
+	c, err := decodeCursor(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
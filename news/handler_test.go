@@ -0,0 +1,121 @@
+package news_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsmemory "github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/Iiqbal2000/bareknews/tags"
+	tagsmemory "github.com/Iiqbal2000/bareknews/tags/memory"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestBulkHandler(t *testing.T) {
+	store := newsmemory.CreateStore()
+	handler := news.CreateHandler(news.CreateSvc(store, tags.CreateSvc(tagsmemory.CreateStore())), zap.NewNop().Sugar())
+	is := is.New(t)
+
+	existing := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(store.Save(context.TODO(), *existing))
+
+	unknown := uuid.New()
+	body, err := json.Marshal(news.BulkIn{
+		IDs:        []uuid.UUID{unknown, existing.Post.ID},
+		Operations: []news.BulkOperation{{Op: news.OpPublish}},
+	})
+	is.NoErr(err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/news/bulk", bytes.NewReader(body))
+	is.NoErr(handler.Bulk(context.TODO(), w, r))
+
+	// the failed item has its own status and the other one is rolled back.
+	is.Equal(w.Code, http.StatusUnprocessableEntity)
+
+	var got struct {
+		Data struct {
+			Applied bool `json:"applied"`
+			Results []struct {
+				ID     uuid.UUID `json:"id"`
+				Status int       `json:"status"`
+				Code   string    `json:"code"`
+			} `json:"results"`
+		} `json:"data"`
+	}
+	is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+	is.True(!got.Data.Applied)
+	is.Equal(len(got.Data.Results), 2)
+	is.Equal(got.Data.Results[0].ID, unknown)
+	is.Equal(got.Data.Results[0].Status, http.StatusNotFound)
+	is.Equal(got.Data.Results[1].ID, existing.Post.ID)
+	is.Equal(got.Data.Results[1].Status, http.StatusConflict)
+	is.Equal(got.Data.Results[1].Code, "rolled_back")
+
+	stored, err := store.GetById(context.TODO(), existing.Post.ID)
+	is.NoErr(err)
+	is.Equal(stored.Status, bareknews.Draft)
+}
+
+func TestGetAllHandler(t *testing.T) {
+	store := newsmemory.CreateStore()
+	handler := news.CreateHandler(news.CreateSvc(store, tags.CreateSvc(tagsmemory.CreateStore())), zap.NewNop().Sugar())
+	is := is.New(t)
+
+	for i, date := range []int64{100, 200, 300} {
+		n := news.Create(fmt.Sprintf("news %d", i+1), "news body", bareknews.Publish, nil, date)
+		is.NoErr(store.Save(context.TODO(), *n))
+	}
+
+	get := func(cursor string) (*httptest.ResponseRecorder, []string) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/news?cursor="+cursor, nil)
+		is.NoErr(handler.GetAll(context.TODO(), w, r))
+
+		// the body keeps the shape of a plain list.
+		var got map[string]json.RawMessage
+		is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+		is.Equal(len(got), 2)
+
+		var items []news.NewsOut
+		is.NoErr(json.Unmarshal(got["data"], &items))
+
+		titles := make([]string, 0, len(items))
+		for _, n := range items {
+			titles = append(titles, n.Title)
+		}
+		return w, titles
+	}
+
+	w, titles := get("")
+	is.Equal(titles, []string{"news 3", "news 2"})
+
+	next := w.Header().Get("X-Next-Cursor")
+	is.True(next != "")
+
+	w, titles = get(next)
+	is.Equal(titles, []string{"news 1"})
+	is.Equal(w.Header().Get("X-Next-Cursor"), "")
+
+	// the older clients send the creation date of the last news item.
+	_, titles = get("300")
+	is.Equal(titles, []string{"news 2", "news 1"})
+
+	_, titles = get("0")
+	is.Equal(titles, []string{"news 3", "news 2"})
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/news?cursor=abc", nil)
+	err := handler.GetAll(context.TODO(), w, r)
+	is.Equal(web.StatusOf(err), http.StatusBadRequest)
+}
@@ -0,0 +1,86 @@
+package news
+
+import (
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// The limits of the images of a news item. The lengths are in characters.
+const (
+	MaxAltLength     = 300
+	MaxCreditLength  = 200
+	MaxCaptionLength = 500
+	MaxGalleryItems  = 50
+)
+
+// imageURL accepts an http(s) URL or a path of this server, such as a
+// size of the media library: "/media/{id}/640".
+var imageURL = validation.By(func(value interface{}) error {
+	s, _ := value.(string)
+	if strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//") {
+		return nil
+	}
+
+	if is.RequestURL.Validate(s) != nil || !httpURLRx.MatchString(s) {
+		return validation.NewError("validation_image_url", "must be an http or https URL or a path of this server")
+	}
+
+	return nil
+})
+
+// Image is the featured image of a news item. The alternative text is
+// required, so the image is described to the readers who can't see it.
+type Image struct {
+	URL    string
+	Alt    string
+	Credit string
+}
+
+func (i Image) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.URL, validation.Required, imageURL),
+		validation.Field(&i.Alt, validation.Required, validation.RuneLength(0, MaxAltLength).Error(
+			fmt.Sprintf("the length must be at most %d characters", MaxAltLength),
+		)),
+		validation.Field(&i.Credit, validation.RuneLength(0, MaxCreditLength).Error(
+			fmt.Sprintf("the length must be at most %d characters", MaxCreditLength),
+		)),
+	)
+}
+
+// GalleryItem is an image of the gallery of a news item. The gallery
+// keeps the order of its items.
+type GalleryItem struct {
+	URL     string
+	Caption string
+}
+
+func (g GalleryItem) Validate() error {
+	return validation.ValidateStruct(&g,
+		validation.Field(&g.URL, validation.Required, imageURL),
+		validation.Field(&g.Caption, validation.RuneLength(0, MaxCaptionLength).Error(
+			fmt.Sprintf("the length must be at most %d characters", MaxCaptionLength),
+		)),
+	)
+}
+
+// validateImages checks the featured image and the gallery of the news.
+func (n News) validateImages() error {
+	if n.FeaturedImage != nil {
+		if err := n.FeaturedImage.Validate(); err != nil {
+			return validation.Errors{"FeaturedImage": err}
+		}
+	}
+
+	err := validation.Validate(n.Gallery, validation.Length(0, MaxGalleryItems).Error(
+		fmt.Sprintf("must have at most %d images", MaxGalleryItems),
+	))
+	if err != nil {
+		return validation.Errors{"Gallery": err}
+	}
+
+	return nil
+}
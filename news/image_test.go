@@ -0,0 +1,55 @@
+package news_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/matryer/is"
+)
+
+func TestImages(t *testing.T) {
+	t.Run("valid images", func(t *testing.T) {
+		is := is.New(t)
+
+		nw := news.Create("news title", "news body", bareknews.Draft, nil, time.Now().Unix())
+		nw.ChangeFeaturedImage(&news.Image{URL: "https://example.com/a.jpg", Alt: "fans", Credit: "someone"})
+		nw.ChangeGallery([]news.GalleryItem{
+			{URL: "/media/0b6c8b5e-0b6c-4b5e-8b5e-0b6c8b5e0b6c/640", Caption: "the stadium"},
+			{URL: "http://example.com/b.png"},
+		})
+		is.NoErr(nw.Validate())
+	})
+
+	payloadTest := []struct {
+		name    string
+		image   *news.Image
+		gallery []news.GalleryItem
+		field   string
+	}{
+		{"featured image without alt", &news.Image{URL: "https://example.com/a.jpg"}, nil, "FeaturedImage"},
+		{"featured image with a javascript URL", &news.Image{URL: "javascript:alert(1)", Alt: "a"}, nil, "FeaturedImage"},
+		{"featured image with a protocol-relative URL", &news.Image{URL: "//example.com/a.jpg", Alt: "a"}, nil, "FeaturedImage"},
+		{"featured image with a long alt", &news.Image{URL: "/a.jpg", Alt: strings.Repeat("a", news.MaxAltLength+1)}, nil, "FeaturedImage"},
+		{"gallery item without URL", nil, []news.GalleryItem{{Caption: "a"}}, "Gallery"},
+		{"gallery item with an ftp URL", nil, []news.GalleryItem{{URL: "ftp://example.com/a.jpg"}}, "Gallery"},
+		{"gallery too long", nil, make([]news.GalleryItem, news.MaxGalleryItems+1), "Gallery"},
+	}
+
+	for _, pt := range payloadTest {
+		t.Run(pt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			nw := news.Create("news title", "news body", bareknews.Draft, nil, time.Now().Unix())
+			nw.ChangeFeaturedImage(pt.image)
+			nw.ChangeGallery(pt.gallery)
+
+			errs, ok := nw.Validate().(validation.Errors)
+			is.True(ok)
+			is.True(errs[pt.field] != nil)
+		})
+	}
+}
@@ -0,0 +1,611 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/changes"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/news/memory")
+
+// Store keeps the news in memory by their ID and hands out copies of
+// them. The lists return the whole news, whatever fields the view asks
+// for. It is safe for concurrent use.
+type Store struct {
+	mu      *sync.RWMutex
+	items   map[uuid.UUID]news.News
+	changes Changes
+	outbox  Outbox
+	log     Log
+}
+
+// Changes is the log of the changes of the news. The store records in it
+// while it holds its lock, so a reader never sees a change without its
+// entry.
+type Changes interface {
+	Record(kind string, deleted bool, ids ...uuid.UUID)
+}
+
+// Outbox receives the events of the news for the webhooks. The store
+// enqueues them while it holds its lock, like the changes.
+type Outbox interface {
+	Enqueue(event string, n news.News)
+}
+
+// Log receives the events of the news for the stream. The store appends
+// them while it holds its lock, like the changes.
+type Log interface {
+	Append(e news.Event)
+}
+
+// Ensure Store does implement news.Repository.
+var _ news.Repository = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:    &sync.RWMutex{},
+		items: make(map[uuid.UUID]news.News),
+	}
+}
+
+// WithChanges returns a copy of the store that records its changes in c.
+func (s Store) WithChanges(c Changes) Store {
+	s.changes = c
+	return s
+}
+
+// WithOutbox returns a copy of the store that enqueues its events in o.
+func (s Store) WithOutbox(o Outbox) Store {
+	s.outbox = o
+	return s
+}
+
+// WithLog returns a copy of the store that appends its events to l.
+func (s Store) WithLog(l Log) Store {
+	s.log = l
+	return s
+}
+
+func (s Store) Save(ctx context.Context, n news.News) error {
+	_, span := tracer.Start(ctx, "news.memory.Save")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[n.Post.ID]; ok {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	if s.isDuplicate(n) {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	s.items[n.Post.ID] = clone(n)
+	s.record(false, n.Post.ID)
+	s.enqueue(news.EventCreated, n)
+	s.append(news.EventCreated, n)
+
+	// a news item that is created as published is published too.
+	if n.Status == bareknews.Publish {
+		s.enqueue(news.EventPublished, n)
+		s.append(news.EventPublished, n)
+	}
+
+	return nil
+}
+
+func (s Store) GetById(ctx context.Context, id uuid.UUID) (*news.News, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetById")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.items[id]
+	if !ok {
+		return &news.News{}, bareknews.ErrDataNotFound
+	}
+
+	result := clone(rec)
+	return &result, nil
+}
+
+func (s Store) GetByIds(ctx context.Context, ids []uuid.UUID, view news.View) ([]news.News, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetByIds")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]news.News, 0, len(ids))
+
+	for _, id := range ids {
+		if rec, ok := s.items[id]; ok {
+			results = append(results, clone(rec))
+		}
+	}
+
+	return results, nil
+}
+
+func (s Store) Update(ctx context.Context, n news.News) error {
+	_, span := tracer.Start(ctx, "news.memory.Update")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.items[n.Post.ID]
+	if !ok {
+		return nil
+	}
+
+	if s.isDuplicate(n) {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	// the creation date is immutable in the SQLite store too.
+	n.DateCreated = rec.DateCreated
+	previous := rec.Status
+	rec = clone(n)
+	s.items[n.Post.ID] = rec
+	s.record(false, n.Post.ID)
+	s.enqueue(news.EventKind(previous, n.Status, false), n)
+	s.append(news.EventKind(previous, n.Status, false), n)
+
+	return nil
+}
+
+func (s Store) Delete(ctx context.Context, id uuid.UUID) error {
+	_, span := tracer.Start(ctx, "news.memory.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.items[id]; ok {
+		delete(s.items, id)
+		s.record(true, id)
+		s.enqueue(news.EventDeleted, rec)
+		s.append(news.EventDeleted, rec)
+	}
+
+	return nil
+}
+
+func (s Store) Count(ctx context.Context, id uuid.UUID) (int, error) {
+	_, span := tracer.Start(ctx, "news.memory.Count")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.items[id]; !ok {
+		return 0, bareknews.ErrDataNotFound
+	}
+
+	return 1, nil
+}
+
+// GetAll returns the whole news whatever the view is, as they are in
+// memory already.
+func (s Store) GetAll(ctx context.Context, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetAll")
+	defer span.End()
+
+	return s.filter(after, limit, func(news.News) bool { return true }), nil
+}
+
+func (s Store) GetAllByTopic(ctx context.Context, topic uuid.UUID, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	ctx, span := tracer.Start(ctx, "news.memory.GetAllByTopic")
+	defer span.End()
+
+	return s.GetAllByTopics(ctx, []uuid.UUID{topic}, after, limit, view)
+}
+
+func (s Store) GetAllByTopics(ctx context.Context, topics []uuid.UUID, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetAllByTopics")
+	defer span.End()
+
+	wanted := make(map[uuid.UUID]bool)
+	for _, id := range topics {
+		wanted[id] = true
+	}
+
+	return s.filter(after, limit, func(n news.News) bool {
+		for _, id := range n.TagsID {
+			if wanted[id] {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (s Store) GetAllByStatus(ctx context.Context, status bareknews.Status, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetAllByStatus")
+	defer span.End()
+
+	return s.filter(after, limit, func(n news.News) bool {
+		return n.Status == status
+	}), nil
+}
+
+func (s Store) GetAllByFilter(ctx context.Context, f news.Filter, limit int, view news.View) ([]news.News, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetAllByFilter")
+	defer span.End()
+
+	return s.filter(nil, limit, matchFilter(f)), nil
+}
+
+func (s Store) GetIdsByFilter(ctx context.Context, f news.Filter, limit int) ([]uuid.UUID, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetIdsByFilter")
+	defer span.End()
+
+	items := s.filter(nil, limit, matchFilter(f))
+
+	ids := make([]uuid.UUID, 0, len(items))
+
+	for _, n := range items {
+		ids = append(ids, n.Post.ID)
+	}
+
+	return ids, nil
+}
+
+func (s Store) GetRelatedIds(ctx context.Context, id uuid.UUID, limit int, now int64) ([]uuid.UUID, error) {
+	_, span := tracer.Start(ctx, "news.memory.GetRelatedIds")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	source, ok := s.items[id]
+	if !ok {
+		return []uuid.UUID{}, nil
+	}
+
+	shared := make(map[uuid.UUID]bool)
+	for _, tg := range source.TagsID {
+		shared[tg] = true
+	}
+
+	total := 0
+	df := make(map[uuid.UUID]int)
+
+	for _, rec := range s.items {
+		if rec.Status != bareknews.Publish {
+			continue
+		}
+
+		total++
+		for _, tg := range unique(rec.TagsID) {
+			df[tg]++
+		}
+	}
+
+	type scored struct {
+		news  news.News
+		score float64
+	}
+
+	ranked := make([]scored, 0)
+
+	for _, rec := range s.items {
+		if rec.Status != bareknews.Publish || rec.Post.ID == id {
+			continue
+		}
+
+		weight := 0.0
+		for _, tg := range unique(rec.TagsID) {
+			if shared[tg] {
+				weight += news.IDF(total, df[tg])
+			}
+		}
+
+		if weight > 0 {
+			score := weight * news.Decay(now-rec.DateCreated)
+			ranked = append(ranked, scored{news: rec, score: score})
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.news.DateCreated != b.news.DateCreated {
+			return a.news.DateCreated > b.news.DateCreated
+		}
+		return a.news.Post.ID.String() < b.news.Post.ID.String()
+	})
+
+	ids := make([]uuid.UUID, 0, limit)
+
+	for i := 0; i < len(ranked) && i < limit; i++ {
+		ids = append(ids, ranked[i].news.Post.ID)
+	}
+
+	return ids, nil
+}
+
+func unique(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	r := make([]uuid.UUID, 0, len(ids))
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			r = append(r, id)
+		}
+	}
+
+	return r
+}
+
+// Bulk reads and changes the items on a staged copy, which replaces the
+// stored items unless a change fails in the atomic mode. The lock is held
+// through both, like the transaction of the SQLite store.
+func (s Store) Bulk(ctx context.Context, ids []uuid.UUID, apply news.Apply, atomic bool) ([]error, error) {
+	_, span := tracer.Start(ctx, "news.memory.Bulk")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	staged := Store{mu: s.mu, items: make(map[uuid.UUID]news.News, len(s.items))}
+	for id, rec := range s.items {
+		staged.items[id] = rec
+	}
+
+	errs := make([]error, len(ids))
+	failed := false
+	// applied holds the changes that are written, and previous the items
+	// before them.
+	applied := make([]news.Change, 0, len(ids))
+	previous := make([]news.News, 0, len(ids))
+
+	for i, id := range ids {
+		rec, ok := staged.items[id]
+		if !ok {
+			errs[i] = bareknews.ErrDataNotFound
+			failed = true
+			continue
+		}
+
+		c, err := apply(clone(rec))
+		if err == nil && !c.Delete && staged.isDuplicate(c.News) {
+			err = bareknews.ErrDataAlreadyExist
+		}
+
+		if err != nil {
+			errs[i] = err
+			failed = true
+			continue
+		}
+
+		if c.Delete {
+			delete(staged.items, id)
+		} else {
+			c.News.DateCreated = rec.DateCreated
+			staged.items[id] = clone(c.News)
+		}
+
+		applied = append(applied, c)
+		previous = append(previous, rec)
+	}
+
+	// every item is tried, so the atomic mode reports all of the failures.
+	if failed && atomic {
+		return errs, nil
+	}
+
+	for id := range s.items {
+		if _, ok := staged.items[id]; !ok {
+			delete(s.items, id)
+		}
+	}
+
+	for id, rec := range staged.items {
+		s.items[id] = rec
+	}
+
+	for i, c := range applied {
+		s.record(c.Delete, c.News.Post.ID)
+
+		if c.Delete {
+			s.enqueue(news.EventDeleted, previous[i])
+			s.append(news.EventDeleted, previous[i])
+		} else {
+			kind := news.EventKind(previous[i].Status, c.News.Status, false)
+			s.enqueue(kind, c.News)
+			s.append(kind, c.News)
+		}
+	}
+
+	return errs, nil
+}
+
+// filter returns the items matching the predicate after the cursor, the
+// newest first.
+func (s Store) filter(after *news.Cursor, limit int, match func(news.News) bool) []news.News {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	recs := make([]news.News, 0)
+
+	for _, rec := range s.items {
+		if after != nil && !before(rec, *after) {
+			continue
+		}
+
+		if match(rec) {
+			recs = append(recs, rec)
+		}
+	}
+
+	sort.Slice(recs, func(i, j int) bool {
+		return before(recs[j], news.Cursor{DateCreated: recs[i].DateCreated, ID: recs[i].Post.ID})
+	})
+
+	if limit == 0 {
+		limit = 2
+	}
+
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+
+	results := make([]news.News, 0, len(recs))
+
+	for _, rec := range recs {
+		results = append(results, clone(rec))
+	}
+
+	return results
+}
+
+// isDuplicate reports whether another item already uses the title or
+// the slug of n. The caller must hold the lock.
+func (s Store) isDuplicate(n news.News) bool {
+	for id, rec := range s.items {
+		if id == n.Post.ID {
+			continue
+		}
+
+		if rec.Post.Title == n.Post.Title || rec.Slug == n.Slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// record records the changes of the news when the store has a log. The
+// caller must hold the lock.
+func (s Store) record(deleted bool, ids ...uuid.UUID) {
+	if s.changes != nil {
+		s.changes.Record(changes.KindNews, deleted, ids...)
+	}
+}
+
+// enqueue enqueues an event of the news item when the store has an
+// outbox. The caller must hold the lock.
+func (s Store) enqueue(event string, n news.News) {
+	if s.outbox != nil {
+		s.outbox.Enqueue(event, clone(n))
+	}
+}
+
+// append appends an event of the news item when the store has a log. The
+// caller must hold the lock.
+func (s Store) append(event string, n news.News) {
+	if s.log != nil {
+		s.log.Append(news.EventOf(event, clone(n)))
+	}
+}
+
+// RelinkTags replaces the source tags of every news with the target tag.
+// It lets the in-memory tags store merge tags.
+func (s Store) RelinkTags(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error {
+	_, span := tracer.Start(ctx, "news.memory.RelinkTags")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, rec := range s.items {
+		before := len(rec.TagsID)
+
+		rec.RemoveTags(sources)
+		if len(rec.TagsID) == before {
+			continue
+		}
+
+		rec.AddTags([]uuid.UUID{target})
+		s.items[id] = rec
+		s.record(false, id)
+	}
+
+	return nil
+}
+
+// CountByTags counts the news of every tag that have the status. It lets
+// the in-memory tags store count the usage of the tags.
+func (s Store) CountByTags(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error) {
+	_, span := tracer.Start(ctx, "news.memory.CountByTags")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int)
+
+	for _, rec := range s.items {
+		if status != "" && rec.Status != status {
+			continue
+		}
+
+		for _, id := range rec.TagsID {
+			counts[id]++
+		}
+	}
+
+	return counts, nil
+}
+
+// clone copies n so the stored item doesn't share the tags, the featured
+// image or the gallery with the caller.
+func clone(n news.News) news.News {
+	tagsID := make([]uuid.UUID, len(n.TagsID))
+	copy(tagsID, n.TagsID)
+	n.TagsID = tagsID
+
+	if n.FeaturedImage != nil {
+		img := *n.FeaturedImage
+		n.FeaturedImage = &img
+	}
+
+	gallery := make([]news.GalleryItem, len(n.Gallery))
+	copy(gallery, n.Gallery)
+	n.Gallery = gallery
+
+	return n
+}
+
+// before tells whether n comes after the cursor in the newest first
+// order, as the SQLite store compares the ids as text.
+func before(n news.News, c news.Cursor) bool {
+	if n.DateCreated != c.DateCreated {
+		return n.DateCreated < c.DateCreated
+	}
+
+	return n.Post.ID.String() < c.ID.String()
+}
+
+// matchFilter returns the predicate of the news that match the filter.
+func matchFilter(f news.Filter) func(news.News) bool {
+	return func(n news.News) bool {
+		if f.Status != "" && n.Status != f.Status {
+			return false
+		}
+
+		if f.TagID == uuid.Nil {
+			return true
+		}
+
+		for _, id := range n.TagsID {
+			if id == f.TagID {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,316 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestSaveNews(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	tgIds := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	want := news.Create("news 1", "news body", bareknews.Draft, tgIds, time.Now().Unix())
+	err := newsStore.Save(context.TODO(), *want)
+	is.NoErr(err)
+
+	got, err := newsStore.GetById(context.TODO(), want.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.Post.Title, want.Post.Title)
+	is.Equal(got.Status, want.Status)
+	is.Equal(got.Post.Body, want.Post.Body)
+	is.Equal(got.Slug, want.Slug)
+	is.Equal(len(got.TagsID), len(tgIds))
+	is.Equal(got.DateCreated, want.DateCreated)
+
+	// the stored item must not share memory with the caller.
+	firstTag := want.TagsID[0]
+	want.TagsID[0] = uuid.New()
+	got, err = newsStore.GetById(context.TODO(), want.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.TagsID[0], firstTag)
+}
+
+func TestSaveNewsDuplicate(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	err := newsStore.Save(context.TODO(), *first)
+	is.NoErr(err)
+
+	second := news.Create("news 1", "another body", bareknews.Draft, nil, time.Now().Unix())
+	err = newsStore.Save(context.TODO(), *second)
+	is.Equal(err, bareknews.ErrDataAlreadyExist)
+
+	third := news.Create("news 2", "another body", bareknews.Draft, nil, time.Now().Unix())
+	err = newsStore.Save(context.TODO(), *third)
+	is.NoErr(err)
+
+	third.ChangeTitle("news 1")
+	err = newsStore.Update(context.TODO(), *third)
+	is.Equal(err, bareknews.ErrDataAlreadyExist)
+}
+
+func TestUpdateNews(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	nws := news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{uuid.New()}, time.Now().Unix())
+	err := newsStore.Save(context.TODO(), *nws)
+	is.NoErr(err)
+
+	wantTags := []uuid.UUID{uuid.New(), uuid.New()}
+
+	nws.ChangeTitle("news 2")
+	nws.ChangeStatus(bareknews.Publish)
+	nws.ChangeTags(wantTags)
+
+	err = newsStore.Update(context.TODO(), *nws)
+	is.NoErr(err)
+
+	got, err := newsStore.GetById(context.TODO(), nws.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.Post.Title, "news 2")
+	is.Equal(got.Slug, bareknews.Slug("news-2"))
+	is.Equal(got.Status, bareknews.Publish)
+	is.Equal(len(got.TagsID), len(wantTags))
+}
+
+func TestDeleteNews(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	nws := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	err := newsStore.Save(context.TODO(), *nws)
+	is.NoErr(err)
+
+	c, err := newsStore.Count(context.TODO(), nws.Post.ID)
+	is.NoErr(err)
+	is.Equal(c, 1)
+
+	err = newsStore.Delete(context.TODO(), nws.Post.ID)
+	is.NoErr(err)
+
+	_, err = newsStore.GetById(context.TODO(), nws.Post.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	_, err = newsStore.Count(context.TODO(), nws.Post.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
+
+func TestGetAllNews(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	tgId := uuid.New()
+	items := make([]*news.News, 0)
+
+	for i, year := range []int{2009, 2010, 2011, 2012} {
+		status := bareknews.Draft
+		if i%2 == 0 {
+			status = bareknews.Publish
+		}
+
+		tgs := []uuid.UUID{}
+		if i < 3 {
+			tgs = append(tgs, tgId)
+		}
+
+		nws := news.Create(
+			fmt.Sprintf("news %d", i+1),
+			"news body",
+			status,
+			tgs,
+			time.Date(year, time.November, 10, 23, 0, 0, 0, time.UTC).Unix(),
+		)
+		is.NoErr(newsStore.Save(context.TODO(), *nws))
+		items = append(items, nws)
+	}
+
+	got, err := newsStore.GetAll(context.TODO(), nil, 2, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Post.ID, items[3].Post.ID)
+	is.Equal(got[1].Post.ID, items[2].Post.ID)
+
+	got, err = newsStore.GetAll(context.TODO(), &news.Cursor{DateCreated: got[1].DateCreated, ID: got[1].Post.ID}, 2, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Post.ID, items[1].Post.ID)
+	is.Equal(got[1].Post.ID, items[0].Post.ID)
+
+	got, err = newsStore.GetAllByTopic(context.TODO(), tgId, nil, 10, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 3)
+	is.Equal(got[0].Post.ID, items[2].Post.ID)
+
+	got, err = newsStore.GetAllByStatus(context.TODO(), bareknews.Publish, nil, 10, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Post.ID, items[2].Post.ID)
+	is.Equal(got[1].Post.ID, items[0].Post.ID)
+}
+
+func TestGetAllSameSecond(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	for i := 0; i < 3; i++ {
+		nws := news.Create(fmt.Sprintf("news %d", i+1), "news body", bareknews.Draft, nil, 1)
+		is.NoErr(newsStore.Save(context.TODO(), *nws))
+	}
+
+	// the news created in the same second aren't skipped by the cursor.
+	first, err := newsStore.GetAll(context.TODO(), nil, 2, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(first), 2)
+
+	last := first[1]
+	second, err := newsStore.GetAll(context.TODO(), &news.Cursor{DateCreated: last.DateCreated, ID: last.Post.ID}, 2, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(second), 1)
+	is.True(second[0].Post.ID != first[0].Post.ID && second[0].Post.ID != first[1].Post.ID)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nws := news.Create(uuid.NewString(), "news body", bareknews.Draft, nil, int64(i+1))
+			_ = newsStore.Save(context.TODO(), *nws)
+			_, _ = newsStore.GetAll(context.TODO(), nil, 10, news.FullView)
+		}(i)
+	}
+
+	wg.Wait()
+
+	got, err := newsStore.GetAll(context.TODO(), nil, 100, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 50)
+}
+
+func TestBulk(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, 1)
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, 2)
+	third := news.Create("news 3", "news body", bareknews.Draft, nil, 3)
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+	is.NoErr(newsStore.Save(context.TODO(), *third))
+
+	// the first item is published, the second one takes the title of the
+	// first one and the third one is deleted.
+	apply := func(n news.News) (news.Change, error) {
+		switch n.Post.ID {
+		case first.Post.ID:
+			n.ChangeStatus(bareknews.Publish)
+		case second.Post.ID:
+			n.ChangeTitle("news 1")
+		default:
+			return news.Change{News: n, Delete: true}, nil
+		}
+		return news.Change{News: n}, nil
+	}
+	ids := []uuid.UUID{first.Post.ID, second.Post.ID, third.Post.ID}
+
+	errs, err := newsStore.Bulk(context.TODO(), append(ids, uuid.New()), apply, true)
+	is.NoErr(err)
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+	is.Equal(errs[3], bareknews.ErrDataNotFound)
+
+	got, err := newsStore.GetById(context.TODO(), first.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.Status, bareknews.Draft)
+
+	errs, err = newsStore.Bulk(context.TODO(), ids, apply, false)
+	is.NoErr(err)
+	is.NoErr(errs[0])
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+	is.NoErr(errs[2])
+
+	got, err = newsStore.GetById(context.TODO(), first.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.Status, bareknews.Publish)
+
+	_, err = newsStore.GetById(context.TODO(), third.Post.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	drafts, err := newsStore.GetIdsByFilter(context.TODO(), news.Filter{Status: bareknews.Draft}, 10)
+	is.NoErr(err)
+	is.Equal(drafts, []uuid.UUID{second.Post.ID})
+}
+
+func TestSaveNewsImages(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	want := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	want.ChangeFeaturedImage(&news.Image{URL: "/media/a.jpg", Alt: "fans"})
+	want.ChangeGallery([]news.GalleryItem{{URL: "/media/b.jpg", Caption: "first"}})
+	is.NoErr(newsStore.Save(context.TODO(), *want))
+
+	// the stored item must not share the images with the caller.
+	want.FeaturedImage.Alt = "changed"
+	want.Gallery[0].Caption = "changed"
+
+	got, err := newsStore.GetById(context.TODO(), want.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.FeaturedImage.Alt, "fans")
+	is.Equal(got.Gallery[0].Caption, "first")
+}
+
+func TestGetRelatedIds(t *testing.T) {
+	newsStore := memory.CreateStore()
+	is := is.New(t)
+
+	const now, day = int64(1700000000), int64(86400)
+	// a is a rare tag and b a common one.
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	add := func(title string, status bareknews.Status, age int64, tgs ...uuid.UUID) uuid.UUID {
+		n := news.Create(title, "news body", status, tgs, now-age*day)
+		is.NoErr(newsStore.Save(context.TODO(), *n))
+		return n.Post.ID
+	}
+
+	source := add("source", bareknews.Publish, 0, a, b)
+	// the duplicated tag counts once.
+	rare := add("rare tag", bareknews.Publish, 1, a, a)
+	common := add("common tag", bareknews.Publish, 1, b)
+	both := add("both tags", bareknews.Publish, 3, a, b)
+	old := add("both tags but old", bareknews.Publish, 60, a, b)
+	for i := 0; i < 3; i++ {
+		add(fmt.Sprintf("filler %d", i), bareknews.Publish, 10, b)
+	}
+	add("draft", bareknews.Draft, 0, a, b)
+	add("unrelated", bareknews.Publish, 0, c)
+
+	got, err := newsStore.GetRelatedIds(context.TODO(), source, 10, now)
+	is.NoErr(err)
+	is.Equal(len(got), 7)
+	is.Equal(got[:3], []uuid.UUID{both, rare, common})
+	is.Equal(got[6], old)
+
+	got, err = newsStore.GetRelatedIds(context.TODO(), source, 2, now)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{both, rare})
+}
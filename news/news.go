@@ -1,7 +1,11 @@
 package news
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
 )
 
@@ -13,16 +17,38 @@ type News struct {
 	TagsID []uuid.UUID
 	DateCreated int64
 	DateUpdated int64
+	// BodyHTML is the sanitized HTML of the body. It follows the body and
+	// its format, as do the excerpt, the word count and the reading time.
+	BodyHTML string
+	// Excerpt is a plain text teaser of the body. It is computed unless
+	// the editor wrote it, which ExcerptCustom tells.
+	Excerpt            string
+	ExcerptCustom      bool
+	WordCount          int
+	ReadingTimeMinutes int
+	// FeaturedImage is nil when the news has none.
+	FeaturedImage *Image
+	Gallery       []GalleryItem
 }
 
 func Create(title, body string, status bareknews.Status, tags []uuid.UUID, timeNowUnix int64) *News {
+	n := create(title, body, status, tags, timeNowUnix)
+	n.render()
+
+	return n
+}
+
+// create is Create without the rendering, for the callers that change the
+// content before they render it.
+func create(title, body string, status bareknews.Status, tags []uuid.UUID, timeNowUnix int64) *News {
 	post := bareknews.Post{
-		ID:    uuid.New(),
-		Title: title,
-		Body:  body,
+		ID:     uuid.New(),
+		Title:  title,
+		Body:   body,
+		Format: bareknews.Plain,
 	}
 
-	return &News{
+	n := &News{
 		Post:   post,
 		Status: status,
 		Slug:   bareknews.NewSlug(post.Title),
@@ -30,10 +56,17 @@ func Create(title, body string, status bareknews.Status, tags []uuid.UUID, timeN
 		DateCreated: timeNowUnix,
 		DateUpdated: timeNowUnix,
 	}
+
+	return n
 }
 
 func (n News) Validate() error {
-	if err := n.Post.Validate(); err != nil {
+	return n.ValidateWith(bareknews.DefaultLimits)
+}
+
+// ValidateWith checks the news item with the limits of its post.
+func (n News) ValidateWith(l bareknews.Limits) error {
+	if err := n.Post.ValidateWith(l); err != nil {
 		return err
 	}
 
@@ -41,6 +74,25 @@ func (n News) Validate() error {
 		return err
 	}
 
+	err := validation.Validate(n.Excerpt,
+		validation.RuneLength(0, MaxExcerptLength).Error(
+			fmt.Sprintf("the length must be at most %d characters", MaxExcerptLength),
+		),
+	)
+	if err != nil {
+		return validation.Errors{"Excerpt": err}
+	}
+
+	if err := n.validateImages(); err != nil {
+		return err
+	}
+
+	if n.Post.Format == bareknews.Blocks {
+		if _, err := ParseDocument(n.Post.Body); err != nil {
+			return validation.Errors{"Body": err}
+		}
+	}
+
 	return nil
 }
 
@@ -59,12 +111,106 @@ func (n *News) ChangeStatus(newStatus bareknews.Status) {
 
 func (n *News) ChangeBody(newBody string) {
 	n.Post.Body = newBody
+	n.render()
+}
+
+func (n *News) ChangeBodyFormat(newFormat bareknews.BodyFormat) {
+	n.Post.Format = newFormat
+	n.render()
+}
+
+// ChangeExcerpt sets the excerpt that the editor wrote. A blank excerpt
+// goes back to the computed one.
+func (n *News) ChangeExcerpt(newExcerpt string) {
+	n.setExcerpt(newExcerpt)
+	n.render()
+}
+
+// ChangeContent sets the body, its format and the excerpt, and renders
+// them once.
+func (n *News) ChangeContent(newBody string, newFormat bareknews.BodyFormat, newExcerpt string) {
+	n.Post.Body = newBody
+	n.Post.Format = newFormat
+	n.setExcerpt(newExcerpt)
+	n.render()
+}
+
+func (n *News) setExcerpt(newExcerpt string) {
+	newExcerpt = strings.TrimSpace(newExcerpt)
+
+	n.ExcerptCustom = newExcerpt != ""
+	if n.ExcerptCustom {
+		n.Excerpt = newExcerpt
+	}
+}
+
+// render updates what follows the body: the HTML, the computed excerpt,
+// the word count and the reading time.
+func (n *News) render() {
+	n.BodyHTML = renderBody(n.Post.Body, n.Post.Format)
+
+	text := n.PlainText()
+	if !n.ExcerptCustom {
+		n.Excerpt = Excerpt(text, ExcerptLength)
+	}
+
+	n.WordCount = WordCount(text)
+	n.ReadingTimeMinutes = ReadingTime(n.WordCount)
+}
+
+// ChangeFeaturedImage sets the featured image; nil removes it.
+func (n *News) ChangeFeaturedImage(newImage *Image) {
+	n.FeaturedImage = newImage
+}
+
+// ChangeGallery replaces the images of the gallery, in order.
+func (n *News) ChangeGallery(newGallery []GalleryItem) {
+	n.Gallery = newGallery
 }
 
 func (n *News) ChangeTags(newTags []uuid.UUID) {
 	n.TagsID = newTags
 }
 
+// AddTags attaches the tags that the news doesn't have yet.
+func (n *News) AddTags(tagsID []uuid.UUID) {
+	for _, id := range tagsID {
+		if !n.hasTag(id) {
+			n.TagsID = append(n.TagsID, id)
+		}
+	}
+}
+
+// RemoveTags detaches the tags from the news.
+func (n *News) RemoveTags(tagsID []uuid.UUID) {
+	remaining := make([]uuid.UUID, 0, len(n.TagsID))
+
+	for _, id := range n.TagsID {
+		removed := false
+		for _, r := range tagsID {
+			if id == r {
+				removed = true
+				break
+			}
+		}
+
+		if !removed {
+			remaining = append(remaining, id)
+		}
+	}
+
+	n.TagsID = remaining
+}
+
+func (n News) hasTag(id uuid.UUID) bool {
+	for _, t := range n.TagsID {
+		if t == id {
+			return true
+		}
+	}
+	return false
+}
+
 func (n *News) ChangeDateUpdated(timeNowUnix int64) {
 	n.DateUpdated = timeNowUnix
 }
\ No newline at end of file
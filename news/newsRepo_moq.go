@@ -20,24 +20,42 @@ var _ Repository = &RepositoryMock{}
 //
 // 		// make and configure a mocked Repository
 // 		mockedRepository := &RepositoryMock{
+// 			BulkFunc: func(ctx context.Context, ids []uuid.UUID, apply Apply, atomic bool) ([]error, error) {
+// 				panic("mock out the Bulk method")
+// 			},
 // 			CountFunc: func(contextMoqParam context.Context, uUID uuid.UUID) (int, error) {
 // 				panic("mock out the Count method")
 // 			},
 // 			DeleteFunc: func(contextMoqParam context.Context, uUID uuid.UUID) error {
 // 				panic("mock out the Delete method")
 // 			},
-// 			GetAllFunc: func(ctx context.Context, cursor int64, limit int) ([]News, error) {
+// 			GetAllFunc: func(ctx context.Context, after *Cursor, limit int, view View) ([]News, error) {
 // 				panic("mock out the GetAll method")
 // 			},
-// 			GetAllByStatusFunc: func(ctx context.Context, status bareknews.Status, cursor int64, limit int) ([]News, error) {
+// 			GetAllByFilterFunc: func(ctx context.Context, f Filter, limit int, view View) ([]News, error) {
+// 				panic("mock out the GetAllByFilter method")
+// 			},
+// 			GetAllByStatusFunc: func(ctx context.Context, status bareknews.Status, after *Cursor, limit int, view View) ([]News, error) {
 // 				panic("mock out the GetAllByStatus method")
 // 			},
-// 			GetAllByTopicFunc: func(ctx context.Context, id uuid.UUID, cursor int64, limit int) ([]News, error) {
+// 			GetAllByTopicFunc: func(ctx context.Context, id uuid.UUID, after *Cursor, limit int, view View) ([]News, error) {
 // 				panic("mock out the GetAllByTopic method")
 // 			},
+// 			GetAllByTopicsFunc: func(ctx context.Context, ids []uuid.UUID, after *Cursor, limit int, view View) ([]News, error) {
+// 				panic("mock out the GetAllByTopics method")
+// 			},
 // 			GetByIdFunc: func(contextMoqParam context.Context, uUID uuid.UUID) (*News, error) {
 // 				panic("mock out the GetById method")
 // 			},
+// 			GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID, view View) ([]News, error) {
+// 				panic("mock out the GetByIds method")
+// 			},
+// 			GetIdsByFilterFunc: func(ctx context.Context, f Filter, limit int) ([]uuid.UUID, error) {
+// 				panic("mock out the GetIdsByFilter method")
+// 			},
+// 			GetRelatedIdsFunc: func(ctx context.Context, id uuid.UUID, limit int, now int64) ([]uuid.UUID, error) {
+// 				panic("mock out the GetRelatedIds method")
+// 			},
 // 			SaveFunc: func(contextMoqParam context.Context, news News) error {
 // 				panic("mock out the Save method")
 // 			},
@@ -51,6 +69,9 @@ var _ Repository = &RepositoryMock{}
 //
 // 	}
 type RepositoryMock struct {
+	// BulkFunc mocks the Bulk method.
+	BulkFunc func(ctx context.Context, ids []uuid.UUID, apply Apply, atomic bool) ([]error, error)
+
 	// CountFunc mocks the Count method.
 	CountFunc func(contextMoqParam context.Context, uUID uuid.UUID) (int, error)
 
@@ -58,17 +79,32 @@ type RepositoryMock struct {
 	DeleteFunc func(contextMoqParam context.Context, uUID uuid.UUID) error
 
 	// GetAllFunc mocks the GetAll method.
-	GetAllFunc func(ctx context.Context, cursor int64, limit int) ([]News, error)
+	GetAllFunc func(ctx context.Context, after *Cursor, limit int, view View) ([]News, error)
+
+	// GetAllByFilterFunc mocks the GetAllByFilter method.
+	GetAllByFilterFunc func(ctx context.Context, f Filter, limit int, view View) ([]News, error)
 
 	// GetAllByStatusFunc mocks the GetAllByStatus method.
-	GetAllByStatusFunc func(ctx context.Context, status bareknews.Status, cursor int64, limit int) ([]News, error)
+	GetAllByStatusFunc func(ctx context.Context, status bareknews.Status, after *Cursor, limit int, view View) ([]News, error)
 
 	// GetAllByTopicFunc mocks the GetAllByTopic method.
-	GetAllByTopicFunc func(ctx context.Context, id uuid.UUID, cursor int64, limit int) ([]News, error)
+	GetAllByTopicFunc func(ctx context.Context, id uuid.UUID, after *Cursor, limit int, view View) ([]News, error)
+
+	// GetAllByTopicsFunc mocks the GetAllByTopics method.
+	GetAllByTopicsFunc func(ctx context.Context, ids []uuid.UUID, after *Cursor, limit int, view View) ([]News, error)
 
 	// GetByIdFunc mocks the GetById method.
 	GetByIdFunc func(contextMoqParam context.Context, uUID uuid.UUID) (*News, error)
 
+	// GetByIdsFunc mocks the GetByIds method.
+	GetByIdsFunc func(ctx context.Context, ids []uuid.UUID, view View) ([]News, error)
+
+	// GetIdsByFilterFunc mocks the GetIdsByFilter method.
+	GetIdsByFilterFunc func(ctx context.Context, f Filter, limit int) ([]uuid.UUID, error)
+
+	// GetRelatedIdsFunc mocks the GetRelatedIds method.
+	GetRelatedIdsFunc func(ctx context.Context, id uuid.UUID, limit int, now int64) ([]uuid.UUID, error)
+
 	// SaveFunc mocks the Save method.
 	SaveFunc func(contextMoqParam context.Context, news News) error
 
@@ -77,6 +113,17 @@ type RepositoryMock struct {
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// Bulk holds details about calls to the Bulk method.
+		Bulk []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []uuid.UUID
+			// Apply is the apply argument value.
+			Apply Apply
+			// Atomic is the atomic argument value.
+			Atomic bool
+		}
 		// Count holds details about calls to the Count method.
 		Count []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -95,10 +142,23 @@ type RepositoryMock struct {
 		GetAll []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
-			// Cursor is the cursor argument value.
-			Cursor int64
+			// After is the after argument value.
+			After *Cursor
 			// Limit is the limit argument value.
 			Limit int
+			// View is the view argument value.
+			View View
+		}
+		// GetAllByFilter holds details about calls to the GetAllByFilter method.
+		GetAllByFilter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// F is the f argument value.
+			F Filter
+			// Limit is the limit argument value.
+			Limit int
+			// View is the view argument value.
+			View View
 		}
 		// GetAllByStatus holds details about calls to the GetAllByStatus method.
 		GetAllByStatus []struct {
@@ -106,10 +166,12 @@ type RepositoryMock struct {
 			Ctx context.Context
 			// Status is the status argument value.
 			Status bareknews.Status
-			// Cursor is the cursor argument value.
-			Cursor int64
+			// After is the after argument value.
+			After *Cursor
 			// Limit is the limit argument value.
 			Limit int
+			// View is the view argument value.
+			View View
 		}
 		// GetAllByTopic holds details about calls to the GetAllByTopic method.
 		GetAllByTopic []struct {
@@ -117,10 +179,25 @@ type RepositoryMock struct {
 			Ctx context.Context
 			// ID is the id argument value.
 			ID uuid.UUID
-			// Cursor is the cursor argument value.
-			Cursor int64
+			// After is the after argument value.
+			After *Cursor
 			// Limit is the limit argument value.
 			Limit int
+			// View is the view argument value.
+			View View
+		}
+		// GetAllByTopics holds details about calls to the GetAllByTopics method.
+		GetAllByTopics []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []uuid.UUID
+			// After is the after argument value.
+			After *Cursor
+			// Limit is the limit argument value.
+			Limit int
+			// View is the view argument value.
+			View View
 		}
 		// GetById holds details about calls to the GetById method.
 		GetById []struct {
@@ -129,6 +206,35 @@ type RepositoryMock struct {
 			// UUID is the uUID argument value.
 			UUID uuid.UUID
 		}
+		// GetByIds holds details about calls to the GetByIds method.
+		GetByIds []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []uuid.UUID
+			// View is the view argument value.
+			View View
+		}
+		// GetIdsByFilter holds details about calls to the GetIdsByFilter method.
+		GetIdsByFilter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// F is the f argument value.
+			F Filter
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetRelatedIds holds details about calls to the GetRelatedIds method.
+		GetRelatedIds []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uuid.UUID
+			// Limit is the limit argument value.
+			Limit int
+			// Now is the now argument value.
+			Now int64
+		}
 		// Save holds details about calls to the Save method.
 		Save []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -144,16 +250,65 @@ type RepositoryMock struct {
 			News News
 		}
 	}
+	lockBulk           sync.RWMutex
 	lockCount          sync.RWMutex
 	lockDelete         sync.RWMutex
 	lockGetAll         sync.RWMutex
+	lockGetAllByFilter sync.RWMutex
 	lockGetAllByStatus sync.RWMutex
 	lockGetAllByTopic  sync.RWMutex
+	lockGetAllByTopics sync.RWMutex
 	lockGetById        sync.RWMutex
+	lockGetByIds       sync.RWMutex
+	lockGetIdsByFilter sync.RWMutex
+	lockGetRelatedIds  sync.RWMutex
 	lockSave           sync.RWMutex
 	lockUpdate         sync.RWMutex
 }
 
+// Bulk calls BulkFunc.
+func (mock *RepositoryMock) Bulk(ctx context.Context, ids []uuid.UUID, apply Apply, atomic bool) ([]error, error) {
+	if mock.BulkFunc == nil {
+		panic("RepositoryMock.BulkFunc: method is nil but Repository.Bulk was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Ids    []uuid.UUID
+		Apply  Apply
+		Atomic bool
+	}{
+		Ctx:    ctx,
+		Ids:    ids,
+		Apply:  apply,
+		Atomic: atomic,
+	}
+	mock.lockBulk.Lock()
+	mock.calls.Bulk = append(mock.calls.Bulk, callInfo)
+	mock.lockBulk.Unlock()
+	return mock.BulkFunc(ctx, ids, apply, atomic)
+}
+
+// BulkCalls gets all the calls that were made to Bulk.
+// Check the length with:
+//     len(mockedRepository.BulkCalls())
+func (mock *RepositoryMock) BulkCalls() []struct {
+	Ctx    context.Context
+	Ids    []uuid.UUID
+	Apply  Apply
+	Atomic bool
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Ids    []uuid.UUID
+		Apply  Apply
+		Atomic bool
+	}
+	mock.lockBulk.RLock()
+	calls = mock.calls.Bulk
+	mock.lockBulk.RUnlock()
+	return calls
+}
+
 // Count calls CountFunc.
 func (mock *RepositoryMock) Count(contextMoqParam context.Context, uUID uuid.UUID) (int, error) {
 	if mock.CountFunc == nil {
@@ -225,37 +380,41 @@ func (mock *RepositoryMock) DeleteCalls() []struct {
 }
 
 // GetAll calls GetAllFunc.
-func (mock *RepositoryMock) GetAll(ctx context.Context, cursor int64, limit int) ([]News, error) {
+func (mock *RepositoryMock) GetAll(ctx context.Context, after *Cursor, limit int, view View) ([]News, error) {
 	if mock.GetAllFunc == nil {
 		panic("RepositoryMock.GetAllFunc: method is nil but Repository.GetAll was just called")
 	}
 	callInfo := struct {
-		Ctx    context.Context
-		Cursor int64
-		Limit  int
+		Ctx   context.Context
+		After *Cursor
+		Limit int
+		View  View
 	}{
-		Ctx:    ctx,
-		Cursor: cursor,
-		Limit:  limit,
+		Ctx:   ctx,
+		After: after,
+		Limit: limit,
+		View:  view,
 	}
 	mock.lockGetAll.Lock()
 	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
 	mock.lockGetAll.Unlock()
-	return mock.GetAllFunc(ctx, cursor, limit)
+	return mock.GetAllFunc(ctx, after, limit, view)
 }
 
 // GetAllCalls gets all the calls that were made to GetAll.
 // Check the length with:
 //     len(mockedRepository.GetAllCalls())
 func (mock *RepositoryMock) GetAllCalls() []struct {
-	Ctx    context.Context
-	Cursor int64
-	Limit  int
+	Ctx   context.Context
+	After *Cursor
+	Limit int
+	View  View
 } {
 	var calls []struct {
-		Ctx    context.Context
-		Cursor int64
-		Limit  int
+		Ctx   context.Context
+		After *Cursor
+		Limit int
+		View  View
 	}
 	mock.lockGetAll.RLock()
 	calls = mock.calls.GetAll
@@ -263,26 +422,71 @@ func (mock *RepositoryMock) GetAllCalls() []struct {
 	return calls
 }
 
+// GetAllByFilter calls GetAllByFilterFunc.
+func (mock *RepositoryMock) GetAllByFilter(ctx context.Context, f Filter, limit int, view View) ([]News, error) {
+	if mock.GetAllByFilterFunc == nil {
+		panic("RepositoryMock.GetAllByFilterFunc: method is nil but Repository.GetAllByFilter was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		F     Filter
+		Limit int
+		View  View
+	}{
+		Ctx:   ctx,
+		F:     f,
+		Limit: limit,
+		View:  view,
+	}
+	mock.lockGetAllByFilter.Lock()
+	mock.calls.GetAllByFilter = append(mock.calls.GetAllByFilter, callInfo)
+	mock.lockGetAllByFilter.Unlock()
+	return mock.GetAllByFilterFunc(ctx, f, limit, view)
+}
+
+// GetAllByFilterCalls gets all the calls that were made to GetAllByFilter.
+// Check the length with:
+//     len(mockedRepository.GetAllByFilterCalls())
+func (mock *RepositoryMock) GetAllByFilterCalls() []struct {
+	Ctx   context.Context
+	F     Filter
+	Limit int
+	View  View
+} {
+	var calls []struct {
+		Ctx   context.Context
+		F     Filter
+		Limit int
+		View  View
+	}
+	mock.lockGetAllByFilter.RLock()
+	calls = mock.calls.GetAllByFilter
+	mock.lockGetAllByFilter.RUnlock()
+	return calls
+}
+
 // GetAllByStatus calls GetAllByStatusFunc.
-func (mock *RepositoryMock) GetAllByStatus(ctx context.Context, status bareknews.Status, cursor int64, limit int) ([]News, error) {
+func (mock *RepositoryMock) GetAllByStatus(ctx context.Context, status bareknews.Status, after *Cursor, limit int, view View) ([]News, error) {
 	if mock.GetAllByStatusFunc == nil {
 		panic("RepositoryMock.GetAllByStatusFunc: method is nil but Repository.GetAllByStatus was just called")
 	}
 	callInfo := struct {
 		Ctx    context.Context
 		Status bareknews.Status
-		Cursor int64
+		After  *Cursor
 		Limit  int
+		View   View
 	}{
 		Ctx:    ctx,
 		Status: status,
-		Cursor: cursor,
+		After:  after,
 		Limit:  limit,
+		View:   view,
 	}
 	mock.lockGetAllByStatus.Lock()
 	mock.calls.GetAllByStatus = append(mock.calls.GetAllByStatus, callInfo)
 	mock.lockGetAllByStatus.Unlock()
-	return mock.GetAllByStatusFunc(ctx, status, cursor, limit)
+	return mock.GetAllByStatusFunc(ctx, status, after, limit, view)
 }
 
 // GetAllByStatusCalls gets all the calls that were made to GetAllByStatus.
@@ -291,14 +495,16 @@ func (mock *RepositoryMock) GetAllByStatus(ctx context.Context, status bareknews
 func (mock *RepositoryMock) GetAllByStatusCalls() []struct {
 	Ctx    context.Context
 	Status bareknews.Status
-	Cursor int64
+	After  *Cursor
 	Limit  int
+	View   View
 } {
 	var calls []struct {
 		Ctx    context.Context
 		Status bareknews.Status
-		Cursor int64
+		After  *Cursor
 		Limit  int
+		View   View
 	}
 	mock.lockGetAllByStatus.RLock()
 	calls = mock.calls.GetAllByStatus
@@ -307,41 +513,45 @@ func (mock *RepositoryMock) GetAllByStatusCalls() []struct {
 }
 
 // GetAllByTopic calls GetAllByTopicFunc.
-func (mock *RepositoryMock) GetAllByTopic(ctx context.Context, id uuid.UUID, cursor int64, limit int) ([]News, error) {
+func (mock *RepositoryMock) GetAllByTopic(ctx context.Context, id uuid.UUID, after *Cursor, limit int, view View) ([]News, error) {
 	if mock.GetAllByTopicFunc == nil {
 		panic("RepositoryMock.GetAllByTopicFunc: method is nil but Repository.GetAllByTopic was just called")
 	}
 	callInfo := struct {
-		Ctx    context.Context
-		ID     uuid.UUID
-		Cursor int64
-		Limit  int
+		Ctx   context.Context
+		ID    uuid.UUID
+		After *Cursor
+		Limit int
+		View  View
 	}{
-		Ctx:    ctx,
-		ID:     id,
-		Cursor: cursor,
-		Limit:  limit,
+		Ctx:   ctx,
+		ID:    id,
+		After: after,
+		Limit: limit,
+		View:  view,
 	}
 	mock.lockGetAllByTopic.Lock()
 	mock.calls.GetAllByTopic = append(mock.calls.GetAllByTopic, callInfo)
 	mock.lockGetAllByTopic.Unlock()
-	return mock.GetAllByTopicFunc(ctx, id, cursor, limit)
+	return mock.GetAllByTopicFunc(ctx, id, after, limit, view)
 }
 
 // GetAllByTopicCalls gets all the calls that were made to GetAllByTopic.
 // Check the length with:
 //     len(mockedRepository.GetAllByTopicCalls())
 func (mock *RepositoryMock) GetAllByTopicCalls() []struct {
-	Ctx    context.Context
-	ID     uuid.UUID
-	Cursor int64
-	Limit  int
+	Ctx   context.Context
+	ID    uuid.UUID
+	After *Cursor
+	Limit int
+	View  View
 } {
 	var calls []struct {
-		Ctx    context.Context
-		ID     uuid.UUID
-		Cursor int64
-		Limit  int
+		Ctx   context.Context
+		ID    uuid.UUID
+		After *Cursor
+		Limit int
+		View  View
 	}
 	mock.lockGetAllByTopic.RLock()
 	calls = mock.calls.GetAllByTopic
@@ -349,6 +559,53 @@ func (mock *RepositoryMock) GetAllByTopicCalls() []struct {
 	return calls
 }
 
+// GetAllByTopics calls GetAllByTopicsFunc.
+func (mock *RepositoryMock) GetAllByTopics(ctx context.Context, ids []uuid.UUID, after *Cursor, limit int, view View) ([]News, error) {
+	if mock.GetAllByTopicsFunc == nil {
+		panic("RepositoryMock.GetAllByTopicsFunc: method is nil but Repository.GetAllByTopics was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Ids   []uuid.UUID
+		After *Cursor
+		Limit int
+		View  View
+	}{
+		Ctx:   ctx,
+		Ids:   ids,
+		After: after,
+		Limit: limit,
+		View:  view,
+	}
+	mock.lockGetAllByTopics.Lock()
+	mock.calls.GetAllByTopics = append(mock.calls.GetAllByTopics, callInfo)
+	mock.lockGetAllByTopics.Unlock()
+	return mock.GetAllByTopicsFunc(ctx, ids, after, limit, view)
+}
+
+// GetAllByTopicsCalls gets all the calls that were made to GetAllByTopics.
+// Check the length with:
+//     len(mockedRepository.GetAllByTopicsCalls())
+func (mock *RepositoryMock) GetAllByTopicsCalls() []struct {
+	Ctx   context.Context
+	Ids   []uuid.UUID
+	After *Cursor
+	Limit int
+	View  View
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Ids   []uuid.UUID
+		After *Cursor
+		Limit int
+		View  View
+	}
+	mock.lockGetAllByTopics.RLock()
+	calls = mock.calls.GetAllByTopics
+	mock.lockGetAllByTopics.RUnlock()
+	return calls
+}
+
 // GetById calls GetByIdFunc.
 func (mock *RepositoryMock) GetById(contextMoqParam context.Context, uUID uuid.UUID) (*News, error) {
 	if mock.GetByIdFunc == nil {
@@ -384,6 +641,127 @@ func (mock *RepositoryMock) GetByIdCalls() []struct {
 	return calls
 }
 
+// GetByIds calls GetByIdsFunc.
+func (mock *RepositoryMock) GetByIds(ctx context.Context, ids []uuid.UUID, view View) ([]News, error) {
+	if mock.GetByIdsFunc == nil {
+		panic("RepositoryMock.GetByIdsFunc: method is nil but Repository.GetByIds was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Ids  []uuid.UUID
+		View View
+	}{
+		Ctx:  ctx,
+		Ids:  ids,
+		View: view,
+	}
+	mock.lockGetByIds.Lock()
+	mock.calls.GetByIds = append(mock.calls.GetByIds, callInfo)
+	mock.lockGetByIds.Unlock()
+	return mock.GetByIdsFunc(ctx, ids, view)
+}
+
+// GetByIdsCalls gets all the calls that were made to GetByIds.
+// Check the length with:
+//     len(mockedRepository.GetByIdsCalls())
+func (mock *RepositoryMock) GetByIdsCalls() []struct {
+	Ctx  context.Context
+	Ids  []uuid.UUID
+	View View
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Ids  []uuid.UUID
+		View View
+	}
+	mock.lockGetByIds.RLock()
+	calls = mock.calls.GetByIds
+	mock.lockGetByIds.RUnlock()
+	return calls
+}
+
+// GetIdsByFilter calls GetIdsByFilterFunc.
+func (mock *RepositoryMock) GetIdsByFilter(ctx context.Context, f Filter, limit int) ([]uuid.UUID, error) {
+	if mock.GetIdsByFilterFunc == nil {
+		panic("RepositoryMock.GetIdsByFilterFunc: method is nil but Repository.GetIdsByFilter was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		F     Filter
+		Limit int
+	}{
+		Ctx:   ctx,
+		F:     f,
+		Limit: limit,
+	}
+	mock.lockGetIdsByFilter.Lock()
+	mock.calls.GetIdsByFilter = append(mock.calls.GetIdsByFilter, callInfo)
+	mock.lockGetIdsByFilter.Unlock()
+	return mock.GetIdsByFilterFunc(ctx, f, limit)
+}
+
+// GetIdsByFilterCalls gets all the calls that were made to GetIdsByFilter.
+// Check the length with:
+//     len(mockedRepository.GetIdsByFilterCalls())
+func (mock *RepositoryMock) GetIdsByFilterCalls() []struct {
+	Ctx   context.Context
+	F     Filter
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		F     Filter
+		Limit int
+	}
+	mock.lockGetIdsByFilter.RLock()
+	calls = mock.calls.GetIdsByFilter
+	mock.lockGetIdsByFilter.RUnlock()
+	return calls
+}
+
+// GetRelatedIds calls GetRelatedIdsFunc.
+func (mock *RepositoryMock) GetRelatedIds(ctx context.Context, id uuid.UUID, limit int, now int64) ([]uuid.UUID, error) {
+	if mock.GetRelatedIdsFunc == nil {
+		panic("RepositoryMock.GetRelatedIdsFunc: method is nil but Repository.GetRelatedIds was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		ID    uuid.UUID
+		Limit int
+		Now   int64
+	}{
+		Ctx:   ctx,
+		ID:    id,
+		Limit: limit,
+		Now:   now,
+	}
+	mock.lockGetRelatedIds.Lock()
+	mock.calls.GetRelatedIds = append(mock.calls.GetRelatedIds, callInfo)
+	mock.lockGetRelatedIds.Unlock()
+	return mock.GetRelatedIdsFunc(ctx, id, limit, now)
+}
+
+// GetRelatedIdsCalls gets all the calls that were made to GetRelatedIds.
+// Check the length with:
+//     len(mockedRepository.GetRelatedIdsCalls())
+func (mock *RepositoryMock) GetRelatedIdsCalls() []struct {
+	Ctx   context.Context
+	ID    uuid.UUID
+	Limit int
+	Now   int64
+} {
+	var calls []struct {
+		Ctx   context.Context
+		ID    uuid.UUID
+		Limit int
+		Now   int64
+	}
+	mock.lockGetRelatedIds.RLock()
+	calls = mock.calls.GetRelatedIds
+	mock.lockGetRelatedIds.RUnlock()
+	return calls
+}
+
 // Save calls SaveFunc.
 func (mock *RepositoryMock) Save(contextMoqParam context.Context, news News) error {
 	if mock.SaveFunc == nil {
@@ -452,4 +830,4 @@ func (mock *RepositoryMock) UpdateCalls() []struct {
 	calls = mock.calls.Update
 	mock.lockUpdate.RUnlock()
 	return calls
-}
\ No newline at end of file
+}
@@ -1,6 +1,7 @@
 package news_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -80,6 +81,21 @@ func TestChangeBody(t *testing.T) {
 	is.Equal(news.Post.Body, "Changing the body")
 }
 
+func TestChangeContent(t *testing.T) {
+	news := news.Create("Test 1", "testing", bareknews.Draft, nil, time.Now().Unix())
+	is := is.New(t)
+
+	news.ChangeContent("**bold** body", bareknews.Markdown, "")
+	is.Equal(news.Post.Format, bareknews.Markdown)
+	is.True(strings.Contains(news.BodyHTML, "<strong>bold</strong>"))
+	is.Equal(news.Excerpt, "bold body")
+	is.Equal(news.WordCount, 2)
+
+	news.ChangeContent("**bold** body", bareknews.Markdown, "  Written  ")
+	is.True(news.ExcerptCustom)
+	is.Equal(news.Excerpt, "Written")
+}
+
 func TestChangeStatus(t *testing.T) {
 	tagId := uuid.New()
 	news := news.Create("Test 1", "testing", bareknews.Draft, []uuid.UUID{tagId}, time.Now().Unix())
@@ -108,4 +124,61 @@ func TestChangeTags(t *testing.T) {
 	err = news.Validate()
 	is.NoErr(err)
 	is.Equal(news.TagsID[0], newTag)
-}
\ No newline at end of file
+}
+func TestAddAndRemoveTags(t *testing.T) {
+	tag1, tag2, tag3 := uuid.New(), uuid.New(), uuid.New()
+	news := news.Create("Test 1", "testing", bareknews.Draft, []uuid.UUID{tag1}, time.Now().Unix())
+
+	is := is.New(t)
+
+	news.AddTags([]uuid.UUID{tag1, tag2, tag3})
+	is.Equal(news.TagsID, []uuid.UUID{tag1, tag2, tag3})
+
+	news.RemoveTags([]uuid.UUID{tag1, tag3})
+	is.Equal(news.TagsID, []uuid.UUID{tag2})
+}
+
+func TestChangeBodyFormat(t *testing.T) {
+	is := is.New(t)
+
+	t.Run("plain text is escaped", func(t *testing.T) {
+		news := news.Create("Test 1", "a <b>\n\nc", bareknews.Draft, nil, time.Now().Unix())
+		is.Equal(news.Post.Format, bareknews.Plain)
+		is.Equal(news.BodyHTML, "<p>a &lt;b&gt;</p>\n<p>c</p>\n")
+	})
+
+	t.Run("markdown is rendered", func(t *testing.T) {
+		news := news.Create("Test 1", "# Title\n\n**bold** [link](https://example.com)", bareknews.Draft, nil, time.Now().Unix())
+		news.ChangeBodyFormat(bareknews.Markdown)
+		is.NoErr(news.Validate())
+		is.True(strings.Contains(news.BodyHTML, "<h1>Title</h1>"))
+		is.True(strings.Contains(news.BodyHTML, "<strong>bold</strong>"))
+		is.True(strings.Contains(news.BodyHTML, `href="https://example.com"`))
+	})
+
+	t.Run("scripts, handlers and javascript URLs are removed", func(t *testing.T) {
+		body := `<p onclick="steal()">hi</p><script>alert(1)</script><a href="javascript:alert(1)">x</a>`
+
+		for _, f := range []bareknews.BodyFormat{bareknews.HTML, bareknews.Markdown} {
+			news := news.Create("Test 1", body, bareknews.Draft, nil, time.Now().Unix())
+			news.ChangeBodyFormat(f)
+			is.True(!strings.Contains(news.BodyHTML, "script"))
+			is.True(!strings.Contains(news.BodyHTML, "onclick"))
+			is.True(!strings.Contains(news.BodyHTML, "javascript:"))
+			is.True(strings.Contains(news.BodyHTML, "hi"))
+		}
+	})
+
+	t.Run("the body is rendered again when it changes", func(t *testing.T) {
+		news := news.Create("Test 1", "old", bareknews.Draft, nil, time.Now().Unix())
+		news.ChangeBodyFormat(bareknews.Markdown)
+		news.ChangeBody("*new*")
+		is.Equal(news.BodyHTML, "<p><em>new</em></p>\n")
+	})
+
+	t.Run("unknown format is invalid", func(t *testing.T) {
+		news := news.Create("Test 1", "testing", bareknews.Draft, nil, time.Now().Unix())
+		news.ChangeBodyFormat("rtf")
+		is.True(news.Validate() != nil)
+	})
+}
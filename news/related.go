@@ -0,0 +1,80 @@
+package news
+
+import (
+	"context"
+	"math"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRelatedLimit is the number of related stories when no limit
+	// is given.
+	DefaultRelatedLimit = 5
+	// MaxRelatedLimit is the maximum number of related stories.
+	MaxRelatedLimit = 20
+	// RelatedHalfLife is the age at which a related story counts half as
+	// much as a new one.
+	RelatedHalfLife = 7 * 24 * time.Hour
+)
+
+// The score of a related story is the sum of the IDF of the tags it
+// shares with the story, times the decay of its age:
+//
+//	score = Σ IDF(shared tag) × Decay(now - date created)
+//
+// A rare tag says more about a story than a tag that most stories have.
+
+// IDF is the weight of a tag that withTag of the total published stories
+// have. It is always above 0, so a tag of every story still counts a bit.
+func IDF(total, withTag int) float64 {
+	return math.Log(1 + float64(total)/float64(withTag))
+}
+
+// Decay is the weight of a story of the age, in seconds: 1 when it is
+// new, halved every RelatedHalfLife.
+func Decay(age int64) float64 {
+	if age < 0 {
+		age = 0
+	}
+
+	return math.Exp(-math.Ln2 * float64(age) / RelatedHalfLife.Seconds())
+}
+
+// GetRelated returns the published stories that are the most related to
+// the news item, without the item itself.
+func (s Service) GetRelated(ctx context.Context, id uuid.UUID, limit int) ([]NewsOut, error) {
+	ctx, span := tracer.Start(ctx, "news.GetRelated")
+	defer span.End()
+
+	if limit == 0 {
+		limit = DefaultRelatedLimit
+	}
+
+	err := validation.Errors{
+		"limit": validation.Validate(limit, validation.Min(1), validation.Max(MaxRelatedLimit)),
+	}.Filter()
+	if err != nil {
+		return []NewsOut{}, err
+	}
+
+	_, err = s.store.Count(ctx, id)
+	if err != nil {
+		return []NewsOut{}, err
+	}
+
+	ids, err := s.store.GetRelatedIds(ctx, id, limit, time.Now().Unix())
+	if err != nil {
+		return []NewsOut{}, errors.Wrap(err, "get the related news ids")
+	}
+
+	r, err := s.GetByIds(ctx, ids)
+	if err != nil {
+		return []NewsOut{}, errors.Wrap(err, "get the related news")
+	}
+
+	return r, nil
+}
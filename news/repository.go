@@ -7,14 +7,59 @@ import (
 	"github.com/google/uuid"
 )
 
+// Filter narrows down the news items. A zero field matches any value.
+type Filter struct {
+	Status bareknews.Status
+	TagID  uuid.UUID
+}
+
+// Cursor is a position in the lists of news, which are ordered by the
+// creation date, then by the id, the newest first.
+type Cursor struct {
+	DateCreated int64     `json:"d"`
+	ID          uuid.UUID `json:"i"`
+}
+
+// Change is a pending write of a news item in a bulk operation.
+type Change struct {
+	News   News
+	Delete bool
+}
+
+// Apply returns the change of a news item in a bulk operation. The store
+// calls it with the item as it is in the transaction, so no other write
+// falls between the read and the change.
+type Apply func(n News) (Change, error)
+
 //go:generate moq -out newsRepo_moq.go . Repository
 type Repository interface {
 	Save(context.Context, News) error
-	GetAll(ctx context.Context, cursor int64, limit int) ([]News, error)
+	// The lists read the parts of the news that the view returns; the
+	// other fields may be left empty. They return the news after the
+	// cursor, and a nil cursor starts from the newest.
+	GetAll(ctx context.Context, after *Cursor, limit int, view View) ([]News, error)
 	GetById(context.Context, uuid.UUID) (*News, error)
-	GetAllByTopic(ctx context.Context, id uuid.UUID, cursor int64, limit int) ([]News, error)
-	GetAllByStatus(ctx context.Context, status bareknews.Status, cursor int64, limit int) ([]News, error)
+	// GetByIds returns the news with the ids in the order of the ids. The
+	// ids of no news are left out.
+	GetByIds(ctx context.Context, ids []uuid.UUID, view View) ([]News, error)
+	GetAllByTopic(ctx context.Context, id uuid.UUID, after *Cursor, limit int, view View) ([]News, error)
+	// GetAllByTopics returns the news that have any of the tags.
+	GetAllByTopics(ctx context.Context, ids []uuid.UUID, after *Cursor, limit int, view View) ([]News, error)
+	GetAllByStatus(ctx context.Context, status bareknews.Status, after *Cursor, limit int, view View) ([]News, error)
 	Count(context.Context, uuid.UUID) (int, error)
 	Update(context.Context, News) error
 	Delete(context.Context, uuid.UUID) error
+	GetIdsByFilter(ctx context.Context, f Filter, limit int) ([]uuid.UUID, error)
+	// GetAllByFilter returns the newest news that match the filter.
+	GetAllByFilter(ctx context.Context, f Filter, limit int, view View) ([]News, error)
+	// GetRelatedIds returns the published news that share tags with the
+	// news, the highest score first, then the newest. The news itself is
+	// left out. See IDF and Decay for the score.
+	GetRelatedIds(ctx context.Context, id uuid.UUID, limit int, now int64) ([]uuid.UUID, error)
+	// Bulk reads the news items and writes the changes that apply returns
+	// for them in one transaction. In the atomic mode every change is
+	// rolled back when one of them fails, otherwise only the failed change
+	// is. It returns the error of every item by index: ErrDataNotFound,
+	// the error of apply or the error of the write.
+	Bulk(ctx context.Context, ids []uuid.UUID, apply Apply, atomic bool) ([]error, error)
 }
@@ -2,10 +2,13 @@ package news
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"strings"
 	"time"
 
 	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/mergepatch"
 	"github.com/Iiqbal2000/bareknews/tags"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -14,60 +17,178 @@ import (
 
 var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/news")
 
+// PageSize is the number of news items of a page of the news list.
+const PageSize = 2
+
 type NewsIn struct {
-	Title  string   `json:"title" validate:"required"`
-	Body   string   `json:"body" validate:"required"`
-	Status string   `json:"status" enums:"publish,draft" default:"draft"`
-	Tags   []string `json:"tags"`
+	Title      string `json:"title" validate:"required"`
+	Body       string `json:"body" validate:"required"`
+	BodyFormat string `json:"body_format" enums:"markdown,html,plain,blocks" default:"plain"`
+	// Excerpt overrides the excerpt computed from the body.
+	Excerpt string   `json:"excerpt"`
+	Status  string   `json:"status" enums:"publish,draft" default:"draft"`
+	Tags    []string `json:"tags"`
+	// FeaturedImage is removed when it is null.
+	FeaturedImage *ImageIn        `json:"featured_image"`
+	Gallery       []GalleryItemIn `json:"gallery"`
+}
+
+// ImageIn is a featured image. The URL is an http(s) URL or a path of this
+// server, such as "/media/{id}/640".
+type ImageIn struct {
+	URL    string `json:"url" validate:"required"`
+	Alt    string `json:"alt" validate:"required"`
+	Credit string `json:"credit"`
+}
+
+type GalleryItemIn struct {
+	URL     string `json:"url" validate:"required"`
+	Caption string `json:"caption"`
+}
+
+type ImageOut struct {
+	URL    string `json:"url"`
+	Alt    string `json:"alt"`
+	Credit string `json:"credit"`
+}
+
+type GalleryItemOut struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption"`
 }
 
 type NewsOut struct {
-	ID          uuid.UUID      `json:"id"`
-	Title       string         `json:"title"`
-	Body        string         `json:"body"`
-	Status      string         `json:"status"`
-	Slug        string         `json:"slug"`
-	Tags        []tags.TagsOut `json:"tags"`
-	DateCreated int64          `json:"date_created"`
-	DateUpdated int64          `json:"date_updated"`
+	ID         uuid.UUID `json:"id"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	BodyFormat string    `json:"body_format"`
+	BodyHTML   string    `json:"body_html"`
+	// Excerpt is a plain text teaser of the body.
+	Excerpt            string         `json:"excerpt"`
+	WordCount          int            `json:"word_count"`
+	ReadingTimeMinutes int            `json:"reading_time_minutes"`
+	Status             string         `json:"status"`
+	Slug               string         `json:"slug"`
+	Tags               []tags.TagsOut `json:"tags"`
+	DateCreated        int64          `json:"date_created"`
+	DateUpdated        int64          `json:"date_updated"`
+	// FeaturedImage is null when the news has none.
+	FeaturedImage *ImageOut        `json:"featured_image"`
+	Gallery       []GalleryItemOut `json:"gallery"`
 }
 
 func createNewsOut(n *News, tgs []tags.TagsOut) NewsOut {
 	return NewsOut{
-		ID:          n.Post.ID,
-		Title:       n.Post.Title,
-		Body:        n.Post.Body,
-		Status:      n.Status.String(),
-		Slug:        n.Slug.String(),
-		Tags:        tgs,
-		DateCreated: n.DateCreated,
-		DateUpdated: n.DateUpdated,
+		ID:                 n.Post.ID,
+		Title:              n.Post.Title,
+		Body:               n.Post.Body,
+		BodyFormat:         n.Post.Format.String(),
+		BodyHTML:           n.BodyHTML,
+		Excerpt:            n.Excerpt,
+		WordCount:          n.WordCount,
+		ReadingTimeMinutes: n.ReadingTimeMinutes,
+		Status:             n.Status.String(),
+		Slug:               n.Slug.String(),
+		Tags:               tgs,
+		DateCreated:        n.DateCreated,
+		DateUpdated:        n.DateUpdated,
+		FeaturedImage:      imageOut(n.FeaturedImage),
+		Gallery:            galleryOut(n.Gallery),
+	}
+}
+
+func imageOut(img *Image) *ImageOut {
+	if img == nil {
+		return nil
+	}
+
+	return &ImageOut{URL: img.URL, Alt: img.Alt, Credit: img.Credit}
+}
+
+func galleryOut(items []GalleryItem) []GalleryItemOut {
+	r := make([]GalleryItemOut, 0, len(items))
+
+	for _, g := range items {
+		r = append(r, GalleryItemOut{URL: g.URL, Caption: g.Caption})
+	}
+
+	return r
+}
+
+// imageOf returns the featured image of the input, nil when there is none.
+func imageOf(input NewsIn) *Image {
+	if input.FeaturedImage == nil {
+		return nil
+	}
+
+	return &Image{
+		URL:    strings.TrimSpace(input.FeaturedImage.URL),
+		Alt:    strings.TrimSpace(input.FeaturedImage.Alt),
+		Credit: strings.TrimSpace(input.FeaturedImage.Credit),
+	}
+}
+
+func galleryOf(input NewsIn) []GalleryItem {
+	r := make([]GalleryItem, 0, len(input.Gallery))
+
+	for _, g := range input.Gallery {
+		r = append(r, GalleryItem{
+			URL:     strings.TrimSpace(g.URL),
+			Caption: strings.TrimSpace(g.Caption),
+		})
 	}
+
+	return r
+}
+
+// formatOf returns the body format of the input, which is plain text
+// unless it is given.
+func formatOf(input NewsIn) bareknews.BodyFormat {
+	if input.BodyFormat == "" {
+		return bareknews.Plain
+	}
+
+	return bareknews.BodyFormat(input.BodyFormat)
 }
 
 type Service struct {
 	store   Repository
 	tagging tags.Service
+	events  Notifier
+	limits  bareknews.Limits
 }
 
 func CreateSvc(repo Repository, tagging tags.Service) Service {
-	return Service{repo, tagging}
+	return Service{store: repo, tagging: tagging, limits: bareknews.DefaultLimits}
+}
+
+// WithLimits returns a copy of the service that validates the news with l.
+func (s Service) WithLimits(l bareknews.Limits) Service {
+	s.limits = l
+	return s
 }
 
 func (s Service) Create(ctx context.Context, input NewsIn) (NewsOut, error) {
 	ctx, span := tracer.Start(ctx, "news.Create")
 	defer span.End()
 
-	tg := s.tagging.GetByNames(ctx, input.Tags)
+	tg, err := s.tagging.GetByNames(ctx, input.Tags)
+	if err != nil {
+		return NewsOut{}, errors.Wrap(err, "get tags by names")
+	}
+
 	tgId := make([]uuid.UUID, 0)
 
 	for _, t := range tg {
 		tgId = append(tgId, t.ID)
 	}
 
-	news := Create(input.Title, input.Body, bareknews.Status(input.Status), tgId, time.Now().Unix())
+	news := create(input.Title, input.Body, bareknews.Status(input.Status), tgId, time.Now().Unix())
+	news.ChangeContent(input.Body, formatOf(input), input.Excerpt)
+	news.ChangeFeaturedImage(imageOf(input))
+	news.ChangeGallery(galleryOf(input))
 
-	err := news.Validate()
+	err = news.ValidateWith(s.limits)
 	if err != nil {
 		return NewsOut{}, err
 	}
@@ -77,9 +198,14 @@ func (s Service) Create(ctx context.Context, input NewsIn) (NewsOut, error) {
 		return NewsOut{}, errors.Wrap(err, "save a news")
 	}
 
+	s.notify()
+
 	return createNewsOut(news, tg), nil
 }
 
+// Update replaces every field of a news item with the input. The input is
+// validated like a new news item, and the tags that aren't given are
+// removed.
 func (s Service) Update(ctx context.Context, id uuid.UUID, input NewsIn) (NewsOut, error) {
 	ctx, span := tracer.Start(ctx, "news.Update")
 	defer span.End()
@@ -89,32 +215,101 @@ func (s Service) Update(ctx context.Context, id uuid.UUID, input NewsIn) (NewsOu
 		return NewsOut{}, err
 	}
 
-	if input.Title != "" && strings.TrimSpace(input.Title) != "" {
-		news.ChangeTitle(input.Title)
+	return s.replace(ctx, news, input)
+}
+
+// Patch applies a JSON merge patch (RFC 7396) to a news item. A null
+// member clears the field and a missing member keeps it.
+func (s Service) Patch(ctx context.Context, id uuid.UUID, patch []byte) (NewsOut, error) {
+	ctx, span := tracer.Start(ctx, "news.Patch")
+	defer span.End()
+
+	news, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return NewsOut{}, err
+	}
+
+	tgs, err := s.tagging.GetByIds(ctx, news.TagsID)
+	if err != nil {
+		return NewsOut{}, errors.Wrap(err, "get tags by ids")
 	}
 
-	if input.Body != "" && strings.TrimSpace(input.Body) != "" {
-		news.ChangeBody(input.Body)
+	current := NewsIn{
+		Title:      news.Post.Title,
+		Body:       news.Post.Body,
+		BodyFormat: news.Post.Format.String(),
+		Status:     news.Status.String(),
+		Tags:       make([]string, 0),
+		Gallery:    make([]GalleryItemIn, 0, len(news.Gallery)),
 	}
 
-	if input.Status != "" && strings.TrimSpace(input.Status) != "" {
-		news.ChangeStatus(bareknews.Status(input.Status))
+	if news.FeaturedImage != nil {
+		current.FeaturedImage = &ImageIn{
+			URL:    news.FeaturedImage.URL,
+			Alt:    news.FeaturedImage.Alt,
+			Credit: news.FeaturedImage.Credit,
+		}
 	}
 
-	news.ChangeDateUpdated(time.Now().Unix())
+	for _, g := range news.Gallery {
+		current.Gallery = append(current.Gallery, GalleryItemIn{URL: g.URL, Caption: g.Caption})
+	}
+
+	if news.ExcerptCustom {
+		current.Excerpt = news.Excerpt
+	}
+
+	for _, t := range tgs {
+		current.Tags = append(current.Tags, t.Name)
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return NewsOut{}, errors.Wrap(err, "marshal the news item")
+	}
+
+	doc, err = mergepatch.Apply(doc, patch)
+	if err != nil {
+		return NewsOut{}, bareknews.ErrInvalidJSON
+	}
+
+	input := NewsIn{}
+
+	err = json.Unmarshal(doc, &input)
+	if err != nil {
+		return NewsOut{}, bareknews.ErrInvalidJSON
+	}
+
+	return s.replace(ctx, news, input)
+}
+
+func (s Service) replace(ctx context.Context, news *News, input NewsIn) (NewsOut, error) {
+	tg := make([]tags.TagsOut, 0)
 
 	if len(input.Tags) > 0 {
-		tg := s.tagging.GetByNames(ctx, input.Tags)
-		tgId := make([]uuid.UUID, 0)
+		var err error
 
-		for _, t := range tg {
-			tgId = append(tgId, t.ID)
+		tg, err = s.tagging.GetByNames(ctx, input.Tags)
+		if err != nil {
+			return NewsOut{}, errors.Wrap(err, "get tags by names")
 		}
+	}
+
+	tgId := make([]uuid.UUID, 0)
 
-		news.ChangeTags(tgId)
+	for _, t := range tg {
+		tgId = append(tgId, t.ID)
 	}
 
-	err = news.Validate()
+	news.ChangeTitle(strings.TrimSpace(input.Title))
+	news.ChangeContent(input.Body, formatOf(input), input.Excerpt)
+	news.ChangeStatus(bareknews.Status(input.Status))
+	news.ChangeTags(tgId)
+	news.ChangeFeaturedImage(imageOf(input))
+	news.ChangeGallery(galleryOf(input))
+	news.ChangeDateUpdated(time.Now().Unix())
+
+	err := news.ValidateWith(s.limits)
 	if err != nil {
 		return NewsOut{}, err
 	}
@@ -124,10 +319,7 @@ func (s Service) Update(ctx context.Context, id uuid.UUID, input NewsIn) (NewsOu
 		return NewsOut{}, errors.Wrap(err, "update a news item")
 	}
 
-	tg, err := s.tagging.GetByIds(ctx, news.TagsID)
-	if err != nil {
-		return NewsOut{}, errors.Wrap(err, "get tags by ids")
-	}
+	s.notify()
 
 	return createNewsOut(news, tg), nil
 }
@@ -146,9 +338,22 @@ func (s Service) Delete(ctx context.Context, id uuid.UUID) error {
 		return errors.Wrap(err, "delete a news item")
 	}
 
+	s.notify()
+
 	return nil
 }
 
+// Exists returns bareknews.ErrDataNotFound when there is no news item
+// with the id.
+func (s Service) Exists(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "news.Exists")
+	defer span.End()
+
+	_, err := s.store.Count(ctx, id)
+
+	return err
+}
+
 func (s Service) GetById(ctx context.Context, id uuid.UUID) (NewsOut, error) {
 	ctx, span := tracer.Start(ctx, "news.GetById")
 	defer span.End()
@@ -166,55 +371,74 @@ func (s Service) GetById(ctx context.Context, id uuid.UUID) (NewsOut, error) {
 	return createNewsOut(news, tgs), nil
 }
 
-func (s Service) GetAll(ctx context.Context, cursor int64) ([]NewsOut, error) {
-	ctx, span := tracer.Start(ctx, "news.GetAll")
+// GetByIds returns the news with the ids in the order of the ids. The ids
+// of no news, e.g. of a deleted one, are left out.
+func (s Service) GetByIds(ctx context.Context, ids []uuid.UUID) ([]NewsOut, error) {
+	ctx, span := tracer.Start(ctx, "news.GetByIds")
 	defer span.End()
 
-	nws, err := s.store.GetAll(ctx, cursor, 2)
+	nws, err := s.store.GetByIds(ctx, ids, FullView)
 	if err != nil {
-		return []NewsOut{}, errors.Wrap(err, "get all news items")
+		return []NewsOut{}, errors.Wrap(err, "get news items by ids")
 	}
 
-	r := make([]NewsOut, 0)
+	return s.listOut(ctx, nws, FullView)
+}
 
-	for _, nw := range nws {
-		tgs, err := s.tagging.GetByIds(ctx, nw.TagsID)
-		if err != nil {
-			return []NewsOut{}, errors.Wrap(err, "get tags by ids")
-		}
+func (s Service) GetAll(ctx context.Context, after *Cursor, view View) ([]NewsOut, error) {
+	ctx, span := tracer.Start(ctx, "news.GetAll")
+	defer span.End()
 
-		r = append(r, createNewsOut(&nw, tgs))
+	nws, err := s.store.GetAll(ctx, after, PageSize, view)
+	if err != nil {
+		return []NewsOut{}, errors.Wrap(err, "get all news items")
 	}
 
-	return r, nil
+	return s.listOut(ctx, nws, view)
 }
 
-func (s Service) GetAllByTopic(ctx context.Context, topic string, cursor int64) ([]NewsOut, error) {
+func (s Service) GetAllByTopic(ctx context.Context, topic string, after *Cursor, view View) ([]NewsOut, error) {
 	ctx, span := tracer.Start(ctx, "news.GetAllByTopic")
 	defer span.End()
 
-	tg := s.tagging.GetByName(ctx, topic)
+	tg, err := s.tagging.GetByName(ctx, topic)
+	if err != nil {
+		return []NewsOut{}, errors.Wrap(err, "get a tag by name")
+	}
 
-	newsItems, err := s.store.GetAllByTopic(ctx, tg.ID, cursor, 2)
+	newsItems, err := s.store.GetAllByTopic(ctx, tg.ID, after, PageSize, view)
 	if err != nil {
 		return []NewsOut{}, errors.Wrap(err, "get all news items by topic")
 	}
 
-	r := make([]NewsOut, 0)
+	return s.listOut(ctx, newsItems, view)
+}
 
-	for _, item := range newsItems {
-		tgs, err := s.tagging.GetByIds(ctx, item.TagsID)
-		if err != nil {
-			return []NewsOut{}, errors.Wrap(err, "get tags by ids")
-		}
+// GetAllBySection returns the news of the topic and of every topic below
+// it.
+func (s Service) GetAllBySection(ctx context.Context, topic string, after *Cursor, view View) ([]NewsOut, error) {
+	ctx, span := tracer.Start(ctx, "news.GetAllBySection")
+	defer span.End()
 
-		r = append(r, createNewsOut(&item, tgs))
+	tg, err := s.tagging.GetByName(ctx, topic)
+	if err != nil {
+		return []NewsOut{}, errors.Wrap(err, "get a tag by name")
 	}
 
-	return r, nil
+	ids, err := s.tagging.GetDescendantIds(ctx, tg.ID)
+	if err != nil {
+		return []NewsOut{}, errors.Wrap(err, "get the descendant tags")
+	}
+
+	newsItems, err := s.store.GetAllByTopics(ctx, ids, after, PageSize, view)
+	if err != nil {
+		return []NewsOut{}, errors.Wrap(err, "get all news items by topics")
+	}
+
+	return s.listOut(ctx, newsItems, view)
 }
 
-func (s Service) GetAllByStatus(ctx context.Context, statusIn string, cursor int64) ([]NewsOut, error) {
+func (s Service) GetAllByStatus(ctx context.Context, statusIn string, after *Cursor, view View) ([]NewsOut, error) {
 	ctx, span := tracer.Start(ctx, "news.GetAllByStatus")
 	defer span.End()
 
@@ -224,21 +448,80 @@ func (s Service) GetAllByStatus(ctx context.Context, statusIn string, cursor int
 		return []NewsOut{}, err
 	}
 
-	nws, err := s.store.GetAllByStatus(ctx, status, cursor, 2)
+	nws, err := s.store.GetAllByStatus(ctx, status, after, PageSize, view)
 	if err != nil {
 		return []NewsOut{}, errors.Wrap(err, "get all news items by status")
 	}
 
-	r := make([]NewsOut, 0)
+	return s.listOut(ctx, nws, view)
+}
+
+// listOut turns the news of a list into their output. The tags are
+// loaded only when the view has them, in one query for the whole list.
+func (s Service) listOut(ctx context.Context, nws []News, view View) ([]NewsOut, error) {
+	r := make([]NewsOut, 0, len(nws))
+
+	if !view.Tags {
+		for _, nw := range nws {
+			r = append(r, createNewsOut(&nw, nil))
+		}
+		return r, nil
+	}
+
+	ids := make([]uuid.UUID, 0)
+	seen := make(map[uuid.UUID]bool)
 
 	for _, nw := range nws {
-		tgs, err := s.tagging.GetByIds(ctx, nw.TagsID)
+		for _, id := range nw.TagsID {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	byID := make(map[uuid.UUID]tags.TagsOut, len(ids))
+
+	if len(ids) > 0 {
+		tgs, err := s.tagging.GetByIds(ctx, ids)
 		if err != nil {
 			return []NewsOut{}, errors.Wrap(err, "get tags by ids")
 		}
 
+		for _, tg := range tgs {
+			byID[tg.ID] = tg
+		}
+	}
+
+	for _, nw := range nws {
+		tgs := make([]tags.TagsOut, 0, len(nw.TagsID))
+
+		for _, id := range nw.TagsID {
+			if tg, ok := byID[id]; ok {
+				tgs = append(tgs, tg)
+			}
+		}
+
 		r = append(r, createNewsOut(&nw, tgs))
 	}
 
 	return r, nil
 }
+
+func encodeCursor(c Cursor) string {
+	// A struct of an int and a UUID always marshals.
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	c := Cursor{}
+	err = json.Unmarshal(raw, &c)
+
+	return c, err
+}
@@ -2,14 +2,16 @@ package news_test
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/Iiqbal2000/bareknews"
 	"github.com/Iiqbal2000/bareknews/news"
 	"github.com/Iiqbal2000/bareknews/tags"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
 	"github.com/matryer/is"
 )
@@ -107,6 +109,18 @@ func TestCreate(t *testing.T) {
 				wantSaveCall: 0,
 				wantGetCall:  1,
 			},
+			{
+				name: "body format of news is invalid",
+				input: news.NewsIn{
+					Title:      "news title",
+					Body:       "news body",
+					BodyFormat: "rtf",
+					Status:     "draft",
+					Tags:       []string{"tag1"},
+				},
+				wantSaveCall: 0,
+				wantGetCall:  1,
+			},
 		}
 
 		for _, test := range payloadTest {
@@ -132,47 +146,242 @@ func TestCreate(t *testing.T) {
 		}
 
 	})
+
+	t.Run("title is longer than the limits of the service", func(t *testing.T) {
+		store := &news.RepositoryMock{
+			SaveFunc: func(ctx context.Context, news news.News) error {
+				return nil
+			},
+		}
+
+		tgStore := &tags.RepositoryMock{
+			GetByNamesFunc: func(ctx context.Context, names ...string) ([]tags.Tags, error) {
+				return nil, nil
+			},
+		}
+
+		limits := bareknews.DefaultLimits
+		limits.TitleMax = 8
+
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore)).WithLimits(limits)
+		_, err := svc.Create(context.TODO(), news.NewsIn{Title: "news title", Body: "news body"})
+		is.True(err != nil)
+		is.Equal(len(store.SaveCalls()), 0)
+	})
+
+	t.Run("body is rendered in its format", func(t *testing.T) {
+		store := &news.RepositoryMock{
+			SaveFunc: func(ctx context.Context, news news.News) error {
+				return nil
+			},
+		}
+
+		tgStore := &tags.RepositoryMock{
+			GetByNamesFunc: func(ctx context.Context, names ...string) ([]tags.Tags, error) {
+				return nil, nil
+			},
+		}
+
+		is := is.New(t)
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+
+		got, err := svc.Create(context.TODO(), news.NewsIn{
+			Title:      "news title",
+			Body:       "**news** body",
+			BodyFormat: "markdown",
+			Status:     "draft",
+		})
+		is.NoErr(err)
+		is.Equal(got.BodyFormat, "markdown")
+		is.Equal(got.BodyHTML, "<p><strong>news</strong> body</p>\n")
+		is.Equal(store.SaveCalls()[0].News.BodyHTML, got.BodyHTML)
+
+		got, err = svc.Create(context.TODO(), news.NewsIn{
+			Title:  "news title",
+			Body:   "news body",
+			Status: "draft",
+		})
+		is.NoErr(err)
+		is.Equal(got.BodyFormat, "plain")
+	})
 }
 
 func TestUpdate(t *testing.T) {
+	t.Run("valid payload should replace every field", func(t *testing.T) {
+		tgId := uuid.New()
+		payload := news.Create("news title", "news body", "draft", []uuid.UUID{tgId}, time.Now().Unix())
+
+		store := &news.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*news.News, error) {
+				return payload, nil
+			},
+			UpdateFunc: func(ctx context.Context, news news.News) error {
+				return nil
+			},
+		}
+
+		tgStore := &tags.RepositoryMock{
+			GetByNamesFunc: func(ctx context.Context, names ...string) ([]tags.Tags, error) {
+				return nil, nil
+			},
+		}
+
+		is := is.New(t)
+
+		newPayload := news.NewsIn{
+			Title:  "news title update",
+			Body:   "news body update",
+			Status: "publish",
+		}
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+		resp, err := svc.Update(context.TODO(), payload.Post.ID, newPayload)
+		is.NoErr(err)
+		is.Equal(resp.Title, "news title update")
+		is.Equal(resp.Body, "news body update")
+		is.Equal(resp.Slug, "news-title-update")
+		is.Equal(resp.Status, "publish")
+		is.Equal(len(resp.Tags), 0)
+		is.Equal(len(store.GetByIdCalls()), 1)
+		is.Equal(len(store.UpdateCalls()), 1)
+		is.Equal(len(store.UpdateCalls()[0].News.TagsID), 0)
+		is.Equal(len(tgStore.GetByNamesCalls()), 0)
+	})
+
+	t.Run("invalid payload: a required field is missing", func(t *testing.T) {
+		payload := news.Create("news title", "news body", "draft", nil, time.Now().Unix())
+
+		store := &news.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*news.News, error) {
+				return payload, nil
+			},
+			UpdateFunc: func(ctx context.Context, news news.News) error {
+				return nil
+			},
+		}
+
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(&tags.RepositoryMock{}))
+		_, err := svc.Update(context.TODO(), payload.Post.ID, news.NewsIn{Title: "news title update"})
+		is.True(err != nil)
+		is.Equal(len(store.UpdateCalls()), 0)
+	})
+}
+
+func TestPatch(t *testing.T) {
 	tgId := uuid.New()
-	payload := news.Create("news title", "news body", "draft", []uuid.UUID{tgId}, time.Now().Unix())
 
-	store := &news.RepositoryMock{
-		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*news.News, error) {
-			return payload, nil
-		},
-		UpdateFunc: func(ctx context.Context, news news.News) error {
-			return nil
-		},
-	}
+	newStores := func() (*news.RepositoryMock, *tags.RepositoryMock) {
+		payload := news.Create("news title", "news body", "draft", []uuid.UUID{tgId}, time.Now().Unix())
 
-	tgStore := &tags.RepositoryMock{
-		GetByNamesFunc: func(ctx context.Context, names ...string) ([]tags.Tags, error) {
-			return nil, nil
-		},
-		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
-			return []tags.Tags{{Label: bareknews.Label{ID: tgId}}}, nil
-		},
-	}
+		store := &news.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*news.News, error) {
+				return payload, nil
+			},
+			UpdateFunc: func(ctx context.Context, news news.News) error {
+				return nil
+			},
+		}
 
-	is := is.New(t)
+		tgStore := &tags.RepositoryMock{
+			GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+				return []tags.Tags{{Label: bareknews.Label{ID: tgId, Name: "tag1"}}}, nil
+			},
+			GetByNamesFunc: func(ctx context.Context, names ...string) ([]tags.Tags, error) {
+				return []tags.Tags{{Label: bareknews.Label{ID: tgId, Name: "tag1"}}}, nil
+			},
+		}
 
-	newPayload := news.NewsIn{
-		Title: "news title update",
+		return store, tgStore
 	}
 
-	svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
-	resp, err := svc.Update(context.TODO(), payload.Post.ID, newPayload)
-	is.NoErr(err)
-	is.True(resp.Title != "news title")
-	is.True(resp.Body != "")
-	is.True(resp.Slug != "")
-	is.True(resp.Status != "")
-	is.Equal(len(resp.Tags), 1)
-	is.Equal(len(store.GetByIdCalls()), 1)
-	is.Equal(len(store.UpdateCalls()), 1)
-	is.Equal(len(tgStore.GetByNamesCalls()), 0)
+	t.Run("a missing member keeps the field", func(t *testing.T) {
+		store, tgStore := newStores()
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+		resp, err := svc.Patch(context.TODO(), uuid.New(), []byte(`{"title": "news title update"}`))
+		is.NoErr(err)
+		is.Equal(resp.Title, "news title update")
+		is.Equal(resp.Body, "news body")
+		is.Equal(resp.Status, "draft")
+		is.Equal(len(resp.Tags), 1)
+		is.Equal(len(store.UpdateCalls()), 1)
+	})
+
+	t.Run("a null member clears the field", func(t *testing.T) {
+		store, tgStore := newStores()
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+		resp, err := svc.Patch(context.TODO(), uuid.New(), []byte(`{"tags": null}`))
+		is.NoErr(err)
+		is.Equal(len(resp.Tags), 0)
+		is.Equal(len(store.UpdateCalls()[0].News.TagsID), 0)
+	})
+
+	t.Run("an empty list removes all tags", func(t *testing.T) {
+		store, tgStore := newStores()
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+		resp, err := svc.Patch(context.TODO(), uuid.New(), []byte(`{"tags": []}`))
+		is.NoErr(err)
+		is.Equal(len(resp.Tags), 0)
+	})
+
+	t.Run("clearing a required field is invalid", func(t *testing.T) {
+		store, tgStore := newStores()
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+		_, err := svc.Patch(context.TODO(), uuid.New(), []byte(`{"body": null}`))
+		is.True(err != nil)
+		is.Equal(len(store.UpdateCalls()), 0)
+	})
+
+	t.Run("images are kept unless they are given", func(t *testing.T) {
+		store, tgStore := newStores()
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+		resp, err := svc.Patch(context.TODO(), uuid.New(), []byte(`{
+			"featured_image": {"url": "/media/a.jpg", "alt": " fans "},
+			"gallery": [{"url": "https://example.com/1.jpg", "caption": "first"}, {"url": "/media/2.jpg"}]
+		}`))
+		is.NoErr(err)
+		is.Equal(resp.FeaturedImage, &news.ImageOut{URL: "/media/a.jpg", Alt: "fans"})
+		is.Equal(resp.Gallery, []news.GalleryItemOut{
+			{URL: "https://example.com/1.jpg", Caption: "first"},
+			{URL: "/media/2.jpg"},
+		})
+
+		resp, err = svc.Patch(context.TODO(), uuid.New(), []byte(`{"title": "news title update"}`))
+		is.NoErr(err)
+		is.Equal(resp.FeaturedImage.URL, "/media/a.jpg")
+		is.Equal(len(resp.Gallery), 2)
+
+		resp, err = svc.Patch(context.TODO(), uuid.New(), []byte(`{"featured_image": null, "gallery": []}`))
+		is.NoErr(err)
+		is.Equal(resp.FeaturedImage, nil)
+		is.Equal(len(resp.Gallery), 0)
+
+		_, err = svc.Patch(context.TODO(), uuid.New(), []byte(`{"featured_image": {"url": "/media/a.jpg"}}`))
+		is.True(err != nil)
+	})
+
+	t.Run("an invalid patch is rejected", func(t *testing.T) {
+		store, tgStore := newStores()
+		is := is.New(t)
+
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+		_, err := svc.Patch(context.TODO(), uuid.New(), []byte(`{"title": `))
+		is.True(errors.Is(err, bareknews.ErrInvalidJSON))
+		is.Equal(len(store.UpdateCalls()), 0)
+	})
 }
 
 func TestDelete(t *testing.T) {
@@ -200,7 +409,7 @@ func TestDelete(t *testing.T) {
 	t.Run("invalid payload: the news is not found", func(t *testing.T) {
 		store := &news.RepositoryMock{
 			CountFunc: func(ctx context.Context, id uuid.UUID) (int, error) {
-				return 0, sql.ErrNoRows
+				return 0, bareknews.ErrDataNotFound
 			},
 			DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
 				return nil
@@ -219,7 +428,7 @@ func TestDelete(t *testing.T) {
 
 func TestGetAllByStatus(t *testing.T) {
 	nwsStore := &news.RepositoryMock{
-		GetAllByStatusFunc: func(ctx context.Context, status bareknews.Status, cursor int64, limit int) ([]news.News, error) {
+		GetAllByStatusFunc: func(ctx context.Context, status bareknews.Status, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
 			return nil, nil
 		},
 	}
@@ -228,15 +437,396 @@ func TestGetAllByStatus(t *testing.T) {
 	nwsSvc := news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
 
 	is := is.New(t)
-	_, err := nwsSvc.GetAllByStatus(context.TODO(), "draft", 0)
+	_, err := nwsSvc.GetAllByStatus(context.TODO(), "draft", nil, news.FullView)
 	is.NoErr(err)
 
-	_, err = nwsSvc.GetAllByStatus(context.TODO(), "publish", 0)
+	_, err = nwsSvc.GetAllByStatus(context.TODO(), "publish", nil, news.FullView)
 	is.NoErr(err)
 
-	_, err = nwsSvc.GetAllByStatus(context.TODO(), "", 0)
+	_, err = nwsSvc.GetAllByStatus(context.TODO(), "", nil, news.FullView)
 	is.True(err != nil)
 
-	_, err = nwsSvc.GetAllByStatus(context.TODO(), "publsjsja", 0)
+	_, err = nwsSvc.GetAllByStatus(context.TODO(), "publsjsja", nil, news.FullView)
 	is.True(err != nil)
-}
\ No newline at end of file
+}
+
+func TestSummaryOut(t *testing.T) {
+	nw := news.Create("news title", `{"version": 1, "blocks": [
+		{"type": "heading", "level": 2, "text": "Heading"},
+		{"type": "paragraph", "text": "First paragraph."}
+	]}`, bareknews.Publish, nil, time.Now().Unix())
+	nw.ChangeBodyFormat(bareknews.Blocks)
+
+	nwsStore := &news.RepositoryMock{
+		GetAllFunc: func(ctx context.Context, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+			return []news.News{*nw}, nil
+		},
+		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*news.News, error) {
+			return nw, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return nil, nil
+		},
+	}
+
+	nwsSvc := news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
+
+	is := is.New(t)
+
+	list, err := nwsSvc.GetAll(context.TODO(), nil, news.FullView)
+	is.NoErr(err)
+	is.Equal(list[0].Excerpt, "Heading First paragraph.")
+
+	is.Equal(list[0].WordCount, 3)
+	is.Equal(list[0].ReadingTimeMinutes, 1)
+
+	one, err := nwsSvc.GetById(context.TODO(), nw.Post.ID)
+	is.NoErr(err)
+	is.Equal(one.Excerpt, list[0].Excerpt)
+
+	// The tags are not loaded when the view doesn't have them.
+	calls := len(tgStore.GetByIdsCalls())
+
+	_, err = nwsSvc.GetAll(context.TODO(), nil, news.View{Fields: []string{"id", "title"}})
+	is.NoErr(err)
+	is.Equal(len(tgStore.GetByIdsCalls()), calls)
+}
+
+func TestGetAllLoadsTagsOnce(t *testing.T) {
+	sports := tags.Create("sports")
+	football := tags.Create("football")
+
+	first := news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{sports.Label.ID, football.Label.ID}, 1)
+	second := news.Create("news 2", "news body", bareknews.Publish, []uuid.UUID{football.Label.ID}, 2)
+	third := news.Create("news 3", "news body", bareknews.Publish, nil, 3)
+
+	nwsStore := &news.RepositoryMock{
+		GetAllFunc: func(ctx context.Context, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+			return []news.News{*first, *second, *third}, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{*football, *sports}, nil
+		},
+	}
+
+	nwsSvc := news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
+	is := is.New(t)
+
+	list, err := nwsSvc.GetAll(context.TODO(), nil, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(tgStore.GetByIdsCalls()), 1)
+	// every tag is asked for once.
+	is.Equal(len(tgStore.GetByIdsCalls()[0].UUIDs), 2)
+
+	is.Equal(len(list[0].Tags), 2)
+	is.Equal(list[0].Tags[0].Name, "sports")
+	is.Equal(list[1].Tags[0].Name, "football")
+	is.Equal(len(list[2].Tags), 0)
+}
+
+func TestGetAllByTopic(t *testing.T) {
+	t.Run("unknown topic should return not found", func(t *testing.T) {
+		nwsStore := &news.RepositoryMock{}
+		tgStore := &tags.RepositoryMock{
+			GetByNameFunc: func(ctx context.Context, name string) (tags.Tags, error) {
+				return tags.Tags{}, bareknews.ErrDataNotFound
+			},
+		}
+
+		nwsSvc := news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
+
+		is := is.New(t)
+		_, err := nwsSvc.GetAllByTopic(context.TODO(), "unknown", nil, news.FullView)
+		is.True(errors.Is(err, bareknews.ErrDataNotFound))
+		is.Equal(len(nwsStore.GetAllByTopicCalls()), 0)
+	})
+}
+
+func TestGetAllBySection(t *testing.T) {
+	sports := tags.Create("sports")
+	football := tags.Create("football")
+	football.ChangeParent(sports.Label.ID)
+
+	nwsStore := &news.RepositoryMock{
+		GetAllByTopicsFunc: func(ctx context.Context, ids []uuid.UUID, after *news.Cursor, limit int, view news.View) ([]news.News, error) {
+			return []news.News{*news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{football.Label.ID}, 1)}, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByNameFunc: func(ctx context.Context, name string) (tags.Tags, error) {
+			return *sports, nil
+		},
+		GetDescendantIdsFunc: func(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+			return []uuid.UUID{sports.Label.ID, football.Label.ID}, nil
+		},
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{*football}, nil
+		},
+	}
+
+	nwsSvc := news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
+
+	is := is.New(t)
+	got, err := nwsSvc.GetAllBySection(context.TODO(), "sports", nil, news.FullView)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Tags[0].ID, football.Label.ID)
+	is.Equal(nwsStore.GetAllByTopicsCalls()[0].Ids, []uuid.UUID{sports.Label.ID, football.Label.ID})
+}
+
+func TestGetTopicPage(t *testing.T) {
+	sports := tags.Create("sports")
+	sports.ChangeMeta(tags.Meta{Description: "Every sport"})
+	story := news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{sports.Label.ID}, 1)
+
+	nwsStore := &news.RepositoryMock{
+		GetAllByFilterFunc: func(ctx context.Context, f news.Filter, limit int, view news.View) ([]news.News, error) {
+			return []news.News{*story}, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetBySlugFunc: func(ctx context.Context, slug string) (tags.Tags, error) {
+			if slug != "sports" {
+				return tags.Tags{}, bareknews.ErrDataNotFound
+			}
+			return *sports, nil
+		},
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{*sports}, nil
+		},
+	}
+
+	nwsSvc := news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
+
+	is := is.New(t)
+	got, err := nwsSvc.GetTopicPage(context.TODO(), "sports")
+	is.NoErr(err)
+	is.Equal(got.Tag.Description, "Every sport")
+	is.Equal(len(got.Stories), 1)
+	is.Equal(got.Stories[0].ID, story.Post.ID)
+	is.Equal(got.Stories[0].Tags[0].ID, sports.Label.ID)
+	// the stories are read in one query.
+	is.Equal(len(nwsStore.GetAllByFilterCalls()), 1)
+	is.Equal(nwsStore.GetAllByFilterCalls()[0].F, news.Filter{Status: bareknews.Publish, TagID: sports.Label.ID})
+	is.Equal(nwsStore.GetAllByFilterCalls()[0].Limit, news.TopicStories)
+
+	_, err = nwsSvc.GetTopicPage(context.TODO(), "unknown")
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
+
+func TestGetRelated(t *testing.T) {
+	source := news.Create("news 1", "news body", bareknews.Publish, nil, 1)
+	first := news.Create("news 2", "news body", bareknews.Publish, nil, 2)
+	second := news.Create("news 3", "news body", bareknews.Publish, nil, 3)
+	stories := map[uuid.UUID]*news.News{source.Post.ID: source, first.Post.ID: first, second.Post.ID: second}
+
+	nwsStore := &news.RepositoryMock{
+		CountFunc: func(ctx context.Context, id uuid.UUID) (int, error) {
+			if stories[id] == nil {
+				return 0, bareknews.ErrDataNotFound
+			}
+			return 1, nil
+		},
+		GetRelatedIdsFunc: func(ctx context.Context, id uuid.UUID, limit int, now int64) ([]uuid.UUID, error) {
+			// the story in the middle is deleted after the ranking.
+			return []uuid.UUID{first.Post.ID, uuid.New(), second.Post.ID}, nil
+		},
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID, view news.View) ([]news.News, error) {
+			nws := []news.News{}
+			for _, id := range ids {
+				if stories[id] != nil {
+					nws = append(nws, *stories[id])
+				}
+			}
+			return nws, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{}, nil
+		},
+	}
+
+	nwsSvc := news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
+	is := is.New(t)
+
+	got, err := nwsSvc.GetRelated(context.TODO(), source.Post.ID, 0)
+	is.NoErr(err)
+	// the order of the store is kept and the deleted story is left out.
+	is.Equal(len(got), 2)
+	is.Equal(got[0].ID, first.Post.ID)
+	is.Equal(got[1].ID, second.Post.ID)
+	is.Equal(nwsStore.GetRelatedIdsCalls()[0].ID, source.Post.ID)
+	is.Equal(nwsStore.GetRelatedIdsCalls()[0].Limit, news.DefaultRelatedLimit)
+	is.Equal(len(nwsStore.GetByIdsCalls()), 1)
+
+	_, err = nwsSvc.GetRelated(context.TODO(), uuid.New(), 0)
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	_, err = nwsSvc.GetRelated(context.TODO(), source.Post.ID, news.MaxRelatedLimit+1)
+	errs, ok := err.(validation.Errors)
+	is.True(ok)
+	is.True(errs["limit"] != nil)
+}
+
+func TestRelatedScore(t *testing.T) {
+	is := is.New(t)
+
+	// a rare tag weighs more than a common one.
+	is.True(news.IDF(100, 2) > news.IDF(100, 50))
+	// a tag of every story still counts.
+	is.True(news.IDF(100, 100) > 0)
+
+	halfLife := int64(news.RelatedHalfLife.Seconds())
+	is.Equal(news.Decay(0), 1.0)
+	is.True(math.Abs(news.Decay(halfLife)-0.5) < 1e-9)
+	is.Equal(news.Decay(-10), 1.0)
+}
+
+func TestBulk(t *testing.T) {
+	tagID := uuid.New()
+	existing := map[uuid.UUID]*news.News{}
+
+	for i := 0; i < 2; i++ {
+		nw := news.Create(fmt.Sprintf("news %d", i+1), "news body", bareknews.Draft, nil, time.Now().Unix())
+		existing[nw.Post.ID] = nw
+	}
+
+	// newStore returns a store that applies the changes to the existing
+	// items and keeps them by ID. The write of the item i fails with
+	// writeErrs[i].
+	newStore := func(writeErrs ...error) (*news.RepositoryMock, map[uuid.UUID]news.Change) {
+		written := map[uuid.UUID]news.Change{}
+
+		return &news.RepositoryMock{
+			GetIdsByFilterFunc: func(ctx context.Context, f news.Filter, limit int) ([]uuid.UUID, error) {
+				ids := make([]uuid.UUID, 0)
+				for id := range existing {
+					ids = append(ids, id)
+				}
+				return ids, nil
+			},
+			BulkFunc: func(ctx context.Context, ids []uuid.UUID, apply news.Apply, atomic bool) ([]error, error) {
+				errs := make([]error, len(ids))
+				for i, id := range ids {
+					nw, ok := existing[id]
+					if !ok {
+						errs[i] = bareknews.ErrDataNotFound
+						continue
+					}
+
+					c, err := apply(*nw)
+					if err == nil && i < len(writeErrs) {
+						err = writeErrs[i]
+					}
+					if err != nil {
+						errs[i] = err
+						continue
+					}
+
+					written[id] = c
+				}
+				return errs, nil
+			},
+		}, written
+	}
+
+	tgStore := &tags.RepositoryMock{
+		GetByNameFunc: func(ctx context.Context, name string) (tags.Tags, error) {
+			if name != "tag1" {
+				return tags.Tags{}, bareknews.ErrDataNotFound
+			}
+			tg := tags.Create("tag1")
+			tg.Label.ID = tagID
+			return *tg, nil
+		},
+	}
+
+	ids := make([]uuid.UUID, 0)
+	for id := range existing {
+		ids = append(ids, id)
+	}
+
+	t.Run("every item is changed", func(t *testing.T) {
+		is := is.New(t)
+		store, written := newStore()
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+
+		out, err := svc.Bulk(context.TODO(), news.BulkIn{
+			IDs: ids,
+			Operations: []news.BulkOperation{
+				{Op: news.OpPublish},
+				{Op: news.OpAddTags, Tags: []string{"tag1"}},
+			},
+		})
+		is.NoErr(err)
+		is.True(out.Applied)
+		is.Equal(out.Mode, news.BulkAtomic)
+		is.Equal(len(out.Results), 2)
+
+		is.Equal(len(written), 2)
+		is.Equal(written[ids[0]].News.Status, bareknews.Publish)
+		is.Equal(written[ids[0]].News.TagsID, []uuid.UUID{tagID})
+	})
+
+	t.Run("unknown item fails the atomic mode", func(t *testing.T) {
+		is := is.New(t)
+		store, _ := newStore()
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+
+		out, err := svc.Bulk(context.TODO(), news.BulkIn{
+			IDs:        append([]uuid.UUID{uuid.New()}, ids...),
+			Operations: []news.BulkOperation{{Op: news.OpDelete}},
+		})
+		is.NoErr(err)
+		is.True(!out.Applied)
+		is.True(errors.Is(out.Results[0].Err, bareknews.ErrDataNotFound))
+		is.True(errors.Is(out.Results[1].Err, bareknews.ErrRolledBack))
+		is.Equal(store.BulkCalls()[0].Atomic, true)
+	})
+
+	t.Run("partial mode keeps the other items", func(t *testing.T) {
+		is := is.New(t)
+		store, _ := newStore(bareknews.ErrDataAlreadyExist)
+		svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+
+		out, err := svc.Bulk(context.TODO(), news.BulkIn{
+			Mode:       news.BulkPartial,
+			Filter:     &news.BulkFilter{Status: "draft"},
+			Operations: []news.BulkOperation{{Op: news.OpSetStatus, Status: "publish"}},
+		})
+		is.NoErr(err)
+		is.True(out.Applied)
+		is.True(errors.Is(out.Results[0].Err, bareknews.ErrDataAlreadyExist))
+		is.NoErr(out.Results[1].Err)
+		is.Equal(store.BulkCalls()[0].Atomic, false)
+	})
+
+	t.Run("invalid payload should be failed", func(t *testing.T) {
+		payloadTest := []news.BulkIn{
+			{Operations: []news.BulkOperation{{Op: news.OpPublish}}},
+			{IDs: ids},
+			{IDs: ids, Operations: []news.BulkOperation{{Op: "archive"}}},
+			{IDs: ids, Operations: []news.BulkOperation{{Op: news.OpAddTags}}},
+			{IDs: ids, Operations: []news.BulkOperation{{Op: news.OpSetStatus, Status: "hidden"}}},
+			{IDs: ids, Mode: "some", Operations: []news.BulkOperation{{Op: news.OpPublish}}},
+			{IDs: ids, Operations: []news.BulkOperation{{Op: news.OpAddTags, Tags: []string{"tag1", "tag9"}}}},
+		}
+
+		for i, pt := range payloadTest {
+			t.Run(fmt.Sprintf("Test case %d", i+1), func(t *testing.T) {
+				is := is.New(t)
+				store, _ := newStore()
+				svc := news.CreateSvc(store, tags.CreateSvc(tgStore))
+
+				_, err := svc.Bulk(context.TODO(), pt)
+				is.True(err != nil)
+				is.Equal(len(store.BulkCalls()), 0)
+			})
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package news
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// ExcerptLength is the maximum length of a computed excerpt, in
+	// characters.
+	ExcerptLength = 200
+	// MaxExcerptLength is the maximum length of an excerpt written by the
+	// editor, in characters.
+	MaxExcerptLength = 300
+	// WordsPerMinute is the reading speed behind the reading time.
+	WordsPerMinute = 200
+)
+
+// Excerpt shortens the text to at most max characters. It cuts after the
+// last sentence that fits, or between two words when the first sentence
+// is already too long. The white space is collapsed, so the excerpt is
+// one line.
+func Excerpt(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	r := []rune(text)
+	if len(r) <= max {
+		return text
+	}
+
+	cut := string(r[:max+1])
+
+	if i := lastSentenceEnd(cut); i > 0 {
+		return cut[:i]
+	}
+
+	cut = string(r[:max])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRightFunc(cut, func(c rune) bool {
+		return unicode.IsPunct(c) || unicode.IsSpace(c)
+	}) + "…"
+}
+
+// lastSentenceEnd returns the end of the last sentence of s, the index
+// after its '.', '!' or '?' that a space follows, or -1.
+func lastSentenceEnd(s string) int {
+	for i := len(s) - 2; i > 0; i-- {
+		if s[i+1] == ' ' && strings.ContainsRune(".!?", rune(s[i])) {
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+// WordCount returns the number of words of the text.
+func WordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// ReadingTime returns the minutes it takes to read the words, rounded up.
+func ReadingTime(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
@@ -0,0 +1,84 @@
+package news_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/matryer/is"
+)
+
+func TestExcerpt(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(news.Excerpt("short  text\n\nhere", 200), "short text here")
+	is.Equal(news.Excerpt("one two three four", 12), "one two…")
+	is.Equal(news.Excerpt("one two, three", 9), "one two…")
+	is.Equal(news.Excerpt("Économie économie", 10), "Économie…")
+
+	// The last sentence that fits is kept whole.
+	is.Equal(news.Excerpt("First one. Second one! Third one is long.", 25), "First one. Second one!")
+	is.Equal(news.Excerpt("First one. Second", 10), "First one.")
+	// A decimal point is not the end of a sentence.
+	is.Equal(news.Excerpt("It costs 2.5 dollars today", 15), "It costs 2.5…")
+}
+
+func TestReadingTime(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(news.ReadingTime(0), 0)
+	is.Equal(news.ReadingTime(1), 1)
+	is.Equal(news.ReadingTime(news.WordsPerMinute), 1)
+	is.Equal(news.ReadingTime(news.WordsPerMinute+1), 2)
+}
+
+func TestSummary(t *testing.T) {
+	body := strings.Repeat("The match ends late tonight. ", 50)
+
+	t.Run("is computed from the body", func(t *testing.T) {
+		is := is.New(t)
+
+		nw := news.Create("news title", body, bareknews.Draft, nil, time.Now().Unix())
+		is.Equal(nw.WordCount, 250)
+		is.Equal(nw.ReadingTimeMinutes, 2)
+		is.True(strings.HasSuffix(nw.Excerpt, "tonight."))
+		is.True(len([]rune(nw.Excerpt)) <= news.ExcerptLength)
+		is.True(!nw.ExcerptCustom)
+
+		nw.ChangeBody("# Heading\n\nOne *two* three.")
+		nw.ChangeBodyFormat(bareknews.Markdown)
+		is.Equal(nw.Excerpt, "Heading One two three.")
+		is.Equal(nw.WordCount, 4)
+	})
+
+	t.Run("the editor's excerpt is kept", func(t *testing.T) {
+		is := is.New(t)
+
+		nw := news.Create("news title", body, bareknews.Draft, nil, time.Now().Unix())
+		nw.ChangeExcerpt("  Written by hand.  ")
+		is.Equal(nw.Excerpt, "Written by hand.")
+		is.True(nw.ExcerptCustom)
+
+		nw.ChangeBody("Another body.")
+		is.Equal(nw.Excerpt, "Written by hand.")
+		is.Equal(nw.WordCount, 2)
+
+		nw.ChangeExcerpt("")
+		is.Equal(nw.Excerpt, "Another body.")
+		is.True(!nw.ExcerptCustom)
+	})
+
+	t.Run("the editor's excerpt is limited", func(t *testing.T) {
+		is := is.New(t)
+
+		nw := news.Create("news title", body, bareknews.Draft, nil, time.Now().Unix())
+		nw.ChangeExcerpt(strings.Repeat("a", news.MaxExcerptLength+1))
+
+		errs, ok := nw.Validate().(validation.Errors)
+		is.True(ok)
+		is.True(errs["Excerpt"] != nil)
+	})
+}
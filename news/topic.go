@@ -0,0 +1,44 @@
+package news
+
+import (
+	"context"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/pkg/errors"
+)
+
+// TopicStories is the number of stories on the landing page of a tag.
+const TopicStories = 10
+
+// TopicPage is the landing page of a tag: the tag with its metadata and
+// its newest published stories.
+type TopicPage struct {
+	Tag     tags.TagsOut `json:"tag"`
+	Stories []NewsOut    `json:"stories"`
+}
+
+// GetTopicPage returns the landing page of the tag with the slug.
+func (s Service) GetTopicPage(ctx context.Context, slug string) (TopicPage, error) {
+	ctx, span := tracer.Start(ctx, "news.GetTopicPage")
+	defer span.End()
+
+	tg, err := s.tagging.GetBySlug(ctx, slug)
+	if err != nil {
+		return TopicPage{}, err
+	}
+
+	nws, err := s.store.GetAllByFilter(ctx, Filter{Status: bareknews.Publish, TagID: tg.ID}, TopicStories, FullView)
+	if err != nil {
+		return TopicPage{}, errors.Wrap(err, "get news items by filter")
+	}
+
+	stories, err := s.listOut(ctx, nws, FullView)
+	if err != nil {
+		return TopicPage{}, err
+	}
+
+	page := TopicPage{Tag: tg, Stories: stories}
+
+	return page, nil
+}
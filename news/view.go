@@ -0,0 +1,150 @@
+package news
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Fields are the JSON names of the NewsOut fields, in their order.
+var Fields = []string{
+	"id", "title", "body", "body_format", "body_html",
+	"excerpt", "word_count", "reading_time_minutes",
+	"status", "slug", "tags", "date_created", "date_updated",
+	"featured_image", "gallery",
+}
+
+// Includes are the relations that a list can load.
+var Includes = []string{"tags"}
+
+// View tells which parts of the news a list returns, so the stores read
+// only them. The zero value returns every field but the tags.
+type View struct {
+	// Fields are the JSON names of the returned fields. Nil returns every
+	// field.
+	Fields []string
+	// Tags tells whether the tags are loaded.
+	Tags bool
+}
+
+// FullView returns every field with the tags.
+var FullView = View{Tags: true}
+
+// ParseView reads the comma-separated fields and includes of a request.
+// The tags are loaded when the fields name them; the includes, when they
+// are given, decide it instead: "tags" loads them and an empty include
+// skips them.
+func ParseView(fields string, include *string) (View, error) {
+	v := View{}
+
+	if strings.TrimSpace(fields) != "" {
+		v.Fields = make([]string, 0)
+		for _, f := range splitList(fields) {
+			if !contains(Fields, f) {
+				return View{}, validation.Errors{"fields": unknownError(f, Fields)}
+			}
+			if !contains(v.Fields, f) {
+				v.Fields = append(v.Fields, f)
+			}
+		}
+	}
+
+	v.Tags = v.Has("tags")
+
+	if include == nil {
+		return v, nil
+	}
+
+	v.Tags = false
+	for _, i := range splitList(*include) {
+		if !contains(Includes, i) {
+			return View{}, validation.Errors{"include": unknownError(i, Includes)}
+		}
+		v.Tags = true
+	}
+
+	if v.Fields == nil {
+		v.Fields = append([]string{}, Fields...)
+	}
+
+	switch {
+	case v.Tags && !v.Has("tags"):
+		v.Fields = append(v.Fields, "tags")
+	case !v.Tags:
+		v.Fields = remove(v.Fields, "tags")
+	}
+
+	return v, nil
+}
+
+// Has tells whether the field is returned.
+func (v View) Has(field string) bool {
+	return v.Fields == nil || contains(v.Fields, field)
+}
+
+// Project returns the fields of the view of out. It returns out itself
+// when the view has every field.
+func (v View) Project(out NewsOut) (interface{}, error) {
+	if v.Fields == nil {
+		return out, nil
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+
+	r := make(map[string]json.RawMessage, len(v.Fields))
+	for _, f := range v.Fields {
+		r[f] = all[f]
+	}
+
+	return r, nil
+}
+
+func unknownError(name string, known []string) error {
+	return validation.NewError(
+		"validation_unknown_field",
+		fmt.Sprintf("unknown value %q; it must be one of %s", name, strings.Join(known, ", ")),
+	)
+}
+
+func splitList(s string) []string {
+	r := make([]string, 0)
+
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			r = append(r, p)
+		}
+	}
+
+	return r
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(list []string, s string) []string {
+	r := make([]string, 0, len(list))
+
+	for _, l := range list {
+		if l != s {
+			r = append(r, l)
+		}
+	}
+
+	return r
+}
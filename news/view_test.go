@@ -0,0 +1,88 @@
+package news_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestParseView(t *testing.T) {
+	empty, tags := "", "tags"
+
+	payloadTest := []struct {
+		name      string
+		fields    string
+		include   *string
+		want      news.View
+		wantField string
+	}{
+		{name: "nothing asks for everything", want: news.FullView},
+		{
+			name:   "fields without tags",
+			fields: "id, title,slug,excerpt,title",
+			want:   news.View{Fields: []string{"id", "title", "slug", "excerpt"}},
+		},
+		{
+			name:   "fields with tags",
+			fields: "id,tags",
+			want:   news.View{Fields: []string{"id", "tags"}, Tags: true},
+		},
+		{
+			name:    "include adds the tags",
+			fields:  "id,title",
+			include: &tags,
+			want:    news.View{Fields: []string{"id", "title", "tags"}, Tags: true},
+		},
+		{
+			name:    "empty include skips the tags",
+			include: &empty,
+			want: news.View{Fields: []string{
+				"id", "title", "body", "body_format", "body_html",
+				"excerpt", "word_count", "reading_time_minutes",
+				"status", "slug", "date_created", "date_updated",
+				"featured_image", "gallery",
+			}},
+		},
+		{name: "unknown field", fields: "id,Body", wantField: "fields"},
+		{name: "unknown include", include: &[]string{"author"}[0], wantField: "include"},
+	}
+
+	for _, pt := range payloadTest {
+		t.Run(pt.name, func(t *testing.T) {
+			is := is.New(t)
+
+			got, err := news.ParseView(pt.fields, pt.include)
+
+			if pt.wantField != "" {
+				errs, ok := err.(validation.Errors)
+				is.True(ok)
+				is.True(errs[pt.wantField] != nil)
+				return
+			}
+
+			is.NoErr(err)
+			is.Equal(got, pt.want)
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	is := is.New(t)
+
+	out := news.NewsOut{ID: uuid.New(), Title: "news title", Body: "a long body", DateCreated: 1666000000}
+
+	got, err := news.FullView.Project(out)
+	is.NoErr(err)
+	is.Equal(got, out)
+
+	got, err = news.View{Fields: []string{"id", "title", "date_created"}}.Project(out)
+	is.NoErr(err)
+
+	b, err := json.Marshal(got)
+	is.NoErr(err)
+	is.Equal(string(b), `{"date_created":1666000000,"id":"`+out.ID.String()+`","title":"news title"}`)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/pkg/idempotency/db")
+
+// Store keeps the idempotent responses in SQLite.
+type Store struct {
+	conn *sql.DB
+}
+
+// Ensure Store does implement web.IdempotencyStore.
+var _ web.IdempotencyStore = Store{}
+
+func CreateStore(conn *sql.DB) Store {
+	return Store{conn: conn}
+}
+
+// Reserve removes the expired keys before it claims the key.
+func (s Store) Reserve(ctx context.Context, key, requestHash string, expiresAt int64) error {
+	ctx, span := tracer.Start(ctx, "idempotency.db.Reserve")
+	defer span.End()
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+
+	defer tx.Rollback()
+
+	del := sqlbuilder.NewDeleteBuilder()
+	del.DeleteFrom("idempotency_keys")
+	del.Where(del.LessEqualThan("expires_at", time.Now().Unix()))
+
+	query, args := del.Build()
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "delete the expired keys")
+	}
+
+	ins := sqlbuilder.NewInsertBuilder()
+	ins.InsertInto("idempotency_keys")
+	ins.Cols("key", "request_hash", "expires_at")
+	ins.Values(key, requestHash, expiresAt)
+
+	query, args = ins.Build()
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		if possibleErr, ok := err.(sqlite3.Error); ok {
+			if possibleErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+				possibleErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return bareknews.ErrDataAlreadyExist
+			}
+		}
+
+		return errors.Wrap(err, "insert the key")
+	}
+
+	return tx.Commit()
+}
+
+func (s Store) Get(ctx context.Context, key string) (web.StoredResponse, error) {
+	ctx, span := tracer.Start(ctx, "idempotency.db.Get")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("key", "request_hash", "done", "status", "content_type", "body", "expires_at")
+	builder.From("idempotency_keys")
+	builder.Where(
+		builder.Equal("key", key),
+		builder.GreaterThan("expires_at", time.Now().Unix()),
+	)
+
+	query, args := builder.Build()
+	row := s.conn.QueryRowContext(ctx, query, args...)
+	resp := web.StoredResponse{}
+
+	err := row.Scan(
+		&resp.Key,
+		&resp.RequestHash,
+		&resp.Done,
+		&resp.Status,
+		&resp.ContentType,
+		&resp.Body,
+		&resp.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.StoredResponse{}, bareknews.ErrDataNotFound
+		}
+
+		return web.StoredResponse{}, errors.Wrap(err, "scan the idempotent response")
+	}
+
+	return resp, nil
+}
+
+func (s Store) Complete(ctx context.Context, resp web.StoredResponse) error {
+	ctx, span := tracer.Start(ctx, "idempotency.db.Complete")
+	defer span.End()
+
+	builder := sqlbuilder.NewUpdateBuilder()
+	builder.Update("idempotency_keys")
+	builder.Set(
+		builder.Assign("done", true),
+		builder.Assign("status", resp.Status),
+		builder.Assign("content_type", resp.ContentType),
+		builder.Assign("body", resp.Body),
+		builder.Assign("expires_at", resp.ExpiresAt),
+	)
+	builder.Where(builder.Equal("key", resp.Key))
+
+	query, args := builder.Build()
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "update the idempotent response")
+	}
+
+	return nil
+}
+
+func (s Store) Release(ctx context.Context, key string) error {
+	ctx, span := tracer.Start(ctx, "idempotency.db.Release")
+	defer span.End()
+
+	builder := sqlbuilder.NewDeleteBuilder()
+	builder.DeleteFrom("idempotency_keys")
+	builder.Where(builder.Equal("key", key))
+
+	query, args := builder.Build()
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "delete the key")
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/idempotency/db"
+	"github.com/Iiqbal2000/bareknews/pkg/sqlite3"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/matryer/is"
+)
+
+func TestStore(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	is := is.New(t)
+
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	err := store.Reserve(context.TODO(), "key-1", "hash", expiresAt)
+	is.NoErr(err)
+
+	err = store.Reserve(context.TODO(), "key-1", "hash", expiresAt)
+	is.Equal(err, bareknews.ErrDataAlreadyExist)
+
+	got, err := store.Get(context.TODO(), "key-1")
+	is.NoErr(err)
+	is.Equal(got.RequestHash, "hash")
+	is.True(!got.Done)
+
+	err = store.Complete(context.TODO(), web.StoredResponse{
+		Key:         "key-1",
+		Status:      201,
+		ContentType: "application/json",
+		Body:        []byte(`{"id":1}`),
+		ExpiresAt:   expiresAt,
+	})
+	is.NoErr(err)
+
+	got, err = store.Get(context.TODO(), "key-1")
+	is.NoErr(err)
+	is.True(got.Done)
+	is.Equal(got.Status, 201)
+	is.Equal(string(got.Body), `{"id":1}`)
+
+	is.NoErr(store.Release(context.TODO(), "key-1"))
+
+	_, err = store.Get(context.TODO(), "key-1")
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
+
+func TestStoreExpired(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	is := is.New(t)
+
+	err := store.Reserve(context.TODO(), "key-1", "hash", time.Now().Add(-time.Second).Unix())
+	is.NoErr(err)
+
+	_, err = store.Get(context.TODO(), "key-1")
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	err = store.Reserve(context.TODO(), "key-1", "another hash", time.Now().Add(time.Hour).Unix())
+	is.NoErr(err)
+}
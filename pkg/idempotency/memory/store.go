@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/pkg/idempotency/memory")
+
+// Store is an in-memory implementation of web.IdempotencyStore. It is safe
+// for concurrent use.
+type Store struct {
+	mu    *sync.Mutex
+	items map[string]web.StoredResponse
+}
+
+// Ensure Store does implement web.IdempotencyStore.
+var _ web.IdempotencyStore = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:    &sync.Mutex{},
+		items: make(map[string]web.StoredResponse),
+	}
+}
+
+// Reserve removes the expired keys before it claims the key.
+func (s Store) Reserve(ctx context.Context, key, requestHash string, expiresAt int64) error {
+	_, span := tracer.Start(ctx, "idempotency.memory.Reserve")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().Unix()
+
+	for k, item := range s.items {
+		if item.ExpiresAt <= now {
+			delete(s.items, k)
+		}
+	}
+
+	if _, ok := s.items[key]; ok {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	s.items[key] = web.StoredResponse{Key: key, RequestHash: requestHash, ExpiresAt: expiresAt}
+
+	return nil
+}
+
+func (s Store) Get(ctx context.Context, key string) (web.StoredResponse, error) {
+	_, span := tracer.Start(ctx, "idempotency.memory.Get")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	if !ok || item.ExpiresAt <= time.Now().Unix() {
+		return web.StoredResponse{}, bareknews.ErrDataNotFound
+	}
+
+	item.Body = append([]byte(nil), item.Body...)
+
+	return item, nil
+}
+
+func (s Store) Complete(ctx context.Context, resp web.StoredResponse) error {
+	_, span := tracer.Start(ctx, "idempotency.memory.Complete")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[resp.Key]
+	if !ok {
+		return nil
+	}
+
+	item.Done = true
+	item.Status = resp.Status
+	item.ContentType = resp.ContentType
+	item.Body = append([]byte(nil), resp.Body...)
+	item.ExpiresAt = resp.ExpiresAt
+	s.items[resp.Key] = item
+
+	return nil
+}
+
+func (s Store) Release(ctx context.Context, key string) error {
+	_, span := tracer.Start(ctx, "idempotency.memory.Release")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package mergepatch implements JSON Merge Patch as described in RFC 7396.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ContentType is the media type of a JSON merge patch document.
+const ContentType = "application/merge-patch+json"
+
+// Apply applies the merge patch to the target document and returns the
+// patched document. A null member in the patch removes the member from the
+// target and a missing member keeps it.
+func Apply(target, patch []byte) ([]byte, error) {
+	t, err := decode(target)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode the target")
+	}
+
+	p, err := decode(patch)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode the patch")
+	}
+
+	return json.Marshal(merge(t, p))
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = merge(t[k], v)
+	}
+
+	return t
+}
+
+func decode(doc []byte) (interface{}, error) {
+	var v interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	// Trailing data after the document is not valid JSON either.
+	if dec.More() {
+		return nil, errors.New("invalid data after the top-level value")
+	}
+
+	return v, nil
+}
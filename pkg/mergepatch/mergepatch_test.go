@@ -0,0 +1,59 @@
+package mergepatch_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews/pkg/mergepatch"
+	"github.com/matryer/is"
+)
+
+// The cases are the examples of the RFC 7396 appendix.
+func TestApply(t *testing.T) {
+	payloadTest := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range payloadTest {
+		t.Run(test.patch, func(t *testing.T) {
+			is := is.New(t)
+
+			got, err := mergepatch.Apply([]byte(test.target), []byte(test.patch))
+			is.NoErr(err)
+
+			var gotV, wantV interface{}
+			is.NoErr(json.Unmarshal(got, &gotV))
+			is.NoErr(json.Unmarshal([]byte(test.want), &wantV))
+			is.True(reflect.DeepEqual(gotV, wantV))
+		})
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	is := is.New(t)
+
+	_, err := mergepatch.Apply([]byte(`{}`), []byte(`{"a":`))
+	is.True(err != nil)
+
+	_, err = mergepatch.Apply([]byte(`{}`), []byte(`{} {}`))
+	is.True(err != nil)
+}
@@ -3,8 +3,9 @@ package sqlite3
 import (
 	"database/sql"
 	"embed"
+	"math"
 
-	_ "github.com/mattn/go-sqlite3"
+	sqlite "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
 	"github.com/pressly/goose/v3"
 	"go.uber.org/zap"
@@ -13,6 +14,21 @@ import (
 //go:embed schema/*.sql
 var embedMigrations embed.FS
 
+// driverName is the SQLite driver with the math functions that the
+// queries use. The SQLite of the driver is built without them.
+const driverName = "sqlite3_math"
+
+func init() {
+	sql.Register(driverName, &sqlite.SQLiteDriver{
+		ConnectHook: func(conn *sqlite.SQLiteConn) error {
+			if err := conn.RegisterFunc("ln", math.Log, true); err != nil {
+				return err
+			}
+			return conn.RegisterFunc("exp", math.Exp, true)
+		},
+	})
+}
+
 type Config struct {
 	URI            string
 	Log            *zap.SugaredLogger
@@ -20,7 +36,7 @@ type Config struct {
 }
 
 func Run(c Config) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", c.URI)
+	db, err := sql.Open(driverName, c.URI)
 	if err != nil {
 		return nil, errors.Wrap(err, "failure when opening db connection")
 	}
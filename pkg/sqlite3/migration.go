@@ -0,0 +1,63 @@
+package sqlite3
+
+import (
+	"database/sql"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/pkg/errors"
+	"github.com/pressly/goose/v3"
+)
+
+// The Go migrations are registered here, next to the SQL ones, so Run
+// applies them whichever packages the program imports.
+func init() {
+	goose.AddNamedMigration("20221019220000_news_render.go", renderUp, renderDown)
+}
+
+// renderUp renders the news that were saved before their HTML and their
+// summary were stored, the same way a save does, so the output doesn't
+// depend on the age of a news item.
+func renderUp(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, body, body_format, excerpt, excerpt_custom FROM news")
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	items := make([]news.News, 0)
+
+	for rows.Next() {
+		n := news.News{}
+		err = rows.Scan(&n.Post.ID, &n.Post.Body, &n.Post.Format, &n.Excerpt, &n.ExcerptCustom)
+		if err != nil {
+			return errors.Wrap(err, "scan a news item")
+		}
+		items = append(items, n)
+	}
+
+	if rows.Err() != nil {
+		return errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	for _, n := range items {
+		// ChangeBody renders the body as a save does.
+		n.ChangeBody(n.Post.Body)
+
+		_, err = tx.Exec(
+			"UPDATE news SET body_html = ?, excerpt = ?, word_count = ?, reading_time_minutes = ? WHERE id = ?",
+			n.BodyHTML, n.Excerpt, n.WordCount, n.ReadingTimeMinutes, n.Post.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "update a news item")
+		}
+	}
+
+	return nil
+}
+
+// renderDown keeps the rendered news: the columns go with their own
+// migrations.
+func renderDown(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package sqlite3_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/news/db"
+	"github.com/Iiqbal2000/bareknews/pkg/sqlite3"
+	"github.com/matryer/is"
+	"github.com/pressly/goose/v3"
+)
+
+func TestRenderMigration(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := db.CreateStore(conn)
+	is := is.New(t)
+
+	want := news.Create("news 1", "Tom & Jerry\n\n<b>second</b>\r\nline  with   spaces. "+strings.Repeat("word ", 60), bareknews.Draft, nil, 1)
+	is.NoErr(newsStore.Save(context.TODO(), *want))
+
+	custom := news.Create("news 2", "news body", bareknews.Draft, nil, 1)
+	custom.ChangeExcerpt("An excerpt of the editor")
+	is.NoErr(newsStore.Save(context.TODO(), *custom))
+
+	// the news as they were before their HTML and their summary were
+	// stored.
+	_, err := conn.Exec("UPDATE news SET body_html = '', word_count = 0, reading_time_minutes = 0 WHERE excerpt_custom = 1")
+	is.NoErr(err)
+	_, err = conn.Exec("UPDATE news SET body_html = '', excerpt = '', word_count = 0, reading_time_minutes = 0 WHERE excerpt_custom = 0")
+	is.NoErr(err)
+
+	is.NoErr(goose.DownTo(conn, "schema", 20221019210000))
+	is.NoErr(goose.Up(conn, "schema"))
+
+	got, err := newsStore.GetById(context.TODO(), want.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.BodyHTML, want.BodyHTML)
+	is.Equal(got.Excerpt, want.Excerpt)
+	is.Equal(got.WordCount, want.WordCount)
+	is.Equal(got.ReadingTimeMinutes, want.ReadingTimeMinutes)
+
+	// an excerpt of the editor is kept.
+	got, err = newsStore.GetById(context.TODO(), custom.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.Excerpt, "An excerpt of the editor")
+	is.Equal(got.WordCount, custom.WordCount)
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+	key VARCHAR (255) PRIMARY KEY,
+	request_hash CHAR (64) NOT NULL,
+	done INT NOT NULL DEFAULT 0,
+	status INT NOT NULL DEFAULT 0,
+	content_type VARCHAR (127) NOT NULL DEFAULT '',
+	body BLOB,
+	expires_at INT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at ON idempotency_keys(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tag_aliases(
+	name VARCHAR (127) PRIMARY KEY,
+	tagID VARCHAR (127) NOT NULL,
+	FOREIGN KEY(tagID) REFERENCES tags(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS tag_aliases_tagID ON tag_aliases(tagID);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE tag_aliases;
+-- +goose StatementEnd
\ No newline at end of file
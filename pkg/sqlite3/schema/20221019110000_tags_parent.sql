@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tags ADD COLUMN parentID VARCHAR (127) NULL REFERENCES tags(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS tags_parentID ON tags(parentID);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX tags_parentID;
+ALTER TABLE tags DROP COLUMN parentID;
+-- +goose StatementEnd
\ No newline at end of file
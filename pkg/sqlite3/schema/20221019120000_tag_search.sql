@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tag_search(
+	key VARCHAR (127) NOT NULL,
+	tagID VARCHAR (127) NOT NULL,
+	PRIMARY KEY(key, tagID),
+	FOREIGN KEY(tagID) REFERENCES tags(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS tag_search_tagID ON tag_search(tagID);
+CREATE INDEX IF NOT EXISTS news_tags_tagsID ON news_tags(tagsID);
+-- lower() folds only ASCII letters; a tag gets its full keys when it's saved again.
+INSERT OR IGNORE INTO tag_search (key, tagID) SELECT lower(name), id FROM tags;
+INSERT OR IGNORE INTO tag_search (key, tagID) SELECT lower(slug), id FROM tags;
+INSERT OR IGNORE INTO tag_search (key, tagID) SELECT lower(name), tagID FROM tag_aliases;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX news_tags_tagsID;
+DROP TABLE tag_search;
+-- +goose StatementEnd
\ No newline at end of file
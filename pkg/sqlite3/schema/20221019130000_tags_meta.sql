@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tags ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN color VARCHAR (7) NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN meta_title VARCHAR (255) NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN meta_description VARCHAR (255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tags DROP COLUMN meta_description;
+ALTER TABLE tags DROP COLUMN meta_title;
+ALTER TABLE tags DROP COLUMN image_url;
+ALTER TABLE tags DROP COLUMN color;
+ALTER TABLE tags DROP COLUMN description;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE news ADD COLUMN body_format VARCHAR (15) NOT NULL DEFAULT 'plain';
+ALTER TABLE news ADD COLUMN body_html TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE news DROP COLUMN body_html;
+ALTER TABLE news DROP COLUMN body_format;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE news ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';
+ALTER TABLE news ADD COLUMN excerpt_custom BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE news ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news ADD COLUMN reading_time_minutes INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE news DROP COLUMN reading_time_minutes;
+ALTER TABLE news DROP COLUMN word_count;
+ALTER TABLE news DROP COLUMN excerpt_custom;
+ALTER TABLE news DROP COLUMN excerpt;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS media(
+	id CHAR (127) PRIMARY KEY,
+	storage_key VARCHAR (255) NOT NULL UNIQUE,
+	filename VARCHAR (255) NOT NULL DEFAULT '',
+	mime_type VARCHAR (127) NOT NULL,
+	size INTEGER NOT NULL,
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	alt TEXT NOT NULL DEFAULT '',
+	credit TEXT NOT NULL DEFAULT '',
+	date_created INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS media_date_created ON media(date_created, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE media;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE news ADD COLUMN featured_image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE news ADD COLUMN featured_image_alt TEXT NOT NULL DEFAULT '';
+ALTER TABLE news ADD COLUMN featured_image_credit TEXT NOT NULL DEFAULT '';
+CREATE TABLE IF NOT EXISTS news_gallery(
+	newsID VARCHAR (127) NOT NULL,
+	position INTEGER NOT NULL,
+	url TEXT NOT NULL,
+	caption TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY(newsID, position),
+	FOREIGN KEY(newsID) REFERENCES news(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE news_gallery;
+ALTER TABLE news DROP COLUMN featured_image_credit;
+ALTER TABLE news DROP COLUMN featured_image_alt;
+ALTER TABLE news DROP COLUMN featured_image_url;
+-- +goose StatementEnd
\ No newline at end of file
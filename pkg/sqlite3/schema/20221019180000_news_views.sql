@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS news_views(
+	newsID CHAR (127) NOT NULL,
+	bucket INTEGER NOT NULL,
+	views INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (newsID, bucket)
+);
+CREATE INDEX IF NOT EXISTS news_views_bucket ON news_views(bucket);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE news_views;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS news_events(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type VARCHAR (31) NOT NULL,
+	newsID CHAR (127) NOT NULL,
+	data TEXT NOT NULL,
+	date_created INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS news_events_date_created ON news_events(date_created);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE news_events;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS changes(
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind VARCHAR (15) NOT NULL,
+	entityID CHAR (127) NOT NULL,
+	deleted INTEGER NOT NULL DEFAULT 0,
+	date_created INTEGER NOT NULL,
+	UNIQUE (kind, entityID)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE changes;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_subscriptions(
+	id CHAR (127) PRIMARY KEY,
+	url VARCHAR (2048) NOT NULL,
+	secret VARCHAR (255) NOT NULL,
+	events VARCHAR (255) NOT NULL,
+	date_created INTEGER NOT NULL
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_outbox(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event VARCHAR (15) NOT NULL,
+	newsID CHAR (127) NOT NULL,
+	data TEXT NOT NULL,
+	dispatched INTEGER NOT NULL DEFAULT 0,
+	date_created INTEGER NOT NULL
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS webhook_outbox_dispatched ON webhook_outbox(dispatched, id);
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	subscriptionID CHAR (127) NOT NULL,
+	eventID INTEGER NOT NULL,
+	status VARCHAR (15) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt INTEGER NOT NULL,
+	last_status_code INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	date_created INTEGER NOT NULL,
+	date_updated INTEGER NOT NULL,
+	UNIQUE (subscriptionID, eventID),
+	FOREIGN KEY (subscriptionID) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+	FOREIGN KEY (eventID) REFERENCES webhook_outbox (id)
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries(status, next_attempt);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE webhook_deliveries;
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE webhook_outbox;
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE webhook_subscriptions;
+-- +goose StatementEnd
\ No newline at end of file
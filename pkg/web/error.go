@@ -1,6 +1,12 @@
 package web
 
-import "errors"
+import (
+	"net/http"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
 
 type RequestError struct {
 	Err    error
@@ -22,3 +28,29 @@ func GetRequestError(err error) *RequestError {
 	}
 	return re
 }
+
+// StatusOf maps an error to its HTTP status code. The domain errors are
+// mapped here so the handlers don't need to know about them.
+func StatusOf(err error) int {
+	if reqErr := GetRequestError(err); reqErr != nil {
+		return reqErr.Status
+	}
+
+	switch {
+	case errors.Is(err, bareknews.ErrDataNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, bareknews.ErrDataAlreadyExist), errors.Is(err, bareknews.ErrRolledBack):
+		return http.StatusConflict
+	case errors.Is(err, bareknews.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, bareknews.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	}
+
+	switch errors.Cause(err).(type) {
+	case validation.Errors, validation.Error:
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
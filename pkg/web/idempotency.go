@@ -0,0 +1,179 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// IdempotencyKeyHeader is the header that carries the idempotency key of a
+// request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKey is the maximum length of an idempotency key.
+const maxIdempotencyKey = 255
+
+// StoredResponse is a response that is kept to be replayed on retries.
+type StoredResponse struct {
+	Key         string
+	RequestHash string
+	// Done is false while the first request is still being handled.
+	Done        bool
+	Status      int
+	ContentType string
+	Body        []byte
+	ExpiresAt   int64
+}
+
+// IdempotencyStore keeps the responses of the requests that carry an
+// idempotency key.
+type IdempotencyStore interface {
+	// Reserve claims the key for a request that is about to be handled. It
+	// returns bareknews.ErrDataAlreadyExist when the key is claimed and not
+	// expired yet.
+	Reserve(ctx context.Context, key, requestHash string, expiresAt int64) error
+	// Get returns the response of the key or bareknews.ErrDataNotFound.
+	Get(ctx context.Context, key string) (StoredResponse, error)
+	// Complete stores the response of a reserved key and keeps it until
+	// resp.ExpiresAt.
+	Complete(ctx context.Context, resp StoredResponse) error
+	// Release removes a reserved key, so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency replays the stored response of a POST request that is sent
+// again with the same Idempotency-Key header. A key that is reused with
+// another request is rejected with 422. It goes before Errors, so the error
+// responses are kept too; only server errors are dropped, so those requests
+// can be retried. The key is held for lock while the request is handled and
+// the response is kept for ttl, so a request that never completes frees its
+// key after lock.
+func Idempotency(store IdempotencyStore, ttl, lock time.Duration) Middleware {
+	return func(handler Handler) Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				return handler(ctx, w, r)
+			}
+
+			if len(key) > maxIdempotencyKey {
+				return respondError(ctx, w, r, NewRequestError(errors.New("the idempotency key is too long"), http.StatusBadRequest))
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return respondError(ctx, w, r, errors.Wrap(err, "read the request body"))
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			hash := requestHash(r, body)
+
+			err = store.Reserve(ctx, key, hash, time.Now().Add(lock).Unix())
+			if errors.Is(err, bareknews.ErrDataAlreadyExist) {
+				if err := replay(ctx, store, w, key, hash); err != nil {
+					return respondError(ctx, w, r, err)
+				}
+				return nil
+			}
+			if err != nil {
+				return respondError(ctx, w, r, errors.Wrap(err, "reserve the idempotency key"))
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			if err := handler(ctx, rec, r); err != nil {
+				if rErr := store.Release(ctx, key); rErr != nil {
+					return errors.Wrap(rErr, "release the idempotency key")
+				}
+				return err
+			}
+
+			if rec.status >= http.StatusInternalServerError {
+				err = store.Release(ctx, key)
+			} else {
+				err = store.Complete(ctx, StoredResponse{
+					Key:         key,
+					RequestHash: hash,
+					Done:        true,
+					Status:      rec.status,
+					ContentType: w.Header().Get("content-type"),
+					Body:        rec.body.Bytes(),
+					ExpiresAt:   time.Now().Add(ttl).Unix(),
+				})
+			}
+
+			// The response is already sent, so a failure only leaves the
+			// key to expire after lock.
+			if err != nil {
+				trace.SpanFromContext(ctx).RecordError(errors.Wrap(err, "store the idempotent response"))
+			}
+
+			return nil
+		}
+
+		return h
+	}
+}
+
+// replay sends the stored response of the key again.
+func replay(ctx context.Context, store IdempotencyStore, w http.ResponseWriter, key, hash string) error {
+	stored, err := store.Get(ctx, key)
+	if err != nil {
+		return errors.Wrap(err, "get the idempotent response")
+	}
+
+	if stored.RequestHash != hash {
+		return NewRequestError(
+			errors.New("the idempotency key is already used with another request"),
+			http.StatusUnprocessableEntity,
+		)
+	}
+
+	if !stored.Done {
+		return NewRequestError(
+			errors.New("a request with the idempotency key is still in progress"),
+			http.StatusConflict,
+		)
+	}
+
+	w.Header().Set("content-type", stored.ContentType)
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(stored.Status)
+
+	_, err = w.Write(stored.Body)
+	return err
+}
+
+// requestHash identifies a request by its method, path and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies the response that is sent to the client.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,142 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/idempotency/memory"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	var fail error
+	// during runs while a request is handled.
+	var during func()
+
+	build := func(lock time.Duration) web.Handler {
+		return web.SetMiddlewares(
+			[]web.Middleware{
+				web.Idempotency(memory.CreateStore(), time.Hour, lock),
+				web.Errors(zap.NewNop().Sugar()),
+			},
+			func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				calls++
+				if during != nil {
+					f := during
+					during = nil
+					f()
+				}
+				if fail != nil {
+					return fail
+				}
+				return web.Respond(w, map[string]int{"call": calls}, http.StatusCreated)
+			},
+		)
+	}
+
+	handler := build(time.Minute)
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/news", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(web.IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		is.New(t).NoErr(handler(context.TODO(), w, r))
+		return w
+	}
+
+	t.Run("retry replays the response", func(t *testing.T) {
+		is := is.New(t)
+
+		first := send("key-1", `{"title":"a"}`)
+		is.Equal(first.Code, http.StatusCreated)
+
+		second := send("key-1", `{"title":"a"}`)
+		is.Equal(second.Code, http.StatusCreated)
+		is.Equal(second.Body.String(), first.Body.String())
+		is.Equal(second.Header().Get("Idempotent-Replayed"), "true")
+		is.Equal(calls, 1)
+	})
+
+	t.Run("another body is rejected", func(t *testing.T) {
+		is := is.New(t)
+
+		w := send("key-1", `{"title":"b"}`)
+		is.Equal(w.Code, http.StatusUnprocessableEntity)
+		is.Equal(calls, 1)
+	})
+
+	t.Run("without a key every request is handled", func(t *testing.T) {
+		is := is.New(t)
+		calls = 0
+
+		send("", `{"title":"a"}`)
+		send("", `{"title":"a"}`)
+		is.Equal(calls, 2)
+	})
+
+	t.Run("error response is replayed", func(t *testing.T) {
+		is := is.New(t)
+		calls = 0
+		fail = bareknews.ErrDataAlreadyExist
+
+		w := send("key-2", `{"title":"a"}`)
+		is.Equal(w.Code, http.StatusConflict)
+
+		fail = nil
+		w = send("key-2", `{"title":"a"}`)
+		is.Equal(w.Code, http.StatusConflict)
+		is.Equal(w.Header().Get("Idempotent-Replayed"), "true")
+		is.Equal(calls, 1)
+	})
+
+	t.Run("server error can be retried", func(t *testing.T) {
+		is := is.New(t)
+		calls = 0
+		fail = errors.New("database is down")
+
+		w := send("key-3", `{"title":"a"}`)
+		is.Equal(w.Code, http.StatusInternalServerError)
+
+		fail = nil
+		w = send("key-3", `{"title":"a"}`)
+		is.Equal(w.Code, http.StatusCreated)
+		is.Equal(calls, 2)
+	})
+
+	t.Run("retry of a request in progress is rejected", func(t *testing.T) {
+		is := is.New(t)
+		calls = 0
+
+		var retry *httptest.ResponseRecorder
+		during = func() { retry = send("key-4", `{"title":"a"}`) }
+
+		w := send("key-4", `{"title":"a"}`)
+		is.Equal(w.Code, http.StatusCreated)
+		is.Equal(retry.Code, http.StatusConflict)
+		is.Equal(calls, 1)
+	})
+
+	t.Run("unfinished request frees the key after lock", func(t *testing.T) {
+		is := is.New(t)
+		calls = 0
+		handler = build(-time.Second)
+
+		var retry *httptest.ResponseRecorder
+		during = func() { retry = send("key-5", `{"title":"a"}`) }
+
+		send("key-5", `{"title":"a"}`)
+		is.Equal(retry.Code, http.StatusCreated)
+		is.Equal(calls, 2)
+	})
+}
@@ -39,8 +39,8 @@ func CORS() Middleware {
 	m := func(next Handler) Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Idempotency-Key")
 			return next(ctx, w, r)
 		}
 
@@ -54,46 +54,8 @@ func Errors(log *zap.SugaredLogger) Middleware {
 	return func(handler Handler) Handler {
 		
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-			var errResp ErrorResponse
-			var status int
-
 			if err := handler(ctx, w, r); err != nil {
-				switch errors.Cause(err).(type) {
-				case validation.Errors, validation.Error:
-					status = http.StatusBadRequest
-					// If the error is validation.Errors we want to return it
-					// as [fieldName]: error message.
-					if ve, ok := err.(validation.Errors); ok {
-						errResp = ErrorResponse{
-							Error:  "invalid data",
-							Fields: make(map[string]interface{}),
-						}
-
-						for k, v := range ve {
-							errResp.Fields[k] = v.Error()
-						}
-
-					} else {
-						errResp = ErrorResponse{
-							Error: errors.Cause(err).Error(),
-						}
-					}
-				case *RequestError:
-					reqErr := GetRequestError(err)
-					status = reqErr.Status
-					errResp = ErrorResponse{
-						Error: reqErr.Error(),
-					}
-				default:
-					status = http.StatusInternalServerError
-					errResp = ErrorResponse{
-						Error: bareknews.ErrInternalServer.Error(),
-					}
-				}
-
-				if err := Respond(w, errResp, status); err != nil {
-					return err
-				}
+				return respondError(ctx, w, r, err)
 			}
 
 			return nil
@@ -103,6 +65,55 @@ func Errors(log *zap.SugaredLogger) Middleware {
 	}
 }
 
+// respondError sends the response of a handler error: problem details when
+// the client accepts them, the legacy error body otherwise.
+func respondError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) error {
+	var errResp ErrorResponse
+
+	w.Header().Add("Vary", "Accept")
+
+	if wantsProblem(r) {
+		return RespondProblem(w, NewProblem(ctx, err))
+	}
+
+	// The legacy body is kept for the clients that don't
+	// accept problem details yet.
+	w.Header().Set("Deprecation", "true")
+
+	status := StatusOf(err)
+
+	switch {
+	case status == http.StatusInternalServerError:
+		errResp = ErrorResponse{
+			Error: bareknews.ErrInternalServer.Error(),
+		}
+	case GetRequestError(err) != nil:
+		errResp = ErrorResponse{
+			Error: GetRequestError(err).Error(),
+		}
+	default:
+		// If the error is validation.Errors we want to return it
+		// as [fieldName]: error message.
+		if ve, ok := errors.Cause(err).(validation.Errors); ok {
+			errResp = ErrorResponse{
+				Error:  "invalid data",
+				Fields: make(map[string]interface{}),
+			}
+
+			for k, v := range ve {
+				errResp.Fields[k] = v.Error()
+			}
+
+		} else {
+			errResp = ErrorResponse{
+				Error: errors.Cause(err).Error(),
+			}
+		}
+	}
+
+	return Respond(w, errResp, status)
+}
+
 func Panics() Middleware {
 	return func(next Handler) Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) (err error) {
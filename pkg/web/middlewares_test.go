@@ -0,0 +1,102 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/matryer/is"
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func TestErrors(t *testing.T) {
+	payloadTest := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"not found", bareknews.ErrDataNotFound, http.StatusNotFound},
+		{"wrapped not found", pkgerrors.Wrap(bareknews.ErrDataNotFound, "get a tag"), http.StatusNotFound},
+		{"already exist", bareknews.ErrDataAlreadyExist, http.StatusConflict},
+		{"rolled back", bareknews.ErrRolledBack, http.StatusConflict},
+		{"invalid json", bareknews.ErrInvalidJSON, http.StatusBadRequest},
+		{"too large", bareknews.ErrTooLarge, http.StatusRequestEntityTooLarge},
+		{"unsupported type", bareknews.ErrUnsupportedType, http.StatusUnsupportedMediaType},
+		{"invalid fields", validation.Errors{"title": errors.New("cannot be blank")}, http.StatusBadRequest},
+		{"request error", web.NewRequestError(errors.New("bad cursor"), http.StatusBadRequest), http.StatusBadRequest},
+		{"unknown error", errors.New("disk is full"), http.StatusInternalServerError},
+	}
+
+	for _, test := range payloadTest {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
+
+			handler := web.Errors(zap.NewNop().Sugar())(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return test.err
+			})
+
+			w := httptest.NewRecorder()
+			err := handler(context.TODO(), w, httptest.NewRequest(http.MethodGet, "/", nil))
+			is.NoErr(err)
+			is.Equal(w.Code, test.wantStatus)
+		})
+	}
+}
+
+func TestErrorsProblem(t *testing.T) {
+	is := is.New(t)
+
+	handler := web.Errors(zap.NewNop().Sugar())(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return validation.Errors{
+			"title": validation.ErrRequired,
+			"body":  validation.ErrLengthTooLong.SetParams(map[string]interface{}{"max": 10}),
+		}
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Accept", web.ProblemContentType)
+	w := httptest.NewRecorder()
+
+	err := handler(context.TODO(), w, r)
+	is.NoErr(err)
+	is.Equal(w.Code, http.StatusBadRequest)
+	is.Equal(w.Header().Get("content-type"), web.ProblemContentType)
+
+	got := web.Problem{}
+	is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+	is.Equal(got.Status, http.StatusBadRequest)
+	is.Equal(got.Code, "invalid_data")
+	is.Equal(len(got.InvalidParams), 2)
+	is.Equal(got.InvalidParams[0].Name, "body")
+	is.Equal(got.InvalidParams[0].Code, validation.ErrLengthTooLong.Code())
+	is.Equal(got.InvalidParams[1].Name, "title")
+	is.Equal(got.InvalidParams[1].Code, validation.ErrRequired.Code())
+}
+
+func TestErrorsProblemCode(t *testing.T) {
+	is := is.New(t)
+
+	handler := web.Errors(zap.NewNop().Sugar())(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return pkgerrors.Wrap(bareknews.ErrDataAlreadyExist, "save a news")
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Accept", web.ProblemContentType)
+	w := httptest.NewRecorder()
+
+	err := handler(context.TODO(), w, r)
+	is.NoErr(err)
+
+	got := web.Problem{}
+	is.NoErr(json.NewDecoder(w.Body).Decode(&got))
+	is.Equal(got.Status, http.StatusConflict)
+	is.Equal(got.Code, "data_already_exist")
+	is.Equal(got.Detail, bareknews.ErrDataAlreadyExist.Error())
+}
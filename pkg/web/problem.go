@@ -0,0 +1,151 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProblemContentType is the media type of the RFC 7807 error responses.
+// Clients opt in by sending it in the Accept header; the legacy
+// ErrorResponse body is sent otherwise until it is removed.
+const ProblemContentType = "application/problem+json"
+
+// Problem represents an RFC 7807 error response body.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam describes why a single field of the payload is invalid.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// NewProblem builds the problem details of err. The instance is the ID of
+// the trace the request belongs to.
+func NewProblem(ctx context.Context, err error) Problem {
+	status := StatusOf(err)
+	code := CodeOf(err, status)
+
+	p := Problem{
+		Type:   "urn:bareknews:problem:" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: DetailOf(err, status),
+		Code:   code,
+	}
+
+	if sc := trace.SpanFromContext(ctx).SpanContext(); sc.HasTraceID() {
+		p.Instance = sc.TraceID().String()
+	}
+
+	if ve, ok := errors.Cause(err).(validation.Errors); ok {
+		p.InvalidParams = invalidParams("", ve)
+	}
+
+	return p
+}
+
+// RespondProblem sends a problem details response to the client.
+func RespondProblem(w http.ResponseWriter, p Problem) error {
+	w.Header().Set("content-type", ProblemContentType)
+	return Respond(w, p, p.Status)
+}
+
+// wantsProblem reports whether the client accepts problem details.
+func wantsProblem(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, ProblemContentType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CodeOf returns the machine-readable code of err. Validation errors carry
+// their own code; other errors get one derived from the status.
+func CodeOf(err error, status int) string {
+	if status == http.StatusInternalServerError {
+		return statusCode(status)
+	}
+
+	cause := errors.Cause(err)
+	if reqErr := GetRequestError(err); reqErr != nil {
+		cause = errors.Cause(reqErr.Err)
+	}
+
+	switch e := cause.(type) {
+	case validation.Errors:
+		return "invalid_data"
+	case validation.Error:
+		return e.Code()
+	}
+
+	return statusCode(status)
+}
+
+// DetailOf returns the human-readable explanation of err that is safe to
+// show to the client.
+func DetailOf(err error, status int) string {
+	if status == http.StatusInternalServerError {
+		return bareknews.ErrInternalServer.Error()
+	}
+
+	if reqErr := GetRequestError(err); reqErr != nil {
+		return reqErr.Error()
+	}
+
+	if _, ok := errors.Cause(err).(validation.Errors); ok {
+		return "invalid data"
+	}
+
+	return errors.Cause(err).Error()
+}
+
+// invalidParams flattens the validation errors into a list sorted by the
+// field name. Nested fields are joined with a dot.
+func invalidParams(prefix string, ve validation.Errors) []InvalidParam {
+	params := make([]InvalidParam, 0)
+
+	for field, err := range ve {
+		name := field
+		if prefix != "" {
+			name = prefix + "." + field
+		}
+
+		switch e := err.(type) {
+		case validation.Errors:
+			params = append(params, invalidParams(name, e)...)
+		case validation.Error:
+			params = append(params, InvalidParam{Name: name, Code: e.Code(), Reason: e.Error()})
+		default:
+			params = append(params, InvalidParam{Name: name, Code: "invalid", Reason: e.Error()})
+		}
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+
+	return params
+}
+
+// statusCode turns a status into a code, e.g. 400 becomes bad_request.
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
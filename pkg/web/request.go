@@ -0,0 +1,32 @@
+package web
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/mergepatch"
+	"github.com/pkg/errors"
+)
+
+// ReadMergePatch reads a JSON merge patch from the request body. Plain JSON
+// is accepted too since a merge patch is a JSON document.
+func ReadMergePatch(r *http.Request) ([]byte, error) {
+	if ct := r.Header.Get("content-type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != mergepatch.ContentType && mediaType != "application/json") {
+			return nil, NewRequestError(
+				errors.Errorf("the content type must be %s", mergepatch.ContentType),
+				http.StatusUnsupportedMediaType,
+			)
+		}
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil || len(patch) == 0 {
+		return nil, bareknews.ErrInvalidJSON
+	}
+
+	return patch, nil
+}
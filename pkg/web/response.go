@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"github.com/Iiqbal2000/bareknews"
-	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -16,6 +15,21 @@ type GeneralResponse struct {
 	Data    interface{} `json:"data"`
 }
 
+// PageResponse represents the response body of a page of a list.
+type PageResponse struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+	Paging  Paging      `json:"paging"`
+}
+
+// Paging tells how to get the next page of a list: the client sends
+// NextCursor back as the cursor query parameter. NextCursor is empty on
+// the last page.
+type Paging struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 // ErrorResponse represents an error response body for JSON type.
 type ErrorResponse struct {
 	Error  string                 `json:"error"`
@@ -23,20 +37,17 @@ type ErrorResponse struct {
 }
 
 func WriteErrResponse(w http.ResponseWriter, log *zap.SugaredLogger, err error) error {
-	switch errors.Cause(err).(type) {
-	case validation.Errors, validation.Error:
-		if errors.Is(err, bareknews.ErrDataNotFound) {
-			w.WriteHeader(http.StatusNotFound)
-		} else {
-			w.WriteHeader(http.StatusBadRequest)
-			err = errors.Cause(err)
-		}
-	default:
+	status := StatusOf(err)
+
+	if status == http.StatusInternalServerError {
 		log.Error(err.Error())
 		err = bareknews.ErrInternalServer
-		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		err = errors.Cause(err)
 	}
 
+	w.WriteHeader(status)
+
 	return json.NewEncoder(w).Encode(
 		ErrorResponse{
 			Error: err.Error(),
@@ -1,6 +1,8 @@
 package bareknews
 
 import (
+	"fmt"
+
 	"github.com/google/uuid"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
@@ -10,11 +12,25 @@ type Post struct {
 	ID uuid.UUID
 	Title string
 	Body string
+	// Format is the markup of Body.
+	Format BodyFormat
 }
 
+// Validate checks the post against the default limits.
 func (p Post) Validate() error {
+	return p.ValidateWith(DefaultLimits)
+}
+
+// ValidateWith checks the post against the limits.
+func (p Post) ValidateWith(l Limits) error {
+	bodyMsg := fmt.Sprintf("must be at most %d bytes", l.BodyMaxBytes)
+
 	return validation.ValidateStruct(&p,
-		validation.Field(&p.Title, validation.Required, validation.Length(5, 50)),
-		validation.Field(&p.Body, validation.Required),
+		validation.Field(&p.Title, lengthRules(l.TitleMin, l.TitleMax)...),
+		validation.Field(&p.Body,
+			validation.Required.Error("cannot be blank; it "+bodyMsg),
+			validation.Length(0, l.BodyMaxBytes).Error("the size "+bodyMsg),
+		),
+		validation.Field(&p.Format),
 	)
 }
\ No newline at end of file
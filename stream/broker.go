@@ -0,0 +1,297 @@
+// Package stream pushes the changes of the news to the clients with
+// Server-Sent Events.
+package stream
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/stream")
+
+// ErrClosed is returned when the broker is closed.
+var ErrClosed = errors.New("the stream is closed")
+
+// SubscriberBuffer is the number of events a subscriber can fall behind
+// before it is dropped. It holds the events of a whole bulk request, which
+// are committed, and so sent, at once. A dropped client catches up from
+// the log when it connects again.
+const SubscriberBuffer = 2 * news.MaxBulkItems
+
+// Event is an entry of the event log.
+type Event struct {
+	ID          int64
+	Type        string
+	News        news.NewsOut
+	DateCreated int64
+}
+
+// Filter matches the events of the news items that have one of the tags
+// and the status. The tags are names or slugs; empty fields match every
+// news item.
+type Filter struct {
+	Tags   []string
+	Status string
+}
+
+func (f Filter) Match(e Event) bool {
+	if f.Status != "" && e.News.Status != f.Status {
+		return false
+	}
+
+	if len(f.Tags) == 0 {
+		return true
+	}
+
+	for _, want := range f.Tags {
+		for _, tg := range e.News.Tags {
+			if strings.EqualFold(tg.Name, want) || strings.EqualFold(tg.Slug, want) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Subscription receives the events that are written after it is made.
+// C is closed when the subscriber falls behind or the broker is closed.
+type Subscription struct {
+	C      <-chan Event
+	events chan Event
+}
+
+// Tagging fills in the tags of the events, which are written with the
+// IDs of their tags only.
+type Tagging interface {
+	GetByIds(ctx context.Context, ids []uuid.UUID) ([]tags.TagsOut, error)
+}
+
+// Broker passes the events of the log on to the subscribers. The news
+// stores write the events in the transaction of the change, and the
+// broker reads the ones that are committed when it is notified, so a
+// subscriber never gets the event of a change that is rolled back. It
+// implements news.Notifier and is safe for concurrent use.
+type Broker struct {
+	mu      sync.Mutex
+	store   Repository
+	tagging Tagging
+	log     *zap.SugaredLogger
+	subs    map[*Subscription]struct{}
+	closed  bool
+	// wake tells Run that there are new events in the log.
+	wake chan struct{}
+	// last is the ID of the last event that was sent, only used by Run.
+	last int64
+}
+
+// Ensure Broker does implement news.Notifier.
+var _ news.Notifier = &Broker{}
+
+// CreateBroker returns a broker that sends the events written after it
+// is made; the older ones are replayed by the clients that ask for them.
+func CreateBroker(ctx context.Context, store Repository, tagging Tagging, log *zap.SugaredLogger) (*Broker, error) {
+	last, err := store.LastID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get the last event id")
+	}
+
+	return &Broker{
+		store:   store,
+		tagging: tagging,
+		log:     log,
+		subs:    make(map[*Subscription]struct{}),
+		wake:    make(chan struct{}, 1),
+		last:    last,
+	}, nil
+}
+
+// Notify tells the broker that there are new events in the log. It
+// never blocks: the notifications that come while Run is busy are
+// merged.
+func (b *Broker) Notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe returns a subscription that gets the events from now on.
+func (b *Broker) Subscribe() (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	events := make(chan Event, SubscriberBuffer)
+	sub := &Subscription{C: events, events: events}
+	b.subs[sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Unsubscribe stops the subscription. It does nothing when the
+// subscription is already stopped.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Since returns up to limit events of the log after the ID, with their
+// tags.
+func (b *Broker) Since(ctx context.Context, id int64, limit int) ([]Event, error) {
+	ctx, span := tracer.Start(ctx, "stream.Since")
+	defer span.End()
+
+	events, err := b.store.GetAfter(ctx, id, limit)
+	if err != nil {
+		return []Event{}, errors.Wrap(err, "get the events")
+	}
+
+	err = b.fillTags(ctx, events)
+	if err != nil {
+		return []Event{}, err
+	}
+
+	return events, nil
+}
+
+// fillTags replaces the tags of the events, which have only their ID,
+// with the tags of the store. The tags that are removed since keep their
+// ID only.
+func (b *Broker) fillTags(ctx context.Context, events []Event) error {
+	ids := make([]uuid.UUID, 0)
+	seen := make(map[uuid.UUID]bool)
+
+	for _, e := range events {
+		for _, tg := range e.News.Tags {
+			if !seen[tg.ID] {
+				seen[tg.ID] = true
+				ids = append(ids, tg.ID)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tgs, err := b.tagging.GetByIds(ctx, ids)
+	if err != nil {
+		return errors.Wrap(err, "get the tags of the events")
+	}
+
+	byID := make(map[uuid.UUID]tags.TagsOut, len(tgs))
+	for _, tg := range tgs {
+		byID[tg.ID] = tg
+	}
+
+	for i := range events {
+		for j, tg := range events[i].News.Tags {
+			if full, ok := byID[tg.ID]; ok {
+				events[i].News.Tags[j] = full
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close ends every subscription and refuses the new ones, so the open
+// streams finish and the server can shut down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Run sends the new events of the log to the subscribers when the
+// broker is notified, and removes the events that are older than the
+// retention every hour, until the context is done.
+func (b *Broker) Run(ctx context.Context, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wake:
+			if err := b.fanOut(ctx); err != nil {
+				// The events stay in the log; they are sent with the
+				// next notification, or replayed by the clients.
+				b.log.Errorw("send the events", "ERROR", err)
+			}
+		case <-ticker.C:
+			before := time.Now().Add(-retention).Unix()
+			if err := b.store.DeleteBefore(ctx, before); err != nil {
+				b.log.Errorw("remove the old events", "ERROR", err)
+			}
+		}
+	}
+}
+
+// fanOut sends the events after the last one that was sent. The log is
+// read without the lock, which is held for the sends only; the events go
+// out in the order of their IDs because only Run sends.
+func (b *Broker) fanOut(ctx context.Context) error {
+	for {
+		events, err := b.Since(ctx, b.last, ReplayBatch)
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		b.send(events)
+		b.last = events[len(events)-1].ID
+
+		if len(events) < ReplayBatch {
+			return nil
+		}
+	}
+}
+
+// send passes the events on to the subscribers, and drops the ones that
+// fall behind.
+func (b *Broker) send(events []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+	sends:
+		for _, e := range events {
+			select {
+			case sub.events <- e:
+			default:
+				delete(b.subs, sub)
+				close(sub.events)
+				break sends
+			}
+		}
+	}
+}
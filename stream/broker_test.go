@@ -0,0 +1,203 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/stream"
+	"github.com/Iiqbal2000/bareknews/stream/memory"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// tagging is a stream.Tagging that knows the tags of the events it made.
+type tagging map[uuid.UUID]tags.TagsOut
+
+func (tg tagging) GetByIds(ctx context.Context, ids []uuid.UUID) ([]tags.TagsOut, error) {
+	r := make([]tags.TagsOut, 0, len(ids))
+	for _, id := range ids {
+		if t, ok := tg[id]; ok {
+			r = append(r, t)
+		}
+	}
+	return r, nil
+}
+
+// eventOf returns an event whose tags carry their ID only, as the news
+// stores write them.
+func (tg tagging) eventOf(kind, status string, tagNames ...string) news.Event {
+	nw := news.NewsOut{ID: uuid.New(), Title: "news title", Status: status, Tags: []tags.TagsOut{}}
+	for _, name := range tagNames {
+		t := tags.TagsOut{ID: uuid.New(), Name: name, Slug: name}
+		tg[t.ID] = t
+		nw.Tags = append(nw.Tags, tags.TagsOut{ID: t.ID})
+	}
+	return news.Event{Type: kind, News: nw}
+}
+
+// run makes a broker of the store that runs until the test ends.
+func run(t *testing.T, store stream.Repository, tg tagging) *stream.Broker {
+	broker, err := stream.CreateBroker(context.TODO(), store, tg, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go broker.Run(ctx, time.Hour)
+
+	return broker
+}
+
+// receive returns the next event of the subscription.
+func receive(t *testing.T, sub *stream.Subscription) stream.Event {
+	select {
+	case e := <-sub.C:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no event is received")
+		return stream.Event{}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	store := memory.CreateStore()
+	tg := tagging{}
+	is := is.New(t)
+
+	// the events written before the broker are only replayed.
+	store.Append(tg.eventOf(news.EventCreated, "draft"))
+	broker := run(t, store, tg)
+
+	sub, err := broker.Subscribe()
+	is.NoErr(err)
+
+	store.Append(tg.eventOf(news.EventCreated, "draft", "golang"))
+	store.Append(tg.eventOf(news.EventPublished, "publish"))
+	broker.Notify()
+
+	first := receive(t, sub)
+	second := receive(t, sub)
+	is.Equal(first.ID, int64(2))
+	is.Equal(first.Type, news.EventCreated)
+	// the tags are filled in.
+	is.Equal(first.News.Tags[0].Name, "golang")
+	is.Equal(second.ID, int64(3))
+
+	// the log has the events for the clients that resume.
+	logged, err := broker.Since(context.TODO(), 1, 10)
+	is.NoErr(err)
+	is.Equal(len(logged), 2)
+	is.Equal(logged[0].News.Tags[0].Name, "golang")
+	is.Equal(logged[1].News.Status, "publish")
+
+	broker.Unsubscribe(sub)
+	broker.Unsubscribe(sub)
+	_, open := <-sub.C
+	is.True(!open)
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	store := memory.CreateStore()
+	tg := tagging{}
+	broker := run(t, store, tg)
+	is := is.New(t)
+
+	slow, err := broker.Subscribe()
+	is.NoErr(err)
+
+	for i := 0; i < stream.SubscriberBuffer; i++ {
+		store.Append(tg.eventOf(news.EventUpdated, "draft"))
+	}
+	broker.Notify()
+
+	for len(slow.C) < stream.SubscriberBuffer {
+		time.Sleep(time.Millisecond)
+	}
+
+	late, err := broker.Subscribe()
+	is.NoErr(err)
+
+	store.Append(tg.eventOf(news.EventUpdated, "draft"))
+	broker.Notify()
+
+	// the late subscriber gets the event in the same send, which is done
+	// when the lock is free again.
+	receive(t, late)
+	broker.Unsubscribe(late)
+
+	got := 0
+	for range slow.C {
+		got++
+	}
+	is.Equal(got, stream.SubscriberBuffer)
+}
+
+func TestBrokerClose(t *testing.T) {
+	broker := run(t, memory.CreateStore(), tagging{})
+	is := is.New(t)
+
+	sub, err := broker.Subscribe()
+	is.NoErr(err)
+
+	broker.Close()
+	_, open := <-sub.C
+	is.True(!open)
+
+	_, err = broker.Subscribe()
+	is.Equal(err, stream.ErrClosed)
+}
+
+func TestBrokerReadFails(t *testing.T) {
+	event := stream.Event{ID: 1, Type: news.EventCreated, News: news.NewsOut{ID: uuid.New()}}
+	store := &stream.RepositoryMock{
+		LastIDFunc: func(ctx context.Context) (int64, error) {
+			return 0, nil
+		},
+	}
+	store.GetAfterFunc = func(ctx context.Context, id int64, limit int) ([]stream.Event, error) {
+		if len(store.GetAfterCalls()) == 1 {
+			return nil, errors.New("database is locked")
+		}
+		return []stream.Event{event}, nil
+	}
+	broker := run(t, store, tagging{})
+	is := is.New(t)
+
+	sub, err := broker.Subscribe()
+	is.NoErr(err)
+
+	broker.Notify()
+	for len(store.GetAfterCalls()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the events stay in the log and go out with the next notification.
+	broker.Notify()
+	is.Equal(receive(t, sub).ID, int64(1))
+}
+
+func TestFilter(t *testing.T) {
+	is := is.New(t)
+
+	e := stream.Event{News: news.NewsOut{
+		ID:     uuid.New(),
+		Status: "publish",
+		Tags: []tags.TagsOut{
+			{ID: uuid.New(), Name: "Golang", Slug: "golang"},
+			{ID: uuid.New(), Name: "Rust", Slug: "rust"},
+		},
+	}}
+
+	is.True(stream.Filter{}.Match(e))
+	is.True(stream.Filter{Status: "publish"}.Match(e))
+	is.True(!stream.Filter{Status: "draft"}.Match(e))
+	is.True(stream.Filter{Tags: []string{"python", "golang"}}.Match(e))
+	is.True(!stream.Filter{Tags: []string{"python"}}.Match(e))
+	is.True(!stream.Filter{Tags: []string{"rust"}, Status: "draft"}.Match(e))
+}
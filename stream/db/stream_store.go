@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/stream"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/stream/db")
+
+type Store struct {
+	conn *sql.DB
+}
+
+// Ensure Store does implement stream.Repository.
+var _ stream.Repository = Store{}
+
+func CreateStore(conn *sql.DB) Store {
+	return Store{conn: conn}
+}
+
+// Append writes the event to the log in the transaction of the change,
+// so the log has the event exactly when the change is committed. The log
+// relies on AUTOINCREMENT, which never hands out the ID of a removed
+// event again, so an old Last-Event-ID can't skip new events.
+func Append(ctx context.Context, tx *sql.Tx, e news.Event) error {
+	data, err := json.Marshal(e.News)
+	if err != nil {
+		return errors.Wrap(err, "marshal the news item")
+	}
+
+	builder := sqlbuilder.NewInsertBuilder()
+	builder.InsertInto("news_events")
+	builder.Cols("type", "newsID", "data", "date_created")
+	builder.Values(e.Type, e.News.ID, string(data), time.Now().Unix())
+	query, args := builder.Build()
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "append the event")
+	}
+
+	return nil
+}
+
+func (s Store) LastID(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "stream.db.LastID")
+	defer span.End()
+
+	// The sequence keeps the last ID when the log is empty.
+	var id int64
+	err := s.conn.QueryRowContext(
+		ctx,
+		"SELECT COALESCE(MAX(seq), 0) FROM sqlite_sequence WHERE name = 'news_events'",
+	).Scan(&id)
+	if err != nil {
+		return 0, errors.Wrap(err, "exec the query")
+	}
+
+	return id, nil
+}
+
+func (s Store) GetAfter(ctx context.Context, id int64, limit int) ([]stream.Event, error) {
+	ctx, span := tracer.Start(ctx, "stream.db.GetAfter")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("id", "type", "data", "date_created")
+	builder.From("news_events")
+	builder.Where(builder.GreaterThan("id", id))
+	builder.OrderBy("id")
+	builder.Limit(limit)
+	query, args := builder.Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []stream.Event{}, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	events := make([]stream.Event, 0)
+
+	for rows.Next() {
+		e := stream.Event{}
+		var data string
+
+		err = rows.Scan(&e.ID, &e.Type, &data, &e.DateCreated)
+		if err != nil {
+			return []stream.Event{}, errors.Wrap(err, "scan an event")
+		}
+
+		err = json.Unmarshal([]byte(data), &e.News)
+		if err != nil {
+			return []stream.Event{}, errors.Wrap(err, "unmarshal the news item")
+		}
+
+		events = append(events, e)
+	}
+
+	if rows.Err() != nil {
+		return []stream.Event{}, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return events, nil
+}
+
+func (s Store) DeleteBefore(ctx context.Context, unix int64) error {
+	ctx, span := tracer.Start(ctx, "stream.db.DeleteBefore")
+	defer span.End()
+
+	builder := sqlbuilder.NewDeleteBuilder()
+	builder.DeleteFrom("news_events")
+	builder.Where(builder.LessThan("date_created", unix))
+	query, args := builder.Build()
+
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
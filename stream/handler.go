@@ -0,0 +1,199 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// ReplayBatch is the number of events of the log that are read at a
+	// time when a client catches up.
+	ReplayBatch = 100
+	// RetryMillis is the time a client waits before it connects again.
+	RetryMillis = 1000
+)
+
+type handler struct {
+	broker *Broker
+	// heartbeat is the time between the comments that keep the idle
+	// connections open.
+	heartbeat time.Duration
+	// lifetime is the time a stream is kept open; zero keeps it open
+	// until the client or the server leaves.
+	lifetime time.Duration
+	log      *zap.SugaredLogger
+}
+
+// CreateHandler returns the handler of the stream. A stream is ended
+// before the write timeout of the server, because the server fails every
+// write after it; the client connects again and resumes from its last
+// event.
+func CreateHandler(b *Broker, heartbeat, writeTimeout time.Duration, log *zap.SugaredLogger) handler {
+	return handler{
+		broker:    b,
+		heartbeat: heartbeat,
+		lifetime:  writeTimeout - writeTimeout/10,
+		log:       log,
+	}
+}
+
+// StreamNews godoc
+// @Summary      Stream the changes of the news
+// @Description  Push the created, updated, published and deleted news as Server-Sent Events. The data of an event is the news item. A client that connects again with the Last-Event-ID header gets the events it missed.
+// @Tags         news
+// @Produce      text/event-stream
+// @Param        tag   query     []string  false  "names or slugs of the tags of the news"  collectionFormat(multi)
+// @Param        status   query     string  false  "status of the news"	Enums(publish, draft)
+// @Param        Last-Event-ID   header     int  false  "ID of the last event the client got"
+// @Success      200  {string}  string  "The stream of the events"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      503  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /stream/news [get]
+func (h handler) Stream(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	filter := Filter{
+		Tags:   r.URL.Query()["tag"],
+		Status: strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))),
+	}
+
+	if filter.Status != "" {
+		err := bareknews.Status(filter.Status).Validate()
+		if err != nil {
+			return validation.Errors{"status": err}
+		}
+	}
+
+	lastID, resume, err := lastEventID(r)
+	if err != nil {
+		return web.NewRequestError(errors.New("failed to convert the last event id"), http.StatusBadRequest)
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("the response can't be streamed")
+	}
+
+	// The subscription is made before the log is read, so no event falls
+	// between them; the events that are in both are skipped by their ID.
+	sub, err := h.broker.Subscribe()
+	if err != nil {
+		return web.NewRequestError(err, http.StatusServiceUnavailable)
+	}
+
+	defer h.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", RetryMillis)
+	if err != nil {
+		return nil
+	}
+
+	for resume {
+		events, err := h.broker.Since(ctx, lastID, ReplayBatch)
+		if err != nil {
+			h.log.Errorw("replay the events", "ERROR", err)
+			return nil
+		}
+
+		for _, e := range events {
+			lastID = e.ID
+			if filter.Match(e) {
+				if err := writeEvent(w, e); err != nil {
+					return nil
+				}
+			}
+		}
+
+		resume = len(events) == ReplayBatch
+	}
+
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	var end <-chan time.Time
+	if h.lifetime > 0 {
+		timer := time.NewTimer(h.lifetime)
+		defer timer.Stop()
+		end = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-end:
+			return nil
+		case e, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+
+			if e.ID <= lastID {
+				continue
+			}
+
+			lastID = e.ID
+			if !filter.Match(e) {
+				continue
+			}
+
+			if err := writeEvent(w, e); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+// lastEventID reads the ID of the last event of a client from the
+// Last-Event-ID header, or from the last_event_id query for the clients
+// that can't set it. The flag is false when there is none.
+func lastEventID(r *http.Request) (int64, bool, error) {
+	raw := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(r.URL.Query().Get("last_event_id"))
+	}
+
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, errors.New("invalid last event id")
+	}
+
+	return id, true, nil
+}
+
+func writeEvent(w http.ResponseWriter, e Event) error {
+	data, err := json.Marshal(e.News)
+	if err != nil {
+		return errors.Wrap(err, "marshal the news item")
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+
+	return err
+}
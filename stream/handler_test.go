@@ -0,0 +1,146 @@
+package stream_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/stream"
+	"github.com/Iiqbal2000/bareknews/stream/memory"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// serve runs the handler of the stream on a test server.
+func serve(t *testing.T, broker *stream.Broker, heartbeat, writeTimeout time.Duration) *httptest.Server {
+	h := stream.CreateHandler(broker, heartbeat, writeTimeout, zap.NewNop().Sugar())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h.Stream(r.Context(), w, r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// eventsOf returns the ID and type lines of the events of a stream.
+func eventsOf(body string) []string {
+	r := make([]string, 0)
+
+	for _, block := range strings.Split(body, "\n\n") {
+		lines := strings.Split(block, "\n")
+		if strings.HasPrefix(lines[0], "id: ") {
+			r = append(r, lines[0]+" "+lines[1])
+		}
+	}
+
+	return r
+}
+
+func TestStream(t *testing.T) {
+	store := memory.CreateStore()
+	tg := tagging{}
+	broker := run(t, store, tg)
+	srv := serve(t, broker, time.Hour, time.Second)
+	is := is.New(t)
+
+	store.Append(tg.eventOf(news.EventCreated, "draft", "golang"))
+	store.Append(tg.eventOf(news.EventCreated, "draft", "rust"))
+	store.Append(tg.eventOf(news.EventPublished, "publish", "golang"))
+	broker.Notify()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"?tag=golang", nil)
+	is.NoErr(err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	res, err := http.DefaultClient.Do(req)
+	is.NoErr(err)
+	defer res.Body.Close()
+
+	is.Equal(res.StatusCode, http.StatusOK)
+	is.Equal(res.Header.Get("Content-Type"), "text/event-stream")
+
+	// a live event after the replay.
+	time.Sleep(100 * time.Millisecond)
+	store.Append(tg.eventOf(news.EventDeleted, "publish", "golang"))
+	broker.Notify()
+
+	// the stream ends by itself before the write timeout.
+	start := time.Now()
+	body, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.True(time.Since(start) < time.Second)
+
+	is.True(strings.HasPrefix(string(body), "retry: "))
+	is.Equal(eventsOf(string(body)), []string{
+		"id: 3 event: published",
+		"id: 4 event: deleted",
+	})
+}
+
+func TestStreamHeartbeat(t *testing.T) {
+	broker := run(t, memory.CreateStore(), tagging{})
+	srv := serve(t, broker, 50*time.Millisecond, 300*time.Millisecond)
+	is := is.New(t)
+
+	res, err := http.Get(srv.URL + "?status=publish")
+	is.NoErr(err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	is.NoErr(err)
+	is.True(strings.Contains(string(body), ": heartbeat\n\n"))
+}
+
+func TestStreamEndsOnClose(t *testing.T) {
+	broker := run(t, memory.CreateStore(), tagging{})
+	srv := serve(t, broker, time.Hour, 0)
+	is := is.New(t)
+
+	res, err := http.Get(srv.URL)
+	is.NoErr(err)
+	defer res.Body.Close()
+
+	done := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, res.Body)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	broker.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the stream is still open")
+	}
+
+	// new clients are turned away.
+	res, err = http.Get(srv.URL)
+	is.NoErr(err)
+	res.Body.Close()
+	is.True(res.StatusCode != http.StatusOK)
+}
+
+func TestStreamBadInput(t *testing.T) {
+	broker := run(t, memory.CreateStore(), tagging{})
+	srv := serve(t, broker, time.Hour, time.Second)
+	is := is.New(t)
+
+	res, err := http.Get(srv.URL + "?status=archived")
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusBadRequest)
+
+	res, err = http.Get(srv.URL + "?last_event_id=abc")
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.StatusCode, http.StatusBadRequest)
+}
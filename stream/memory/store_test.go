@@ -0,0 +1,118 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsmemory "github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/Iiqbal2000/bareknews/stream"
+	"github.com/Iiqbal2000/bareknews/stream/memory"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func typesOf(got []stream.Event) []string {
+	r := make([]string, 0, len(got))
+	for _, e := range got {
+		r = append(r, e.Type)
+	}
+	return r
+}
+
+func TestStore(t *testing.T) {
+	store := memory.CreateStore()
+	newsStore := newsmemory.CreateStore().WithLog(store)
+	is := is.New(t)
+
+	last, err := store.LastID(context.TODO())
+	is.NoErr(err)
+	is.Equal(last, int64(0))
+
+	tagID := uuid.New()
+	n := news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{tagID}, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *n))
+
+	n.ChangeTitle("news 2")
+	is.NoErr(newsStore.Update(context.TODO(), *n))
+
+	n.ChangeStatus(bareknews.Publish)
+	is.NoErr(newsStore.Update(context.TODO(), *n))
+
+	is.NoErr(newsStore.Delete(context.TODO(), n.Post.ID))
+
+	got, err := store.GetAfter(context.TODO(), 0, 10)
+	is.NoErr(err)
+	is.Equal(typesOf(got), []string{news.EventCreated, news.EventUpdated, news.EventPublished, news.EventDeleted})
+	is.Equal(got[0].ID, int64(1))
+	is.Equal(got[0].News.Title, "news 1")
+	// the tags carry their ID only.
+	is.Equal(got[0].News.Tags[0].ID, tagID)
+	is.Equal(got[0].News.Tags[0].Name, "")
+	// the deleted news item is as it was before it is deleted.
+	is.Equal(got[3].News.Title, "news 2")
+	is.Equal(got[3].News.Status, bareknews.Publish.String())
+
+	got, err = store.GetAfter(context.TODO(), 2, 1)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].ID, int64(3))
+
+	is.NoErr(store.DeleteBefore(context.TODO(), time.Now().Unix()+1))
+	got, err = store.GetAfter(context.TODO(), 0, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+
+	// the IDs of the removed events aren't handed out again.
+	last, err = store.LastID(context.TODO())
+	is.NoErr(err)
+	is.Equal(last, int64(4))
+
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 3", "news body", bareknews.Publish, nil, time.Now().Unix())))
+
+	got, err = store.GetAfter(context.TODO(), 0, 10)
+	is.NoErr(err)
+	// a news item that is created as published is published too.
+	is.Equal(typesOf(got), []string{news.EventCreated, news.EventPublished})
+	is.Equal(got[0].ID, int64(5))
+	is.Equal(got[1].News.Status, bareknews.Publish.String())
+}
+
+func TestStoreRollBack(t *testing.T) {
+	store := memory.CreateStore()
+	newsStore := newsmemory.CreateStore().WithLog(store)
+	is := is.New(t)
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	// both items are retitled, so the second one is a duplicate.
+	retitle := func(n news.News) (news.Change, error) {
+		n.ChangeTitle("news 3")
+		return news.Change{News: n}, nil
+	}
+	ids := []uuid.UUID{first.Post.ID, second.Post.ID}
+
+	// nothing is written when the whole bulk is rolled back.
+	errs, err := newsStore.Bulk(context.TODO(), ids, retitle, true)
+	is.NoErr(err)
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+
+	got, err := store.GetAfter(context.TODO(), 2, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+
+	// the failed change is left out with its event.
+	errs, err = newsStore.Bulk(context.TODO(), ids, retitle, false)
+	is.NoErr(err)
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+
+	got, err = store.GetAfter(context.TODO(), 2, 10)
+	is.NoErr(err)
+	is.Equal(typesOf(got), []string{news.EventUpdated})
+	is.Equal(got[0].News.ID, first.Post.ID)
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/stream"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/stream/memory")
+
+// Store keeps the stream events in memory, in the order they are
+// appended. The IDs come from a counter that a prune never resets, so a
+// client can resume after the last ID it saw. It is safe for concurrent
+// use.
+type Store struct {
+	mu     *sync.RWMutex
+	lastID *int64
+	items  *[]stream.Event
+}
+
+// Ensure Store does implement stream.Repository.
+var _ stream.Repository = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:     &sync.RWMutex{},
+		lastID: new(int64),
+		items:  &[]stream.Event{},
+	}
+}
+
+// Append writes the event to the log. The news store appends its events
+// while it holds its own lock, which stands in for the transaction of the
+// SQLite store.
+func (s Store) Append(e news.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	*s.lastID++
+	*s.items = append(*s.items, stream.Event{
+		ID:          *s.lastID,
+		Type:        e.Type,
+		News:        e.News,
+		DateCreated: time.Now().Unix(),
+	})
+}
+
+func (s Store) LastID(ctx context.Context) (int64, error) {
+	_, span := tracer.Start(ctx, "stream.memory.LastID")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return *s.lastID, nil
+}
+
+func (s Store) GetAfter(ctx context.Context, id int64, limit int) ([]stream.Event, error) {
+	_, span := tracer.Start(ctx, "stream.memory.GetAfter")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r := make([]stream.Event, 0)
+
+	// The events are kept in the order of their IDs.
+	for _, e := range *s.items {
+		if len(r) == limit {
+			break
+		}
+		if e.ID > id {
+			r = append(r, e)
+		}
+	}
+
+	return r, nil
+}
+
+func (s Store) DeleteBefore(ctx context.Context, unix int64) error {
+	_, span := tracer.Start(ctx, "stream.memory.DeleteBefore")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := make([]stream.Event, 0, len(*s.items))
+	for _, e := range *s.items {
+		if e.DateCreated >= unix {
+			kept = append(kept, e)
+		}
+	}
+
+	*s.items = kept
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package stream
+
+import "context"
+
+//go:generate moq -out streamRepo_moq.go . Repository
+type Repository interface {
+	// LastID returns the ID of the last event that was written to the
+	// log, zero when there is none. The ID of an event is greater than
+	// the ID of every event before it.
+	LastID(ctx context.Context) (int64, error)
+	// GetAfter returns up to limit events after the ID, the oldest first.
+	GetAfter(ctx context.Context, id int64, limit int) ([]Event, error)
+	// DeleteBefore removes the events that are created before the Unix
+	// time.
+	DeleteBefore(ctx context.Context, unix int64) error
+}
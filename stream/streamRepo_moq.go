@@ -0,0 +1,178 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package stream
+
+import (
+	"context"
+	"sync"
+)
+
+// Ensure, that RepositoryMock does implement Repository.
+// If this is not the case, regenerate this file with moq.
+var _ Repository = &RepositoryMock{}
+
+// RepositoryMock is a mock implementation of Repository.
+//
+// 	func TestSomethingThatUsesRepository(t *testing.T) {
+//
+// 		// make and configure a mocked Repository
+// 		mockedRepository := &RepositoryMock{
+// 			DeleteBeforeFunc: func(ctx context.Context, unix int64) error {
+// 				panic("mock out the DeleteBefore method")
+// 			},
+// 			GetAfterFunc: func(ctx context.Context, id int64, limit int) ([]Event, error) {
+// 				panic("mock out the GetAfter method")
+// 			},
+// 			LastIDFunc: func(ctx context.Context) (int64, error) {
+// 				panic("mock out the LastID method")
+// 			},
+// 		}
+//
+// 		// use mockedRepository in code that requires Repository
+// 		// and then make assertions.
+//
+// 	}
+type RepositoryMock struct {
+	// DeleteBeforeFunc mocks the DeleteBefore method.
+	DeleteBeforeFunc func(ctx context.Context, unix int64) error
+
+	// GetAfterFunc mocks the GetAfter method.
+	GetAfterFunc func(ctx context.Context, id int64, limit int) ([]Event, error)
+
+	// LastIDFunc mocks the LastID method.
+	LastIDFunc func(ctx context.Context) (int64, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteBefore holds details about calls to the DeleteBefore method.
+		DeleteBefore []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Unix is the unix argument value.
+			Unix int64
+		}
+		// GetAfter holds details about calls to the GetAfter method.
+		GetAfter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// LastID holds details about calls to the LastID method.
+		LastID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockDeleteBefore sync.RWMutex
+	lockGetAfter     sync.RWMutex
+	lockLastID       sync.RWMutex
+}
+
+// DeleteBefore calls DeleteBeforeFunc.
+func (mock *RepositoryMock) DeleteBefore(ctx context.Context, unix int64) error {
+	if mock.DeleteBeforeFunc == nil {
+		panic("RepositoryMock.DeleteBeforeFunc: method is nil but Repository.DeleteBefore was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Unix int64
+	}{
+		Ctx:  ctx,
+		Unix: unix,
+	}
+	mock.lockDeleteBefore.Lock()
+	mock.calls.DeleteBefore = append(mock.calls.DeleteBefore, callInfo)
+	mock.lockDeleteBefore.Unlock()
+	return mock.DeleteBeforeFunc(ctx, unix)
+}
+
+// DeleteBeforeCalls gets all the calls that were made to DeleteBefore.
+// Check the length with:
+//     len(mockedRepository.DeleteBeforeCalls())
+func (mock *RepositoryMock) DeleteBeforeCalls() []struct {
+	Ctx  context.Context
+	Unix int64
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Unix int64
+	}
+	mock.lockDeleteBefore.RLock()
+	calls = mock.calls.DeleteBefore
+	mock.lockDeleteBefore.RUnlock()
+	return calls
+}
+
+// GetAfter calls GetAfterFunc.
+func (mock *RepositoryMock) GetAfter(ctx context.Context, id int64, limit int) ([]Event, error) {
+	if mock.GetAfterFunc == nil {
+		panic("RepositoryMock.GetAfterFunc: method is nil but Repository.GetAfter was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		ID    int64
+		Limit int
+	}{
+		Ctx:   ctx,
+		ID:    id,
+		Limit: limit,
+	}
+	mock.lockGetAfter.Lock()
+	mock.calls.GetAfter = append(mock.calls.GetAfter, callInfo)
+	mock.lockGetAfter.Unlock()
+	return mock.GetAfterFunc(ctx, id, limit)
+}
+
+// GetAfterCalls gets all the calls that were made to GetAfter.
+// Check the length with:
+//     len(mockedRepository.GetAfterCalls())
+func (mock *RepositoryMock) GetAfterCalls() []struct {
+	Ctx   context.Context
+	ID    int64
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		ID    int64
+		Limit int
+	}
+	mock.lockGetAfter.RLock()
+	calls = mock.calls.GetAfter
+	mock.lockGetAfter.RUnlock()
+	return calls
+}
+
+// LastID calls LastIDFunc.
+func (mock *RepositoryMock) LastID(ctx context.Context) (int64, error) {
+	if mock.LastIDFunc == nil {
+		panic("RepositoryMock.LastIDFunc: method is nil but Repository.LastID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockLastID.Lock()
+	mock.calls.LastID = append(mock.calls.LastID, callInfo)
+	mock.lockLastID.Unlock()
+	return mock.LastIDFunc(ctx)
+}
+
+// LastIDCalls gets all the calls that were made to LastID.
+// Check the length with:
+//     len(mockedRepository.LastIDCalls())
+func (mock *RepositoryMock) LastIDCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockLastID.RLock()
+	calls = mock.calls.LastID
+	mock.lockLastID.RUnlock()
+	return calls
+}
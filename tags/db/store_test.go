@@ -2,9 +2,12 @@ package db_test
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
 	"testing"
 
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsdb "github.com/Iiqbal2000/bareknews/news/db"
 	"github.com/Iiqbal2000/bareknews/pkg/sqlite3"
 	"github.com/Iiqbal2000/bareknews/tags"
 	"github.com/Iiqbal2000/bareknews/tags/db"
@@ -76,7 +79,7 @@ func TestDelete(t *testing.T) {
 	err = storage.Delete(context.TODO(), tag.Label.ID)
 	is.NoErr(err)
 	_, err = storage.GetById(context.TODO(), tag.Label.ID)
-	is.Equal(err, sql.ErrNoRows)
+	is.Equal(err, bareknews.ErrDataNotFound)
 }
 
 func TestCount(t *testing.T) {
@@ -99,7 +102,7 @@ func TestCount(t *testing.T) {
 		is := is.New(t)
 
 		c, err := storage.Count(context.TODO(), uuid.New())
-		is.Equal(err, sql.ErrNoRows)
+		is.Equal(err, bareknews.ErrDataNotFound)
 		is.Equal(c, 0)
 	})
 }
@@ -153,4 +156,329 @@ func TestGetByIds(t *testing.T) {
 	got, err := storage.GetByIds(context.TODO(), []uuid.UUID{tag1.Label.ID, tag2.Label.ID})
 	is.NoErr(err)
 	is.Equal(len(got), 2)
-}
\ No newline at end of file
+}
+func TestMerge(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	storage := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	target := tags.Create("covid")
+	source1 := tags.Create("covid-19")
+	source2 := tags.Create("Covid19")
+	for _, tg := range []*tags.Tags{target, source1, source2} {
+		is.NoErr(storage.Save(context.TODO(), *tg))
+	}
+
+	both := news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{target.Label.ID, source1.Label.ID}, 1)
+	sourceOnly := news.Create("news 2", "news body", bareknews.Draft, []uuid.UUID{source1.Label.ID, source2.Label.ID}, 2)
+	is.NoErr(newsStore.Save(context.TODO(), *both))
+	is.NoErr(newsStore.Save(context.TODO(), *sourceOnly))
+
+	err := storage.Merge(context.TODO(), target.Label.ID, []uuid.UUID{source1.Label.ID, source2.Label.ID})
+	is.NoErr(err)
+
+	got, err := newsStore.GetById(context.TODO(), both.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.TagsID, []uuid.UUID{target.Label.ID})
+
+	got, err = newsStore.GetById(context.TODO(), sourceOnly.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.TagsID, []uuid.UUID{target.Label.ID})
+
+	_, err = storage.GetById(context.TODO(), source1.Label.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	tg, err := storage.GetById(context.TODO(), target.Label.ID)
+	is.NoErr(err)
+	is.Equal(tg.Aliases, []string{"Covid19", "covid-19"})
+
+	// the aliases resolve to the target tag.
+	byAlias, err := storage.GetByName(context.TODO(), "covid-19")
+	is.NoErr(err)
+	is.Equal(byAlias.Label.ID, target.Label.ID)
+
+	byNames, err := storage.GetByNames(context.TODO(), "covid", "Covid19")
+	is.NoErr(err)
+	is.Equal(len(byNames), 1)
+	is.Equal(byNames[0].Label.ID, target.Label.ID)
+
+	// an alias cannot be used as the name of another tag.
+	err = storage.Save(context.TODO(), *tags.Create("covid-19"))
+	is.Equal(err, bareknews.ErrDataAlreadyExist)
+
+	err = storage.Merge(context.TODO(), target.Label.ID, []uuid.UUID{uuid.New()})
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
+
+func TestGetDescendantIds(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	storage := db.CreateStore(conn)
+	is := is.New(t)
+
+	sports := tags.Create("sports")
+	football := tags.Create("football")
+	football.ChangeParent(sports.Label.ID)
+	league := tags.Create("premier league")
+	league.ChangeParent(football.Label.ID)
+	politics := tags.Create("politics")
+	for _, tg := range []*tags.Tags{sports, football, league, politics} {
+		is.NoErr(storage.Save(context.TODO(), *tg))
+	}
+
+	got, err := storage.GetById(context.TODO(), league.Label.ID)
+	is.NoErr(err)
+	is.Equal(got.ParentID, football.Label.ID)
+
+	ids, err := storage.GetDescendantIds(context.TODO(), sports.Label.ID)
+	is.NoErr(err)
+	is.Equal(len(ids), 3)
+	is.Equal(ids[0], sports.Label.ID)
+
+	ids, err = storage.GetDescendantIds(context.TODO(), league.Label.ID)
+	is.NoErr(err)
+	is.Equal(ids, []uuid.UUID{league.Label.ID})
+
+	_, err = storage.GetDescendantIds(context.TODO(), uuid.New())
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	// the children of a deleted tag become top-level tags.
+	is.NoErr(storage.Delete(context.TODO(), sports.Label.ID))
+
+	got, err = storage.GetById(context.TODO(), football.Label.ID)
+	is.NoErr(err)
+	is.Equal(got.ParentID, uuid.Nil)
+}
+
+func TestCountNews(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	storage := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	tag1 := tags.Create("tag 1")
+	tag2 := tags.Create("tag 2")
+	is.NoErr(storage.Save(context.TODO(), *tag1))
+	is.NoErr(storage.Save(context.TODO(), *tag2))
+	is.NoErr(storage.Save(context.TODO(), *tags.Create("tag 3")))
+
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{tag1.Label.ID, tag2.Label.ID}, 1)))
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 2", "news body", bareknews.Publish, []uuid.UUID{tag1.Label.ID}, 2)))
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 3", "news body", bareknews.Draft, []uuid.UUID{tag2.Label.ID}, 3)))
+
+	got, err := storage.CountNews(context.TODO(), bareknews.Publish)
+	is.NoErr(err)
+	is.Equal(got, map[uuid.UUID]int{tag1.Label.ID: 2, tag2.Label.ID: 1})
+
+	got, err = storage.CountNews(context.TODO(), "")
+	is.NoErr(err)
+	is.Equal(got, map[uuid.UUID]int{tag1.Label.ID: 2, tag2.Label.ID: 2})
+}
+
+func TestSuggest(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	storage := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	economy := tags.Create("Économie")
+	ecology := tags.Create("ecology")
+	sports := tags.Create("sports")
+	is.NoErr(storage.Save(context.TODO(), *economy))
+	is.NoErr(storage.Save(context.TODO(), *ecology))
+	is.NoErr(storage.Save(context.TODO(), *sports))
+
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{ecology.Label.ID}, 1)))
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 2", "news body", bareknews.Draft, []uuid.UUID{economy.Label.ID}, 2)))
+
+	got, err := storage.Suggest(context.TODO(), "eco", 10)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Tag.Label.ID, ecology.Label.ID)
+	is.Equal(got[0].Count, 1)
+	is.Equal(got[1].Tag.Label.ID, economy.Label.ID)
+	is.Equal(got[1].Count, 0)
+
+	got, err = storage.Suggest(context.TODO(), "eco", 1)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+
+	// The source names of a merge become searchable aliases.
+	football := tags.Create("football")
+	is.NoErr(storage.Save(context.TODO(), *football))
+	is.NoErr(storage.Merge(context.TODO(), sports.Label.ID, []uuid.UUID{football.Label.ID}))
+
+	got, err = storage.Suggest(context.TODO(), "foot", 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Tag.Label.ID, sports.Label.ID)
+
+	// A renamed tag isn't found by its old name anymore.
+	ecology.ChangeName("environment")
+	is.NoErr(storage.Update(context.TODO(), *ecology))
+
+	got, err = storage.Suggest(context.TODO(), "eco", 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Tag.Label.ID, economy.Label.ID)
+
+	is.NoErr(storage.Delete(context.TODO(), economy.Label.ID))
+
+	got, err = storage.Suggest(context.TODO(), "eco", 10)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+}
+
+func BenchmarkSuggest(b *testing.B) {
+	conn, err := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	conn.SetMaxOpenConns(1)
+	storage := db.CreateStore(conn)
+
+	tx, err := conn.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// The tags are inserted directly, since saving them one by one takes
+	// longer than the benchmark itself.
+	for i := 0; i < 100000; i++ {
+		id := uuid.New()
+		name := fmt.Sprintf("tag %05d", i)
+
+		_, err = tx.Exec("INSERT INTO tags (id, name, slug) VALUES (?, ?, ?)", id, name, bareknews.NewSlug(name))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		_, err = tx.Exec("INSERT INTO tag_search (key, tagID) VALUES (?, ?)", name, id)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		got, err := storage.Suggest(context.TODO(), "tag 0424", 10)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if len(got) != 10 {
+			b.Fatalf("got %d tags, want 10", len(got))
+		}
+	}
+}
+
+func TestGetPage(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	storage := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	asia := tags.Create("Asia")
+	covid := tags.Create("covid")
+	economy := tags.Create("economy")
+	ecology := tags.Create("ecology")
+	for _, tg := range []*tags.Tags{asia, covid, economy, ecology} {
+		is.NoErr(storage.Save(context.TODO(), *tg))
+	}
+
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{covid.Label.ID, economy.Label.ID}, 1)))
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 2", "news body", bareknews.Publish, []uuid.UUID{covid.Label.ID}, 2)))
+
+	names := func(got []tags.TagCount) []string {
+		r := make([]string, 0, len(got))
+		for _, tc := range got {
+			r = append(r, tc.Tag.Label.Name)
+		}
+		return r
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByName, Limit: 2})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"Asia", "covid"})
+
+		last := got[1]
+		got, err = storage.GetPage(context.TODO(), tags.PageQuery{
+			By:    tags.PageByName,
+			Limit: 2,
+			After: &tags.Cursor{Name: last.Tag.Label.Name, ID: last.Tag.Label.ID},
+		})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"ecology", "economy"})
+
+		got, err = storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByName, Desc: true, Limit: 10})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"economy", "ecology", "covid", "Asia"})
+	})
+
+	t.Run("by count", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByCount, Desc: true, Limit: 2})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"covid", "economy"})
+		is.Equal(got[0].Count, 2)
+
+		last := got[1]
+		got, err = storage.GetPage(context.TODO(), tags.PageQuery{
+			By:    tags.PageByCount,
+			Desc:  true,
+			Limit: 2,
+			After: &tags.Cursor{Count: last.Count, Name: last.Tag.Label.Name, ID: last.Tag.Label.ID},
+		})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"Asia", "ecology"})
+	})
+
+	t.Run("search and unused", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByName, Prefix: "eco", Unused: true, Limit: 10})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"ecology"})
+	})
+}
+
+func TestGetBySlug(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	storage := db.CreateStore(conn)
+	is := is.New(t)
+
+	tag := tags.Create("world cup")
+	tag.ChangeMeta(tags.Meta{
+		Description:     "Every match of the world cup",
+		Color:           "#1e90ff",
+		ImageURL:        "https://example.com/world-cup.png",
+		MetaTitle:       "World cup news",
+		MetaDescription: "Scores and stories",
+	})
+	is.NoErr(storage.Save(context.TODO(), *tag))
+
+	got, err := storage.GetBySlug(context.TODO(), "world-cup")
+	is.NoErr(err)
+	is.Equal(got.Label.ID, tag.Label.ID)
+	is.Equal(got.Meta, tag.Meta)
+
+	tag.ChangeMeta(tags.Meta{Color: "#000000"})
+	is.NoErr(storage.Update(context.TODO(), *tag))
+
+	byID, err := storage.GetById(context.TODO(), tag.Label.ID)
+	is.NoErr(err)
+	is.Equal(byID.Meta, tags.Meta{Color: "#000000"})
+
+	_, err = storage.GetBySlug(context.TODO(), "unknown")
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
@@ -3,8 +3,11 @@ package db
 import (
 	"context"
 	"database/sql"
+	"unicode/utf8"
 
 	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/changes"
+	changesdb "github.com/Iiqbal2000/bareknews/changes/db"
 	"github.com/Iiqbal2000/bareknews/tags"
 	"github.com/google/uuid"
 	"github.com/huandu/go-sqlbuilder"
@@ -28,15 +31,37 @@ func (t Store) Save(ctx context.Context, tag tags.Tags) error {
 	ctx, span := tracer.Start(ctx, "tags.db.Save")
 	defer span.End()
 
+	err := t.checkAlias(ctx, tag)
+	if err != nil {
+		return err
+	}
+
 	builder := sqlbuilder.InsertInto("tags").
-	Cols("id", "name", "slug").
-	Values(tag.Label.ID, tag.Label.Name, tag.Slug)
+	Cols(tagColumns("")...).
+	Values(
+		tag.Label.ID,
+		tag.Label.Name,
+		tag.Slug,
+		nullID(tag.ParentID),
+		tag.Meta.Description,
+		tag.Meta.Color,
+		tag.Meta.ImageURL,
+		tag.Meta.MetaTitle,
+		tag.Meta.MetaDescription,
+	)
 
 	span.SetAttributes(attribute.String("sql query", builder.String()))
 
 	query, args := builder.Build()
 
-	_, err := t.conn.ExecContext(ctx, query, args...)
+	tx, err := t.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		if possibleErr, ok := err.(sqlite3.Error); ok {
 			if possibleErr.ExtendedCode == sqlite3.ErrConstraintUnique {
@@ -47,46 +72,92 @@ func (t Store) Save(ctx context.Context, tag tags.Tags) error {
 		return errors.Wrap(err, "when executing the query")
 	}
 
-	return nil
+	err = reindex(ctx, tx, tag.Label.ID)
+	if err != nil {
+		return err
+	}
+
+	err = changesdb.Record(ctx, tx, changes.KindTag, false, tag.Label.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (t Store) Update(ctx context.Context, tag tags.Tags) error {
 	ctx, span := tracer.Start(ctx, "tags.db.Update")
 	defer span.End()
 
+	err := t.checkAlias(ctx, tag)
+	if err != nil {
+		return err
+	}
+
 	builder := sqlbuilder.NewUpdateBuilder()
 	builder.Update("tags")
 	builder.Set(
 		builder.Assign("name", tag.Label.Name),
 		builder.Assign("slug", tag.Slug),
+		builder.Assign("parentID", nullID(tag.ParentID)),
+		builder.Assign("description", tag.Meta.Description),
+		builder.Assign("color", tag.Meta.Color),
+		builder.Assign("image_url", tag.Meta.ImageURL),
+		builder.Assign("meta_title", tag.Meta.MetaTitle),
+		builder.Assign("meta_description", tag.Meta.MetaDescription),
 	)
 	builder.Where(builder.Equal("id", tag.Label.ID.String()))
 
 	query, args := builder.Build()
-	_, err := t.conn.ExecContext(ctx, query, args...)
+
+	tx, err := t.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
+		if possibleErr, ok := err.(sqlite3.Error); ok {
+			if possibleErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return bareknews.ErrDataAlreadyExist
+			}
+		}
+
 		return errors.Wrap(err, "when executing the query")
 	}
 
-	return nil
+	err = reindex(ctx, tx, tag.Label.ID)
+	if err != nil {
+		return err
+	}
+
+	err = changesdb.Record(ctx, tx, changes.KindTag, false, tag.Label.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (t Store) Delete(ctx context.Context, id uuid.UUID) error {
 	ctx, span := tracer.Start(ctx, "tags.db.Delete")
 	defer span.End()
 
-	d := sqlbuilder.NewDeleteBuilder()
-	d.DeleteFrom("tags")
-	d.Where(d.Equal("id", id))
+	tx, err := t.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
 
-	query, args := d.Build()
+	defer tx.Rollback()
 
-	_, err := t.conn.ExecContext(ctx, query, args...)
+	err = deleteTags(ctx, tx, []uuid.UUID{id})
 	if err != nil {
-		return errors.Wrap(err, "when executing the query")
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 func (t Store) GetById(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
@@ -94,28 +165,52 @@ func (t Store) GetById(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
 	defer span.End()
 
 	builder := sqlbuilder.NewSelectBuilder()
-	builder.Select("id", "name", "slug")
+	builder.Select(tagColumns("")...)
 	builder.From("tags")
 	builder.Where(builder.Equal("id", id))
 	query, args := builder.Build()
 
 	row := t.conn.QueryRowContext(ctx, query, args...)
 
-	label := bareknews.Label{}
-	var slug bareknews.Slug
-
-	err := row.Scan(&label.ID, &label.Name, &slug)
+	tag, err := scanTag(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return &tags.Tags{}, sql.ErrNoRows
+			return &tags.Tags{}, bareknews.ErrDataNotFound
 		} else {
 			return &tags.Tags{}, errors.Wrap(err, "when scanning the data")
 		}
 	}
 
-	tag := &tags.Tags{
-		Label: label,
-		Slug:  slug,
+	tag.Aliases, err = t.getAliases(ctx, tag.Label.ID)
+	if err != nil {
+		return &tags.Tags{}, err
+	}
+
+	return &tag, nil
+}
+
+func (t Store) GetBySlug(ctx context.Context, slug string) (tags.Tags, error) {
+	ctx, span := tracer.Start(ctx, "tags.db.GetBySlug")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(tagColumns("")...)
+	builder.From("tags")
+	builder.Where(builder.Equal("slug", slug))
+	query, args := builder.Build()
+
+	tag, err := scanTag(t.conn.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tags.Tags{}, bareknews.ErrDataNotFound
+		}
+
+		return tags.Tags{}, errors.Wrap(err, "when scanning the data")
+	}
+
+	tag.Aliases, err = t.getAliases(ctx, tag.Label.ID)
+	if err != nil {
+		return tags.Tags{}, err
 	}
 
 	return tag, nil
@@ -134,7 +229,7 @@ func (t Store) GetByIds(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, erro
 
 	listMark := sqlbuilder.List(idstr)
 
-	builder.Select("id", "name", "slug")
+	builder.Select(tagColumns("")...)
 	builder.From("tags")
 	builder.Where(builder.In("id", listMark))
 	query, args := builder.Build()
@@ -149,17 +244,12 @@ func (t Store) GetByIds(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, erro
 	results := make([]tags.Tags, 0)
 
 	for rows.Next() {
-		label := bareknews.Label{}
-		var slug bareknews.Slug
-		err := rows.Scan(&label.ID, &label.Name, &slug)
+		tag, err := scanTag(rows)
 		if err != nil {
 			return []tags.Tags{}, errors.Wrap(err, "when scanning the data")
 		}
 
-		results = append(results, tags.Tags{
-			Label: label,
-			Slug:  slug,
-		})
+		results = append(results, tag)
 	}
 
 	if rows.Err() != nil {
@@ -173,7 +263,7 @@ func (t Store) GetAll(ctx context.Context) ([]tags.Tags, error) {
 	ctx, span := tracer.Start(ctx, "tags.db.GetAll")
 	defer span.End()
 
-	query, args := sqlbuilder.Select("id", "name", "slug").
+	query, args := sqlbuilder.Select(tagColumns("")...).
 		From("tags").
 		Build()
 
@@ -187,17 +277,12 @@ func (t Store) GetAll(ctx context.Context) ([]tags.Tags, error) {
 	results := make([]tags.Tags, 0)
 
 	for rows.Next() {
-		label := bareknews.Label{}
-		var slug bareknews.Slug
-		err := rows.Scan(&label.ID, &label.Name, &slug)
+		tag, err := scanTag(rows)
 		if err != nil {
 			return []tags.Tags{}, errors.Wrap(err, "when executing the data")
 		}
 
-		results = append(results, tags.Tags{
-			Label: label,
-			Slug:  slug,
-		})
+		results = append(results, tag)
 	}
 
 	if rows.Err() != nil {
@@ -225,7 +310,7 @@ func (t Store) Count(ctx context.Context, id uuid.UUID) (int, error) {
 	}
 
 	if c == 0 {
-		return c, sql.ErrNoRows
+		return c, bareknews.ErrDataNotFound
 	}
 
 	return c, nil
@@ -237,9 +322,12 @@ func (t Store) GetByNames(ctx context.Context, names ...string) ([]tags.Tags, er
 	
 	builder := sqlbuilder.NewSelectBuilder()
 	listMark := sqlbuilder.List(names)
-	builder.Select("id", "name", "slug")
+	builder.Select(tagColumns("")...)
 	builder.From("tags")
-	builder.Where(builder.In("name", listMark))
+	builder.Where(builder.Or(
+		builder.In("name", listMark),
+		builder.In("id", sqlbuilder.Buildf("SELECT tagID FROM tag_aliases WHERE name IN (%s)", listMark)),
+	))
 	query, args := builder.Build()
 
 	rows, err := t.conn.QueryContext(ctx, query, args...)
@@ -252,17 +340,12 @@ func (t Store) GetByNames(ctx context.Context, names ...string) ([]tags.Tags, er
 	results := make([]tags.Tags, 0)
 
 	for rows.Next() {
-		label := bareknews.Label{}
-		var slug bareknews.Slug
-		err := rows.Scan(&label.ID, &label.Name, &slug)
+		tag, err := scanTag(rows)
 		if err != nil {
 			return []tags.Tags{}, errors.Wrap(err, "when scanning the data")
 		}
 
-		results = append(results, tags.Tags{
-			Label: label,
-			Slug:  slug,
-		})
+		results = append(results, tag)
 	}
 
 	return results, nil
@@ -273,29 +356,634 @@ func (t Store) GetByName(ctx context.Context, name string) (tags.Tags, error) {
 	defer span.End()
 	
 	queryBuilder := sqlbuilder.NewSelectBuilder()
-	queryBuilder.Select("id", "name", "slug")
+	queryBuilder.Select(tagColumns("")...)
 	queryBuilder.From("tags")
-	queryBuilder.Where(queryBuilder.Equal("name", name))
+	queryBuilder.Where(queryBuilder.Or(
+		queryBuilder.Equal("name", name),
+		queryBuilder.In("id", sqlbuilder.Buildf("SELECT tagID FROM tag_aliases WHERE name = %s", name)),
+	))
+	// A tag named after the name wins over a tag that has it as an alias.
+	queryBuilder.OrderBy(queryBuilder.Var(sqlbuilder.Buildf("name = %s", name))).Desc()
+	queryBuilder.Limit(1)
 
 	query, args := queryBuilder.Build()
 	row := t.conn.QueryRowContext(ctx, query, args...)
 
-	label := bareknews.Label{}
-	var slug bareknews.Slug
-
-	err := row.Scan(&label.ID, &label.Name, &slug)
+	tag, err := scanTag(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return tags.Tags{}, sql.ErrNoRows
+			return tags.Tags{}, bareknews.ErrDataNotFound
 		} else {
 			return tags.Tags{}, errors.Wrap(err, "when scannig the data")
 		}
 	}
 
-	tag := tags.Tags{
-		Label: label,
-		Slug:  slug,
+	return tag, nil
+}
+
+func (t Store) Merge(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "tags.db.Merge")
+	defer span.End()
+
+	tx, err := t.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
 	}
 
-	return tag, nil
+	defer tx.Rollback()
+
+	ids := append([]uuid.UUID{target}, sources...)
+	idstr := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		idstr = append(idstr, id.String())
+	}
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(builder.As("COUNT(id)", "c"))
+	builder.From("tags")
+	builder.Where(builder.In("id", sqlbuilder.List(idstr)))
+	query, args := builder.Build()
+
+	var c int
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&c)
+	if err != nil {
+		return errors.Wrap(err, "count the tags")
+	}
+
+	if c != len(ids) {
+		return bareknews.ErrDataNotFound
+	}
+
+	sourceList := sqlbuilder.List(idstr[1:])
+
+	// The children of the sources move to the target below, so they are
+	// recorded before.
+	children, err := selectIds(ctx, tx, sqlbuilder.Buildf("SELECT id FROM tags WHERE parentID IN (%s)", sourceList))
+	if err != nil {
+		return errors.Wrap(err, "get the children")
+	}
+
+	err = changesdb.Record(ctx, tx, changes.KindTag, false, append(children, target)...)
+	if err != nil {
+		return err
+	}
+
+	// The news that already have the target tag only lose the source tags,
+	// so no news ends up with the same tag twice.
+	statements := []sqlbuilder.Builder{
+		sqlbuilder.Buildf(
+			"INSERT INTO news_tags (newsID, tagsID) "+
+				"SELECT DISTINCT newsID, %s FROM news_tags WHERE tagsID IN (%s) "+
+				"AND newsID NOT IN (SELECT newsID FROM news_tags WHERE tagsID = %s)",
+			target, sourceList, target,
+		),
+		sqlbuilder.Buildf("UPDATE tag_aliases SET tagID = %s WHERE tagID IN (%s)", target, sourceList),
+		sqlbuilder.Buildf("INSERT INTO tag_aliases (name, tagID) SELECT name, %s FROM tags WHERE id IN (%s)", target, sourceList),
+		sqlbuilder.Buildf("UPDATE tags SET parentID = %s WHERE parentID IN (%s)", target, sourceList),
+	}
+
+	for _, stmt := range statements {
+		query, args := stmt.Build()
+
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return errors.Wrap(err, "merge the tags")
+		}
+	}
+
+	err = deleteTags(ctx, tx, sources)
+	if err != nil {
+		return err
+	}
+
+	err = reindex(ctx, tx, target)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetDescendantIds walks down the hierarchy with a recursive CTE.
+func (t Store) GetDescendantIds(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "tags.db.GetDescendantIds")
+	defer span.End()
+
+	// UNION instead of UNION ALL stops the walk on a cycle.
+	query, args := sqlbuilder.Buildf(
+		"WITH RECURSIVE descendants(id) AS ("+
+			"SELECT id FROM tags WHERE id = %s "+
+			"UNION SELECT tags.id FROM tags JOIN descendants ON tags.parentID = descendants.id"+
+			") SELECT id FROM descendants",
+		id,
+	).Build()
+
+	rows, err := t.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "when executing the query")
+	}
+
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "when scanning the data")
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "when iterating rows")
+	}
+
+	if len(ids) == 0 {
+		return nil, bareknews.ErrDataNotFound
+	}
+
+	return ids, nil
+}
+
+func (t Store) CountNews(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error) {
+	ctx, span := tracer.Start(ctx, "tags.db.CountNews")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("news_tags.tagsID", builder.As("COUNT(DISTINCT news.id)", "c"))
+	builder.From("news_tags")
+	builder.Join("news", "news.id = news_tags.newsID")
+	if status != "" {
+		builder.Where(builder.Equal("news.status", status))
+	}
+	builder.GroupBy("news_tags.tagsID")
+	query, args := builder.Build()
+
+	rows, err := t.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "when executing the query")
+	}
+
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int)
+
+	for rows.Next() {
+		var id uuid.UUID
+		var c int
+		if err := rows.Scan(&id, &c); err != nil {
+			return nil, errors.Wrap(err, "when scanning the data")
+		}
+
+		counts[id] = c
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "when iterating rows")
+	}
+
+	return counts, nil
+}
+
+// Suggest walks the primary key of tag_search with a range instead of a
+// LIKE, so only the matching keys are read.
+func (t Store) Suggest(ctx context.Context, prefix string, limit int) ([]tags.TagCount, error) {
+	ctx, span := tracer.Start(ctx, "tags.db.Suggest")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(append(tagColumns("tags"), builder.As("COUNT(DISTINCT news.id)", "c"))...)
+	builder.From("tags")
+	builder.JoinWithOption(sqlbuilder.LeftJoin, "news_tags", "news_tags.tagsID = tags.id")
+	builder.JoinWithOption(sqlbuilder.LeftJoin, "news",
+		"news.id = news_tags.newsID",
+		builder.Equal("news.status", bareknews.Publish),
+	)
+	builder.Where(builder.In("tags.id", sqlbuilder.Buildf(
+		"SELECT tagID FROM tag_search WHERE key >= %s AND key < %s",
+		prefix, prefixEnd(prefix),
+	)))
+	builder.GroupBy("tags.id")
+	builder.OrderBy("c DESC", "tags.name")
+	builder.Limit(limit)
+	query, args := builder.Build()
+
+	rows, err := t.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "when executing the query")
+	}
+
+	defer rows.Close()
+
+	results := make([]tags.TagCount, 0)
+
+	for rows.Next() {
+		var c int
+		tag, err := scanTag(rows, &c)
+		if err != nil {
+			return nil, errors.Wrap(err, "when scanning the data")
+		}
+
+		results = append(results, tags.TagCount{Tag: tag, Count: c})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "when iterating rows")
+	}
+
+	return results, nil
+}
+
+// GetPage joins the tags with their counts and seeks past the cursor
+// with the keys of the order, so a page costs the same wherever it is.
+func (t Store) GetPage(ctx context.Context, q tags.PageQuery) ([]tags.TagCount, error) {
+	ctx, span := tracer.Start(ctx, "tags.db.GetPage")
+	defer span.End()
+
+	counts := sqlbuilder.NewSelectBuilder()
+	counts.Select(counts.As("news_tags.tagsID", "tagID"), counts.As("COUNT(DISTINCT news.id)", "c"))
+	counts.From("news_tags")
+	counts.Join("news", "news.id = news_tags.newsID")
+	if q.Status != "" {
+		counts.Where(counts.Equal("news.status", q.Status))
+	}
+	counts.GroupBy("news_tags.tagsID")
+
+	const (
+		countCol = "COALESCE(counts.c, 0)"
+		nameCol  = "tags.name COLLATE NOCASE"
+		idCol    = "tags.id"
+	)
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(append(tagColumns("tags"), builder.As(countCol, "c"))...)
+	builder.From("tags")
+	builder.JoinWithOption(sqlbuilder.LeftJoin, builder.BuilderAs(counts, "counts"), "counts.tagID = tags.id")
+
+	if q.Prefix != "" {
+		builder.Where(builder.In("tags.id", sqlbuilder.Buildf(
+			"SELECT tagID FROM tag_search WHERE key >= %s AND key < %s",
+			q.Prefix, prefixEnd(q.Prefix),
+		)))
+	}
+
+	if q.Unused {
+		builder.Where(builder.Equal(countCol, 0))
+	}
+
+	keys := []pageKey{{col: nameCol}, {col: idCol}}
+	if q.After != nil {
+		keys[0].value = q.After.Name
+		keys[1].value = q.After.ID
+	}
+
+	if q.By == tags.PageByCount {
+		keys = append([]pageKey{{col: countCol, desc: q.Desc}}, keys...)
+		if q.After != nil {
+			keys[0].value = q.After.Count
+		}
+	} else {
+		keys[0].desc = q.Desc
+	}
+
+	if q.After != nil {
+		builder.Where(seek(builder, keys))
+	}
+
+	order := make([]string, 0, len(keys))
+	for _, k := range keys {
+		dir := "ASC"
+		if k.desc {
+			dir = "DESC"
+		}
+
+		order = append(order, k.col+" "+dir)
+	}
+
+	builder.OrderBy(order...)
+	builder.Limit(q.Limit)
+	query, args := builder.Build()
+
+	rows, err := t.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "when executing the query")
+	}
+
+	defer rows.Close()
+
+	results := make([]tags.TagCount, 0)
+
+	for rows.Next() {
+		var c int
+		tag, err := scanTag(rows, &c)
+		if err != nil {
+			return nil, errors.Wrap(err, "when scanning the data")
+		}
+
+		results = append(results, tags.TagCount{Tag: tag, Count: c})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "when iterating rows")
+	}
+
+	return results, nil
+}
+
+// pageKey is a column of the order of a page with its value in the
+// cursor.
+type pageKey struct {
+	col   string
+	value interface{}
+	desc  bool
+}
+
+// seek returns the condition of the rows after the cursor: the first key
+// is past its value, or equal and the next keys are past theirs.
+func seek(builder *sqlbuilder.SelectBuilder, keys []pageKey) string {
+	k := keys[0]
+
+	past := builder.GreaterThan(k.col, k.value)
+	if k.desc {
+		past = builder.LessThan(k.col, k.value)
+	}
+
+	if len(keys) == 1 {
+		return past
+	}
+
+	return builder.Or(past, builder.And(builder.Equal(k.col, k.value), seek(builder, keys[1:])))
+}
+
+// prefixEnd returns the smallest string above every string that starts
+// with the prefix, since no valid UTF-8 goes past U+10FFFF.
+func prefixEnd(prefix string) string {
+	return prefix + string(utf8.MaxRune)
+}
+
+// reindex replaces the search keys of the tag with the keys of its name,
+// slug and aliases as they are in the transaction.
+func reindex(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	tag := tags.Tags{}
+
+	query, args := sqlbuilder.Buildf("SELECT id, name, slug FROM tags WHERE id = %s", id).Build()
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&tag.Label.ID, &tag.Label.Name, &tag.Slug)
+	if err != nil {
+		return errors.Wrap(err, "when scanning the data")
+	}
+
+	query, args = sqlbuilder.Buildf("SELECT name FROM tag_aliases WHERE tagID = %s", id).Build()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "when executing the query")
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return errors.Wrap(err, "when scanning the data")
+		}
+
+		tag.Aliases = append(tag.Aliases, alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "when iterating rows")
+	}
+
+	query, args = sqlbuilder.Buildf("DELETE FROM tag_search WHERE tagID = %s", id).Build()
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "delete the search keys")
+	}
+
+	keys := tags.SearchKeys(tag)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ins := sqlbuilder.NewInsertBuilder()
+	ins.InsertInto("tag_search")
+	ins.Cols("key", "tagID")
+
+	for _, key := range keys {
+		ins.Values(key, id)
+	}
+
+	query, args = ins.Build()
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "insert the search keys")
+	}
+
+	return nil
+}
+
+// deleteTags deletes the tags and everything that refers to them.
+func deleteTags(ctx context.Context, tx *sql.Tx, ids []uuid.UUID) error {
+	idstr := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		idstr = append(idstr, id.String())
+	}
+
+	list := sqlbuilder.List(idstr)
+
+	// The news lose the tags and the children are moved up, so they are
+	// changed too.
+	newsIds, err := selectIds(ctx, tx, sqlbuilder.Buildf("SELECT DISTINCT newsID FROM news_tags WHERE tagsID IN (%s)", list))
+	if err != nil {
+		return errors.Wrap(err, "get the news of the tags")
+	}
+
+	children, err := selectIds(ctx, tx, sqlbuilder.Buildf("SELECT id FROM tags WHERE parentID IN (%s)", list))
+	if err != nil {
+		return errors.Wrap(err, "get the children")
+	}
+
+	err = changesdb.Record(ctx, tx, changes.KindNews, false, newsIds...)
+	if err != nil {
+		return err
+	}
+
+	err = changesdb.Record(ctx, tx, changes.KindTag, false, children...)
+	if err != nil {
+		return err
+	}
+
+	err = changesdb.Record(ctx, tx, changes.KindTag, true, ids...)
+	if err != nil {
+		return err
+	}
+
+	// The children of the deleted tags become top-level tags.
+	orphan := sqlbuilder.NewUpdateBuilder()
+	orphan.Update("tags")
+	orphan.Set(orphan.Assign("parentID", nil))
+	orphan.Where(orphan.In("parentID", list))
+
+	query, args := orphan.Build()
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "detach the children")
+	}
+
+	refs := []struct{ table, col string }{
+		{"news_tags", "tagsID"},
+		{"tag_aliases", "tagID"},
+		{"tag_search", "tagID"},
+		{"tags", "id"},
+	}
+
+	for _, ref := range refs {
+		d := sqlbuilder.NewDeleteBuilder()
+		d.DeleteFrom(ref.table)
+		d.Where(d.In(ref.col, list))
+
+		query, args := d.Build()
+
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return errors.Wrapf(err, "delete from %s", ref.table)
+		}
+	}
+
+	return nil
+}
+
+// selectIds returns the IDs that the query selects in the transaction.
+func selectIds(ctx context.Context, tx *sql.Tx, b sqlbuilder.Builder) ([]uuid.UUID, error) {
+	query, args := b.Build()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+
+	for rows.Next() {
+		id := uuid.UUID{}
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "scan an id")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// getAliases returns the aliases of the tag in alphabetical order.
+func (t Store) getAliases(ctx context.Context, id uuid.UUID) ([]string, error) {
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("name")
+	builder.From("tag_aliases")
+	builder.Where(builder.Equal("tagID", id))
+	builder.OrderBy("name")
+	query, args := builder.Build()
+
+	rows, err := t.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "when executing the query")
+	}
+
+	defer rows.Close()
+
+	aliases := make([]string, 0)
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.Wrap(err, "when scanning the data")
+		}
+
+		aliases = append(aliases, name)
+	}
+
+	return aliases, rows.Err()
+}
+
+// checkAlias rejects a tag named after an alias of another tag.
+func (t Store) checkAlias(ctx context.Context, tag tags.Tags) error {
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(builder.As("COUNT(name)", "c"))
+	builder.From("tag_aliases")
+	builder.Where(
+		builder.Equal("name", tag.Label.Name),
+		builder.NotEqual("tagID", tag.Label.ID),
+	)
+	query, args := builder.Build()
+
+	var c int
+	err := t.conn.QueryRowContext(ctx, query, args...).Scan(&c)
+	if err != nil {
+		return errors.Wrap(err, "when scanning the data")
+	}
+
+	if c > 0 {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	return nil
+}
+
+// tagColumns are the columns of a tag in the order of scanTag. They are
+// qualified with the table when it is given.
+func tagColumns(table string) []string {
+	cols := []string{
+		"id", "name", "slug", "parentID",
+		"description", "color", "image_url", "meta_title", "meta_description",
+	}
+
+	if table != "" {
+		for i := range cols {
+			cols[i] = table + "." + cols[i]
+		}
+	}
+
+	return cols
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTag scans the tagColumns of a row, then the extra columns.
+func scanTag(row scanner, extra ...interface{}) (tags.Tags, error) {
+	tag := tags.Tags{}
+	var parent uuid.NullUUID
+
+	dest := []interface{}{
+		&tag.Label.ID,
+		&tag.Label.Name,
+		&tag.Slug,
+		&parent,
+		&tag.Meta.Description,
+		&tag.Meta.Color,
+		&tag.Meta.ImageURL,
+		&tag.Meta.MetaTitle,
+		&tag.Meta.MetaDescription,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+	tag.ParentID = parent.UUID
+
+	return tag, err
+}
+
+// nullID stores a nil ID as NULL.
+func nullID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
 }
@@ -1,15 +1,16 @@
 package tags
 
 import (
-	"database/sql"
 	"encoding/json"
-	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Iiqbal2000/bareknews"
 	"github.com/Iiqbal2000/bareknews/pkg/web"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
 )
@@ -20,7 +21,19 @@ type handler struct {
 }
 
 type InputTag struct {
-	Name string `json:"name" validate:"required"`
+	Name     string     `json:"name" validate:"required"`
+	ParentID *uuid.UUID `json:"parent_id"`
+	// Description is shown on the landing page of the tag.
+	Description string `json:"description" maxLength:"1000"`
+	// Color is a hex color such as "#1e90ff".
+	Color           string `json:"color" example:"#1e90ff"`
+	ImageURL        string `json:"image_url" maxLength:"2048"`
+	MetaTitle       string `json:"meta_title" maxLength:"70"`
+	MetaDescription string `json:"meta_description" maxLength:"160"`
+}
+
+type MergeIn struct {
+	SourceIDs []uuid.UUID `json:"source_ids" validate:"required"`
 }
 
 func CreateHandler(svc Service, log *zap.SugaredLogger) handler {
@@ -47,11 +60,8 @@ func (t handler) Create(ctx context.Context, w http.ResponseWriter, r *http.Requ
 		return bareknews.ErrInvalidJSON
 	}
 
-	tagRes, err := t.service.Create(ctx, payload.Name)
+	tagRes, err := t.service.Create(ctx, payload)
 	if err != nil {
-		if errors.Is(err, bareknews.ErrDataAlreadyExist) {
-			return web.NewRequestError(bareknews.ErrDataAlreadyExist, http.StatusConflict)
-		}
 		return err
 	}
 
@@ -79,14 +89,11 @@ func (t handler) GetById(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	id, err := uuid.Parse(rawId)
 	if err != nil {
-		return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
+		return bareknews.ErrDataNotFound
 	}
 
 	tg, err := t.service.GetById(ctx, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
-		}
 		return err
 	}
 
@@ -116,7 +123,7 @@ func (t handler) Update(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 	id, err := uuid.Parse(rawId)
 	if err != nil {
-		return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
+		return bareknews.ErrDataNotFound
 	}
 
 	payload := InputTag{}
@@ -126,11 +133,8 @@ func (t handler) Update(ctx context.Context, w http.ResponseWriter, r *http.Requ
 		return bareknews.ErrInvalidJSON
 	}
 
-	tg, err := t.service.Update(ctx, id, payload.Name)
+	tg, err := t.service.Update(ctx, id, payload)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
-		}
 		return err
 	}
 
@@ -142,23 +146,192 @@ func (t handler) Update(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	return web.Respond(w, payloadRes, http.StatusOK)
 }
 
+// PatchTags godoc
+// @Summary      Patch a tag
+// @Description  Partially update a tag with a JSON merge patch (RFC 7396) and return it. A null member clears the field and a missing member keeps it.
+// @Tags         tags
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        id   path      string  true  "Tag ID"  Format(uuid)
+// @Param tag body InputTag true "A merge patch of the tag"
+// @Success      200  {object}  web.RespBody{data=tagging.Response} "Response body for a tag"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      415  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /tags/{id} [patch]
+func (t handler) Patch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rawId := chi.URLParam(r, "tagId")
+
+	id, err := uuid.Parse(rawId)
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	patch, err := web.ReadMergePatch(r)
+	if err != nil {
+		return err
+	}
+
+	tg, err := t.service.Patch(ctx, id, patch)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully patching a tag",
+		Data:    tg,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
 // GetAllTags godoc
 // @Summary      Get all tags
-// @Description  Get all tags
+// @Description  Get a page of tags with the number of their news, or every tag nested below its parent
 // @Tags         tags
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  web.RespBody{data=[]tagging.Response} "Array of tag body"
+// @Param   tree      query     bool     false  "nest the tags below their parent, without paging"
+// @Param   q      query     string     false  "the start of the name, slug or alias of the tags"
+// @Param   status      query     string     false  "count the news with the status"	Enums(publish, draft, all) default(publish)
+// @Param   sort      query     string     false  "order of the tags"	Enums(name, count) default(name)
+// @Param   order      query     string     false  "direction of the order, desc by default for count"	Enums(asc, desc) default(asc)
+// @Param   unused      query     bool     false  "only the tags that no news has"
+// @Param   cursor      query     string     false  "next_cursor of the previous page"
+// @Param   limit      query     int     false  "number of tags"	minimum(1) maximum(100) default(20)
+// @Success      200  {object}  web.PageResponse{data=[]TagUsage} "Page of tags"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
 // @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
 // @Router       /tags [get]
 func (t handler) GetAll(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	tgs, err := t.service.GetAll(ctx)
+	q := r.URL.Query()
+
+	tree, err := parseBool(q.Get("tree"), "tree")
 	if err != nil {
 		return err
 	}
 
-	payloadRes := web.GeneralResponse{
+	if tree {
+		tgs, err := t.service.GetTree(ctx)
+		if err != nil {
+			return err
+		}
+
+		payloadRes := web.GeneralResponse{
+			Message: "Successfully getting all tags",
+			Data:    tgs,
+		}
+
+		return web.Respond(w, payloadRes, http.StatusOK)
+	}
+
+	unused, err := parseBool(q.Get("unused"), "unused")
+	if err != nil {
+		return err
+	}
+
+	limit := 0
+
+	if rawLimit := strings.TrimSpace(q.Get("limit")); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the limit"), http.StatusBadRequest)
+		}
+	}
+
+	page, err := t.service.List(ctx, ListQuery{
+		Search: q.Get("q"),
+		Status: strings.TrimSpace(q.Get("status")),
+		Sort:   strings.TrimSpace(q.Get("sort")),
+		Order:  strings.TrimSpace(q.Get("order")),
+		Unused: unused,
+		Cursor: strings.TrimSpace(q.Get("cursor")),
+		Limit:  limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.PageResponse{
 		Message: "Successfully getting all tags",
+		Data:    page.Items,
+		Paging: web.Paging{
+			Limit:      page.Limit,
+			NextCursor: page.NextCursor,
+		},
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// GetTagCloud godoc
+// @Summary      Get the tag cloud
+// @Description  Get the tags of the published news with their weight, from 1 for the least used tags up to the number of buckets
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param   buckets      query     int     false  "number of weights"	minimum(1) maximum(10) default(5)
+// @Success      200  {object}  web.RespBody{data=[]CloudItem} "Array of the tag cloud"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /tags/cloud [get]
+func (t handler) GetCloud(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	buckets := 0
+
+	if rawBuckets := strings.TrimSpace(r.URL.Query().Get("buckets")); rawBuckets != "" {
+		var err error
+
+		buckets, err = strconv.Atoi(rawBuckets)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the buckets"), http.StatusBadRequest)
+		}
+	}
+
+	cloud, err := t.service.GetCloud(ctx, buckets)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting the tag cloud",
+		Data:    cloud,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// SuggestTags godoc
+// @Summary      Suggest tags
+// @Description  Get the tags whose name, slug or alias starts with the prefix, whatever its case and diacritics are. The tags with the most published news come first.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param   prefix       query     string  true   "the start of the tag"
+// @Param   limit        query     int     false  "number of tags"	minimum(1) maximum(50) default(10)
+// @Success      200  {object}  web.RespBody{data=[]TagUsage} "Array of the suggested tags"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /tags/suggest [get]
+func (t handler) Suggest(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	limit := 0
+
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		var err error
+
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the limit"), http.StatusBadRequest)
+		}
+	}
+
+	tgs, err := t.service.Suggest(ctx, r.URL.Query().Get("prefix"), limit)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully suggesting the tags",
 		Data:    tgs,
 	}
 
@@ -181,14 +354,11 @@ func (t handler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 	id, err := uuid.Parse(rawId)
 	if err != nil {
-		return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
+		return bareknews.ErrDataNotFound
 	}
 
 	err = t.service.Delete(ctx, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return web.NewRequestError(bareknews.ErrDataNotFound, http.StatusNotFound)
-		}
 		return err
 	}
 
@@ -199,3 +369,59 @@ func (t handler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Requ
 
 	return web.Respond(w, payloadRes, http.StatusOK)
 }
+
+// MergeTags godoc
+// @Summary      Merge tags
+// @Description  Merge the source tags into a tag. The news of the source tags get the tag and the names of the source tags become its aliases.
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Tag ID"  Format(uuid)
+// @Param merge body MergeIn true "The tags to merge"
+// @Success      200  {object}  web.RespBody{data=tagging.Response} "Response body for the merged tag"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /tags/{id}/merge [post]
+func (t handler) Merge(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rawId := chi.URLParam(r, "tagId")
+
+	id, err := uuid.Parse(rawId)
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	payload := MergeIn{}
+
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		return bareknews.ErrInvalidJSON
+	}
+
+	tg, err := t.service.Merge(ctx, id, payload.SourceIDs)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully merging the tags",
+		Data:    tg,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// parseBool parses an optional boolean query parameter.
+func parseBool(raw, name string) (bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, web.NewRequestError(errors.Errorf("failed to convert the %s", name), http.StatusBadRequest)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,319 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsmemory "github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/Iiqbal2000/bareknews/tags/memory"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestSave(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+
+	tag := tags.Create("tag 1")
+	err := storage.Save(context.TODO(), *tag)
+	is.NoErr(err)
+
+	got, err := storage.GetById(context.TODO(), tag.Label.ID)
+	is.NoErr(err)
+	is.Equal(got.Label.Name, "tag 1")
+	is.Equal(got.Slug, bareknews.Slug("tag-1"))
+
+	err = storage.Save(context.TODO(), *tags.Create("tag 1"))
+	is.Equal(err, bareknews.ErrDataAlreadyExist)
+}
+
+func TestGetAll(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+	tag1 := tags.Create("tag 1")
+	tag2 := tags.Create("tag 2")
+	is.NoErr(storage.Save(context.TODO(), *tag1))
+	is.NoErr(storage.Save(context.TODO(), *tag2))
+
+	got, err := storage.GetAll(context.TODO())
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Label.ID, tag1.Label.ID)
+	is.Equal(got[1].Label.ID, tag2.Label.ID)
+}
+
+func TestUpdate(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+	tag := tags.Create("tag 1")
+	is.NoErr(storage.Save(context.TODO(), *tag))
+	is.NoErr(storage.Save(context.TODO(), *tags.Create("tag 2")))
+
+	tag.ChangeName("tag 16")
+	err := storage.Update(context.TODO(), *tag)
+	is.NoErr(err)
+
+	got, err := storage.GetById(context.TODO(), tag.Label.ID)
+	is.NoErr(err)
+	is.Equal(got.Label.Name, "tag 16")
+	is.Equal(got.Slug.String(), "tag-16")
+
+	tag.ChangeName("tag 2")
+	err = storage.Update(context.TODO(), *tag)
+	is.Equal(err, bareknews.ErrDataAlreadyExist)
+}
+
+func TestDelete(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+
+	tag := tags.Create("tag 1")
+	is.NoErr(storage.Save(context.TODO(), *tag))
+	is.NoErr(storage.Delete(context.TODO(), tag.Label.ID))
+
+	_, err := storage.GetById(context.TODO(), tag.Label.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	c, err := storage.Count(context.TODO(), tag.Label.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+	is.Equal(c, 0)
+}
+
+func TestGetByNames(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+	tag1 := tags.Create("tag 1")
+	tag2 := tags.Create("tag 2")
+	is.NoErr(storage.Save(context.TODO(), *tag1))
+	is.NoErr(storage.Save(context.TODO(), *tag2))
+	is.NoErr(storage.Save(context.TODO(), *tags.Create("tag 3")))
+
+	got, err := storage.GetByNames(context.TODO(), tag1.Label.Name, tag2.Label.Name)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Label.ID, tag1.Label.ID)
+	is.Equal(got[1].Label.ID, tag2.Label.ID)
+
+	one, err := storage.GetByName(context.TODO(), "tag 2")
+	is.NoErr(err)
+	is.Equal(one.Label.ID, tag2.Label.ID)
+
+	_, err = storage.GetByName(context.TODO(), "tag 9")
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
+
+func TestGetByIds(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+	tag1 := tags.Create("tag 1")
+	tag2 := tags.Create("tag 2")
+	is.NoErr(storage.Save(context.TODO(), *tag1))
+	is.NoErr(storage.Save(context.TODO(), *tag2))
+
+	got, err := storage.GetByIds(context.TODO(), []uuid.UUID{tag1.Label.ID, tag2.Label.ID, uuid.New()})
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+}
+
+func TestMerge(t *testing.T) {
+	newsStore := newsmemory.CreateStore()
+	storage := memory.CreateStore().WithNews(newsStore)
+	is := is.New(t)
+
+	target := tags.Create("covid")
+	source := tags.Create("covid-19")
+	is.NoErr(storage.Save(context.TODO(), *target))
+	is.NoErr(storage.Save(context.TODO(), *source))
+
+	nws := news.Create("news 1", "news body", bareknews.Draft, []uuid.UUID{target.Label.ID, source.Label.ID}, 1)
+	is.NoErr(newsStore.Save(context.TODO(), *nws))
+
+	err := storage.Merge(context.TODO(), target.Label.ID, []uuid.UUID{source.Label.ID})
+	is.NoErr(err)
+
+	got, err := newsStore.GetById(context.TODO(), nws.Post.ID)
+	is.NoErr(err)
+	is.Equal(got.TagsID, []uuid.UUID{target.Label.ID})
+
+	tg, err := storage.GetById(context.TODO(), target.Label.ID)
+	is.NoErr(err)
+	is.Equal(tg.Aliases, []string{"covid-19"})
+
+	byAlias, err := storage.GetByName(context.TODO(), "covid-19")
+	is.NoErr(err)
+	is.Equal(byAlias.Label.ID, target.Label.ID)
+
+	byNames, err := storage.GetByNames(context.TODO(), "covid-19")
+	is.NoErr(err)
+	is.Equal(len(byNames), 1)
+
+	err = storage.Save(context.TODO(), *tags.Create("covid-19"))
+	is.Equal(err, bareknews.ErrDataAlreadyExist)
+}
+
+func TestGetDescendantIds(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+
+	sports := tags.Create("sports")
+	football := tags.Create("football")
+	football.ChangeParent(sports.Label.ID)
+	league := tags.Create("premier league")
+	league.ChangeParent(football.Label.ID)
+	for _, tg := range []*tags.Tags{sports, football, league, tags.Create("politics")} {
+		is.NoErr(storage.Save(context.TODO(), *tg))
+	}
+
+	ids, err := storage.GetDescendantIds(context.TODO(), sports.Label.ID)
+	is.NoErr(err)
+	is.Equal(ids, []uuid.UUID{sports.Label.ID, football.Label.ID, league.Label.ID})
+
+	is.NoErr(storage.Delete(context.TODO(), sports.Label.ID))
+
+	got, err := storage.GetById(context.TODO(), football.Label.ID)
+	is.NoErr(err)
+	is.Equal(got.ParentID, uuid.Nil)
+}
+
+func TestCountNews(t *testing.T) {
+	newsStore := newsmemory.CreateStore()
+	storage := memory.CreateStore().WithNews(newsStore)
+	is := is.New(t)
+
+	tag1 := tags.Create("tag 1")
+	is.NoErr(storage.Save(context.TODO(), *tag1))
+
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{tag1.Label.ID}, 1)))
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 2", "news body", bareknews.Draft, []uuid.UUID{tag1.Label.ID}, 2)))
+
+	got, err := storage.CountNews(context.TODO(), bareknews.Publish)
+	is.NoErr(err)
+	is.Equal(got[tag1.Label.ID], 1)
+
+	got, err = storage.CountNews(context.TODO(), "")
+	is.NoErr(err)
+	is.Equal(got[tag1.Label.ID], 2)
+}
+
+func TestSuggest(t *testing.T) {
+	newsStore := newsmemory.CreateStore()
+	storage := memory.CreateStore().WithNews(newsStore)
+	is := is.New(t)
+
+	economy := tags.Create("Économie")
+	ecology := tags.Create("ecology")
+	sports := tags.Create("sports")
+	football := tags.Create("football")
+	is.NoErr(storage.Save(context.TODO(), *economy))
+	is.NoErr(storage.Save(context.TODO(), *ecology))
+	is.NoErr(storage.Save(context.TODO(), *sports))
+	is.NoErr(storage.Save(context.TODO(), *football))
+	is.NoErr(storage.Merge(context.TODO(), sports.Label.ID, []uuid.UUID{football.Label.ID}))
+
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{ecology.Label.ID}, 1)))
+
+	got, err := storage.Suggest(context.TODO(), "eco", 10)
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Tag.Label.ID, ecology.Label.ID)
+	is.Equal(got[0].Count, 1)
+	is.Equal(got[1].Tag.Label.ID, economy.Label.ID)
+
+	got, err = storage.Suggest(context.TODO(), "foot", 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Tag.Label.ID, sports.Label.ID)
+}
+
+func TestGetPage(t *testing.T) {
+	newsStore := newsmemory.CreateStore()
+	storage := memory.CreateStore().WithNews(newsStore)
+	is := is.New(t)
+
+	asia := tags.Create("Asia")
+	covid := tags.Create("covid")
+	economy := tags.Create("economy")
+	ecology := tags.Create("ecology")
+	for _, tg := range []*tags.Tags{asia, covid, economy, ecology} {
+		is.NoErr(storage.Save(context.TODO(), *tg))
+	}
+
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 1", "news body", bareknews.Publish, []uuid.UUID{covid.Label.ID, economy.Label.ID}, 1)))
+	is.NoErr(newsStore.Save(context.TODO(), *news.Create("news 2", "news body", bareknews.Publish, []uuid.UUID{covid.Label.ID}, 2)))
+
+	names := func(got []tags.TagCount) []string {
+		r := make([]string, 0, len(got))
+		for _, tc := range got {
+			r = append(r, tc.Tag.Label.Name)
+		}
+		return r
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByName, Limit: 2})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"Asia", "covid"})
+
+		last := got[1]
+		got, err = storage.GetPage(context.TODO(), tags.PageQuery{
+			By:    tags.PageByName,
+			Limit: 2,
+			After: &tags.Cursor{Name: last.Tag.Label.Name, ID: last.Tag.Label.ID},
+		})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"ecology", "economy"})
+
+		got, err = storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByName, Desc: true, Limit: 10})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"economy", "ecology", "covid", "Asia"})
+	})
+
+	t.Run("by count", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByCount, Desc: true, Limit: 2})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"covid", "economy"})
+		is.Equal(got[0].Count, 2)
+
+		last := got[1]
+		got, err = storage.GetPage(context.TODO(), tags.PageQuery{
+			By:    tags.PageByCount,
+			Desc:  true,
+			Limit: 2,
+			After: &tags.Cursor{Count: last.Count, Name: last.Tag.Label.Name, ID: last.Tag.Label.ID},
+		})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"Asia", "ecology"})
+	})
+
+	t.Run("search and unused", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := storage.GetPage(context.TODO(), tags.PageQuery{By: tags.PageByName, Prefix: "eco", Unused: true, Limit: 10})
+		is.NoErr(err)
+		is.Equal(names(got), []string{"ecology"})
+	})
+}
+
+func TestGetBySlug(t *testing.T) {
+	storage := memory.CreateStore()
+	is := is.New(t)
+
+	tag := tags.Create("world cup")
+	tag.ChangeMeta(tags.Meta{Color: "#1e90ff"})
+	is.NoErr(storage.Save(context.TODO(), *tag))
+
+	got, err := storage.GetBySlug(context.TODO(), "world-cup")
+	is.NoErr(err)
+	is.Equal(got.Label.ID, tag.Label.ID)
+	is.Equal(got.Meta.Color, "#1e90ff")
+
+	_, err = storage.GetBySlug(context.TODO(), "unknown")
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
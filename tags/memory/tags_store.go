@@ -0,0 +1,550 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/changes"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/tags/memory")
+
+// Store keeps the tags and their aliases in memory. It holds no links
+// to the news: it counts and moves the news of the tags through the news
+// store. It is safe for concurrent use.
+type Store struct {
+	mu    *sync.RWMutex
+	items *[]tags.Tags
+	// aliases maps an alias to the ID of its tag.
+	aliases map[string]uuid.UUID
+	news    News
+	changes Changes
+}
+
+// Changes is the log of the changes of the tags. The store records in it
+// while it holds its lock, so a reader never sees a change without its
+// entry.
+type Changes interface {
+	Record(kind string, deleted bool, ids ...uuid.UUID)
+}
+
+// News is the part of the news store that this store needs, because the
+// news aren't kept here.
+type News interface {
+	// RelinkTags moves the news of the source tags to the target tag.
+	RelinkTags(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error
+	// CountByTags counts the news of every tag that have the status. An
+	// empty status counts every news.
+	CountByTags(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error)
+}
+
+// Ensure Store does implement tags.Repository.
+var _ tags.Repository = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:      &sync.RWMutex{},
+		items:   &[]tags.Tags{},
+		aliases: make(map[string]uuid.UUID),
+	}
+}
+
+// WithNews returns a copy of the store that counts and moves the news of
+// the tags with n.
+func (t Store) WithNews(n News) Store {
+	t.news = n
+	return t
+}
+
+// WithChanges returns a copy of the store that records its changes in c.
+func (t Store) WithChanges(c Changes) Store {
+	t.changes = c
+	return t
+}
+
+func (t Store) Save(ctx context.Context, tag tags.Tags) error {
+	_, span := tracer.Start(ctx, "tags.memory.Save")
+	defer span.End()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.indexOf(tag.Label.ID) != -1 || t.isDuplicate(tag) || t.isAlias(tag) {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	*t.items = append(*t.items, tag)
+	t.record(false, tag.Label.ID)
+
+	return nil
+}
+
+func (t Store) Update(ctx context.Context, tag tags.Tags) error {
+	_, span := tracer.Start(ctx, "tags.memory.Update")
+	defer span.End()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := t.indexOf(tag.Label.ID)
+	if i == -1 {
+		return nil
+	}
+
+	if t.isDuplicate(tag) || t.isAlias(tag) {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	(*t.items)[i] = tag
+	t.record(false, tag.Label.ID)
+
+	return nil
+}
+
+func (t Store) Delete(ctx context.Context, id uuid.UUID) error {
+	_, span := tracer.Start(ctx, "tags.memory.Delete")
+	defer span.End()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := t.indexOf(id)
+	if i == -1 {
+		return nil
+	}
+
+	t.remove(id)
+
+	return nil
+}
+
+func (t Store) GetById(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
+	_, span := tracer.Start(ctx, "tags.memory.GetById")
+	defer span.End()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	i := t.indexOf(id)
+	if i == -1 {
+		return &tags.Tags{}, bareknews.ErrDataNotFound
+	}
+
+	tag := (*t.items)[i]
+	tag.Aliases = t.aliasesOf(id)
+	return &tag, nil
+}
+
+func (t Store) GetByIds(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+	_, span := tracer.Start(ctx, "tags.memory.GetByIds")
+	defer span.End()
+
+	wanted := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	return t.filter(func(tag tags.Tags) bool {
+		return wanted[tag.Label.ID]
+	}), nil
+}
+
+func (t Store) GetAll(ctx context.Context) ([]tags.Tags, error) {
+	_, span := tracer.Start(ctx, "tags.memory.GetAll")
+	defer span.End()
+
+	return t.filter(func(tags.Tags) bool { return true }), nil
+}
+
+func (t Store) Count(ctx context.Context, id uuid.UUID) (int, error) {
+	_, span := tracer.Start(ctx, "tags.memory.Count")
+	defer span.End()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.indexOf(id) == -1 {
+		return 0, bareknews.ErrDataNotFound
+	}
+
+	return 1, nil
+}
+
+func (t Store) GetByNames(ctx context.Context, names ...string) ([]tags.Tags, error) {
+	_, span := tracer.Start(ctx, "tags.memory.GetByNames")
+	defer span.End()
+
+	t.mu.RLock()
+	wanted := make(map[string]bool)
+	wantedIds := make(map[uuid.UUID]bool)
+	for _, name := range names {
+		wanted[name] = true
+		if id, ok := t.aliases[name]; ok {
+			wantedIds[id] = true
+		}
+	}
+	t.mu.RUnlock()
+
+	return t.filter(func(tag tags.Tags) bool {
+		return wanted[tag.Label.Name] || wantedIds[tag.Label.ID]
+	}), nil
+}
+
+func (t Store) GetByName(ctx context.Context, name string) (tags.Tags, error) {
+	_, span := tracer.Start(ctx, "tags.memory.GetByName")
+	defer span.End()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, tag := range *t.items {
+		if tag.Label.Name == name {
+			return tag, nil
+		}
+	}
+
+	if id, ok := t.aliases[name]; ok {
+		return (*t.items)[t.indexOf(id)], nil
+	}
+
+	return tags.Tags{}, bareknews.ErrDataNotFound
+}
+
+func (t Store) GetBySlug(ctx context.Context, slug string) (tags.Tags, error) {
+	_, span := tracer.Start(ctx, "tags.memory.GetBySlug")
+	defer span.End()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, tag := range *t.items {
+		if tag.Slug.String() == slug {
+			tag.Aliases = t.aliasesOf(tag.Label.ID)
+			return tag, nil
+		}
+	}
+
+	return tags.Tags{}, bareknews.ErrDataNotFound
+}
+
+func (t Store) Merge(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "tags.memory.Merge")
+	defer span.End()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, id := range append([]uuid.UUID{target}, sources...) {
+		if t.indexOf(id) == -1 {
+			return bareknews.ErrDataNotFound
+		}
+	}
+
+	if t.news != nil {
+		if err := t.news.RelinkTags(ctx, target, sources); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range sources {
+		for alias, tagID := range t.aliases {
+			if tagID == id {
+				t.aliases[alias] = target
+			}
+		}
+
+		for i := range *t.items {
+			if (*t.items)[i].ParentID == id {
+				(*t.items)[i].ParentID = target
+				t.record(false, (*t.items)[i].Label.ID)
+			}
+		}
+
+		t.aliases[(*t.items)[t.indexOf(id)].Label.Name] = target
+		t.remove(id)
+	}
+
+	t.record(false, target)
+
+	return nil
+}
+
+func (t Store) CountNews(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error) {
+	ctx, span := tracer.Start(ctx, "tags.memory.CountNews")
+	defer span.End()
+
+	if t.news == nil {
+		return map[uuid.UUID]int{}, nil
+	}
+
+	return t.news.CountByTags(ctx, status)
+}
+
+func (t Store) GetDescendantIds(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	_, span := tracer.Start(ctx, "tags.memory.GetDescendantIds")
+	defer span.End()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.indexOf(id) == -1 {
+		return nil, bareknews.ErrDataNotFound
+	}
+
+	ids := []uuid.UUID{id}
+	seen := map[uuid.UUID]bool{id: true}
+
+	for i := 0; i < len(ids); i++ {
+		for _, tag := range *t.items {
+			if tag.ParentID == ids[i] && !seen[tag.Label.ID] {
+				seen[tag.Label.ID] = true
+				ids = append(ids, tag.Label.ID)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+func (t Store) Suggest(ctx context.Context, prefix string, limit int) ([]tags.TagCount, error) {
+	ctx, span := tracer.Start(ctx, "tags.memory.Suggest")
+	defer span.End()
+
+	counts, err := t.CountNews(ctx, bareknews.Publish)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	results := make([]tags.TagCount, 0)
+
+	for _, tag := range *t.items {
+		keyed := tag
+		keyed.Aliases = t.aliasesOf(tag.Label.ID)
+
+		if hasPrefix(tags.SearchKeys(keyed), prefix) {
+			results = append(results, tags.TagCount{Tag: tag, Count: counts[tag.Label.ID]})
+		}
+	}
+	t.mu.RUnlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+
+		return results[i].Tag.Label.Name < results[j].Tag.Label.Name
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+func (t Store) GetPage(ctx context.Context, q tags.PageQuery) ([]tags.TagCount, error) {
+	ctx, span := tracer.Start(ctx, "tags.memory.GetPage")
+	defer span.End()
+
+	counts, err := t.CountNews(ctx, q.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	results := make([]tags.TagCount, 0)
+
+	for _, tag := range *t.items {
+		c := counts[tag.Label.ID]
+		if q.Unused && c > 0 {
+			continue
+		}
+
+		if q.Prefix != "" {
+			keyed := tag
+			keyed.Aliases = t.aliasesOf(tag.Label.ID)
+
+			if !hasPrefix(tags.SearchKeys(keyed), q.Prefix) {
+				continue
+			}
+		}
+
+		results = append(results, tags.TagCount{Tag: tag, Count: c})
+	}
+	t.mu.RUnlock()
+
+	// cmp orders a before b with a negative number, like the SQLite store.
+	cmp := func(a, b tags.Cursor) int {
+		if q.By == tags.PageByCount && a.Count != b.Count {
+			return flip(a.Count-b.Count, q.Desc)
+		}
+
+		if an, bn := foldASCII(a.Name), foldASCII(b.Name); an != bn {
+			r := strings.Compare(an, bn)
+			if q.By == tags.PageByName {
+				r = flip(r, q.Desc)
+			}
+
+			return r
+		}
+
+		return strings.Compare(a.ID.String(), b.ID.String())
+	}
+
+	cursorOf := func(tc tags.TagCount) tags.Cursor {
+		return tags.Cursor{Count: tc.Count, Name: tc.Tag.Label.Name, ID: tc.Tag.Label.ID}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return cmp(cursorOf(results[i]), cursorOf(results[j])) < 0
+	})
+
+	if q.After != nil {
+		i := sort.Search(len(results), func(i int) bool {
+			return cmp(cursorOf(results[i]), *q.After) > 0
+		})
+		results = results[i:]
+	}
+
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+
+	return results, nil
+}
+
+// remove deletes the tag and its aliases; its children become top-level
+// tags. The caller must hold the lock.
+func (t Store) remove(id uuid.UUID) {
+	i := t.indexOf(id)
+	*t.items = append((*t.items)[:i], (*t.items)[i+1:]...)
+
+	for i := range *t.items {
+		if (*t.items)[i].ParentID == id {
+			(*t.items)[i].ParentID = uuid.Nil
+			t.record(false, (*t.items)[i].Label.ID)
+		}
+	}
+
+	t.record(true, id)
+
+	for alias, tagID := range t.aliases {
+		if tagID == id {
+			delete(t.aliases, alias)
+		}
+	}
+}
+
+// record records the changes of the tags when the store has a log. The
+// caller must hold the lock.
+func (t Store) record(deleted bool, ids ...uuid.UUID) {
+	if t.changes != nil {
+		t.changes.Record(changes.KindTag, deleted, ids...)
+	}
+}
+
+// aliasesOf returns the aliases of the tag in alphabetical order. The
+// caller must hold the lock.
+func (t Store) aliasesOf(id uuid.UUID) []string {
+	aliases := make([]string, 0)
+
+	for alias, tagID := range t.aliases {
+		if tagID == id {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	sort.Strings(aliases)
+
+	return aliases
+}
+
+// isAlias reports whether the name of tag is an alias of another tag. The
+// caller must hold the lock.
+func (t Store) isAlias(tag tags.Tags) bool {
+	id, ok := t.aliases[tag.Label.Name]
+	return ok && id != tag.Label.ID
+}
+
+// filter returns the tags matching the predicate in insertion order.
+func (t Store) filter(match func(tags.Tags) bool) []tags.Tags {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	results := make([]tags.Tags, 0)
+
+	for _, tag := range *t.items {
+		if match(tag) {
+			results = append(results, tag)
+		}
+	}
+
+	return results
+}
+
+// indexOf returns the position of the tag with the given id or -1. The
+// caller must hold the lock.
+func (t Store) indexOf(id uuid.UUID) int {
+	for i, tag := range *t.items {
+		if tag.Label.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isDuplicate reports whether another tag already uses the name or the
+// slug of tag. The caller must hold the lock.
+func (t Store) isDuplicate(tag tags.Tags) bool {
+	for _, elem := range *t.items {
+		if elem.Label.ID == tag.Label.ID {
+			continue
+		}
+
+		if elem.Label.Name == tag.Label.Name || elem.Slug == tag.Slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasPrefix reports whether one of the keys starts with the prefix.
+func hasPrefix(keys []string, prefix string) bool {
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// foldASCII lowercases the ASCII letters only, like the NOCASE collation
+// of SQLite.
+func foldASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+
+		return r
+	}, s)
+}
+
+// flip reverses the result of a comparison when desc is set.
+func flip(r int, desc bool) int {
+	if desc {
+		return -r
+	}
+
+	return r
+}
@@ -0,0 +1,51 @@
+package tags
+
+import (
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// The maximum lengths of the metadata of a tag, in characters. The meta
+// lengths follow what the search engines show.
+const (
+	MaxDescriptionLength     = 1000
+	MaxMetaTitleLength       = 70
+	MaxMetaDescriptionLength = 160
+	MaxImageURLLength        = 2048
+)
+
+var (
+	colorRx = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	// httpURLRx keeps the schemes such as javascript: and data: out of
+	// the image, which the frontends render.
+	httpURLRx = regexp.MustCompile(`^https?://`)
+)
+
+// Meta describes the landing page of a tag. Every field is optional.
+// The JSON names only name the fields in the validation errors.
+type Meta struct {
+	Description string `json:"description"`
+	// Color is a hex color such as "#1e90ff".
+	Color    string `json:"color"`
+	ImageURL string `json:"image_url"`
+	// MetaTitle and MetaDescription go to the <title> and the meta
+	// description of the landing page.
+	MetaTitle       string `json:"meta_title"`
+	MetaDescription string `json:"meta_description"`
+}
+
+func (m Meta) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Description, validation.RuneLength(0, MaxDescriptionLength)),
+		validation.Field(&m.Color, validation.Match(colorRx).Error("must be a hex color like #1e90ff")),
+		validation.Field(&m.ImageURL,
+			validation.Length(0, MaxImageURLLength),
+			is.RequestURL,
+			validation.Match(httpURLRx).Error("must be an http or https URL"),
+		),
+		validation.Field(&m.MetaTitle, validation.RuneLength(0, MaxMetaTitleLength)),
+		validation.Field(&m.MetaDescription, validation.RuneLength(0, MaxMetaDescriptionLength)),
+	)
+}
@@ -0,0 +1,171 @@
+package tags
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultPageLimit is the number of tags of a page when no limit is
+	// given.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the maximum number of tags of a page.
+	MaxPageLimit = 100
+)
+
+// The directions of a page of tags.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// Cursor is the position of a tag in a page. It is sent to the clients
+// as an opaque string.
+type Cursor struct {
+	By    string    `json:"b"`
+	Count int       `json:"c,omitempty"`
+	Name  string    `json:"n"`
+	ID    uuid.UUID `json:"i"`
+}
+
+// ListQuery is a request for a page of tags. The zero value returns the
+// first page of the tags sorted by name with their published news.
+type ListQuery struct {
+	// Search keeps the tags whose name, slug or alias starts with it,
+	// whatever its case and diacritics are.
+	Search string
+	Status string
+	// Sort is either SortByName or SortByCount.
+	Sort string
+	// Order defaults to OrderAsc by name and to OrderDesc by count, so
+	// the most used tags come first.
+	Order  string
+	Unused bool
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+	Limit  int
+}
+
+// Page is a page of tags. NextCursor is empty on the last page.
+type Page struct {
+	Items      []TagUsage
+	Limit      int
+	NextCursor string
+}
+
+func (q ListQuery) Validate() error {
+	return validation.ValidateStruct(&q,
+		validation.Field(&q.Status, validation.In(StatusAll, bareknews.Publish.String(), bareknews.Draft.String()).
+			Error("status must be one of 'all', 'publish', 'draft'")),
+		validation.Field(&q.Sort, validation.In(SortByName, SortByCount).
+			Error("sort must be one of 'name', 'count'")),
+		validation.Field(&q.Order, validation.In(OrderAsc, OrderDesc).
+			Error("order must be one of 'asc', 'desc'")),
+		validation.Field(&q.Limit, validation.Min(1), validation.Max(MaxPageLimit)),
+	)
+}
+
+// List returns a page of tags with the number of their news. The pages
+// are keyed by the last tag of the previous page, so a tag that is added
+// or removed while browsing doesn't shift the next pages.
+func (s Service) List(ctx context.Context, q ListQuery) (Page, error) {
+	ctx, span := tracer.Start(ctx, "tags.List")
+	defer span.End()
+
+	if q.Status == "" {
+		q.Status = bareknews.Publish.String()
+	}
+
+	if q.Sort == "" {
+		q.Sort = SortByName
+	}
+
+	if q.Order == "" {
+		q.Order = OrderAsc
+		if q.Sort == SortByCount {
+			q.Order = OrderDesc
+		}
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageLimit
+	}
+
+	err := q.Validate()
+	if err != nil {
+		return Page{}, err
+	}
+
+	pq := PageQuery{
+		Prefix: SearchKey(q.Search),
+		Status: bareknews.Status(q.Status),
+		Unused: q.Unused,
+		By:     q.Sort,
+		Desc:   q.Order == OrderDesc,
+		// One more tag tells whether there is a next page.
+		Limit: q.Limit + 1,
+	}
+
+	if q.Status == StatusAll {
+		pq.Status = ""
+	}
+
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor)
+		if err != nil || after.By != q.Sort {
+			return Page{}, validation.Errors{
+				"cursor": validation.NewError("invalid_cursor", "cursor is invalid or belongs to another sort"),
+			}
+		}
+
+		pq.After = &after
+	}
+
+	found, err := s.store.GetPage(ctx, pq)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{Items: make([]TagUsage, 0, len(found)), Limit: q.Limit}
+
+	if len(found) > q.Limit {
+		found = found[:q.Limit]
+		last := found[len(found)-1]
+
+		page.NextCursor = encodeCursor(Cursor{
+			By:    q.Sort,
+			Count: last.Count,
+			Name:  last.Tag.Label.Name,
+			ID:    last.Tag.Label.ID,
+		})
+	}
+
+	for _, f := range found {
+		page.Items = append(page.Items, TagUsage{TagsOut: createTagsOut(f.Tag), Count: f.Count})
+	}
+
+	return page, nil
+}
+
+func encodeCursor(c Cursor) string {
+	// A struct of strings, an int and a UUID always marshals.
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	c := Cursor{}
+	err = json.Unmarshal(raw, &c)
+
+	return c, err
+}
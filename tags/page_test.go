@@ -0,0 +1,99 @@
+package tags_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/tags"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/matryer/is"
+)
+
+func TestList(t *testing.T) {
+	all := make([]tags.TagCount, 0)
+	for _, name := range []string{"Asia", "covid", "economy"} {
+		all = append(all, tags.TagCount{Tag: *tags.Create(name)})
+	}
+
+	store := &tags.RepositoryMock{
+		GetPageFunc: func(ctx context.Context, q tags.PageQuery) ([]tags.TagCount, error) {
+			start := 0
+			if q.After != nil {
+				for i, tc := range all {
+					if tc.Tag.Label.ID == q.After.ID {
+						start = i + 1
+					}
+				}
+			}
+
+			end := start + q.Limit
+			if end > len(all) {
+				end = len(all)
+			}
+
+			return all[start:end], nil
+		},
+	}
+	svc := tags.CreateSvc(store)
+
+	t.Run("walks the pages", func(t *testing.T) {
+		is := is.New(t)
+
+		page, err := svc.List(context.TODO(), tags.ListQuery{Limit: 2})
+		is.NoErr(err)
+		is.Equal(len(page.Items), 2)
+		is.Equal(page.Items[0].Name, "Asia")
+		is.True(page.NextCursor != "")
+
+		q := store.GetPageCalls()[0].Q
+		is.Equal(q.Limit, 3)
+		is.Equal(q.By, tags.PageByName)
+		is.Equal(q.Status, bareknews.Publish)
+		is.True(!q.Desc)
+
+		page, err = svc.List(context.TODO(), tags.ListQuery{Limit: 2, Cursor: page.NextCursor})
+		is.NoErr(err)
+		is.Equal(len(page.Items), 1)
+		is.Equal(page.Items[0].Name, "economy")
+		is.Equal(page.NextCursor, "")
+	})
+
+	t.Run("sorts by count from the most used", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := svc.List(context.TODO(), tags.ListQuery{Sort: tags.SortByCount, Status: tags.StatusAll, Search: "Éco"})
+		is.NoErr(err)
+
+		calls := store.GetPageCalls()
+		q := calls[len(calls)-1].Q
+		is.Equal(q.By, tags.PageByCount)
+		is.True(q.Desc)
+		is.Equal(q.Status, bareknews.Status(""))
+		is.Equal(q.Prefix, "eco")
+		is.Equal(q.Limit, tags.DefaultPageLimit+1)
+	})
+
+	t.Run("rejects a cursor of another sort", func(t *testing.T) {
+		is := is.New(t)
+
+		page, err := svc.List(context.TODO(), tags.ListQuery{Limit: 1})
+		is.NoErr(err)
+
+		_, err = svc.List(context.TODO(), tags.ListQuery{Sort: tags.SortByCount, Cursor: page.NextCursor})
+		_, ok := err.(validation.Errors)
+		is.True(ok)
+
+		_, err = svc.List(context.TODO(), tags.ListQuery{Cursor: "not a cursor"})
+		_, ok = err.(validation.Errors)
+		is.True(ok)
+	})
+
+	t.Run("rejects a large limit", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := svc.List(context.TODO(), tags.ListQuery{Limit: tags.MaxPageLimit + 1})
+		_, ok := err.(validation.Errors)
+		is.True(ok)
+	})
+}
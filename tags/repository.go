@@ -3,9 +3,42 @@ package tags
 import (
 	"context"
 
+	"github.com/Iiqbal2000/bareknews"
 	"github.com/google/uuid"
 )
 
+// TagCount is a tag with the number of its news.
+type TagCount struct {
+	Tag   Tags
+	Count int
+}
+
+// The keys of a page of tags.
+const (
+	PageByName  = "name"
+	PageByCount = "count"
+)
+
+// PageQuery selects a page of tags with the number of their news.
+type PageQuery struct {
+	// Prefix keeps the tags that have a search key starting with it.
+	Prefix string
+	// Status counts the news that have it; an empty status counts every
+	// news.
+	Status bareknews.Status
+	// Unused keeps only the tags that no news has.
+	Unused bool
+	// By is the key of the order, either PageByName or PageByCount. The
+	// order is ascending unless Desc is set. The ties are broken by name,
+	// then by ID, in ascending order.
+	By   string
+	Desc bool
+	// After is the last tag of the previous page, or nil for the first
+	// page.
+	After *Cursor
+	Limit int
+}
+
 //go:generate moq -out tagRepo_moq.go . Repository
 type Repository interface {
 	Save(context.Context, Tags) error
@@ -16,5 +49,21 @@ type Repository interface {
 	Count(context.Context, uuid.UUID) (int, error)
 	GetByNames(context.Context, ...string) ([]Tags, error)
 	GetByName(ctx context.Context, name string) (Tags, error)
+	GetBySlug(ctx context.Context, slug string) (Tags, error)
 	GetByIds(context.Context, []uuid.UUID) ([]Tags, error)
+	// Merge moves the news of the source tags to the target tag, deletes the
+	// source tags and keeps their names as aliases of the target tag.
+	Merge(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error
+	// GetDescendantIds returns the ID of the tag and of every tag below it.
+	GetDescendantIds(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	// CountNews counts the news of every tag that have the status with one
+	// aggregate query. An empty status counts every news. Unused tags are
+	// left out.
+	CountNews(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error)
+	// Suggest returns at most limit tags that have a search key starting
+	// with the prefix, which is already a search key. The tags with the
+	// most published news come first, then by name.
+	Suggest(ctx context.Context, prefix string, limit int) ([]TagCount, error)
+	// GetPage returns a page of tags with their number of news.
+	GetPage(ctx context.Context, q PageQuery) ([]TagCount, error)
 }
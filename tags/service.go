@@ -2,36 +2,111 @@ package tags
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
 	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/mergepatch"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
 )
 
 var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/tags")
 
 type TagsOut struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
-	Slug string    `json:"slug"`
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Slug    string    `json:"slug"`
+	Aliases []string  `json:"aliases,omitempty"`
+	// ParentID is null for a top-level tag.
+	ParentID        *uuid.UUID `json:"parent_id"`
+	Description     string     `json:"description,omitempty"`
+	Color           string     `json:"color,omitempty"`
+	ImageURL        string     `json:"image_url,omitempty"`
+	MetaTitle       string     `json:"meta_title,omitempty"`
+	MetaDescription string     `json:"meta_description,omitempty"`
+}
+
+// TagNode is a tag with the tags below it.
+type TagNode struct {
+	TagsOut
+	Children []TagNode `json:"children"`
+}
+
+// maxDepth is the maximum depth of the tag hierarchy.
+const maxDepth = 32
+
+func createTagsOut(t Tags) TagsOut {
+	out := TagsOut{
+		ID:      t.Label.ID,
+		Name:    t.Label.Name,
+		Slug:    t.Slug.String(),
+		Aliases: t.Aliases,
+
+		Description:     t.Meta.Description,
+		Color:           t.Meta.Color,
+		ImageURL:        t.Meta.ImageURL,
+		MetaTitle:       t.Meta.MetaTitle,
+		MetaDescription: t.Meta.MetaDescription,
+	}
+
+	if t.ParentID != uuid.Nil {
+		parentID := t.ParentID
+		out.ParentID = &parentID
+	}
+
+	return out
+}
+
+func parentOf(input InputTag) uuid.UUID {
+	if input.ParentID == nil {
+		return uuid.Nil
+	}
+
+	return *input.ParentID
+}
+
+func metaOf(input InputTag) Meta {
+	return Meta{
+		Description:     strings.TrimSpace(input.Description),
+		Color:           strings.TrimSpace(input.Color),
+		ImageURL:        strings.TrimSpace(input.ImageURL),
+		MetaTitle:       strings.TrimSpace(input.MetaTitle),
+		MetaDescription: strings.TrimSpace(input.MetaDescription),
+	}
 }
 
 type Service struct {
-	store Repository
+	store  Repository
+	limits bareknews.Limits
 }
 
 func CreateSvc(repo Repository) Service {
-	return Service{repo}
+	return Service{store: repo, limits: bareknews.DefaultLimits}
+}
+
+// WithLimits returns a copy of the service that validates the tags with l.
+func (s Service) WithLimits(l bareknews.Limits) Service {
+	s.limits = l
+	return s
 }
 
-func (s Service) Create(ctx context.Context, tagName string) (TagsOut, error) {
+func (s Service) Create(ctx context.Context, input InputTag) (TagsOut, error) {
 	ctx, span := tracer.Start(ctx, "tags.Create")
 	defer span.End()
 
-	tag := Create(strings.TrimSpace(tagName))
+	tag := Create(strings.TrimSpace(input.Name))
+	tag.ChangeParent(parentOf(input))
+	tag.ChangeMeta(metaOf(input))
+
+	err := tag.ValidateWith(s.limits)
+	if err != nil {
+		return TagsOut{}, err
+	}
 
-	err := tag.Validate()
+	err = s.checkParent(ctx, *tag)
 	if err != nil {
 		return TagsOut{}, err
 	}
@@ -41,14 +116,13 @@ func (s Service) Create(ctx context.Context, tagName string) (TagsOut, error) {
 		return TagsOut{}, err
 	}
 
-	return TagsOut{
-		ID:   tag.Label.ID,
-		Name: tag.Label.Name,
-		Slug: tag.Slug.String(),
-	}, nil
+	return createTagsOut(*tag), nil
 }
 
-func (s Service) Update(ctx context.Context, id uuid.UUID, newTagname string) (TagsOut, error) {
+// Update replaces the name, the parent and the metadata of a tag. A tag
+// without a parent becomes a top-level tag; a missing metadata field is
+// cleared.
+func (s Service) Update(ctx context.Context, id uuid.UUID, input InputTag) (TagsOut, error) {
 	ctx, span := tracer.Start(ctx, "tags.Update")
 	defer span.End()
 
@@ -57,9 +131,63 @@ func (s Service) Update(ctx context.Context, id uuid.UUID, newTagname string) (T
 		return TagsOut{}, err
 	}
 
-	tag.ChangeName(strings.TrimSpace(newTagname))
+	return s.replace(ctx, tag, input)
+}
+
+// Patch applies a JSON merge patch (RFC 7396) to a tag. A null member
+// clears the field and a missing member keeps it.
+func (s Service) Patch(ctx context.Context, id uuid.UUID, patch []byte) (TagsOut, error) {
+	ctx, span := tracer.Start(ctx, "tags.Patch")
+	defer span.End()
 
-	err = tag.Validate()
+	tag, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return TagsOut{}, err
+	}
+
+	current := InputTag{
+		Name:            tag.Label.Name,
+		Description:     tag.Meta.Description,
+		Color:           tag.Meta.Color,
+		ImageURL:        tag.Meta.ImageURL,
+		MetaTitle:       tag.Meta.MetaTitle,
+		MetaDescription: tag.Meta.MetaDescription,
+	}
+	if tag.ParentID != uuid.Nil {
+		current.ParentID = &tag.ParentID
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return TagsOut{}, errors.Wrap(err, "marshal the tag")
+	}
+
+	doc, err = mergepatch.Apply(doc, patch)
+	if err != nil {
+		return TagsOut{}, bareknews.ErrInvalidJSON
+	}
+
+	input := InputTag{}
+
+	err = json.Unmarshal(doc, &input)
+	if err != nil {
+		return TagsOut{}, bareknews.ErrInvalidJSON
+	}
+
+	return s.replace(ctx, tag, input)
+}
+
+func (s Service) replace(ctx context.Context, tag *Tags, input InputTag) (TagsOut, error) {
+	tag.ChangeName(strings.TrimSpace(input.Name))
+	tag.ChangeParent(parentOf(input))
+	tag.ChangeMeta(metaOf(input))
+
+	err := tag.ValidateWith(s.limits)
+	if err != nil {
+		return TagsOut{}, err
+	}
+
+	err = s.checkParent(ctx, *tag)
 	if err != nil {
 		return TagsOut{}, err
 	}
@@ -69,11 +197,33 @@ func (s Service) Update(ctx context.Context, id uuid.UUID, newTagname string) (T
 		return TagsOut{}, err
 	}
 
-	return TagsOut{
-		ID:   tag.Label.ID,
-		Name: tag.Label.Name,
-		Slug: tag.Slug.String(),
-	}, nil
+	return createTagsOut(*tag), nil
+}
+
+// checkParent makes sure the parent of the tag exists and the tag isn't
+// one of its own ancestors.
+func (s Service) checkParent(ctx context.Context, tag Tags) error {
+	for id, depth := tag.ParentID, 0; id != uuid.Nil; depth++ {
+		if id == tag.Label.ID || depth == maxDepth {
+			return validation.Errors{
+				"parent_id": validation.NewError("tag_cycle", "the tag cannot be a descendant of itself"),
+			}
+		}
+
+		parent, err := s.store.GetById(ctx, id)
+		if errors.Is(err, bareknews.ErrDataNotFound) {
+			return validation.Errors{
+				"parent_id": validation.NewError("parent_not_found", "the parent tag is not found"),
+			}
+		}
+		if err != nil {
+			return errors.Wrap(err, "get the parent tag")
+		}
+
+		id = parent.ParentID
+	}
+
+	return nil
 }
 
 func (s Service) Delete(ctx context.Context, id uuid.UUID) error {
@@ -102,11 +252,7 @@ func (s Service) GetById(ctx context.Context, id uuid.UUID) (TagsOut, error) {
 		return TagsOut{}, err
 	}
 
-	return TagsOut{
-		ID:   tg.Label.ID,
-		Name: tg.Label.Name,
-		Slug: tg.Slug.String(),
-	}, nil
+	return createTagsOut(*tg), nil
 }
 
 func (s Service) GetByIds(ctx context.Context, ids []uuid.UUID) ([]TagsOut, error) {
@@ -115,17 +261,13 @@ func (s Service) GetByIds(ctx context.Context, ids []uuid.UUID) ([]TagsOut, erro
 
 	tgs, err := s.store.GetByIds(ctx, ids)
 	if err != nil {
-		return []TagsOut{}, bareknews.ErrInternalServer
+		return []TagsOut{}, err
 	}
 
 	r := make([]TagsOut, 0)
 
 	for _, t := range tgs {
-		r = append(r, TagsOut{
-			ID:   t.Label.ID,
-			Name: t.Label.Name,
-			Slug: t.Slug.String(),
-		})
+		r = append(r, createTagsOut(t))
 	}
 
 	return r, nil
@@ -143,50 +285,162 @@ func (s Service) GetAll(ctx context.Context) ([]TagsOut, error) {
 	r := make([]TagsOut, 0)
 
 	for _, t := range tg {
-		r = append(r, TagsOut{
-			ID:   t.Label.ID,
-			Name: t.Label.Name,
-			Slug: t.Slug.String(),
-		})
+		r = append(r, createTagsOut(t))
 	}
 
 	return r, nil
 }
 
-func (s Service) GetByNames(ctx context.Context, names []string) []TagsOut {
+func (s Service) GetByNames(ctx context.Context, names []string) ([]TagsOut, error) {
 	ctx, span := tracer.Start(ctx, "tags.GetByNames")
 	defer span.End()
 
 	tg, err := s.store.GetByNames(ctx, names...)
 	if err != nil {
-		return []TagsOut{}
+		return []TagsOut{}, err
 	}
 
 	r := make([]TagsOut, 0)
 
 	for _, t := range tg {
-		r = append(r, TagsOut{
-			ID:   t.Label.ID,
-			Name: t.Label.Name,
-			Slug: t.Slug.String(),
-		})
+		r = append(r, createTagsOut(t))
 	}
 
-	return r
+	return r, nil
 }
 
-func (s Service) GetByName(ctx context.Context, name string) TagsOut {
+func (s Service) GetByName(ctx context.Context, name string) (TagsOut, error) {
 	ctx, span := tracer.Start(ctx, "tags.GetByName")
 	defer span.End()
 
 	tg, err := s.store.GetByName(ctx, name)
 	if err != nil {
-		return TagsOut{}
+		return TagsOut{}, err
+	}
+
+	return createTagsOut(tg), nil
+}
+
+// GetBySlug returns the tag with its aliases and metadata.
+func (s Service) GetBySlug(ctx context.Context, slug string) (TagsOut, error) {
+	ctx, span := tracer.Start(ctx, "tags.GetBySlug")
+	defer span.End()
+
+	tg, err := s.store.GetBySlug(ctx, slug)
+	if err != nil {
+		return TagsOut{}, err
+	}
+
+	return createTagsOut(tg), nil
+}
+
+// Merge merges the source tags into the target tag. The news of the source
+// tags get the target tag and the source names become its aliases.
+func (s Service) Merge(ctx context.Context, target uuid.UUID, sources []uuid.UUID) (TagsOut, error) {
+	ctx, span := tracer.Start(ctx, "tags.Merge")
+	defer span.End()
+
+	unique := make([]uuid.UUID, 0, len(sources))
+	seen := make(map[uuid.UUID]bool)
+
+	for _, id := range sources {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	err := validation.Errors{
+		"source_ids": validation.Validate(unique,
+			validation.Required,
+			validation.By(func(interface{}) error {
+				if seen[target] {
+					return validation.NewError("merge_into_itself", "a tag cannot be merged into itself")
+				}
+				return nil
+			}),
+		),
+	}.Filter()
+	if err != nil {
+		return TagsOut{}, err
+	}
+
+	// Merging a tag into one of its descendants would make the descendant
+	// its own ancestor.
+	for id, depth := target, 0; id != uuid.Nil && depth < maxDepth; depth++ {
+		tg, err := s.store.GetById(ctx, id)
+		if err != nil {
+			return TagsOut{}, err
+		}
+
+		if id != target && seen[id] {
+			return TagsOut{}, validation.Errors{
+				"source_ids": validation.NewError("merge_into_descendant", "a tag cannot be merged into its descendant"),
+			}
+		}
+
+		id = tg.ParentID
+	}
+
+	err = s.store.Merge(ctx, target, unique)
+	if err != nil {
+		return TagsOut{}, errors.Wrap(err, "merge the tags")
+	}
+
+	return s.GetById(ctx, target)
+}
+
+// GetTree returns the top-level tags with the tags below them.
+func (s Service) GetTree(ctx context.Context) ([]TagNode, error) {
+	ctx, span := tracer.Start(ctx, "tags.GetTree")
+	defer span.End()
+
+	tgs, err := s.store.GetAll(ctx)
+	if err != nil {
+		return []TagNode{}, err
+	}
+
+	exists := make(map[uuid.UUID]bool)
+	children := make(map[uuid.UUID][]Tags)
+
+	for _, t := range tgs {
+		exists[t.Label.ID] = true
 	}
 
-	return TagsOut{
-		ID:   tg.Label.ID,
-		Name: tg.Label.Name,
-		Slug: tg.Slug.String(),
+	for _, t := range tgs {
+		parent := t.ParentID
+		if !exists[parent] {
+			parent = uuid.Nil
+		}
+
+		children[parent] = append(children[parent], t)
 	}
+
+	var build func(parent uuid.UUID, depth int) []TagNode
+	build = func(parent uuid.UUID, depth int) []TagNode {
+		nodes := make([]TagNode, 0)
+
+		if depth > maxDepth {
+			return nodes
+		}
+
+		for _, t := range children[parent] {
+			nodes = append(nodes, TagNode{
+				TagsOut:  createTagsOut(t),
+				Children: build(t.Label.ID, depth+1),
+			})
+		}
+
+		return nodes
+	}
+
+	return build(uuid.Nil, 0), nil
+}
+
+// GetDescendantIds returns the ID of the tag and of every tag below it.
+func (s Service) GetDescendantIds(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "tags.GetDescendantIds")
+	defer span.End()
+
+	return s.store.GetDescendantIds(ctx, id)
 }
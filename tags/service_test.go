@@ -2,7 +2,7 @@ package tags_test
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/Iiqbal2000/bareknews"
@@ -23,7 +23,7 @@ func TestCreate(t *testing.T) {
 		}
 
 		svc := tags.CreateSvc(store)
-		_, err := svc.Create(context.TODO(), "tag 1")
+		_, err := svc.Create(context.TODO(), tags.InputTag{Name: "tag 1"})
 
 		is := is.New(t)
 		is.Equal(err, nil)
@@ -41,7 +41,7 @@ func TestCreate(t *testing.T) {
 		}
 
 		svc := tags.CreateSvc(store)
-		_, err := svc.Create(context.TODO(), "")
+		_, err := svc.Create(context.TODO(), tags.InputTag{Name: ""})
 
 		is := is.New(t)
 		is.True(err != nil)
@@ -59,7 +59,27 @@ func TestCreate(t *testing.T) {
 		}
 
 		svc := tags.CreateSvc(store)
-		_, err := svc.Create(context.TODO(), "Lorem Ipsum is simply dummy text of the printing and typesetting industry.")
+		_, err := svc.Create(context.TODO(), tags.InputTag{Name: "Lorem Ipsum is simply dummy text of the printing and typesetting industry."})
+		is := is.New(t)
+		is.True(err != nil)
+		is.Equal(len(store.SaveCalls()), 0)
+	})
+
+	t.Run("invalid payload: tag name is longer than the limits of the service", func(t *testing.T) {
+		store := &tags.RepositoryMock{
+			SaveFunc: func(ctx context.Context, tags tags.Tags) error {
+				return nil
+			},
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
+				return &tags.Tags{}, nil
+			},
+		}
+
+		limits := bareknews.DefaultLimits
+		limits.LabelMax = 5
+
+		svc := tags.CreateSvc(store).WithLimits(limits)
+		_, err := svc.Create(context.TODO(), tags.InputTag{Name: "Lorem Ipsum"})
 		is := is.New(t)
 		is.True(err != nil)
 		is.Equal(len(store.SaveCalls()), 0)
@@ -76,7 +96,7 @@ func TestCreate(t *testing.T) {
 		}
 
 		svc := tags.CreateSvc(store)
-		_, err := svc.Create(context.TODO(), "Lorem Ipsum")
+		_, err := svc.Create(context.TODO(), tags.InputTag{Name: "Lorem Ipsum"})
 		is := is.New(t)
 		is.True(err != nil)
 		is.Equal(err, bareknews.ErrDataAlreadyExist)
@@ -99,7 +119,7 @@ func TestUpdate(t *testing.T) {
 
 		svc := tags.CreateSvc(store)
 		name := "tag 2"
-		got, err := svc.Update(context.TODO(), tg.Label.ID, name)
+		got, err := svc.Update(context.TODO(), tg.Label.ID, tags.InputTag{Name: name})
 		is := is.New(t)
 		is.Equal(err, nil)
 		is.Equal(len(store.UpdateCalls()), 1)
@@ -109,7 +129,49 @@ func TestUpdate(t *testing.T) {
 	t.Run("invalid payload: the tags is not found", func(t *testing.T) {
 		store := &tags.RepositoryMock{
 			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
-				return nil, sql.ErrNoRows
+				return nil, bareknews.ErrDataNotFound
+			},
+			UpdateFunc: func(ctx context.Context, tagsIn tags.Tags) error {
+				return nil
+			},
+		}
+
+		svc := tags.CreateSvc(store)
+		_, err := svc.Update(context.TODO(), uuid.New(), tags.InputTag{Name: "tag 2"})
+		is := is.New(t)
+		is.Equal(err, bareknews.ErrDataNotFound)
+		is.Equal(len(store.UpdateCalls()), 0)
+	})
+}
+
+func TestPatch(t *testing.T) {
+	t.Run("valid patch should be success", func(t *testing.T) {
+		tg := tags.Create("tag 1")
+
+		store := &tags.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
+				return tg, nil
+			},
+			UpdateFunc: func(ctx context.Context, tagsIn tags.Tags) error {
+				return nil
+			},
+		}
+
+		svc := tags.CreateSvc(store)
+		got, err := svc.Patch(context.TODO(), tg.Label.ID, []byte(`{"name": "tag 2"}`))
+		is := is.New(t)
+		is.NoErr(err)
+		is.Equal(got.Name, "tag 2")
+		is.Equal(got.Slug, "tag-2")
+		is.Equal(len(store.UpdateCalls()), 1)
+	})
+
+	t.Run("invalid patch: the name is cleared", func(t *testing.T) {
+		tg := tags.Create("tag 1")
+
+		store := &tags.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
+				return tg, nil
 			},
 			UpdateFunc: func(ctx context.Context, tagsIn tags.Tags) error {
 				return nil
@@ -117,9 +179,9 @@ func TestUpdate(t *testing.T) {
 		}
 
 		svc := tags.CreateSvc(store)
-		_, err := svc.Update(context.TODO(), uuid.New(), "tag 2")
+		_, err := svc.Patch(context.TODO(), tg.Label.ID, []byte(`{"name": null}`))
 		is := is.New(t)
-		is.Equal(err, sql.ErrNoRows)
+		is.True(err != nil)
 		is.Equal(len(store.UpdateCalls()), 0)
 	})
 }
@@ -146,7 +208,7 @@ func TestDelete(t *testing.T) {
 	t.Run("invalid payload: the tags is not found", func(t *testing.T) {
 		store := &tags.RepositoryMock{
 			CountFunc: func(ctx context.Context, id uuid.UUID) (int, error) {
-				return 0, sql.ErrNoRows
+				return 0, bareknews.ErrDataNotFound
 			},
 			DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
 				return nil
@@ -161,3 +223,182 @@ func TestDelete(t *testing.T) {
 		is.Equal(len(store.DeleteCalls()), 0)
 	})
 }
+
+func TestGetByName(t *testing.T) {
+	t.Run("valid payload should be success", func(t *testing.T) {
+		tg := tags.Create("tag 1")
+
+		store := &tags.RepositoryMock{
+			GetByNameFunc: func(ctx context.Context, name string) (tags.Tags, error) {
+				return *tg, nil
+			},
+		}
+
+		svc := tags.CreateSvc(store)
+		is := is.New(t)
+
+		got, err := svc.GetByName(context.TODO(), "tag 1")
+		is.NoErr(err)
+		is.Equal(got.ID, tg.Label.ID)
+	})
+
+	t.Run("invalid payload: the tags is not found", func(t *testing.T) {
+		store := &tags.RepositoryMock{
+			GetByNameFunc: func(ctx context.Context, name string) (tags.Tags, error) {
+				return tags.Tags{}, bareknews.ErrDataNotFound
+			},
+		}
+
+		svc := tags.CreateSvc(store)
+		is := is.New(t)
+
+		_, err := svc.GetByName(context.TODO(), "tag 1")
+		is.True(errors.Is(err, bareknews.ErrDataNotFound))
+	})
+}
+
+func TestGetByIds(t *testing.T) {
+	store := &tags.RepositoryMock{
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return nil, bareknews.ErrDataNotFound
+		},
+	}
+
+	svc := tags.CreateSvc(store)
+	is := is.New(t)
+
+	_, err := svc.GetByIds(context.TODO(), []uuid.UUID{uuid.New()})
+	is.True(errors.Is(err, bareknews.ErrDataNotFound))
+}
+
+func TestMerge(t *testing.T) {
+	target := tags.Create("covid")
+	source := uuid.New()
+
+	newStore := func() *tags.RepositoryMock {
+		return &tags.RepositoryMock{
+			MergeFunc: func(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error {
+				return nil
+			},
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
+				tg := *target
+				tg.Aliases = []string{"covid-19"}
+				return &tg, nil
+			},
+		}
+	}
+
+	t.Run("valid payload should be success", func(t *testing.T) {
+		is := is.New(t)
+		store := newStore()
+
+		got, err := tags.CreateSvc(store).Merge(context.TODO(), target.Label.ID, []uuid.UUID{source, source})
+		is.NoErr(err)
+		is.Equal(got.Aliases, []string{"covid-19"})
+		is.Equal(store.MergeCalls()[0].Sources, []uuid.UUID{source})
+	})
+
+	t.Run("invalid payload should be failed", func(t *testing.T) {
+		payloadTest := [][]uuid.UUID{
+			nil,
+			{source, target.Label.ID},
+		}
+
+		for _, pt := range payloadTest {
+			is := is.New(t)
+			store := newStore()
+
+			_, err := tags.CreateSvc(store).Merge(context.TODO(), target.Label.ID, pt)
+			is.True(err != nil)
+			is.Equal(len(store.MergeCalls()), 0)
+		}
+	})
+}
+
+func TestUpdateParent(t *testing.T) {
+	sports := tags.Create("sports")
+	football := tags.Create("football")
+	football.ChangeParent(sports.Label.ID)
+	league := tags.Create("premier league")
+	league.ChangeParent(football.Label.ID)
+
+	newStore := func() *tags.RepositoryMock {
+		stored := map[uuid.UUID]tags.Tags{}
+		for _, tg := range []*tags.Tags{sports, football, league} {
+			stored[tg.Label.ID] = *tg
+		}
+
+		return &tags.RepositoryMock{
+			GetByIdFunc: func(ctx context.Context, id uuid.UUID) (*tags.Tags, error) {
+				tg, ok := stored[id]
+				if !ok {
+					return &tags.Tags{}, bareknews.ErrDataNotFound
+				}
+				return &tg, nil
+			},
+			UpdateFunc: func(ctx context.Context, tg tags.Tags) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("valid parent should be success", func(t *testing.T) {
+		is := is.New(t)
+		store := newStore()
+
+		got, err := tags.CreateSvc(store).Update(context.TODO(), league.Label.ID, tags.InputTag{
+			Name:     "premier league",
+			ParentID: &sports.Label.ID,
+		})
+		is.NoErr(err)
+		is.Equal(*got.ParentID, sports.Label.ID)
+	})
+
+	t.Run("invalid parent should be failed", func(t *testing.T) {
+		unknown := uuid.New()
+		payloadTest := []*uuid.UUID{
+			&sports.Label.ID,
+			&league.Label.ID,
+			&unknown,
+		}
+
+		for _, pt := range payloadTest {
+			is := is.New(t)
+			store := newStore()
+
+			_, err := tags.CreateSvc(store).Update(context.TODO(), sports.Label.ID, tags.InputTag{
+				Name:     "sports",
+				ParentID: pt,
+			})
+			is.True(err != nil)
+			is.Equal(len(store.UpdateCalls()), 0)
+		}
+	})
+}
+
+func TestGetTree(t *testing.T) {
+	sports := tags.Create("sports")
+	football := tags.Create("football")
+	football.ChangeParent(sports.Label.ID)
+	league := tags.Create("premier league")
+	league.ChangeParent(football.Label.ID)
+	politics := tags.Create("politics")
+
+	store := &tags.RepositoryMock{
+		GetAllFunc: func(ctx context.Context) ([]tags.Tags, error) {
+			return []tags.Tags{*league, *sports, *politics, *football}, nil
+		},
+	}
+
+	is := is.New(t)
+
+	got, err := tags.CreateSvc(store).GetTree(context.TODO())
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].ID, sports.Label.ID)
+	is.Equal(got[1].ID, politics.Label.ID)
+	is.Equal(len(got[0].Children), 1)
+	is.Equal(got[0].Children[0].ID, football.Label.ID)
+	is.Equal(got[0].Children[0].Children[0].ID, league.Label.ID)
+	is.Equal(len(got[1].Children), 0)
+}
@@ -0,0 +1,88 @@
+package tags
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// DefaultSuggestLimit is the number of suggested tags when no limit
+	// is given.
+	DefaultSuggestLimit = 10
+	// MaxSuggestLimit is the maximum number of suggested tags.
+	MaxSuggestLimit = 50
+)
+
+// SearchKey folds s for a prefix search: the diacritics are removed and
+// the letters are lowercased, so "Économie" and "economie" give the same
+// key.
+func SearchKey(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	r, _, err := transform.String(t, s)
+	if err != nil {
+		r = s
+	}
+
+	return strings.ToLower(strings.TrimSpace(r))
+}
+
+// SearchKeys returns the unique keys of the name, the slug and the
+// aliases of the tag.
+func SearchKeys(tag Tags) []string {
+	keys := make([]string, 0, 2+len(tag.Aliases))
+	seen := make(map[string]bool)
+
+	for _, s := range append([]string{tag.Label.Name, tag.Slug.String()}, tag.Aliases...) {
+		k := SearchKey(s)
+		if k == "" || seen[k] {
+			continue
+		}
+
+		seen[k] = true
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// Suggest returns the tags whose name, slug or alias starts with the
+// prefix, the most used ones first. The prefix is matched whatever its
+// case and diacritics are.
+func (s Service) Suggest(ctx context.Context, prefix string, limit int) ([]TagUsage, error) {
+	ctx, span := tracer.Start(ctx, "tags.Suggest")
+	defer span.End()
+
+	if limit == 0 {
+		limit = DefaultSuggestLimit
+	}
+
+	key := SearchKey(prefix)
+
+	err := validation.Errors{
+		"prefix": validation.Validate(key, validation.Required),
+		"limit":  validation.Validate(limit, validation.Min(1), validation.Max(MaxSuggestLimit)),
+	}.Filter()
+	if err != nil {
+		return []TagUsage{}, err
+	}
+
+	found, err := s.store.Suggest(ctx, key, limit)
+	if err != nil {
+		return []TagUsage{}, err
+	}
+
+	r := make([]TagUsage, 0, len(found))
+
+	for _, f := range found {
+		r = append(r, TagUsage{TagsOut: createTagsOut(f.Tag), Count: f.Count})
+	}
+
+	return r, nil
+}
@@ -0,0 +1,62 @@
+package tags_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews/tags"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/matryer/is"
+)
+
+func TestSearchKey(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(tags.SearchKey(" Économie "), "economie")
+	is.Equal(tags.SearchKey("São Paulo"), "sao paulo")
+	is.Equal(tags.SearchKey("COVID-19"), "covid-19")
+
+	tg := tags.Create("Café")
+	tg.Aliases = []string{"cafe", "Coffee"}
+	is.Equal(tags.SearchKeys(*tg), []string{"cafe", "coffee"})
+}
+
+func TestSuggest(t *testing.T) {
+	economy := tags.Create("economy")
+	store := &tags.RepositoryMock{
+		SuggestFunc: func(ctx context.Context, prefix string, limit int) ([]tags.TagCount, error) {
+			return []tags.TagCount{{Tag: *economy, Count: 4}}, nil
+		},
+	}
+	svc := tags.CreateSvc(store)
+
+	t.Run("folds the prefix", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := svc.Suggest(context.TODO(), "Éco", 0)
+		is.NoErr(err)
+		is.Equal(len(got), 1)
+		is.Equal(got[0].ID, economy.Label.ID)
+		is.Equal(got[0].Count, 4)
+
+		calls := store.SuggestCalls()
+		is.Equal(calls[len(calls)-1].Prefix, "eco")
+		is.Equal(calls[len(calls)-1].Limit, tags.DefaultSuggestLimit)
+	})
+
+	t.Run("rejects an empty prefix", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := svc.Suggest(context.TODO(), "  ", 0)
+		_, ok := err.(validation.Errors)
+		is.True(ok)
+	})
+
+	t.Run("rejects a large limit", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := svc.Suggest(context.TODO(), "eco", tags.MaxSuggestLimit+1)
+		_, ok := err.(validation.Errors)
+		is.True(ok)
+	})
+}
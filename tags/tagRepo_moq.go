@@ -5,6 +5,7 @@ package tags
 
 import (
 	"context"
+	"github.com/Iiqbal2000/bareknews"
 	"github.com/google/uuid"
 	"sync"
 )
@@ -22,6 +23,9 @@ var _ Repository = &RepositoryMock{}
 // 			CountFunc: func(contextMoqParam context.Context, uUID uuid.UUID) (int, error) {
 // 				panic("mock out the Count method")
 // 			},
+// 			CountNewsFunc: func(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error) {
+// 				panic("mock out the CountNews method")
+// 			},
 // 			DeleteFunc: func(contextMoqParam context.Context, uUID uuid.UUID) error {
 // 				panic("mock out the Delete method")
 // 			},
@@ -40,9 +44,24 @@ var _ Repository = &RepositoryMock{}
 // 			GetByNamesFunc: func(contextMoqParam context.Context, strings ...string) ([]Tags, error) {
 // 				panic("mock out the GetByNames method")
 // 			},
+// 			GetBySlugFunc: func(ctx context.Context, slug string) (Tags, error) {
+// 				panic("mock out the GetBySlug method")
+// 			},
+// 			GetDescendantIdsFunc: func(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+// 				panic("mock out the GetDescendantIds method")
+// 			},
+// 			GetPageFunc: func(ctx context.Context, q PageQuery) ([]TagCount, error) {
+// 				panic("mock out the GetPage method")
+// 			},
+// 			MergeFunc: func(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error {
+// 				panic("mock out the Merge method")
+// 			},
 // 			SaveFunc: func(contextMoqParam context.Context, tags Tags) error {
 // 				panic("mock out the Save method")
 // 			},
+// 			SuggestFunc: func(ctx context.Context, prefix string, limit int) ([]TagCount, error) {
+// 				panic("mock out the Suggest method")
+// 			},
 // 			UpdateFunc: func(contextMoqParam context.Context, tags Tags) error {
 // 				panic("mock out the Update method")
 // 			},
@@ -56,6 +75,9 @@ type RepositoryMock struct {
 	// CountFunc mocks the Count method.
 	CountFunc func(contextMoqParam context.Context, uUID uuid.UUID) (int, error)
 
+	// CountNewsFunc mocks the CountNews method.
+	CountNewsFunc func(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error)
+
 	// DeleteFunc mocks the Delete method.
 	DeleteFunc func(contextMoqParam context.Context, uUID uuid.UUID) error
 
@@ -74,9 +96,24 @@ type RepositoryMock struct {
 	// GetByNamesFunc mocks the GetByNames method.
 	GetByNamesFunc func(contextMoqParam context.Context, strings ...string) ([]Tags, error)
 
+	// GetBySlugFunc mocks the GetBySlug method.
+	GetBySlugFunc func(ctx context.Context, slug string) (Tags, error)
+
+	// GetDescendantIdsFunc mocks the GetDescendantIds method.
+	GetDescendantIdsFunc func(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+
+	// GetPageFunc mocks the GetPage method.
+	GetPageFunc func(ctx context.Context, q PageQuery) ([]TagCount, error)
+
+	// MergeFunc mocks the Merge method.
+	MergeFunc func(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error
+
 	// SaveFunc mocks the Save method.
 	SaveFunc func(contextMoqParam context.Context, tags Tags) error
 
+	// SuggestFunc mocks the Suggest method.
+	SuggestFunc func(ctx context.Context, prefix string, limit int) ([]TagCount, error)
+
 	// UpdateFunc mocks the Update method.
 	UpdateFunc func(contextMoqParam context.Context, tags Tags) error
 
@@ -89,6 +126,13 @@ type RepositoryMock struct {
 			// UUID is the uUID argument value.
 			UUID uuid.UUID
 		}
+		// CountNews holds details about calls to the CountNews method.
+		CountNews []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Status is the status argument value.
+			Status bareknews.Status
+		}
 		// Delete holds details about calls to the Delete method.
 		Delete []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -129,6 +173,36 @@ type RepositoryMock struct {
 			// Strings is the strings argument value.
 			Strings []string
 		}
+		// GetBySlug holds details about calls to the GetBySlug method.
+		GetBySlug []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Slug is the slug argument value.
+			Slug string
+		}
+		// GetDescendantIds holds details about calls to the GetDescendantIds method.
+		GetDescendantIds []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uuid.UUID
+		}
+		// GetPage holds details about calls to the GetPage method.
+		GetPage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Q is the q argument value.
+			Q PageQuery
+		}
+		// Merge holds details about calls to the Merge method.
+		Merge []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Target is the target argument value.
+			Target uuid.UUID
+			// Sources is the sources argument value.
+			Sources []uuid.UUID
+		}
 		// Save holds details about calls to the Save method.
 		Save []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -136,6 +210,15 @@ type RepositoryMock struct {
 			// Tags is the tags argument value.
 			Tags Tags
 		}
+		// Suggest holds details about calls to the Suggest method.
+		Suggest []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Prefix is the prefix argument value.
+			Prefix string
+			// Limit is the limit argument value.
+			Limit int
+		}
 		// Update holds details about calls to the Update method.
 		Update []struct {
 			// ContextMoqParam is the contextMoqParam argument value.
@@ -144,15 +227,21 @@ type RepositoryMock struct {
 			Tags Tags
 		}
 	}
-	lockCount      sync.RWMutex
-	lockDelete     sync.RWMutex
-	lockGetAll     sync.RWMutex
-	lockGetById    sync.RWMutex
-	lockGetByIds   sync.RWMutex
-	lockGetByName  sync.RWMutex
-	lockGetByNames sync.RWMutex
-	lockSave       sync.RWMutex
-	lockUpdate     sync.RWMutex
+	lockCount            sync.RWMutex
+	lockCountNews        sync.RWMutex
+	lockDelete           sync.RWMutex
+	lockGetAll           sync.RWMutex
+	lockGetById          sync.RWMutex
+	lockGetByIds         sync.RWMutex
+	lockGetByName        sync.RWMutex
+	lockGetByNames       sync.RWMutex
+	lockGetBySlug        sync.RWMutex
+	lockGetDescendantIds sync.RWMutex
+	lockGetPage          sync.RWMutex
+	lockMerge            sync.RWMutex
+	lockSave             sync.RWMutex
+	lockSuggest          sync.RWMutex
+	lockUpdate           sync.RWMutex
 }
 
 // Count calls CountFunc.
@@ -190,6 +279,41 @@ func (mock *RepositoryMock) CountCalls() []struct {
 	return calls
 }
 
+// CountNews calls CountNewsFunc.
+func (mock *RepositoryMock) CountNews(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error) {
+	if mock.CountNewsFunc == nil {
+		panic("RepositoryMock.CountNewsFunc: method is nil but Repository.CountNews was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Status bareknews.Status
+	}{
+		Ctx:    ctx,
+		Status: status,
+	}
+	mock.lockCountNews.Lock()
+	mock.calls.CountNews = append(mock.calls.CountNews, callInfo)
+	mock.lockCountNews.Unlock()
+	return mock.CountNewsFunc(ctx, status)
+}
+
+// CountNewsCalls gets all the calls that were made to CountNews.
+// Check the length with:
+//     len(mockedRepository.CountNewsCalls())
+func (mock *RepositoryMock) CountNewsCalls() []struct {
+	Ctx    context.Context
+	Status bareknews.Status
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Status bareknews.Status
+	}
+	mock.lockCountNews.RLock()
+	calls = mock.calls.CountNews
+	mock.lockCountNews.RUnlock()
+	return calls
+}
+
 // Delete calls DeleteFunc.
 func (mock *RepositoryMock) Delete(contextMoqParam context.Context, uUID uuid.UUID) error {
 	if mock.DeleteFunc == nil {
@@ -396,6 +520,150 @@ func (mock *RepositoryMock) GetByNamesCalls() []struct {
 	return calls
 }
 
+// GetBySlug calls GetBySlugFunc.
+func (mock *RepositoryMock) GetBySlug(ctx context.Context, slug string) (Tags, error) {
+	if mock.GetBySlugFunc == nil {
+		panic("RepositoryMock.GetBySlugFunc: method is nil but Repository.GetBySlug was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Slug string
+	}{
+		Ctx:  ctx,
+		Slug: slug,
+	}
+	mock.lockGetBySlug.Lock()
+	mock.calls.GetBySlug = append(mock.calls.GetBySlug, callInfo)
+	mock.lockGetBySlug.Unlock()
+	return mock.GetBySlugFunc(ctx, slug)
+}
+
+// GetBySlugCalls gets all the calls that were made to GetBySlug.
+// Check the length with:
+//     len(mockedRepository.GetBySlugCalls())
+func (mock *RepositoryMock) GetBySlugCalls() []struct {
+	Ctx  context.Context
+	Slug string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Slug string
+	}
+	mock.lockGetBySlug.RLock()
+	calls = mock.calls.GetBySlug
+	mock.lockGetBySlug.RUnlock()
+	return calls
+}
+
+// GetDescendantIds calls GetDescendantIdsFunc.
+func (mock *RepositoryMock) GetDescendantIds(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	if mock.GetDescendantIdsFunc == nil {
+		panic("RepositoryMock.GetDescendantIdsFunc: method is nil but Repository.GetDescendantIds was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  uuid.UUID
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetDescendantIds.Lock()
+	mock.calls.GetDescendantIds = append(mock.calls.GetDescendantIds, callInfo)
+	mock.lockGetDescendantIds.Unlock()
+	return mock.GetDescendantIdsFunc(ctx, id)
+}
+
+// GetDescendantIdsCalls gets all the calls that were made to GetDescendantIds.
+// Check the length with:
+//     len(mockedRepository.GetDescendantIdsCalls())
+func (mock *RepositoryMock) GetDescendantIdsCalls() []struct {
+	Ctx context.Context
+	ID  uuid.UUID
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  uuid.UUID
+	}
+	mock.lockGetDescendantIds.RLock()
+	calls = mock.calls.GetDescendantIds
+	mock.lockGetDescendantIds.RUnlock()
+	return calls
+}
+
+// GetPage calls GetPageFunc.
+func (mock *RepositoryMock) GetPage(ctx context.Context, q PageQuery) ([]TagCount, error) {
+	if mock.GetPageFunc == nil {
+		panic("RepositoryMock.GetPageFunc: method is nil but Repository.GetPage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Q   PageQuery
+	}{
+		Ctx: ctx,
+		Q:   q,
+	}
+	mock.lockGetPage.Lock()
+	mock.calls.GetPage = append(mock.calls.GetPage, callInfo)
+	mock.lockGetPage.Unlock()
+	return mock.GetPageFunc(ctx, q)
+}
+
+// GetPageCalls gets all the calls that were made to GetPage.
+// Check the length with:
+//     len(mockedRepository.GetPageCalls())
+func (mock *RepositoryMock) GetPageCalls() []struct {
+	Ctx context.Context
+	Q   PageQuery
+} {
+	var calls []struct {
+		Ctx context.Context
+		Q   PageQuery
+	}
+	mock.lockGetPage.RLock()
+	calls = mock.calls.GetPage
+	mock.lockGetPage.RUnlock()
+	return calls
+}
+
+// Merge calls MergeFunc.
+func (mock *RepositoryMock) Merge(ctx context.Context, target uuid.UUID, sources []uuid.UUID) error {
+	if mock.MergeFunc == nil {
+		panic("RepositoryMock.MergeFunc: method is nil but Repository.Merge was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Target  uuid.UUID
+		Sources []uuid.UUID
+	}{
+		Ctx:     ctx,
+		Target:  target,
+		Sources: sources,
+	}
+	mock.lockMerge.Lock()
+	mock.calls.Merge = append(mock.calls.Merge, callInfo)
+	mock.lockMerge.Unlock()
+	return mock.MergeFunc(ctx, target, sources)
+}
+
+// MergeCalls gets all the calls that were made to Merge.
+// Check the length with:
+//     len(mockedRepository.MergeCalls())
+func (mock *RepositoryMock) MergeCalls() []struct {
+	Ctx     context.Context
+	Target  uuid.UUID
+	Sources []uuid.UUID
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Target  uuid.UUID
+		Sources []uuid.UUID
+	}
+	mock.lockMerge.RLock()
+	calls = mock.calls.Merge
+	mock.lockMerge.RUnlock()
+	return calls
+}
+
 // Save calls SaveFunc.
 func (mock *RepositoryMock) Save(contextMoqParam context.Context, tags Tags) error {
 	if mock.SaveFunc == nil {
@@ -431,6 +699,45 @@ func (mock *RepositoryMock) SaveCalls() []struct {
 	return calls
 }
 
+// Suggest calls SuggestFunc.
+func (mock *RepositoryMock) Suggest(ctx context.Context, prefix string, limit int) ([]TagCount, error) {
+	if mock.SuggestFunc == nil {
+		panic("RepositoryMock.SuggestFunc: method is nil but Repository.Suggest was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Prefix string
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Prefix: prefix,
+		Limit:  limit,
+	}
+	mock.lockSuggest.Lock()
+	mock.calls.Suggest = append(mock.calls.Suggest, callInfo)
+	mock.lockSuggest.Unlock()
+	return mock.SuggestFunc(ctx, prefix, limit)
+}
+
+// SuggestCalls gets all the calls that were made to Suggest.
+// Check the length with:
+//     len(mockedRepository.SuggestCalls())
+func (mock *RepositoryMock) SuggestCalls() []struct {
+	Ctx    context.Context
+	Prefix string
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Prefix string
+		Limit  int
+	}
+	mock.lockSuggest.RLock()
+	calls = mock.calls.Suggest
+	mock.lockSuggest.RUnlock()
+	return calls
+}
+
 // Update calls UpdateFunc.
 func (mock *RepositoryMock) Update(contextMoqParam context.Context, tags Tags) error {
 	if mock.UpdateFunc == nil {
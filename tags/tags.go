@@ -9,6 +9,13 @@ import (
 type Tags struct {
 	Label bareknews.Label
 	Slug  bareknews.Slug
+	// Aliases are the other names of the tag, e.g. the names of the tags
+	// that are merged into it.
+	Aliases []string
+	// ParentID is the ID of the parent tag, or uuid.Nil for a top-level
+	// tag.
+	ParentID uuid.UUID
+	Meta     Meta
 }
 
 func Create(tagName string) *Tags {
@@ -28,6 +35,24 @@ func (t *Tags) ChangeName(newName string) {
 	t.Slug = bareknews.NewSlug(newName)
 }
 
+func (t *Tags) ChangeParent(parentID uuid.UUID) {
+	t.ParentID = parentID
+}
+
+func (t *Tags) ChangeMeta(meta Meta) {
+	t.Meta = meta
+}
+
 func (t Tags) Validate() error {
-	return t.Label.Validate()
+	return t.ValidateWith(bareknews.DefaultLimits)
+}
+
+// ValidateWith checks the tag with the limits of its label.
+func (t Tags) ValidateWith(l bareknews.Limits) error {
+	err := t.Label.ValidateWith(l)
+	if err != nil {
+		return err
+	}
+
+	return t.Meta.Validate()
 }
@@ -1,6 +1,7 @@
 package tags_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Iiqbal2000/bareknews/tags"
@@ -53,3 +54,39 @@ func TestChangeNameTags(t *testing.T) {
 		is.True(err != nil)
 	})
 }
+
+func TestMetaTags(t *testing.T) {
+	t.Run("Valid meta", func(t *testing.T) {
+		is := is.New(t)
+		tag := tags.Create("tag 1")
+		tag.ChangeMeta(tags.Meta{
+			Description: "All about tag 1",
+			Color:       "#1E90ff",
+			ImageURL:    "https://example.com/tag-1.png",
+			MetaTitle:   "Tag 1 news",
+		})
+		is.NoErr(tag.Validate())
+
+		tag.ChangeMeta(tags.Meta{})
+		is.NoErr(tag.Validate())
+	})
+
+	t.Run("Invalid meta", func(t *testing.T) {
+		for _, meta := range []tags.Meta{
+			{Color: "blue"},
+			{Color: "#12345"},
+			{ImageURL: "not a url"},
+			{ImageURL: "javascript:alert(1)"},
+			{ImageURL: "javascript:/x/;alert(1)"},
+			{ImageURL: "data:/x"},
+			{ImageURL: "ftp://example.com/tag-1.png"},
+			{MetaTitle: strings.Repeat("a", tags.MaxMetaTitleLength+1)},
+			{MetaDescription: strings.Repeat("é", tags.MaxMetaDescriptionLength+1)},
+		} {
+			is := is.New(t)
+			tag := tags.Create("tag 1")
+			tag.ChangeMeta(meta)
+			is.True(tag.Validate() != nil)
+		}
+	})
+}
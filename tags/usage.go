@@ -0,0 +1,181 @@
+package tags
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+// The orders of the tags with their usage.
+const (
+	SortByName  = "name"
+	SortByCount = "count"
+)
+
+// StatusAll counts the news whatever their status is.
+const StatusAll = "all"
+
+const (
+	// DefaultCloudBuckets is the number of weights of the tag cloud.
+	DefaultCloudBuckets = 5
+	// MaxCloudBuckets is the maximum number of weights of the tag cloud.
+	MaxCloudBuckets = 10
+)
+
+// UsageQuery selects and orders the tags with their usage. The news are
+// counted when they have the status; it defaults to the published news.
+type UsageQuery struct {
+	Status string
+	Sort   string
+	// Unused keeps only the tags that no news has.
+	Unused bool
+}
+
+// TagUsage is a tag with the number of news that have it.
+type TagUsage struct {
+	TagsOut
+	Count int `json:"count"`
+}
+
+// CloudItem is a tag of the tag cloud. Weight goes from 1 for the least
+// used tags up to the number of buckets for the most used ones.
+type CloudItem struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Slug   string    `json:"slug"`
+	Count  int       `json:"count"`
+	Weight int       `json:"weight"`
+}
+
+func (q UsageQuery) Validate() error {
+	return validation.ValidateStruct(&q,
+		validation.Field(&q.Status, validation.In(StatusAll, bareknews.Publish.String(), bareknews.Draft.String()).
+			Error("status must be one of 'all', 'publish', 'draft'")),
+		validation.Field(&q.Sort, validation.In(SortByName, SortByCount).
+			Error("sort must be one of 'name', 'count'")),
+	)
+}
+
+// GetUsage returns the tags with the number of their news. The most used
+// tags come first when they are sorted by count.
+func (s Service) GetUsage(ctx context.Context, q UsageQuery) ([]TagUsage, error) {
+	ctx, span := tracer.Start(ctx, "tags.GetUsage")
+	defer span.End()
+
+	if q.Status == "" {
+		q.Status = bareknews.Publish.String()
+	}
+
+	if q.Sort == "" {
+		q.Sort = SortByName
+	}
+
+	err := q.Validate()
+	if err != nil {
+		return []TagUsage{}, err
+	}
+
+	tgs, err := s.store.GetAll(ctx)
+	if err != nil {
+		return []TagUsage{}, err
+	}
+
+	status := bareknews.Status(q.Status)
+	if q.Status == StatusAll {
+		status = ""
+	}
+
+	counts, err := s.store.CountNews(ctx, status)
+	if err != nil {
+		return []TagUsage{}, err
+	}
+
+	r := make([]TagUsage, 0)
+
+	for _, t := range tgs {
+		c := counts[t.Label.ID]
+		if q.Unused && c > 0 {
+			continue
+		}
+
+		r = append(r, TagUsage{TagsOut: createTagsOut(t), Count: c})
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		if q.Sort == SortByCount && r[i].Count != r[j].Count {
+			return r[i].Count > r[j].Count
+		}
+
+		return strings.ToLower(r[i].Name) < strings.ToLower(r[j].Name)
+	})
+
+	return r, nil
+}
+
+// GetCloud returns the tags of the published news, sorted by name, with
+// their weight. The weights are spread on a logarithmic scale, so a few
+// very popular tags don't flatten the others.
+func (s Service) GetCloud(ctx context.Context, buckets int) ([]CloudItem, error) {
+	ctx, span := tracer.Start(ctx, "tags.GetCloud")
+	defer span.End()
+
+	if buckets == 0 {
+		buckets = DefaultCloudBuckets
+	}
+
+	err := validation.Validate(buckets, validation.Min(1), validation.Max(MaxCloudBuckets))
+	if err != nil {
+		return []CloudItem{}, validation.Errors{"buckets": err}
+	}
+
+	usage, err := s.GetUsage(ctx, UsageQuery{Status: bareknews.Publish.String(), Sort: SortByName})
+	if err != nil {
+		return []CloudItem{}, err
+	}
+
+	minCount, maxCount := 0, 0
+
+	for _, u := range usage {
+		if u.Count == 0 {
+			continue
+		}
+
+		if minCount == 0 || u.Count < minCount {
+			minCount = u.Count
+		}
+
+		if u.Count > maxCount {
+			maxCount = u.Count
+		}
+	}
+
+	r := make([]CloudItem, 0)
+	spread := math.Log(float64(maxCount)) - math.Log(float64(minCount))
+
+	for _, u := range usage {
+		if u.Count == 0 {
+			continue
+		}
+
+		weight := 1
+		if spread > 0 {
+			scaled := (math.Log(float64(u.Count)) - math.Log(float64(minCount))) / spread
+			weight = 1 + int(math.Round(scaled*float64(buckets-1)))
+		}
+
+		r = append(r, CloudItem{
+			ID:     u.ID,
+			Name:   u.Name,
+			Slug:   u.Slug,
+			Count:  u.Count,
+			Weight: weight,
+		})
+	}
+
+	return r, nil
+}
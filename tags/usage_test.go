@@ -0,0 +1,103 @@
+package tags_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func usageStore(counts map[string]int) (*tags.RepositoryMock, map[string]uuid.UUID) {
+	ids := make(map[string]uuid.UUID)
+	all := make([]tags.Tags, 0)
+
+	for _, name := range []string{"covid", "Asia", "economy", "sports"} {
+		tg := tags.Create(name)
+		ids[name] = tg.Label.ID
+		all = append(all, *tg)
+	}
+
+	store := &tags.RepositoryMock{
+		GetAllFunc: func(ctx context.Context) ([]tags.Tags, error) {
+			return all, nil
+		},
+		CountNewsFunc: func(ctx context.Context, status bareknews.Status) (map[uuid.UUID]int, error) {
+			r := make(map[uuid.UUID]int)
+			for name, c := range counts {
+				r[ids[name]] = c
+			}
+			return r, nil
+		},
+	}
+
+	return store, ids
+}
+
+func TestGetUsage(t *testing.T) {
+	store, _ := usageStore(map[string]int{"covid": 3, "economy": 7, "Asia": 3})
+	svc := tags.CreateSvc(store)
+
+	t.Run("sort by name", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := svc.GetUsage(context.TODO(), tags.UsageQuery{})
+		is.NoErr(err)
+		is.Equal(len(got), 4)
+		is.Equal(got[0].Name, "Asia")
+		is.Equal(got[3].Name, "sports")
+		is.Equal(got[3].Count, 0)
+		is.Equal(store.CountNewsCalls()[0].Status, bareknews.Publish)
+	})
+
+	t.Run("sort by count", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := svc.GetUsage(context.TODO(), tags.UsageQuery{Sort: tags.SortByCount, Status: tags.StatusAll})
+		is.NoErr(err)
+		is.Equal(got[0].Name, "economy")
+		is.Equal(got[1].Name, "Asia")
+		is.Equal(got[2].Name, "covid")
+		is.Equal(store.CountNewsCalls()[1].Status, bareknews.Status(""))
+	})
+
+	t.Run("unused only", func(t *testing.T) {
+		is := is.New(t)
+
+		got, err := svc.GetUsage(context.TODO(), tags.UsageQuery{Unused: true})
+		is.NoErr(err)
+		is.Equal(len(got), 1)
+		is.Equal(got[0].Name, "sports")
+	})
+
+	t.Run("invalid query should be failed", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := svc.GetUsage(context.TODO(), tags.UsageQuery{Sort: "date"})
+		is.True(err != nil)
+
+		_, err = svc.GetUsage(context.TODO(), tags.UsageQuery{Status: "archived"})
+		is.True(err != nil)
+	})
+}
+
+func TestGetCloud(t *testing.T) {
+	store, _ := usageStore(map[string]int{"covid": 1, "economy": 100, "Asia": 10})
+	svc := tags.CreateSvc(store)
+	is := is.New(t)
+
+	got, err := svc.GetCloud(context.TODO(), 0)
+	is.NoErr(err)
+	is.Equal(len(got), 3)
+	is.Equal(got[0].Name, "Asia")
+	is.Equal(got[0].Weight, 3)
+	is.Equal(got[1].Name, "covid")
+	is.Equal(got[1].Weight, 1)
+	is.Equal(got[2].Name, "economy")
+	is.Equal(got[2].Weight, tags.DefaultCloudBuckets)
+
+	_, err = svc.GetCloud(context.TODO(), tags.MaxCloudBuckets+1)
+	is.True(err != nil)
+}
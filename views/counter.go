@@ -0,0 +1,119 @@
+package views
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BucketSize is the span of time that the views are counted by.
+const BucketSize = time.Hour
+
+// Count is the number of views of a news item in the hour that starts at
+// Bucket, a Unix time.
+type Count struct {
+	NewsID uuid.UUID
+	Bucket int64
+	Views  int64
+}
+
+type key struct {
+	newsID uuid.UUID
+	bucket int64
+}
+
+// Counter adds up the views in memory and writes them to the store in
+// batches, so a view costs no write. It is safe for concurrent use.
+type Counter struct {
+	mu      sync.Mutex
+	pending map[key]int64
+	store   Repository
+}
+
+func CreateCounter(store Repository) *Counter {
+	return &Counter{pending: make(map[key]int64), store: store}
+}
+
+// Add counts a view of the news item at the time.
+func (c *Counter) Add(id uuid.UUID, at time.Time) {
+	k := key{newsID: id, bucket: bucketOf(at.Unix())}
+
+	c.mu.Lock()
+	c.pending[k]++
+	c.mu.Unlock()
+}
+
+// Pending returns the number of views that are not written yet.
+func (c *Counter) Pending() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int64
+	for _, v := range c.pending {
+		n += v
+	}
+
+	return n
+}
+
+// Flush writes the pending views to the store. The views that fail to be
+// written are put back, so the next flush writes them.
+func (c *Counter) Flush(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "views.Flush")
+	defer span.End()
+
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[key]int64)
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	counts := make([]Count, 0, len(batch))
+	for k, v := range batch {
+		counts = append(counts, Count{NewsID: k.newsID, Bucket: k.bucket, Views: v})
+	}
+
+	err := c.store.AddViews(ctx, counts)
+	if err != nil {
+		c.mu.Lock()
+		for k, v := range batch {
+			c.pending[k] += v
+		}
+		c.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// Run flushes the views every interval until the context is done. The
+// last views are left to a final Flush, which the caller runs once the
+// server stops taking requests.
+func (c *Counter) Run(ctx context.Context, interval time.Duration, log *zap.SugaredLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Flush(ctx); err != nil {
+				log.Errorw("flush the views", "ERROR", err)
+			}
+		}
+	}
+}
+
+// bucketOf returns the start of the hour of the Unix time.
+func bucketOf(unix int64) int64 {
+	size := int64(BucketSize.Seconds())
+	return unix - unix%size
+}
@@ -0,0 +1,91 @@
+package views_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/views"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestCounter(t *testing.T) {
+	var added []views.Count
+	store := &views.RepositoryMock{
+		AddViewsFunc: func(ctx context.Context, counts []views.Count) error {
+			added = append(added, counts...)
+			return nil
+		},
+	}
+	counter := views.CreateCounter(store)
+	is := is.New(t)
+
+	id := uuid.New()
+	hour := time.Unix(1_666_000_800, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				counter.Add(id, hour.Add(59*time.Minute))
+			}
+		}()
+	}
+	wg.Wait()
+
+	counter.Add(id, hour.Add(time.Hour))
+	is.Equal(counter.Pending(), int64(1001))
+
+	is.NoErr(counter.Flush(context.TODO()))
+	is.Equal(counter.Pending(), int64(0))
+	// the views are counted by the hour that they are in.
+	is.Equal(len(added), 2)
+	byBucket := map[int64]int64{}
+	for _, c := range added {
+		is.Equal(c.NewsID, id)
+		byBucket[c.Bucket] = c.Views
+	}
+	is.Equal(byBucket[hour.Unix()], int64(1000))
+	is.Equal(byBucket[hour.Unix()+3600], int64(1))
+
+	// nothing is written when there are no views.
+	is.NoErr(counter.Flush(context.TODO()))
+	is.Equal(len(store.AddViewsCalls()), 1)
+}
+
+func TestCounterFlushFails(t *testing.T) {
+	fail := true
+	var added []views.Count
+	store := &views.RepositoryMock{
+		AddViewsFunc: func(ctx context.Context, counts []views.Count) error {
+			if fail {
+				return errors.New("disk is full")
+			}
+			added = append(added, counts...)
+			return nil
+		},
+	}
+	counter := views.CreateCounter(store)
+	is := is.New(t)
+
+	id := uuid.New()
+	now := time.Now()
+	counter.Add(id, now)
+	counter.Add(id, now)
+
+	is.True(counter.Flush(context.TODO()) != nil)
+	// the views are kept for the next flush.
+	is.Equal(counter.Pending(), int64(2))
+
+	counter.Add(id, now)
+	fail = false
+
+	is.NoErr(counter.Flush(context.TODO()))
+	is.Equal(len(added), 1)
+	is.Equal(added[0].Views, int64(3))
+}
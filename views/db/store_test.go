@@ -0,0 +1,61 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsdb "github.com/Iiqbal2000/bareknews/news/db"
+	"github.com/Iiqbal2000/bareknews/pkg/sqlite3"
+	"github.com/Iiqbal2000/bareknews/views"
+	"github.com/Iiqbal2000/bareknews/views/db"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestGetTrendingIds(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	newsStore := newsdb.CreateStore(conn)
+	store := db.CreateStore(conn)
+	is := is.New(t)
+
+	const hour = int64(3600)
+	now := 100_000 * hour
+
+	old := news.Create("old news", "news body", bareknews.Publish, nil, 1)
+	fresh := news.Create("fresh news", "news body", bareknews.Publish, nil, 2)
+	draft := news.Create("draft news", "news body", bareknews.Draft, nil, 3)
+	stale := news.Create("stale news", "news body", bareknews.Publish, nil, 4)
+	for _, n := range []*news.News{old, fresh, draft, stale} {
+		is.NoErr(newsStore.Save(context.TODO(), *n))
+	}
+
+	is.NoErr(store.AddViews(context.TODO(), []views.Count{
+		{NewsID: old.Post.ID, Bucket: now - 20*hour, Views: 6},
+		{NewsID: fresh.Post.ID, Bucket: now - hour, Views: 4},
+		{NewsID: draft.Post.ID, Bucket: now - hour, Views: 100},
+		{NewsID: stale.Post.ID, Bucket: now - 30*hour, Views: 100},
+	}))
+	// the counts of a bucket add up.
+	is.NoErr(store.AddViews(context.TODO(), []views.Count{
+		{NewsID: old.Post.ID, Bucket: now - 20*hour, Views: 4},
+	}))
+
+	since := now - 24*hour
+
+	// the recent views weigh more.
+	got, err := store.GetTrendingIds(context.TODO(), since, now, 6*time.Hour, 10)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{fresh.Post.ID, old.Post.ID})
+
+	// with a long half-life the most views win.
+	got, err = store.GetTrendingIds(context.TODO(), since, now, 1000*time.Hour, 10)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{old.Post.ID, fresh.Post.ID})
+
+	got, err = store.GetTrendingIds(context.TODO(), since, now, 6*time.Hour, 1)
+	is.NoErr(err)
+	is.Equal(got, []uuid.UUID{fresh.Post.ID})
+}
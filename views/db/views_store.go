@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/views"
+	"github.com/google/uuid"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/views/db")
+
+type Store struct {
+	conn *sql.DB
+}
+
+// Ensure Store does implement views.Repository.
+var _ views.Repository = Store{}
+
+func CreateStore(conn *sql.DB) Store {
+	return Store{conn: conn}
+}
+
+func (s Store) AddViews(ctx context.Context, counts []views.Count) error {
+	ctx, span := tracer.Start(ctx, "views.db.AddViews")
+	defer span.End()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	builder := sqlbuilder.NewInsertBuilder()
+	builder.InsertInto("news_views")
+	builder.Cols("newsID", "bucket", "views")
+	for _, c := range counts {
+		builder.Values(c.NewsID, c.Bucket, c.Views)
+	}
+	builder.SQL("ON CONFLICT(newsID, bucket) DO UPDATE SET views = views + excluded.views")
+
+	query, args := builder.Build()
+
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
+
+// GetTrendingIds sums the views of every published news in the window,
+// each bucket weighted by the decay of its age.
+func (s Store) GetTrendingIds(ctx context.Context, since, now int64, halfLife time.Duration, limit int) ([]uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "views.db.GetTrendingIds")
+	defer span.End()
+
+	// A bucket is counted when any of its hour is in the window.
+	from := since - int64(views.BucketSize.Seconds())
+
+	query, args := sqlbuilder.Buildf(
+		"SELECT news_views.newsID FROM news_views "+
+			"JOIN news ON news.id = news_views.newsID "+
+			"WHERE news.status = %s AND news_views.bucket > %s "+
+			"GROUP BY news_views.newsID "+
+			"ORDER BY SUM(news_views.views * exp(-ln(2.0) * max(0, %s - news_views.bucket) / %s)) DESC, "+
+			"MAX(news_views.bucket) DESC, news_views.newsID "+
+			"LIMIT %s",
+		bareknews.Publish, from, now, halfLife.Seconds(), limit,
+	).Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []uuid.UUID{}, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+
+	for rows.Next() {
+		id := uuid.UUID{}
+		err = rows.Scan(&id)
+		if err != nil {
+			return []uuid.UUID{}, errors.Wrap(err, "scan a news id")
+		}
+		ids = append(ids, id)
+	}
+
+	if rows.Err() != nil {
+		return []uuid.UUID{}, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,98 @@
+package views
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	service Service
+	log     *zap.SugaredLogger
+}
+
+func CreateHandler(svc Service, log *zap.SugaredLogger) handler {
+	return handler{service: svc, log: log}
+}
+
+// CountView godoc
+// @Summary      Count a view of a news
+// @Description  Count a view of a news. The views are written in batches, so they show up in the trending news a bit later.
+// @Tags         news
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "News ID"  Format(uuid)
+// @Success      202  {object}  web.RespBody
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /news/{id}/views [post]
+func (h handler) Count(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "newsId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	err = h.service.Count(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully counting a view",
+	}
+
+	return web.Respond(w, payloadRes, http.StatusAccepted)
+}
+
+// GetTrendingNews godoc
+// @Summary      Get the trending news
+// @Description  Get the published news with the most views in the window. The views lose half their weight every quarter of the window.
+// @Tags         news
+// @Accept       json
+// @Produce      json
+// @Param        window   query     string  false  "span of the views, such as 6h or 7d"  default(24h)
+// @Param        limit   query     int     false  "number of news"	minimum(1) maximum(50) default(10)
+// @Success      200  {object}  web.RespBody{data=[]news.NewsOut} "The trending news, the most viewed first"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /news/trending [get]
+func (h handler) GetTrending(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var err error
+	window := DefaultWindow
+
+	if rawWindow := strings.TrimSpace(r.URL.Query().Get("window")); rawWindow != "" {
+		window, err = ParseWindow(rawWindow)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to parse the window"), http.StatusBadRequest)
+		}
+	}
+
+	limit := 0
+
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the limit"), http.StatusBadRequest)
+		}
+	}
+
+	nws, err := h.service.Trending(ctx, window, limit)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting the trending news",
+		Data:    nws,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/views"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/views/memory")
+
+type key struct {
+	newsID uuid.UUID
+	bucket int64
+}
+
+// Store keeps the view counts in memory by news item and bucket. It
+// checks the status of the ranked news in the news store, as it can't
+// join them. It is safe for concurrent use.
+type Store struct {
+	mu    *sync.RWMutex
+	items map[key]int64
+	news  News
+}
+
+// News is the part of the news store that this store needs, because the
+// news aren't kept here.
+type News interface {
+	GetById(ctx context.Context, id uuid.UUID) (*news.News, error)
+}
+
+// Ensure Store does implement views.Repository.
+var _ views.Repository = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:    &sync.RWMutex{},
+		items: make(map[key]int64),
+	}
+}
+
+// WithNews returns a copy of the store that ranks only the published
+// news of n.
+func (s Store) WithNews(n News) Store {
+	s.news = n
+	return s
+}
+
+func (s Store) AddViews(ctx context.Context, counts []views.Count) error {
+	_, span := tracer.Start(ctx, "views.memory.AddViews")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range counts {
+		s.items[key{newsID: c.NewsID, bucket: c.Bucket}] += c.Views
+	}
+
+	return nil
+}
+
+func (s Store) GetTrendingIds(ctx context.Context, since, now int64, halfLife time.Duration, limit int) ([]uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "views.memory.GetTrendingIds")
+	defer span.End()
+
+	from := since - int64(views.BucketSize.Seconds())
+
+	type scored struct {
+		id     uuid.UUID
+		score  float64
+		latest int64
+	}
+
+	s.mu.RLock()
+	byNews := make(map[uuid.UUID]*scored)
+	for k, n := range s.items {
+		if k.bucket <= from {
+			continue
+		}
+
+		sc, ok := byNews[k.newsID]
+		if !ok {
+			sc = &scored{id: k.newsID}
+			byNews[k.newsID] = sc
+		}
+
+		sc.score += float64(n) * views.Decay(now-k.bucket, halfLife)
+		if k.bucket > sc.latest {
+			sc.latest = k.bucket
+		}
+	}
+	s.mu.RUnlock()
+
+	ranked := make([]scored, 0, len(byNews))
+
+	for id, sc := range byNews {
+		if s.news != nil {
+			nw, err := s.news.GetById(ctx, id)
+			if err != nil || nw.Status != bareknews.Publish {
+				continue
+			}
+		}
+
+		ranked = append(ranked, *sc)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		if ranked[i].latest != ranked[j].latest {
+			return ranked[i].latest > ranked[j].latest
+		}
+		return ranked[i].id.String() < ranked[j].id.String()
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	ids := make([]uuid.UUID, 0, len(ranked))
+	for _, sc := range ranked {
+		ids = append(ids, sc.id)
+	}
+
+	return ids, nil
+}
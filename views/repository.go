@@ -0,0 +1,18 @@
+package views
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:generate moq -out viewsRepo_moq.go . Repository
+type Repository interface {
+	// AddViews adds the counts to the stored ones, all or none.
+	AddViews(ctx context.Context, counts []Count) error
+	// GetTrendingIds returns the published news that have views in the
+	// buckets from since, the highest score first. The views of a bucket
+	// lose half their weight every halfLife before now.
+	GetTrendingIds(ctx context.Context, since, now int64, halfLife time.Duration, limit int) ([]uuid.UUID, error)
+}
@@ -0,0 +1,124 @@
+// Package views counts the views of the news and ranks the trending ones.
+package views
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/views")
+
+const (
+	// DefaultWindow is the span of the trending views when none is given.
+	DefaultWindow = 24 * time.Hour
+	// MinWindow and MaxWindow bound the span of the trending views.
+	MinWindow = time.Hour
+	MaxWindow = 30 * 24 * time.Hour
+	// DefaultTrendingLimit is the number of trending news when no limit is
+	// given.
+	DefaultTrendingLimit = 10
+	// MaxTrendingLimit is the maximum number of trending news.
+	MaxTrendingLimit = 50
+)
+
+type Service struct {
+	counter *Counter
+	store   Repository
+	news    news.Service
+}
+
+func CreateSvc(counter *Counter, store Repository, nws news.Service) Service {
+	return Service{counter: counter, store: store, news: nws}
+}
+
+// Count counts a view of the news item. The view is written by the next
+// flush of the counter.
+func (s Service) Count(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "views.Count")
+	defer span.End()
+
+	err := s.news.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.counter.Add(id, time.Now())
+
+	return nil
+}
+
+// Trending returns the published news with the most views in the window,
+// the most viewed first. The views lose half their weight every quarter
+// of the window, so the recent views count more.
+func (s Service) Trending(ctx context.Context, window time.Duration, limit int) ([]news.NewsOut, error) {
+	ctx, span := tracer.Start(ctx, "views.Trending")
+	defer span.End()
+
+	if window == 0 {
+		window = DefaultWindow
+	}
+
+	if limit == 0 {
+		limit = DefaultTrendingLimit
+	}
+
+	err := validation.Errors{
+		"window": validation.Validate(window,
+			validation.Min(MinWindow).Error("must be at least 1h"),
+			validation.Max(MaxWindow).Error("must be at most 30d"),
+		),
+		"limit": validation.Validate(limit, validation.Min(1), validation.Max(MaxTrendingLimit)),
+	}.Filter()
+	if err != nil {
+		return []news.NewsOut{}, err
+	}
+
+	now := time.Now().Unix()
+	since := now - int64(window.Seconds())
+
+	ids, err := s.store.GetTrendingIds(ctx, since, now, window/4, limit)
+	if err != nil {
+		return []news.NewsOut{}, errors.Wrap(err, "get the trending news ids")
+	}
+
+	r, err := s.news.GetByIds(ctx, ids)
+	if err != nil {
+		return []news.NewsOut{}, errors.Wrap(err, "get the trending news")
+	}
+
+	return r, nil
+}
+
+// Decay is the weight of the views of the age, in seconds: 1 when they
+// are new, halved every halfLife.
+func Decay(age int64, halfLife time.Duration) float64 {
+	if age < 0 {
+		age = 0
+	}
+
+	return math.Exp(-math.Ln2 * float64(age) / halfLife.Seconds())
+}
+
+// ParseWindow reads a window such as "24h", "90m" or "7d".
+func ParseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(s)
+}
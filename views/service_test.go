@@ -0,0 +1,115 @@
+package views_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/tags"
+	"github.com/Iiqbal2000/bareknews/views"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func newsSvcOf(stories map[uuid.UUID]*news.News) news.Service {
+	nwsStore := &news.RepositoryMock{
+		CountFunc: func(ctx context.Context, id uuid.UUID) (int, error) {
+			if stories[id] == nil {
+				return 0, bareknews.ErrDataNotFound
+			}
+			return 1, nil
+		},
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID, view news.View) ([]news.News, error) {
+			nws := []news.News{}
+			for _, id := range ids {
+				if stories[id] != nil {
+					nws = append(nws, *stories[id])
+				}
+			}
+			return nws, nil
+		},
+	}
+	tgStore := &tags.RepositoryMock{
+		GetByIdsFunc: func(ctx context.Context, ids []uuid.UUID) ([]tags.Tags, error) {
+			return []tags.Tags{}, nil
+		},
+	}
+
+	return news.CreateSvc(nwsStore, tags.CreateSvc(tgStore))
+}
+
+func TestCount(t *testing.T) {
+	story := news.Create("news 1", "news body", bareknews.Publish, nil, 1)
+	store := &views.RepositoryMock{}
+	counter := views.CreateCounter(store)
+	svc := views.CreateSvc(counter, store, newsSvcOf(map[uuid.UUID]*news.News{story.Post.ID: story}))
+	is := is.New(t)
+
+	is.NoErr(svc.Count(context.TODO(), story.Post.ID))
+	is.NoErr(svc.Count(context.TODO(), story.Post.ID))
+	is.Equal(counter.Pending(), int64(2))
+
+	is.Equal(svc.Count(context.TODO(), uuid.New()), bareknews.ErrDataNotFound)
+	is.Equal(counter.Pending(), int64(2))
+}
+
+func TestTrending(t *testing.T) {
+	first := news.Create("news 1", "news body", bareknews.Publish, nil, 1)
+	second := news.Create("news 2", "news body", bareknews.Publish, nil, 2)
+	stories := map[uuid.UUID]*news.News{first.Post.ID: first, second.Post.ID: second}
+
+	store := &views.RepositoryMock{
+		GetTrendingIdsFunc: func(ctx context.Context, since, now int64, halfLife time.Duration, limit int) ([]uuid.UUID, error) {
+			// the third story is deleted since its views were counted.
+			return []uuid.UUID{second.Post.ID, uuid.New(), first.Post.ID}, nil
+		},
+	}
+	svc := views.CreateSvc(views.CreateCounter(store), store, newsSvcOf(stories))
+	is := is.New(t)
+
+	got, err := svc.Trending(context.TODO(), 0, 0)
+	is.NoErr(err)
+	// the order of the store is kept and the deleted story is left out.
+	is.Equal(len(got), 2)
+	is.Equal(got[0].ID, second.Post.ID)
+	is.Equal(got[1].ID, first.Post.ID)
+
+	call := store.GetTrendingIdsCalls()[0]
+	is.Equal(call.Now-call.Since, int64(views.DefaultWindow.Seconds()))
+	is.Equal(call.HalfLife, views.DefaultWindow/4)
+	is.Equal(call.Limit, views.DefaultTrendingLimit)
+
+	_, err = svc.Trending(context.TODO(), 31*24*time.Hour, views.MaxTrendingLimit+1)
+	errs, ok := err.(validation.Errors)
+	is.True(ok)
+	is.True(errs["window"] != nil)
+	is.True(errs["limit"] != nil)
+}
+
+func TestParseWindow(t *testing.T) {
+	is := is.New(t)
+
+	got, err := views.ParseWindow("24h")
+	is.NoErr(err)
+	is.Equal(got, 24*time.Hour)
+
+	got, err = views.ParseWindow("7d")
+	is.NoErr(err)
+	is.Equal(got, 7*24*time.Hour)
+
+	_, err = views.ParseWindow("week")
+	is.True(err != nil)
+}
+
+func TestDecay(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(views.Decay(0, time.Hour), 1.0)
+	is.Equal(views.Decay(-60, time.Hour), 1.0)
+	is.True(math.Abs(views.Decay(3600, time.Hour)-0.5) < 1e-9)
+	is.True(math.Abs(views.Decay(7200, time.Hour)-0.25) < 1e-9)
+}
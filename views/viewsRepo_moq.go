@@ -0,0 +1,149 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package views
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// Ensure, that RepositoryMock does implement Repository.
+// If this is not the case, regenerate this file with moq.
+var _ Repository = &RepositoryMock{}
+
+// RepositoryMock is a mock implementation of Repository.
+//
+// 	func TestSomethingThatUsesRepository(t *testing.T) {
+//
+// 		// make and configure a mocked Repository
+// 		mockedRepository := &RepositoryMock{
+// 			AddViewsFunc: func(ctx context.Context, counts []Count) error {
+// 				panic("mock out the AddViews method")
+// 			},
+// 			GetTrendingIdsFunc: func(ctx context.Context, since int64, now int64, halfLife time.Duration, limit int) ([]uuid.UUID, error) {
+// 				panic("mock out the GetTrendingIds method")
+// 			},
+// 		}
+//
+// 		// use mockedRepository in code that requires Repository
+// 		// and then make assertions.
+//
+// 	}
+type RepositoryMock struct {
+	// AddViewsFunc mocks the AddViews method.
+	AddViewsFunc func(ctx context.Context, counts []Count) error
+
+	// GetTrendingIdsFunc mocks the GetTrendingIds method.
+	GetTrendingIdsFunc func(ctx context.Context, since int64, now int64, halfLife time.Duration, limit int) ([]uuid.UUID, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddViews holds details about calls to the AddViews method.
+		AddViews []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Counts is the counts argument value.
+			Counts []Count
+		}
+		// GetTrendingIds holds details about calls to the GetTrendingIds method.
+		GetTrendingIds []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Since is the since argument value.
+			Since int64
+			// Now is the now argument value.
+			Now int64
+			// HalfLife is the halfLife argument value.
+			HalfLife time.Duration
+			// Limit is the limit argument value.
+			Limit int
+		}
+	}
+	lockAddViews       sync.RWMutex
+	lockGetTrendingIds sync.RWMutex
+}
+
+// AddViews calls AddViewsFunc.
+func (mock *RepositoryMock) AddViews(ctx context.Context, counts []Count) error {
+	if mock.AddViewsFunc == nil {
+		panic("RepositoryMock.AddViewsFunc: method is nil but Repository.AddViews was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Counts []Count
+	}{
+		Ctx:    ctx,
+		Counts: counts,
+	}
+	mock.lockAddViews.Lock()
+	mock.calls.AddViews = append(mock.calls.AddViews, callInfo)
+	mock.lockAddViews.Unlock()
+	return mock.AddViewsFunc(ctx, counts)
+}
+
+// AddViewsCalls gets all the calls that were made to AddViews.
+// Check the length with:
+//     len(mockedRepository.AddViewsCalls())
+func (mock *RepositoryMock) AddViewsCalls() []struct {
+	Ctx    context.Context
+	Counts []Count
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Counts []Count
+	}
+	mock.lockAddViews.RLock()
+	calls = mock.calls.AddViews
+	mock.lockAddViews.RUnlock()
+	return calls
+}
+
+// GetTrendingIds calls GetTrendingIdsFunc.
+func (mock *RepositoryMock) GetTrendingIds(ctx context.Context, since int64, now int64, halfLife time.Duration, limit int) ([]uuid.UUID, error) {
+	if mock.GetTrendingIdsFunc == nil {
+		panic("RepositoryMock.GetTrendingIdsFunc: method is nil but Repository.GetTrendingIds was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Since    int64
+		Now      int64
+		HalfLife time.Duration
+		Limit    int
+	}{
+		Ctx:      ctx,
+		Since:    since,
+		Now:      now,
+		HalfLife: halfLife,
+		Limit:    limit,
+	}
+	mock.lockGetTrendingIds.Lock()
+	mock.calls.GetTrendingIds = append(mock.calls.GetTrendingIds, callInfo)
+	mock.lockGetTrendingIds.Unlock()
+	return mock.GetTrendingIdsFunc(ctx, since, now, halfLife, limit)
+}
+
+// GetTrendingIdsCalls gets all the calls that were made to GetTrendingIds.
+// Check the length with:
+//     len(mockedRepository.GetTrendingIdsCalls())
+func (mock *RepositoryMock) GetTrendingIdsCalls() []struct {
+	Ctx      context.Context
+	Since    int64
+	Now      int64
+	HalfLife time.Duration
+	Limit    int
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Since    int64
+		Now      int64
+		HalfLife time.Duration
+		Limit    int
+	}
+	mock.lockGetTrendingIds.RLock()
+	calls = mock.calls.GetTrendingIds
+	mock.lockGetTrendingIds.RUnlock()
+	return calls
+}
@@ -0,0 +1,280 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsdb "github.com/Iiqbal2000/bareknews/news/db"
+	"github.com/Iiqbal2000/bareknews/pkg/sqlite3"
+	"github.com/Iiqbal2000/bareknews/webhooks"
+	"github.com/Iiqbal2000/bareknews/webhooks/db"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+// delivery is the part of a delivery that the tests compare.
+type delivery struct {
+	Event  string
+	NewsID uuid.UUID
+	Title  string
+}
+
+func deliveriesOf(got []webhooks.Delivery) []delivery {
+	r := make([]delivery, 0, len(got))
+	for _, d := range got {
+		r = append(r, delivery{Event: d.Event.Type, NewsID: d.Event.Data.ID, Title: d.Event.Data.Title})
+	}
+	return r
+}
+
+func TestOutbox(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	all := webhooks.Create("http://localhost/all", "0123456789abcdef", []string{
+		news.EventCreated, news.EventUpdated, news.EventPublished, news.EventDeleted,
+	}, time.Now().Unix())
+	published := webhooks.Create("http://localhost/published", "0123456789abcdef", []string{news.EventPublished}, time.Now().Unix())
+	is.NoErr(store.Save(context.TODO(), *all))
+	is.NoErr(store.Save(context.TODO(), *published))
+
+	n := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *n))
+
+	n.ChangeTitle("news 2")
+	is.NoErr(newsStore.Update(context.TODO(), *n))
+
+	n.ChangeStatus(bareknews.Publish)
+	is.NoErr(newsStore.Update(context.TODO(), *n))
+
+	is.NoErr(newsStore.Delete(context.TODO(), n.Post.ID))
+
+	now := time.Now().Unix()
+
+	count, err := store.Dispatch(context.TODO(), now)
+	is.NoErr(err)
+	is.Equal(count, 5)
+
+	// the events are dispatched once.
+	count, err = store.Dispatch(context.TODO(), now)
+	is.NoErr(err)
+	is.Equal(count, 0)
+
+	got, err := store.GetDeliveries(context.TODO(), all.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveriesOf(got), []delivery{
+		{Event: news.EventDeleted, NewsID: n.Post.ID, Title: "news 2"},
+		{Event: news.EventPublished, NewsID: n.Post.ID, Title: "news 2"},
+		{Event: news.EventUpdated, NewsID: n.Post.ID, Title: "news 2"},
+		{Event: news.EventCreated, NewsID: n.Post.ID, Title: "news 1"},
+	})
+	is.Equal(got[0].Status, webhooks.StatusPending)
+	is.Equal(got[0].URL, all.URL)
+	is.Equal(got[0].Secret, all.Secret)
+
+	got, err = store.GetDeliveries(context.TODO(), published.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveriesOf(got), []delivery{{Event: news.EventPublished, NewsID: n.Post.ID, Title: "news 2"}})
+	is.Equal(got[0].Event.Data.Status, bareknews.Publish.String())
+
+	// a news item that is created as published is published too.
+	live := news.Create("news 3", "news body", bareknews.Publish, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *live))
+
+	count, err = store.Dispatch(context.TODO(), now)
+	is.NoErr(err)
+	is.Equal(count, 3)
+
+	got, err = store.GetDeliveries(context.TODO(), published.ID, 1)
+	is.NoErr(err)
+	is.Equal(deliveriesOf(got), []delivery{{Event: news.EventPublished, NewsID: live.Post.ID, Title: "news 3"}})
+}
+
+func TestOutboxRollBack(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	sub := webhooks.Create("http://localhost/hook", "0123456789abcdef", []string{news.EventUpdated}, time.Now().Unix())
+	is.NoErr(store.Save(context.TODO(), *sub))
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	// both items are retitled, so the second one is a duplicate.
+	retitle := func(n news.News) (news.Change, error) {
+		n.ChangeTitle("news 3")
+		return news.Change{News: n}, nil
+	}
+	ids := []uuid.UUID{first.Post.ID, second.Post.ID}
+
+	// nothing is enqueued when the whole bulk is rolled back.
+	errs, err := newsStore.Bulk(context.TODO(), ids, retitle, true)
+	is.NoErr(err)
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+
+	count, err := store.Dispatch(context.TODO(), time.Now().Unix())
+	is.NoErr(err)
+	is.Equal(count, 0)
+
+	// the failed change is rolled back with its event.
+	errs, err = newsStore.Bulk(context.TODO(), ids, retitle, false)
+	is.NoErr(err)
+	is.Equal(errs[1], bareknews.ErrDataAlreadyExist)
+
+	count, err = store.Dispatch(context.TODO(), time.Now().Unix())
+	is.NoErr(err)
+	is.Equal(count, 1)
+
+	got, err := store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveriesOf(got), []delivery{{Event: news.EventUpdated, NewsID: first.Post.ID, Title: "news 3"}})
+}
+
+func TestDeliveries(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	sub := webhooks.Create("http://localhost/hook", "0123456789abcdef", []string{news.EventCreated}, time.Now().Unix())
+	is.NoErr(store.Save(context.TODO(), *sub))
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	const now = int64(1_666_000_000)
+
+	_, err := store.Dispatch(context.TODO(), now)
+	is.NoErr(err)
+
+	due, err := store.GetDue(context.TODO(), now, 10)
+	is.NoErr(err)
+	is.Equal(len(due), 2)
+	is.Equal(due[0].Event.Data.ID, first.Post.ID)
+
+	// a retried delivery is due at its next attempt.
+	due[0].Attempts = 1
+	due[0].NextAttempt = now + 30
+	due[0].LastStatusCode = 500
+	due[0].LastError = "unexpected status 500 Internal Server Error"
+	due[0].DateUpdated = now
+	is.NoErr(store.UpdateDelivery(context.TODO(), due[0]))
+
+	due[1].Status = webhooks.StatusSucceeded
+	due[1].Attempts = 1
+	due[1].LastStatusCode = 200
+	is.NoErr(store.UpdateDelivery(context.TODO(), due[1]))
+
+	got, err := store.GetDue(context.TODO(), now, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+
+	got, err = store.GetDue(context.TODO(), now+30, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Attempts, 1)
+	is.Equal(got[0].LastStatusCode, 500)
+	is.Equal(got[0].LastError, "unexpected status 500 Internal Server Error")
+
+	// a dead delivery isn't due any more.
+	got[0].Status = webhooks.StatusDead
+	is.NoErr(store.UpdateDelivery(context.TODO(), got[0]))
+
+	got, err = store.GetDue(context.TODO(), now+3600, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+
+	got, err = store.GetDeliveries(context.TODO(), sub.ID, 1)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].Status, webhooks.StatusSucceeded)
+
+	// the deliveries go with their subscription.
+	is.NoErr(store.Delete(context.TODO(), sub.ID))
+
+	_, err = store.GetById(context.TODO(), sub.ID)
+	is.Equal(err, bareknews.ErrDataNotFound)
+
+	got, err = store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+}
+
+func TestPrune(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	newsStore := newsdb.CreateStore(conn)
+	is := is.New(t)
+
+	sub := webhooks.Create("http://localhost/hook", "0123456789abcdef", []string{news.EventCreated}, time.Now().Unix())
+	is.NoErr(store.Save(context.TODO(), *sub))
+
+	for _, title := range []string{"news 1", "news 2", "news 3"} {
+		n := news.Create(title, "news body", bareknews.Draft, nil, time.Now().Unix())
+		is.NoErr(newsStore.Save(context.TODO(), *n))
+	}
+
+	const now = int64(1_666_000_000)
+
+	_, err := store.Dispatch(context.TODO(), now)
+	is.NoErr(err)
+
+	due, err := store.GetDue(context.TODO(), now, 10)
+	is.NoErr(err)
+	is.Equal(len(due), 3)
+
+	due[0].Status = webhooks.StatusSucceeded
+	due[1].Status = webhooks.StatusDead
+	for _, d := range due {
+		is.NoErr(store.UpdateDelivery(context.TODO(), d))
+	}
+
+	// the events of the updated rows are kept until the retention ends.
+	is.NoErr(store.Prune(context.TODO(), now))
+	got, err := store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 3)
+
+	// a pending delivery is kept with its event.
+	is.NoErr(store.Prune(context.TODO(), now+1))
+	got, err = store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 1)
+	is.Equal(got[0].ID, due[2].ID)
+
+	var events int
+	is.NoErr(conn.QueryRow("SELECT COUNT(*) FROM webhook_outbox").Scan(&events))
+	is.Equal(events, 1)
+}
+
+func TestSubscriptions(t *testing.T) {
+	conn, _ := sqlite3.Run(sqlite3.Config{URI: ":memory:", DropTableFirst: true})
+	store := db.CreateStore(conn)
+	is := is.New(t)
+
+	sub := webhooks.Create("http://localhost/hook", "0123456789abcdef", []string{news.EventCreated}, 1)
+	is.NoErr(store.Save(context.TODO(), *sub))
+
+	sub.URL = "https://example.com/hook"
+	sub.Events = []string{news.EventPublished, news.EventDeleted}
+	is.NoErr(store.Update(context.TODO(), *sub))
+
+	got, err := store.GetById(context.TODO(), sub.ID)
+	is.NoErr(err)
+	is.Equal(got, *sub)
+
+	all, err := store.GetAll(context.TODO())
+	is.NoErr(err)
+	is.Equal(all, []webhooks.Subscription{*sub})
+}
@@ -0,0 +1,419 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/webhooks"
+	"github.com/google/uuid"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/webhooks/db")
+
+type Store struct {
+	conn *sql.DB
+}
+
+// Ensure Store does implement webhooks.Repository.
+var _ webhooks.Repository = Store{}
+
+func CreateStore(conn *sql.DB) Store {
+	return Store{conn: conn}
+}
+
+// Enqueue writes an event of the news item to the outbox in the
+// transaction of the change, so an event is delivered if and only if its
+// change is committed.
+func Enqueue(ctx context.Context, tx *sql.Tx, event string, n news.News) error {
+	data, err := json.Marshal(webhooks.NewsDataOf(n))
+	if err != nil {
+		return errors.Wrap(err, "marshal the news item")
+	}
+
+	builder := sqlbuilder.NewInsertBuilder()
+	builder.InsertInto("webhook_outbox")
+	builder.Cols("event", "newsID", "data", "date_created")
+	builder.Values(event, n.Post.ID, string(data), time.Now().Unix())
+	query, args := builder.Build()
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "enqueue the event")
+	}
+
+	return nil
+}
+
+func (s Store) Save(ctx context.Context, sub webhooks.Subscription) error {
+	ctx, span := tracer.Start(ctx, "webhooks.db.Save")
+	defer span.End()
+
+	builder := sqlbuilder.NewInsertBuilder()
+	builder.InsertInto("webhook_subscriptions")
+	builder.Cols("id", "url", "secret", "events", "date_created")
+	builder.Values(sub.ID, sub.URL, sub.Secret, strings.Join(sub.Events, ","), sub.DateCreated)
+	query, args := builder.Build()
+
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
+
+func (s Store) Update(ctx context.Context, sub webhooks.Subscription) error {
+	ctx, span := tracer.Start(ctx, "webhooks.db.Update")
+	defer span.End()
+
+	builder := sqlbuilder.NewUpdateBuilder()
+	builder.Update("webhook_subscriptions")
+	builder.Set(
+		builder.Assign("url", sub.URL),
+		builder.Assign("secret", sub.Secret),
+		builder.Assign("events", strings.Join(sub.Events, ",")),
+	)
+	builder.Where(builder.Equal("id", sub.ID))
+	query, args := builder.Build()
+
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
+
+func (s Store) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "webhooks.db.Delete")
+	defer span.End()
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+
+	defer tx.Rollback()
+
+	for _, table := range []struct{ name, col string }{
+		{"webhook_deliveries", "subscriptionID"},
+		{"webhook_subscriptions", "id"},
+	} {
+		builder := sqlbuilder.NewDeleteBuilder()
+		builder.DeleteFrom(table.name)
+		builder.Where(builder.Equal(table.col, id))
+		query, args := builder.Build()
+
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return errors.Wrapf(err, "delete from %s", table.name)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+
+	return nil
+}
+
+func (s Store) GetById(ctx context.Context, id uuid.UUID) (webhooks.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.db.GetById")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("id", "url", "secret", "events", "date_created")
+	builder.From("webhook_subscriptions")
+	builder.Where(builder.Equal("id", id))
+	query, args := builder.Build()
+
+	sub, err := scanSubscription(s.conn.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhooks.Subscription{}, bareknews.ErrDataNotFound
+		}
+		return webhooks.Subscription{}, errors.Wrap(err, "scan a subscription")
+	}
+
+	return sub, nil
+}
+
+func (s Store) GetAll(ctx context.Context) ([]webhooks.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.db.GetAll")
+	defer span.End()
+
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select("id", "url", "secret", "events", "date_created")
+	builder.From("webhook_subscriptions")
+	builder.OrderBy("date_created", "id")
+	query, args := builder.Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []webhooks.Subscription{}, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	subs := make([]webhooks.Subscription, 0)
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return []webhooks.Subscription{}, errors.Wrap(err, "scan a subscription")
+		}
+
+		subs = append(subs, sub)
+	}
+
+	if rows.Err() != nil {
+		return []webhooks.Subscription{}, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return subs, nil
+}
+
+// Dispatch bounds both of its statements by the last event that it
+// reads, so an event that is committed meanwhile is left for the next
+// call instead of being marked without its deliveries. A subscription
+// only gets the events that are written after it is created.
+func (s Store) Dispatch(ctx context.Context, now int64) (int, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.db.Dispatch")
+	defer span.End()
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "begin tx")
+	}
+
+	defer tx.Rollback()
+
+	var last int64
+
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(id), 0) FROM webhook_outbox WHERE dispatched = 0",
+	).Scan(&last)
+	if err != nil {
+		return 0, errors.Wrap(err, "get the last event")
+	}
+
+	if last == 0 {
+		return 0, nil
+	}
+
+	query, args := sqlbuilder.Buildf(`
+		INSERT OR IGNORE INTO webhook_deliveries(
+			subscriptionID, eventID, status, attempts, next_attempt,
+			last_status_code, last_error, date_created, date_updated
+		)
+		SELECT s.id, o.id, %v, 0, %v, 0, '', %v, %v
+		FROM webhook_outbox o
+		JOIN webhook_subscriptions s
+			ON instr(',' || s.events || ',', ',' || o.event || ',') > 0
+			AND s.date_created <= o.date_created
+		WHERE o.dispatched = 0 AND o.id <= %v
+		ORDER BY o.id, s.id`,
+		webhooks.StatusPending, now, now, now, last,
+	).Build()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert the deliveries")
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "count the deliveries")
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE webhook_outbox SET dispatched = 1 WHERE dispatched = 0 AND id <= ?", last,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "mark the events dispatched")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit tx")
+	}
+
+	return int(n), nil
+}
+
+func (s Store) GetDue(ctx context.Context, now int64, limit int) ([]webhooks.Delivery, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.db.GetDue")
+	defer span.End()
+
+	builder := selectDeliveries()
+	builder.Where(
+		builder.Equal("d.status", webhooks.StatusPending),
+		builder.LessEqualThan("d.next_attempt", now),
+	)
+	builder.OrderBy("d.next_attempt", "d.id")
+	builder.Limit(limit)
+
+	return s.deliveries(ctx, builder)
+}
+
+func (s Store) UpdateDelivery(ctx context.Context, d webhooks.Delivery) error {
+	ctx, span := tracer.Start(ctx, "webhooks.db.UpdateDelivery")
+	defer span.End()
+
+	builder := sqlbuilder.NewUpdateBuilder()
+	builder.Update("webhook_deliveries")
+	builder.Set(
+		builder.Assign("status", d.Status),
+		builder.Assign("attempts", d.Attempts),
+		builder.Assign("next_attempt", d.NextAttempt),
+		builder.Assign("last_status_code", d.LastStatusCode),
+		builder.Assign("last_error", d.LastError),
+		builder.Assign("date_updated", d.DateUpdated),
+	)
+	builder.Where(builder.Equal("id", d.ID))
+	query, args := builder.Build()
+
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec the query")
+	}
+
+	return nil
+}
+
+func (s Store) GetDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]webhooks.Delivery, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.db.GetDeliveries")
+	defer span.End()
+
+	builder := selectDeliveries()
+	builder.Where(builder.Equal("d.subscriptionID", id))
+	builder.OrderBy("d.id").Desc()
+	builder.Limit(limit)
+
+	return s.deliveries(ctx, builder)
+}
+
+func (s Store) Prune(ctx context.Context, before int64) error {
+	ctx, span := tracer.Start(ctx, "webhooks.db.Prune")
+	defer span.End()
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+
+	defer tx.Rollback()
+
+	builder := sqlbuilder.NewDeleteBuilder()
+	builder.DeleteFrom("webhook_deliveries")
+	builder.Where(
+		builder.In("status", webhooks.StatusSucceeded, webhooks.StatusDead),
+		builder.LessThan("date_updated", before),
+	)
+	query, args := builder.Build()
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "delete the deliveries")
+	}
+
+	// an event that isn't dispatched yet has no delivery either, so it
+	// is kept by the dispatched flag.
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM webhook_outbox
+		WHERE dispatched = 1
+		AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.eventID = webhook_outbox.id)`,
+	)
+	if err != nil {
+		return errors.Wrap(err, "delete the events")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+
+	return nil
+}
+
+// selectDeliveries selects the deliveries with their subscriptions and
+// their events.
+func selectDeliveries() *sqlbuilder.SelectBuilder {
+	builder := sqlbuilder.NewSelectBuilder()
+	builder.Select(
+		"d.id", "d.subscriptionID", "s.url", "s.secret",
+		"o.id", "o.event", "o.data", "o.date_created",
+		"d.status", "d.attempts", "d.next_attempt",
+		"d.last_status_code", "d.last_error", "d.date_created", "d.date_updated",
+	)
+	builder.From("webhook_deliveries d")
+	builder.Join("webhook_subscriptions s", "s.id = d.subscriptionID")
+	builder.Join("webhook_outbox o", "o.id = d.eventID")
+
+	return builder
+}
+
+func (s Store) deliveries(ctx context.Context, builder *sqlbuilder.SelectBuilder) ([]webhooks.Delivery, error) {
+	query, args := builder.Build()
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []webhooks.Delivery{}, errors.Wrap(err, "exec the query")
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]webhooks.Delivery, 0)
+
+	for rows.Next() {
+		d := webhooks.Delivery{}
+		var data string
+
+		err = rows.Scan(
+			&d.ID, &d.SubscriptionID, &d.URL, &d.Secret,
+			&d.Event.ID, &d.Event.Type, &data, &d.Event.DateCreated,
+			&d.Status, &d.Attempts, &d.NextAttempt,
+			&d.LastStatusCode, &d.LastError, &d.DateCreated, &d.DateUpdated,
+		)
+		if err != nil {
+			return []webhooks.Delivery{}, errors.Wrap(err, "scan a delivery")
+		}
+
+		err = json.Unmarshal([]byte(data), &d.Event.Data)
+		if err != nil {
+			return []webhooks.Delivery{}, errors.Wrap(err, "unmarshal the news item")
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if rows.Err() != nil {
+		return []webhooks.Delivery{}, errors.Wrap(rows.Err(), "failed get items during iteration")
+	}
+
+	return deliveries, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row scanner) (webhooks.Subscription, error) {
+	sub := webhooks.Subscription{}
+	var events string
+
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, &events, &sub.DateCreated)
+	if err != nil {
+		return webhooks.Subscription{}, err
+	}
+
+	sub.Events = strings.Split(events, ",")
+
+	return sub, nil
+}
@@ -0,0 +1,220 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/pkg/web"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	service Service
+	log     *zap.SugaredLogger
+}
+
+func CreateHandler(svc Service, log *zap.SugaredLogger) handler {
+	return handler{service: svc, log: log}
+}
+
+// CreateWebhooks godoc
+// @Summary      Create a webhook
+// @Description  Subscribe a URL to the events of the news. A secret is made when none is given; it is returned only here. Every delivery is signed in the X-Bareknews-Signature header with "sha256=" and the hex HMAC-SHA256 of the X-Bareknews-Timestamp, a dot and the body.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param webhook body SubscriptionIn true "A payload of new webhook"
+// @Success      201  {object}  web.RespBody{data=SubscriptionOut} "Response body for a new webhook"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /webhooks [post]
+func (h handler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	payload := SubscriptionIn{}
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		return bareknews.ErrInvalidJSON
+	}
+
+	sub, err := h.service.Create(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully creating a webhook",
+		Data:    sub,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusCreated)
+}
+
+// GetAllWebhooks godoc
+// @Summary      Get all webhooks
+// @Description  Get every webhook, without its secret
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  web.RespBody{data=[]SubscriptionOut} "Response body for the webhooks"
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /webhooks [get]
+func (h handler) GetAll(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	subs, err := h.service.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting all webhooks",
+		Data:    subs,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// GetWebhookById godoc
+// @Summary      Get a webhook
+// @Description  Get a webhook by id, without its secret
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"  Format(uuid)
+// @Success      200  {object}  web.RespBody{data=SubscriptionOut} "Response body for a webhook"
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /webhooks/{id} [get]
+func (h handler) GetById(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	sub, err := h.service.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting a webhook",
+		Data:    sub,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// UpdateWebhooks godoc
+// @Summary      Update a webhook
+// @Description  Replace the URL and the events of a webhook, and its secret when one is given
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"  Format(uuid)
+// @Param webhook body SubscriptionIn true "A payload of the webhook"
+// @Success      200  {object}  web.RespBody{data=SubscriptionOut} "Response body for a webhook"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /webhooks/{id} [put]
+func (h handler) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	payload := SubscriptionIn{}
+
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		return bareknews.ErrInvalidJSON
+	}
+
+	sub, err := h.service.Update(ctx, id, payload)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully updating a webhook",
+		Data:    sub,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// DeleteWebhooks godoc
+// @Summary      Delete a webhook
+// @Description  Delete a webhook by id with its deliveries
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"  Format(uuid)
+// @Success      200  {object}  web.RespBody{data=object}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /webhooks/{id} [delete]
+func (h handler) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	err = h.service.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully deleting a webhook",
+		Data:    struct{}{},
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
+
+// GetDeliveries godoc
+// @Summary      Get the deliveries of a webhook
+// @Description  Get the log of the deliveries of a webhook, the newest first. A pending delivery is tried again with an exponential backoff; a dead one failed every attempt and isn't tried again.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"  Format(uuid)
+// @Param        limit   query     int     false  "number of deliveries"	minimum(1) maximum(200) default(50)
+// @Success      200  {object}  web.RespBody{data=[]DeliveryOut} "Response body for the deliveries"
+// @Failure      400  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      404  {object}  web.ErrRespBody{error=object{message=string}}
+// @Failure      500  {object}  web.ErrRespBody{error=object{message=string}}
+// @Router       /webhooks/{id}/deliveries [get]
+func (h handler) GetDeliveries(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		return bareknews.ErrDataNotFound
+	}
+
+	limit := 0
+
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			return web.NewRequestError(errors.New("failed to convert the limit"), http.StatusBadRequest)
+		}
+	}
+
+	deliveries, err := h.service.GetDeliveries(ctx, id, limit)
+	if err != nil {
+		return err
+	}
+
+	payloadRes := web.GeneralResponse{
+		Message: "Successfully getting the deliveries",
+		Data:    deliveries,
+	}
+
+	return web.Respond(w, payloadRes, http.StatusOK)
+}
@@ -0,0 +1,134 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsmemory "github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/Iiqbal2000/bareknews/webhooks"
+	"github.com/Iiqbal2000/bareknews/webhooks/memory"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func eventsOf(got []webhooks.Delivery) []string {
+	r := make([]string, 0, len(got))
+	for _, d := range got {
+		r = append(r, d.Event.Type)
+	}
+	return r
+}
+
+func TestOutbox(t *testing.T) {
+	store := memory.CreateStore()
+	newsStore := newsmemory.CreateStore().WithOutbox(store)
+	is := is.New(t)
+
+	sub := webhooks.Create("http://localhost/hook", "0123456789abcdef", []string{
+		news.EventCreated, news.EventUpdated, news.EventPublished, news.EventDeleted,
+	}, time.Now().Unix())
+	is.NoErr(store.Save(context.TODO(), *sub))
+
+	first := news.Create("news 1", "news body", bareknews.Draft, nil, time.Now().Unix())
+	second := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *first))
+	is.NoErr(newsStore.Save(context.TODO(), *second))
+
+	first.ChangeStatus(bareknews.Publish)
+	is.NoErr(newsStore.Update(context.TODO(), *first))
+
+	// the second item is retitled and the first one is deleted.
+	retitle := func(title string) news.Apply {
+		return func(n news.News) (news.Change, error) {
+			if n.Post.ID == first.Post.ID {
+				return news.Change{News: n, Delete: true}, nil
+			}
+			n.ChangeTitle(title)
+			return news.Change{News: n}, nil
+		}
+	}
+	ids := []uuid.UUID{second.Post.ID, first.Post.ID}
+
+	// nothing is enqueued when the whole bulk is rolled back.
+	errs, err := newsStore.Bulk(context.TODO(), ids, retitle("news 1"), true)
+	is.NoErr(err)
+	is.Equal(errs[0], bareknews.ErrDataAlreadyExist)
+
+	errs, err = newsStore.Bulk(context.TODO(), ids, retitle("news 3"), true)
+	is.NoErr(err)
+	is.Equal(errs[0], nil)
+
+	now := time.Now().Unix()
+
+	count, err := store.Dispatch(context.TODO(), now)
+	is.NoErr(err)
+	is.Equal(count, 5)
+
+	count, err = store.Dispatch(context.TODO(), now)
+	is.NoErr(err)
+	is.Equal(count, 0)
+
+	got, err := store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(eventsOf(got), []string{
+		news.EventDeleted, news.EventUpdated, news.EventPublished, news.EventCreated, news.EventCreated,
+	})
+	// the deleted news item is as it was before it is deleted.
+	is.Equal(got[0].Event.Data.Status, bareknews.Publish.String())
+	is.Equal(got[1].Event.Data.Title, "news 3")
+	is.Equal(got[0].URL, sub.URL)
+
+	due, err := store.GetDue(context.TODO(), now, 2)
+	is.NoErr(err)
+	is.Equal(eventsOf(due), []string{news.EventCreated, news.EventCreated})
+
+	due[0].Status = webhooks.StatusDead
+	is.NoErr(store.UpdateDelivery(context.TODO(), due[0]))
+
+	due, err = store.GetDue(context.TODO(), now, 10)
+	is.NoErr(err)
+	is.Equal(len(due), 4)
+
+	// a finished delivery is pruned once the retention ends.
+	is.NoErr(store.Prune(context.TODO(), now))
+	got, err = store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 5)
+
+	is.NoErr(store.Prune(context.TODO(), now+1))
+	got, err = store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 4)
+
+	// the deliveries go with their subscription.
+	is.NoErr(store.Delete(context.TODO(), sub.ID))
+
+	got, err = store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(len(got), 0)
+}
+
+func TestSubscriptions(t *testing.T) {
+	store := memory.CreateStore()
+	is := is.New(t)
+
+	later := webhooks.Create("http://localhost/later", "0123456789abcdef", []string{news.EventCreated}, 2)
+	sub := webhooks.Create("http://localhost/hook", "0123456789abcdef", []string{news.EventCreated}, 1)
+	is.NoErr(store.Save(context.TODO(), *later))
+	is.NoErr(store.Save(context.TODO(), *sub))
+	is.Equal(store.Save(context.TODO(), *sub), bareknews.ErrDataAlreadyExist)
+
+	sub.Events = []string{news.EventDeleted}
+	is.NoErr(store.Update(context.TODO(), *sub))
+
+	got, err := store.GetById(context.TODO(), sub.ID)
+	is.NoErr(err)
+	is.Equal(got, *sub)
+
+	all, err := store.GetAll(context.TODO())
+	is.NoErr(err)
+	is.Equal(all, []webhooks.Subscription{*sub, *later})
+}
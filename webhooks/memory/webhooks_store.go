@@ -0,0 +1,298 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/webhooks"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/webhooks/memory")
+
+// Store is an in-memory implementation of webhooks.Repository. The news
+// store enqueues its events in it while it holds its own lock, which
+// stands in for the transaction of the SQLite store.
+type Store struct {
+	mu           *sync.RWMutex
+	lastEvent    *int64
+	lastDelivery *int64
+	subs         map[uuid.UUID]webhooks.Subscription
+	// outbox holds the events that aren't dispatched yet.
+	outbox     *[]webhooks.Event
+	deliveries map[int64]webhooks.Delivery
+}
+
+// Ensure Store does implement webhooks.Repository.
+var _ webhooks.Repository = Store{}
+
+func CreateStore() Store {
+	return Store{
+		mu:           &sync.RWMutex{},
+		lastEvent:    new(int64),
+		lastDelivery: new(int64),
+		subs:         make(map[uuid.UUID]webhooks.Subscription),
+		outbox:       &[]webhooks.Event{},
+		deliveries:   make(map[int64]webhooks.Delivery),
+	}
+}
+
+// Enqueue writes an event of the news item to the outbox.
+func (s Store) Enqueue(event string, n news.News) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	*s.lastEvent++
+	*s.outbox = append(*s.outbox, webhooks.Event{
+		ID:          *s.lastEvent,
+		Type:        event,
+		Data:        webhooks.NewsDataOf(n),
+		DateCreated: time.Now().Unix(),
+	})
+}
+
+func (s Store) Save(ctx context.Context, sub webhooks.Subscription) error {
+	_, span := tracer.Start(ctx, "webhooks.memory.Save")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subs[sub.ID]; ok {
+		return bareknews.ErrDataAlreadyExist
+	}
+
+	s.subs[sub.ID] = clone(sub)
+
+	return nil
+}
+
+func (s Store) Update(ctx context.Context, sub webhooks.Subscription) error {
+	_, span := tracer.Start(ctx, "webhooks.memory.Update")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, ok := s.subs[sub.ID]
+	if !ok {
+		return nil
+	}
+
+	sub.DateCreated = prev.DateCreated
+	s.subs[sub.ID] = clone(sub)
+
+	return nil
+}
+
+func (s Store) Delete(ctx context.Context, id uuid.UUID) error {
+	_, span := tracer.Start(ctx, "webhooks.memory.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subs, id)
+
+	for dID, d := range s.deliveries {
+		if d.SubscriptionID == id {
+			delete(s.deliveries, dID)
+		}
+	}
+
+	return nil
+}
+
+func (s Store) GetById(ctx context.Context, id uuid.UUID) (webhooks.Subscription, error) {
+	_, span := tracer.Start(ctx, "webhooks.memory.GetById")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subs[id]
+	if !ok {
+		return webhooks.Subscription{}, bareknews.ErrDataNotFound
+	}
+
+	return clone(sub), nil
+}
+
+func (s Store) GetAll(ctx context.Context) ([]webhooks.Subscription, error) {
+	_, span := tracer.Start(ctx, "webhooks.memory.GetAll")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sorted(), nil
+}
+
+func (s Store) Dispatch(ctx context.Context, now int64) (int, error) {
+	_, span := tracer.Start(ctx, "webhooks.memory.Dispatch")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := s.sorted()
+	n := 0
+
+	for _, e := range *s.outbox {
+		for _, sub := range subs {
+			if !sub.Wants(e.Type) || sub.DateCreated > e.DateCreated {
+				continue
+			}
+
+			*s.lastDelivery++
+			s.deliveries[*s.lastDelivery] = webhooks.Delivery{
+				ID:             *s.lastDelivery,
+				SubscriptionID: sub.ID,
+				Event:          e,
+				Status:         webhooks.StatusPending,
+				NextAttempt:    now,
+				DateCreated:    now,
+				DateUpdated:    now,
+			}
+			n++
+		}
+	}
+
+	*s.outbox = []webhooks.Event{}
+
+	return n, nil
+}
+
+func (s Store) GetDue(ctx context.Context, now int64, limit int) ([]webhooks.Delivery, error) {
+	_, span := tracer.Start(ctx, "webhooks.memory.GetDue")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r := s.filter(func(d webhooks.Delivery) bool {
+		return d.Status == webhooks.StatusPending && d.NextAttempt <= now
+	})
+
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].NextAttempt != r[j].NextAttempt {
+			return r[i].NextAttempt < r[j].NextAttempt
+		}
+		return r[i].ID < r[j].ID
+	})
+
+	if len(r) > limit {
+		r = r[:limit]
+	}
+
+	return r, nil
+}
+
+func (s Store) UpdateDelivery(ctx context.Context, d webhooks.Delivery) error {
+	_, span := tracer.Start(ctx, "webhooks.memory.UpdateDelivery")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, ok := s.deliveries[d.ID]
+	if !ok {
+		return nil
+	}
+
+	prev.Status = d.Status
+	prev.Attempts = d.Attempts
+	prev.NextAttempt = d.NextAttempt
+	prev.LastStatusCode = d.LastStatusCode
+	prev.LastError = d.LastError
+	prev.DateUpdated = d.DateUpdated
+	s.deliveries[d.ID] = prev
+
+	return nil
+}
+
+func (s Store) GetDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]webhooks.Delivery, error) {
+	_, span := tracer.Start(ctx, "webhooks.memory.GetDeliveries")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r := s.filter(func(d webhooks.Delivery) bool {
+		return d.SubscriptionID == id
+	})
+
+	sort.Slice(r, func(i, j int) bool { return r[i].ID > r[j].ID })
+
+	if len(r) > limit {
+		r = r[:limit]
+	}
+
+	return r, nil
+}
+
+// Prune deletes the deliveries only: the events of the outbox are
+// dropped once they are dispatched, and a delivery holds its event.
+func (s Store) Prune(ctx context.Context, before int64) error {
+	_, span := tracer.Start(ctx, "webhooks.memory.Prune")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, d := range s.deliveries {
+		if d.Status != webhooks.StatusPending && d.DateUpdated < before {
+			delete(s.deliveries, id)
+		}
+	}
+
+	return nil
+}
+
+// filter returns the deliveries that match with the URL and the secret
+// of their subscriptions. The caller must hold the lock.
+func (s Store) filter(match func(webhooks.Delivery) bool) []webhooks.Delivery {
+	r := make([]webhooks.Delivery, 0)
+
+	for _, d := range s.deliveries {
+		sub, ok := s.subs[d.SubscriptionID]
+		if !ok || !match(d) {
+			continue
+		}
+
+		d.URL = sub.URL
+		d.Secret = sub.Secret
+		r = append(r, d)
+	}
+
+	return r
+}
+
+// sorted returns the subscriptions ordered by the creation date. The
+// caller must hold the lock.
+func (s Store) sorted() []webhooks.Subscription {
+	r := make([]webhooks.Subscription, 0, len(s.subs))
+
+	for _, sub := range s.subs {
+		r = append(r, clone(sub))
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].DateCreated != r[j].DateCreated {
+			return r[i].DateCreated < r[j].DateCreated
+		}
+		return r[i].ID.String() < r[j].ID.String()
+	})
+
+	return r
+}
+
+func clone(sub webhooks.Subscription) webhooks.Subscription {
+	sub.Events = append([]string(nil), sub.Events...)
+	return sub
+}
@@ -0,0 +1,33 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+//go:generate moq -out webhooksRepo_moq.go . Repository
+type Repository interface {
+	Save(context.Context, Subscription) error
+	Update(context.Context, Subscription) error
+	// Delete removes the subscription with its deliveries.
+	Delete(context.Context, uuid.UUID) error
+	GetById(context.Context, uuid.UUID) (Subscription, error)
+	GetAll(context.Context) ([]Subscription, error)
+	// Dispatch turns the events of the outbox that aren't dispatched yet
+	// into a pending delivery to every subscription that wants them, due
+	// at now. It returns the number of the new deliveries.
+	Dispatch(ctx context.Context, now int64) (int, error)
+	// GetDue returns up to limit pending deliveries that are due at now,
+	// the oldest first.
+	GetDue(ctx context.Context, now int64, limit int) ([]Delivery, error)
+	// UpdateDelivery writes the outcome of an attempt of the delivery.
+	UpdateDelivery(context.Context, Delivery) error
+	// GetDeliveries returns up to limit deliveries of the subscription,
+	// the newest first.
+	GetDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]Delivery, error)
+	// Prune deletes the deliveries that succeeded or are dead and were
+	// last updated before the Unix time, then the dispatched events that
+	// have no delivery left.
+	Prune(ctx context.Context, before int64) error
+}
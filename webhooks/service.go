@@ -0,0 +1,249 @@
+// Package webhooks sends the events of the news to the URLs that
+// subscribe to them. The events are written to an outbox in the
+// transactions of the news store and a worker delivers them.
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/Iiqbal2000/bareknews/webhooks")
+
+const (
+	// DefaultDeliveriesLimit is the number of deliveries of the log when
+	// no limit is given.
+	DefaultDeliveriesLimit = 50
+	// MaxDeliveriesLimit is the maximum number of deliveries of the log.
+	MaxDeliveriesLimit = 200
+)
+
+type SubscriptionIn struct {
+	URL string `json:"url" validate:"required"`
+	// Secret signs the deliveries. A random one is made when it is empty
+	// on creation, and it is kept when it is empty on update.
+	Secret string   `json:"secret"`
+	Events []string `json:"events" validate:"required" enums:"created,updated,published,deleted"`
+}
+
+type SubscriptionOut struct {
+	ID  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Secret is returned only when the subscription is created.
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events"`
+	DateCreated int64    `json:"date_created"`
+}
+
+func createSubscriptionOut(s Subscription) SubscriptionOut {
+	return SubscriptionOut{
+		ID:          s.ID,
+		URL:         s.URL,
+		Events:      s.Events,
+		DateCreated: s.DateCreated,
+	}
+}
+
+type DeliveryOut struct {
+	ID       int64     `json:"id"`
+	EventID  int64     `json:"event_id"`
+	Event    string    `json:"event"`
+	NewsID   uuid.UUID `json:"news_id"`
+	Status   string    `json:"status" enums:"pending,succeeded,dead"`
+	Attempts int       `json:"attempts"`
+	// NextAttemptAt is the Unix time of the next attempt of a pending
+	// delivery.
+	NextAttemptAt  int64  `json:"next_attempt_at"`
+	LastStatusCode int    `json:"last_status_code"`
+	LastError      string `json:"last_error"`
+	DateCreated    int64  `json:"date_created"`
+	DateUpdated    int64  `json:"date_updated"`
+}
+
+func createDeliveryOut(d Delivery) DeliveryOut {
+	return DeliveryOut{
+		ID:             d.ID,
+		EventID:        d.Event.ID,
+		Event:          d.Event.Type,
+		NewsID:         d.Event.Data.ID,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttempt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DateCreated:    d.DateCreated,
+		DateUpdated:    d.DateUpdated,
+	}
+}
+
+type Service struct {
+	store Repository
+}
+
+func CreateSvc(store Repository) Service {
+	return Service{store: store}
+}
+
+func (s Service) Create(ctx context.Context, input SubscriptionIn) (SubscriptionOut, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.Create")
+	defer span.End()
+
+	secret := input.Secret
+	if secret == "" {
+		var err error
+		secret, err = NewSecret()
+		if err != nil {
+			return SubscriptionOut{}, err
+		}
+	}
+
+	sub := Create(strings.TrimSpace(input.URL), secret, unique(input.Events), time.Now().Unix())
+
+	err := sub.Validate()
+	if err != nil {
+		return SubscriptionOut{}, err
+	}
+
+	err = s.store.Save(ctx, *sub)
+	if err != nil {
+		return SubscriptionOut{}, errors.Wrap(err, "save a subscription")
+	}
+
+	out := createSubscriptionOut(*sub)
+	out.Secret = sub.Secret
+
+	return out, nil
+}
+
+// Update replaces the URL and the events of a subscription, and its
+// secret when one is given.
+func (s Service) Update(ctx context.Context, id uuid.UUID, input SubscriptionIn) (SubscriptionOut, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.Update")
+	defer span.End()
+
+	sub, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return SubscriptionOut{}, err
+	}
+
+	sub.URL = strings.TrimSpace(input.URL)
+	sub.Events = unique(input.Events)
+	if input.Secret != "" {
+		sub.Secret = input.Secret
+	}
+
+	err = sub.Validate()
+	if err != nil {
+		return SubscriptionOut{}, err
+	}
+
+	err = s.store.Update(ctx, sub)
+	if err != nil {
+		return SubscriptionOut{}, errors.Wrap(err, "update a subscription")
+	}
+
+	return createSubscriptionOut(sub), nil
+}
+
+func (s Service) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "webhooks.Delete")
+	defer span.End()
+
+	_, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Delete(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "delete a subscription")
+	}
+
+	return nil
+}
+
+func (s Service) GetById(ctx context.Context, id uuid.UUID) (SubscriptionOut, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.GetById")
+	defer span.End()
+
+	sub, err := s.store.GetById(ctx, id)
+	if err != nil {
+		return SubscriptionOut{}, err
+	}
+
+	return createSubscriptionOut(sub), nil
+}
+
+func (s Service) GetAll(ctx context.Context) ([]SubscriptionOut, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.GetAll")
+	defer span.End()
+
+	subs, err := s.store.GetAll(ctx)
+	if err != nil {
+		return []SubscriptionOut{}, errors.Wrap(err, "get all subscriptions")
+	}
+
+	r := make([]SubscriptionOut, 0, len(subs))
+	for _, sub := range subs {
+		r = append(r, createSubscriptionOut(sub))
+	}
+
+	return r, nil
+}
+
+// GetDeliveries returns the log of the deliveries of a subscription, the
+// newest first.
+func (s Service) GetDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]DeliveryOut, error) {
+	ctx, span := tracer.Start(ctx, "webhooks.GetDeliveries")
+	defer span.End()
+
+	if limit == 0 {
+		limit = DefaultDeliveriesLimit
+	}
+
+	err := validation.Errors{
+		"limit": validation.Validate(limit, validation.Min(1), validation.Max(MaxDeliveriesLimit)),
+	}.Filter()
+	if err != nil {
+		return []DeliveryOut{}, err
+	}
+
+	_, err = s.store.GetById(ctx, id)
+	if err != nil {
+		return []DeliveryOut{}, err
+	}
+
+	deliveries, err := s.store.GetDeliveries(ctx, id, limit)
+	if err != nil {
+		return []DeliveryOut{}, errors.Wrap(err, "get the deliveries")
+	}
+
+	r := make([]DeliveryOut, 0, len(deliveries))
+	for _, d := range deliveries {
+		r = append(r, createDeliveryOut(d))
+	}
+
+	return r, nil
+}
+
+// unique returns the events without the repeated ones, in order.
+func unique(events []string) []string {
+	r := make([]string, 0, len(events))
+	seen := make(map[string]bool)
+
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if !seen[e] {
+			seen[e] = true
+			r = append(r, e)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,168 @@
+package webhooks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	"github.com/Iiqbal2000/bareknews/webhooks"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestCreate(t *testing.T) {
+	var saved webhooks.Subscription
+	store := &webhooks.RepositoryMock{
+		SaveFunc: func(ctx context.Context, sub webhooks.Subscription) error {
+			saved = sub
+			return nil
+		},
+	}
+	svc := webhooks.CreateSvc(store)
+	is := is.New(t)
+
+	out, err := svc.Create(context.TODO(), webhooks.SubscriptionIn{
+		URL:    " https://example.com/hook ",
+		Events: []string{news.EventPublished, news.EventDeleted, news.EventPublished},
+	})
+	is.NoErr(err)
+	is.Equal(out.URL, "https://example.com/hook")
+	is.Equal(out.Events, []string{news.EventPublished, news.EventDeleted})
+	// a secret is made and returned once.
+	is.Equal(len(out.Secret), 64)
+	is.Equal(saved.Secret, out.Secret)
+
+	out, err = svc.Create(context.TODO(), webhooks.SubscriptionIn{
+		URL:    "http://localhost:8080/hook",
+		Secret: "0123456789abcdef",
+		Events: []string{news.EventCreated},
+	})
+	is.NoErr(err)
+	is.Equal(out.Secret, "0123456789abcdef")
+}
+
+func TestCreateInvalid(t *testing.T) {
+	store := &webhooks.RepositoryMock{
+		SaveFunc: func(ctx context.Context, sub webhooks.Subscription) error {
+			return nil
+		},
+	}
+	svc := webhooks.CreateSvc(store)
+
+	tests := []struct {
+		name  string
+		input webhooks.SubscriptionIn
+		field string
+	}{
+		{"no url", webhooks.SubscriptionIn{Events: []string{news.EventCreated}}, "URL"},
+		{"not a url", webhooks.SubscriptionIn{URL: "hook", Events: []string{news.EventCreated}}, "URL"},
+		{"not http", webhooks.SubscriptionIn{URL: "ftp://example.com/hook", Events: []string{news.EventCreated}}, "URL"},
+		{"short secret", webhooks.SubscriptionIn{URL: "https://example.com", Secret: "secret", Events: []string{news.EventCreated}}, "Secret"},
+		{"no events", webhooks.SubscriptionIn{URL: "https://example.com"}, "Events"},
+		{"unknown event", webhooks.SubscriptionIn{URL: "https://example.com", Events: []string{"viewed"}}, "Events"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+
+			_, err := svc.Create(context.TODO(), tc.input)
+			errs, ok := err.(validation.Errors)
+			is.True(ok)
+			is.True(errs[tc.field] != nil)
+		})
+	}
+
+	is.New(t).Equal(len(store.SaveCalls()), 0)
+}
+
+func TestUpdate(t *testing.T) {
+	sub := webhooks.Create("https://example.com/hook", "0123456789abcdef", []string{news.EventCreated}, 1)
+
+	var updated webhooks.Subscription
+	store := &webhooks.RepositoryMock{
+		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (webhooks.Subscription, error) {
+			if id != sub.ID {
+				return webhooks.Subscription{}, bareknews.ErrDataNotFound
+			}
+			return *sub, nil
+		},
+		UpdateFunc: func(ctx context.Context, s webhooks.Subscription) error {
+			updated = s
+			return nil
+		},
+	}
+	svc := webhooks.CreateSvc(store)
+	is := is.New(t)
+
+	// the secret is kept when none is given.
+	out, err := svc.Update(context.TODO(), sub.ID, webhooks.SubscriptionIn{
+		URL:    "https://example.com/other",
+		Events: []string{news.EventDeleted},
+	})
+	is.NoErr(err)
+	is.Equal(out.URL, "https://example.com/other")
+	is.Equal(out.Events, []string{news.EventDeleted})
+	is.Equal(out.Secret, "")
+	is.Equal(updated.Secret, sub.Secret)
+
+	_, err = svc.Update(context.TODO(), sub.ID, webhooks.SubscriptionIn{
+		URL:    "https://example.com/other",
+		Secret: "fedcba9876543210",
+		Events: []string{news.EventDeleted},
+	})
+	is.NoErr(err)
+	is.Equal(updated.Secret, "fedcba9876543210")
+
+	_, err = svc.Update(context.TODO(), uuid.New(), webhooks.SubscriptionIn{})
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
+
+func TestGetDeliveries(t *testing.T) {
+	sub := webhooks.Create("https://example.com/hook", "0123456789abcdef", []string{news.EventCreated}, 1)
+	n := news.Create("news 1", "news body", bareknews.Draft, nil, 1)
+
+	store := &webhooks.RepositoryMock{
+		GetByIdFunc: func(ctx context.Context, id uuid.UUID) (webhooks.Subscription, error) {
+			if id != sub.ID {
+				return webhooks.Subscription{}, bareknews.ErrDataNotFound
+			}
+			return *sub, nil
+		},
+		GetDeliveriesFunc: func(ctx context.Context, id uuid.UUID, limit int) ([]webhooks.Delivery, error) {
+			return []webhooks.Delivery{{
+				ID:             7,
+				SubscriptionID: id,
+				Secret:         sub.Secret,
+				Event:          webhooks.Event{ID: 3, Type: news.EventCreated, Data: webhooks.NewsDataOf(*n)},
+				Status:         webhooks.StatusDead,
+				Attempts:       8,
+				LastStatusCode: 500,
+			}}, nil
+		},
+	}
+	svc := webhooks.CreateSvc(store)
+	is := is.New(t)
+
+	got, err := svc.GetDeliveries(context.TODO(), sub.ID, 0)
+	is.NoErr(err)
+	is.Equal(got, []webhooks.DeliveryOut{{
+		ID:             7,
+		EventID:        3,
+		Event:          news.EventCreated,
+		NewsID:         n.Post.ID,
+		Status:         webhooks.StatusDead,
+		Attempts:       8,
+		LastStatusCode: 500,
+	}})
+	is.Equal(store.GetDeliveriesCalls()[0].Limit, webhooks.DefaultDeliveriesLimit)
+
+	_, err = svc.GetDeliveries(context.TODO(), sub.ID, webhooks.MaxDeliveriesLimit+1)
+	_, ok := err.(validation.Errors)
+	is.True(ok)
+
+	_, err = svc.GetDeliveries(context.TODO(), uuid.New(), 10)
+	is.Equal(err, bareknews.ErrDataNotFound)
+}
@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/Iiqbal2000/bareknews/news"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MinSecretLength is the minimum length of a secret that is given.
+	MinSecretLength = 16
+	// MaxURLLength is the maximum length of the URL of a subscription.
+	MaxURLLength = 2048
+)
+
+// Events are the kinds of the events that a subscription can get.
+var Events = []interface{}{
+	news.EventCreated, news.EventUpdated, news.EventPublished, news.EventDeleted,
+}
+
+// The states of a delivery. A delivery is dead once it fails MaxAttempts
+// times; it is kept in the log and isn't tried again.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// Subscription is an aggregate that represents a receiver of the events
+// of the news. The deliveries are signed with its secret.
+type Subscription struct {
+	ID          uuid.UUID
+	URL         string
+	Secret      string
+	Events      []string
+	DateCreated int64
+}
+
+func Create(url, secret string, events []string, timeNowUnix int64) *Subscription {
+	return &Subscription{
+		ID:          uuid.New(),
+		URL:         url,
+		Secret:      secret,
+		Events:      events,
+		DateCreated: timeNowUnix,
+	}
+}
+
+func (s Subscription) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.URL,
+			validation.Required,
+			validation.Length(1, MaxURLLength),
+			is.URL,
+			validation.By(httpURL),
+		),
+		validation.Field(&s.Secret, validation.Required, validation.Length(MinSecretLength, 0)),
+		validation.Field(&s.Events,
+			validation.Required,
+			validation.Each(validation.In(Events...).Error("must be one of 'created', 'updated', 'published', 'deleted'")),
+		),
+	)
+}
+
+// Wants reports whether the subscription gets the events of the kind.
+func (s Subscription) Wants(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+func httpURL(value interface{}) error {
+	u, err := url.Parse(value.(string))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+
+	return nil
+}
+
+// NewSecret returns a random secret of 32 bytes in hex.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "read random bytes")
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature of a delivery: the HMAC-SHA256 of the
+// timestamp, a dot and the body, keyed with the secret, in hex.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewsData is the news item of an event. It is taken when the event is
+// written, so a delivery tells the state of the news at that time.
+type NewsData struct {
+	ID          uuid.UUID   `json:"id"`
+	Title       string      `json:"title"`
+	Slug        string      `json:"slug"`
+	Status      string      `json:"status"`
+	Excerpt     string      `json:"excerpt"`
+	TagIDs      []uuid.UUID `json:"tag_ids"`
+	DateCreated int64       `json:"date_created"`
+	DateUpdated int64       `json:"date_updated"`
+}
+
+func NewsDataOf(n news.News) NewsData {
+	tagIDs := make([]uuid.UUID, 0, len(n.TagsID))
+	tagIDs = append(tagIDs, n.TagsID...)
+
+	return NewsData{
+		ID:          n.Post.ID,
+		Title:       n.Post.Title,
+		Slug:        n.Slug.String(),
+		Status:      n.Status.String(),
+		Excerpt:     n.Excerpt,
+		TagIDs:      tagIDs,
+		DateCreated: n.DateCreated,
+		DateUpdated: n.DateUpdated,
+	}
+}
+
+// Event is an event of the outbox.
+type Event struct {
+	ID          int64
+	Type        string
+	Data        NewsData
+	DateCreated int64
+}
+
+// body is the JSON body of a delivery.
+type body struct {
+	ID          int64    `json:"id"`
+	Event       string   `json:"event"`
+	DateCreated int64    `json:"date_created"`
+	News        NewsData `json:"news"`
+}
+
+// Body returns the JSON body of the deliveries of the event.
+func (e Event) Body() ([]byte, error) {
+	b, err := json.Marshal(body{ID: e.ID, Event: e.Type, DateCreated: e.DateCreated, News: e.Data})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal the event")
+	}
+
+	return b, nil
+}
+
+// Delivery is the sending of an event to a subscription. URL and Secret
+// are of the subscription.
+type Delivery struct {
+	ID             int64
+	SubscriptionID uuid.UUID
+	URL            string
+	Secret         string
+	Event          Event
+	Status         string
+	Attempts       int
+	// NextAttempt is the Unix time the delivery is due.
+	NextAttempt int64
+	// LastStatusCode is the status code of the response of the last
+	// attempt, zero when there is none.
+	LastStatusCode int
+	LastError      string
+	DateCreated    int64
+	DateUpdated    int64
+}
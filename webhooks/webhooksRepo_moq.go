@@ -0,0 +1,528 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package webhooks
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sync"
+)
+
+// Ensure, that RepositoryMock does implement Repository.
+// If this is not the case, regenerate this file with moq.
+var _ Repository = &RepositoryMock{}
+
+// RepositoryMock is a mock implementation of Repository.
+//
+// 	func TestSomethingThatUsesRepository(t *testing.T) {
+//
+// 		// make and configure a mocked Repository
+// 		mockedRepository := &RepositoryMock{
+// 			DeleteFunc: func(contextMoqParam context.Context, uUID uuid.UUID) error {
+// 				panic("mock out the Delete method")
+// 			},
+// 			DispatchFunc: func(ctx context.Context, now int64) (int, error) {
+// 				panic("mock out the Dispatch method")
+// 			},
+// 			GetAllFunc: func(contextMoqParam context.Context) ([]Subscription, error) {
+// 				panic("mock out the GetAll method")
+// 			},
+// 			GetByIdFunc: func(contextMoqParam context.Context, uUID uuid.UUID) (Subscription, error) {
+// 				panic("mock out the GetById method")
+// 			},
+// 			GetDeliveriesFunc: func(ctx context.Context, id uuid.UUID, limit int) ([]Delivery, error) {
+// 				panic("mock out the GetDeliveries method")
+// 			},
+// 			GetDueFunc: func(ctx context.Context, now int64, limit int) ([]Delivery, error) {
+// 				panic("mock out the GetDue method")
+// 			},
+// 			PruneFunc: func(ctx context.Context, before int64) error {
+// 				panic("mock out the Prune method")
+// 			},
+// 			SaveFunc: func(contextMoqParam context.Context, subscription Subscription) error {
+// 				panic("mock out the Save method")
+// 			},
+// 			UpdateFunc: func(contextMoqParam context.Context, subscription Subscription) error {
+// 				panic("mock out the Update method")
+// 			},
+// 			UpdateDeliveryFunc: func(contextMoqParam context.Context, delivery Delivery) error {
+// 				panic("mock out the UpdateDelivery method")
+// 			},
+// 		}
+//
+// 		// use mockedRepository in code that requires Repository
+// 		// and then make assertions.
+//
+// 	}
+type RepositoryMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(contextMoqParam context.Context, uUID uuid.UUID) error
+
+	// DispatchFunc mocks the Dispatch method.
+	DispatchFunc func(ctx context.Context, now int64) (int, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(contextMoqParam context.Context) ([]Subscription, error)
+
+	// GetByIdFunc mocks the GetById method.
+	GetByIdFunc func(contextMoqParam context.Context, uUID uuid.UUID) (Subscription, error)
+
+	// GetDeliveriesFunc mocks the GetDeliveries method.
+	GetDeliveriesFunc func(ctx context.Context, id uuid.UUID, limit int) ([]Delivery, error)
+
+	// GetDueFunc mocks the GetDue method.
+	GetDueFunc func(ctx context.Context, now int64, limit int) ([]Delivery, error)
+
+	// PruneFunc mocks the Prune method.
+	PruneFunc func(ctx context.Context, before int64) error
+
+	// SaveFunc mocks the Save method.
+	SaveFunc func(contextMoqParam context.Context, subscription Subscription) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(contextMoqParam context.Context, subscription Subscription) error
+
+	// UpdateDeliveryFunc mocks the UpdateDelivery method.
+	UpdateDeliveryFunc func(contextMoqParam context.Context, delivery Delivery) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// UUID is the uUID argument value.
+			UUID uuid.UUID
+		}
+		// Dispatch holds details about calls to the Dispatch method.
+		Dispatch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now int64
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
+		// GetById holds details about calls to the GetById method.
+		GetById []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// UUID is the uUID argument value.
+			UUID uuid.UUID
+		}
+		// GetDeliveries holds details about calls to the GetDeliveries method.
+		GetDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uuid.UUID
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetDue holds details about calls to the GetDue method.
+		GetDue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now int64
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// Prune holds details about calls to the Prune method.
+		Prune []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before int64
+		}
+		// Save holds details about calls to the Save method.
+		Save []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// Subscription is the subscription argument value.
+			Subscription Subscription
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// Subscription is the subscription argument value.
+			Subscription Subscription
+		}
+		// UpdateDelivery holds details about calls to the UpdateDelivery method.
+		UpdateDelivery []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// Delivery is the delivery argument value.
+			Delivery Delivery
+		}
+	}
+	lockDelete         sync.RWMutex
+	lockDispatch       sync.RWMutex
+	lockGetAll         sync.RWMutex
+	lockGetById        sync.RWMutex
+	lockGetDeliveries  sync.RWMutex
+	lockGetDue         sync.RWMutex
+	lockPrune          sync.RWMutex
+	lockSave           sync.RWMutex
+	lockUpdate         sync.RWMutex
+	lockUpdateDelivery sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *RepositoryMock) Delete(contextMoqParam context.Context, uUID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("RepositoryMock.DeleteFunc: method is nil but Repository.Delete was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}{
+		ContextMoqParam: contextMoqParam,
+		UUID:            uUID,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(contextMoqParam, uUID)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//     len(mockedRepository.DeleteCalls())
+func (mock *RepositoryMock) DeleteCalls() []struct {
+	ContextMoqParam context.Context
+	UUID            uuid.UUID
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Dispatch calls DispatchFunc.
+func (mock *RepositoryMock) Dispatch(ctx context.Context, now int64) (int, error) {
+	if mock.DispatchFunc == nil {
+		panic("RepositoryMock.DispatchFunc: method is nil but Repository.Dispatch was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Now int64
+	}{
+		Ctx: ctx,
+		Now: now,
+	}
+	mock.lockDispatch.Lock()
+	mock.calls.Dispatch = append(mock.calls.Dispatch, callInfo)
+	mock.lockDispatch.Unlock()
+	return mock.DispatchFunc(ctx, now)
+}
+
+// DispatchCalls gets all the calls that were made to Dispatch.
+// Check the length with:
+//     len(mockedRepository.DispatchCalls())
+func (mock *RepositoryMock) DispatchCalls() []struct {
+	Ctx context.Context
+	Now int64
+} {
+	var calls []struct {
+		Ctx context.Context
+		Now int64
+	}
+	mock.lockDispatch.RLock()
+	calls = mock.calls.Dispatch
+	mock.lockDispatch.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *RepositoryMock) GetAll(contextMoqParam context.Context) ([]Subscription, error) {
+	if mock.GetAllFunc == nil {
+		panic("RepositoryMock.GetAllFunc: method is nil but Repository.GetAll was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+	}{
+		ContextMoqParam: contextMoqParam,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	return mock.GetAllFunc(contextMoqParam)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//     len(mockedRepository.GetAllCalls())
+func (mock *RepositoryMock) GetAllCalls() []struct {
+	ContextMoqParam context.Context
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetById calls GetByIdFunc.
+func (mock *RepositoryMock) GetById(contextMoqParam context.Context, uUID uuid.UUID) (Subscription, error) {
+	if mock.GetByIdFunc == nil {
+		panic("RepositoryMock.GetByIdFunc: method is nil but Repository.GetById was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}{
+		ContextMoqParam: contextMoqParam,
+		UUID:            uUID,
+	}
+	mock.lockGetById.Lock()
+	mock.calls.GetById = append(mock.calls.GetById, callInfo)
+	mock.lockGetById.Unlock()
+	return mock.GetByIdFunc(contextMoqParam, uUID)
+}
+
+// GetByIdCalls gets all the calls that were made to GetById.
+// Check the length with:
+//     len(mockedRepository.GetByIdCalls())
+func (mock *RepositoryMock) GetByIdCalls() []struct {
+	ContextMoqParam context.Context
+	UUID            uuid.UUID
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		UUID            uuid.UUID
+	}
+	mock.lockGetById.RLock()
+	calls = mock.calls.GetById
+	mock.lockGetById.RUnlock()
+	return calls
+}
+
+// GetDeliveries calls GetDeliveriesFunc.
+func (mock *RepositoryMock) GetDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]Delivery, error) {
+	if mock.GetDeliveriesFunc == nil {
+		panic("RepositoryMock.GetDeliveriesFunc: method is nil but Repository.GetDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		ID    uuid.UUID
+		Limit int
+	}{
+		Ctx:   ctx,
+		ID:    id,
+		Limit: limit,
+	}
+	mock.lockGetDeliveries.Lock()
+	mock.calls.GetDeliveries = append(mock.calls.GetDeliveries, callInfo)
+	mock.lockGetDeliveries.Unlock()
+	return mock.GetDeliveriesFunc(ctx, id, limit)
+}
+
+// GetDeliveriesCalls gets all the calls that were made to GetDeliveries.
+// Check the length with:
+//     len(mockedRepository.GetDeliveriesCalls())
+func (mock *RepositoryMock) GetDeliveriesCalls() []struct {
+	Ctx   context.Context
+	ID    uuid.UUID
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		ID    uuid.UUID
+		Limit int
+	}
+	mock.lockGetDeliveries.RLock()
+	calls = mock.calls.GetDeliveries
+	mock.lockGetDeliveries.RUnlock()
+	return calls
+}
+
+// GetDue calls GetDueFunc.
+func (mock *RepositoryMock) GetDue(ctx context.Context, now int64, limit int) ([]Delivery, error) {
+	if mock.GetDueFunc == nil {
+		panic("RepositoryMock.GetDueFunc: method is nil but Repository.GetDue was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Now   int64
+		Limit int
+	}{
+		Ctx:   ctx,
+		Now:   now,
+		Limit: limit,
+	}
+	mock.lockGetDue.Lock()
+	mock.calls.GetDue = append(mock.calls.GetDue, callInfo)
+	mock.lockGetDue.Unlock()
+	return mock.GetDueFunc(ctx, now, limit)
+}
+
+// GetDueCalls gets all the calls that were made to GetDue.
+// Check the length with:
+//     len(mockedRepository.GetDueCalls())
+func (mock *RepositoryMock) GetDueCalls() []struct {
+	Ctx   context.Context
+	Now   int64
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Now   int64
+		Limit int
+	}
+	mock.lockGetDue.RLock()
+	calls = mock.calls.GetDue
+	mock.lockGetDue.RUnlock()
+	return calls
+}
+
+// Prune calls PruneFunc.
+func (mock *RepositoryMock) Prune(ctx context.Context, before int64) error {
+	if mock.PruneFunc == nil {
+		panic("RepositoryMock.PruneFunc: method is nil but Repository.Prune was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before int64
+	}{
+		Ctx:    ctx,
+		Before: before,
+	}
+	mock.lockPrune.Lock()
+	mock.calls.Prune = append(mock.calls.Prune, callInfo)
+	mock.lockPrune.Unlock()
+	return mock.PruneFunc(ctx, before)
+}
+
+// PruneCalls gets all the calls that were made to Prune.
+// Check the length with:
+//     len(mockedRepository.PruneCalls())
+func (mock *RepositoryMock) PruneCalls() []struct {
+	Ctx    context.Context
+	Before int64
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before int64
+	}
+	mock.lockPrune.RLock()
+	calls = mock.calls.Prune
+	mock.lockPrune.RUnlock()
+	return calls
+}
+
+// Save calls SaveFunc.
+func (mock *RepositoryMock) Save(contextMoqParam context.Context, subscription Subscription) error {
+	if mock.SaveFunc == nil {
+		panic("RepositoryMock.SaveFunc: method is nil but Repository.Save was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		Subscription    Subscription
+	}{
+		ContextMoqParam: contextMoqParam,
+		Subscription:    subscription,
+	}
+	mock.lockSave.Lock()
+	mock.calls.Save = append(mock.calls.Save, callInfo)
+	mock.lockSave.Unlock()
+	return mock.SaveFunc(contextMoqParam, subscription)
+}
+
+// SaveCalls gets all the calls that were made to Save.
+// Check the length with:
+//     len(mockedRepository.SaveCalls())
+func (mock *RepositoryMock) SaveCalls() []struct {
+	ContextMoqParam context.Context
+	Subscription    Subscription
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		Subscription    Subscription
+	}
+	mock.lockSave.RLock()
+	calls = mock.calls.Save
+	mock.lockSave.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *RepositoryMock) Update(contextMoqParam context.Context, subscription Subscription) error {
+	if mock.UpdateFunc == nil {
+		panic("RepositoryMock.UpdateFunc: method is nil but Repository.Update was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		Subscription    Subscription
+	}{
+		ContextMoqParam: contextMoqParam,
+		Subscription:    subscription,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	return mock.UpdateFunc(contextMoqParam, subscription)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//     len(mockedRepository.UpdateCalls())
+func (mock *RepositoryMock) UpdateCalls() []struct {
+	ContextMoqParam context.Context
+	Subscription    Subscription
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		Subscription    Subscription
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// UpdateDelivery calls UpdateDeliveryFunc.
+func (mock *RepositoryMock) UpdateDelivery(contextMoqParam context.Context, delivery Delivery) error {
+	if mock.UpdateDeliveryFunc == nil {
+		panic("RepositoryMock.UpdateDeliveryFunc: method is nil but Repository.UpdateDelivery was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		Delivery        Delivery
+	}{
+		ContextMoqParam: contextMoqParam,
+		Delivery:        delivery,
+	}
+	mock.lockUpdateDelivery.Lock()
+	mock.calls.UpdateDelivery = append(mock.calls.UpdateDelivery, callInfo)
+	mock.lockUpdateDelivery.Unlock()
+	return mock.UpdateDeliveryFunc(contextMoqParam, delivery)
+}
+
+// UpdateDeliveryCalls gets all the calls that were made to UpdateDelivery.
+// Check the length with:
+//     len(mockedRepository.UpdateDeliveryCalls())
+func (mock *RepositoryMock) UpdateDeliveryCalls() []struct {
+	ContextMoqParam context.Context
+	Delivery        Delivery
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		Delivery        Delivery
+	}
+	mock.lockUpdateDelivery.RLock()
+	calls = mock.calls.UpdateDelivery
+	mock.lockUpdateDelivery.RUnlock()
+	return calls
+}
@@ -0,0 +1,324 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// The headers of a delivery. The signature is "sha256=" and the Sign of
+// the timestamp and the body with the secret of the subscription.
+const (
+	HeaderEvent     = "X-Bareknews-Event"
+	HeaderDelivery  = "X-Bareknews-Delivery"
+	HeaderTimestamp = "X-Bareknews-Timestamp"
+	HeaderSignature = "X-Bareknews-Signature"
+)
+
+// maxErrorLength is the maximum length of the last error of a delivery.
+const maxErrorLength = 512
+
+const (
+	// DefaultBatchSize is the number of the deliveries that are read at
+	// a time.
+	DefaultBatchSize = 50
+	// DefaultConcurrency is the number of the subscriptions that are sent
+	// to at the same time.
+	DefaultConcurrency = 8
+	// DefaultMaxPerWork is the number of the deliveries that a Work sends
+	// at most, so a backlog can't hold it for long.
+	DefaultMaxPerWork = 500
+)
+
+// Retry tells how the failed deliveries are tried again: after BaseDelay,
+// then twice as long after each attempt up to MaxDelay. A delivery is
+// dead after MaxAttempts attempts.
+type Retry struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Validate checks that a failed delivery waits at least a second, the
+// precision of the next attempt, so it isn't tried again at once.
+func (r Retry) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.MaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&r.BaseDelay, validation.Required, validation.Min(time.Second)),
+		validation.Field(&r.MaxDelay, validation.Required, validation.Min(r.BaseDelay)),
+	)
+}
+
+// Backoff returns the delay before the next attempt of a delivery that
+// failed attempts times.
+func (r Retry) Backoff(attempts int) time.Duration {
+	delay := r.BaseDelay
+
+	for i := 1; i < attempts && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+
+	return delay
+}
+
+// Worker dispatches the events of the outbox and sends the deliveries
+// that are due.
+type Worker struct {
+	store       Repository
+	client      *http.Client
+	retry       Retry
+	batchSize   int
+	concurrency int
+	maxPerWork  int
+	log         *zap.SugaredLogger
+}
+
+func CreateWorker(store Repository, client *http.Client, retry Retry, log *zap.SugaredLogger) (*Worker, error) {
+	err := retry.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Worker{
+		store:       store,
+		client:      client,
+		retry:       retry,
+		batchSize:   DefaultBatchSize,
+		concurrency: DefaultConcurrency,
+		maxPerWork:  DefaultMaxPerWork,
+		log:         log,
+	}, nil
+}
+
+// Run works every interval and prunes the outbox every hour until the
+// context is done. A delivery that is cut off by the context is left
+// pending, so it is sent again after a restart.
+func (w *Worker) Run(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pruner := time.NewTicker(time.Hour)
+	defer pruner.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Work(ctx, time.Now()); err != nil {
+				w.log.Errorw("deliver the webhooks", "ERROR", err)
+			}
+		case <-pruner.C:
+			if err := w.Prune(ctx, time.Now().Add(-retention)); err != nil {
+				w.log.Errorw("prune the webhooks", "ERROR", err)
+			}
+		}
+	}
+}
+
+// Prune deletes the deliveries that succeeded or are dead and haven't
+// changed since before, and the events that have no delivery left.
+func (w *Worker) Prune(ctx context.Context, before time.Time) error {
+	ctx, span := tracer.Start(ctx, "webhooks.Prune")
+	defer span.End()
+
+	err := w.store.Prune(ctx, before.Unix())
+	if err != nil {
+		return errors.Wrap(err, "prune the deliveries")
+	}
+
+	return nil
+}
+
+// Work dispatches the events of the outbox and sends the deliveries that
+// are due at now, a batch at a time. The deliveries of a subscription are
+// sent in order, and the subscriptions at the same time, so a slow
+// receiver holds up only its own deliveries. A delivery is tried at most
+// once a Work, and a Work sends at most maxPerWork deliveries; the rest
+// wait for the next one.
+func (w *Worker) Work(ctx context.Context, now time.Time) error {
+	ctx, span := tracer.Start(ctx, "webhooks.Work")
+	defer span.End()
+
+	_, err := w.store.Dispatch(ctx, now.Unix())
+	if err != nil {
+		return errors.Wrap(err, "dispatch the events")
+	}
+
+	tried := make(map[int64]bool)
+
+	for len(tried) < w.maxPerWork && ctx.Err() == nil {
+		due, err := w.store.GetDue(ctx, now.Unix(), w.batchSize)
+		if err != nil {
+			return errors.Wrap(err, "get the due deliveries")
+		}
+
+		bySub := make(map[uuid.UUID][]Delivery)
+		order := make([]uuid.UUID, 0)
+
+		for _, d := range due {
+			if tried[d.ID] || len(tried) >= w.maxPerWork {
+				continue
+			}
+
+			tried[d.ID] = true
+
+			if _, ok := bySub[d.SubscriptionID]; !ok {
+				order = append(order, d.SubscriptionID)
+			}
+			bySub[d.SubscriptionID] = append(bySub[d.SubscriptionID], d)
+		}
+
+		if len(order) == 0 {
+			return nil
+		}
+
+		err = w.deliverAll(ctx, now, order, bySub)
+		if err != nil {
+			return err
+		}
+
+		if len(due) < w.batchSize {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// deliverAll sends the deliveries of each subscription in its own
+// goroutine, up to the concurrency of the worker at a time. It returns
+// the first error.
+func (w *Worker) deliverAll(ctx context.Context, now time.Time, order []uuid.UUID, bySub map[uuid.UUID][]Delivery) error {
+	sem := make(chan struct{}, w.concurrency)
+	errs := make(chan error, len(order))
+
+	var wg sync.WaitGroup
+
+	for _, id := range order {
+		deliveries := bySub[id]
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for _, d := range deliveries {
+				if ctx.Err() != nil {
+					return
+				}
+
+				if err := w.deliver(ctx, d, now); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// deliver sends the delivery and writes the outcome.
+func (w *Worker) deliver(ctx context.Context, d Delivery, now time.Time) error {
+	ctx, span := tracer.Start(ctx, "webhooks.deliver")
+	defer span.End()
+
+	code, err := w.send(ctx, d, now)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	d.Attempts++
+	d.LastStatusCode = code
+	d.LastError = ""
+	d.DateUpdated = now.Unix()
+
+	switch {
+	case err == nil:
+		d.Status = StatusSucceeded
+	case d.Attempts >= w.retry.MaxAttempts:
+		d.Status = StatusDead
+		d.LastError = truncate(err.Error())
+	default:
+		d.NextAttempt = now.Add(w.retry.Backoff(d.Attempts)).Unix()
+		d.LastError = truncate(err.Error())
+	}
+
+	if d.Status == StatusDead {
+		w.log.Warnw("webhook delivery is dead",
+			"delivery", d.ID, "subscription", d.SubscriptionID, "ERROR", d.LastError,
+		)
+	}
+
+	err = w.store.UpdateDelivery(ctx, d)
+	if err != nil {
+		return errors.Wrap(err, "update the delivery")
+	}
+
+	return nil
+}
+
+// send posts the event of the delivery. It returns the status code of
+// the response, and an error when there is none or it isn't a 2xx.
+func (w *Worker) send(ctx context.Context, d Delivery, now time.Time) (int, error) {
+	body, err := d.Event.Body()
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now.Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrap(err, "create the request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.Event.Type)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(d.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	// the body is drained, so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+	return s
+}
@@ -0,0 +1,328 @@
+package webhooks_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Iiqbal2000/bareknews"
+	"github.com/Iiqbal2000/bareknews/news"
+	newsmemory "github.com/Iiqbal2000/bareknews/news/memory"
+	"github.com/Iiqbal2000/bareknews/webhooks"
+	"github.com/Iiqbal2000/bareknews/webhooks/memory"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+// receiver records the deliveries that it gets and answers with the
+// next of its status codes, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+
+	code := http.StatusOK
+	if len(rc.codes) > 0 {
+		code, rc.codes = rc.codes[0], rc.codes[1:]
+	}
+
+	w.WriteHeader(code)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+func setup(t *testing.T, rc *receiver, events ...string) (*webhooks.Worker, memory.Store, *webhooks.Subscription, *news.News) {
+	t.Helper()
+
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	store := memory.CreateStore()
+	sub := webhooks.Create(srv.URL, "0123456789abcdef", events, time.Now().Unix())
+	if err := store.Save(context.TODO(), *sub); err != nil {
+		t.Fatal(err)
+	}
+
+	n := news.Create("news 1", "news body", bareknews.Publish, nil, time.Now().Unix())
+	store.Enqueue(news.EventCreated, *n)
+
+	retry := webhooks.Retry{MaxAttempts: 3, BaseDelay: 30 * time.Second, MaxDelay: time.Minute}
+	worker, err := webhooks.CreateWorker(store, srv.Client(), retry, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return worker, store, sub, n
+}
+
+func TestWorkerDelivers(t *testing.T) {
+	rc := &receiver{}
+	worker, store, sub, n := setup(t, rc, news.EventCreated)
+	is := is.New(t)
+
+	now := time.Unix(1_666_000_000, 0)
+	is.NoErr(worker.Work(context.TODO(), now))
+	is.Equal(rc.count(), 1)
+
+	r, body := rc.requests[0], rc.bodies[0]
+	is.Equal(r.Method, http.MethodPost)
+	is.Equal(r.Header.Get("Content-Type"), "application/json")
+	is.Equal(r.Header.Get(webhooks.HeaderEvent), news.EventCreated)
+	is.Equal(r.Header.Get(webhooks.HeaderTimestamp), "1666000000")
+
+	// the receiver checks the signature with the secret.
+	timestamp, err := strconv.ParseInt(r.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+	is.NoErr(err)
+	signature := strings.TrimPrefix(r.Header.Get(webhooks.HeaderSignature), "sha256=")
+	is.True(hmac.Equal([]byte(signature), []byte(webhooks.Sign(sub.Secret, timestamp, body))))
+	is.True(!hmac.Equal([]byte(signature), []byte(webhooks.Sign("another secret!!", timestamp, body))))
+
+	var got struct {
+		Event string            `json:"event"`
+		News  webhooks.NewsData `json:"news"`
+	}
+	is.NoErr(json.Unmarshal(body, &got))
+	is.Equal(got.Event, news.EventCreated)
+	is.Equal(got.News.ID, n.Post.ID)
+	is.Equal(got.News.Title, "news 1")
+
+	deliveries, err := store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(len(deliveries), 1)
+	is.Equal(r.Header.Get(webhooks.HeaderDelivery), strconv.FormatInt(deliveries[0].ID, 10))
+	is.Equal(deliveries[0].Status, webhooks.StatusSucceeded)
+	is.Equal(deliveries[0].Attempts, 1)
+	is.Equal(deliveries[0].LastStatusCode, http.StatusOK)
+
+	// a delivery that succeeded isn't sent again.
+	is.NoErr(worker.Work(context.TODO(), now.Add(time.Hour)))
+	is.Equal(rc.count(), 1)
+}
+
+func TestWorkerRetries(t *testing.T) {
+	rc := &receiver{codes: []int{http.StatusInternalServerError, http.StatusServiceUnavailable}}
+	worker, store, sub, _ := setup(t, rc, news.EventCreated)
+	is := is.New(t)
+
+	now := time.Unix(1_666_000_000, 0)
+	is.NoErr(worker.Work(context.TODO(), now))
+
+	deliveries, err := store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveries[0].Status, webhooks.StatusPending)
+	is.Equal(deliveries[0].Attempts, 1)
+	is.Equal(deliveries[0].LastStatusCode, http.StatusInternalServerError)
+	is.Equal(deliveries[0].LastError, "unexpected status 500 Internal Server Error")
+	is.Equal(deliveries[0].NextAttempt, now.Add(30*time.Second).Unix())
+
+	// the delivery waits for its next attempt.
+	is.NoErr(worker.Work(context.TODO(), now.Add(29*time.Second)))
+	is.Equal(rc.count(), 1)
+
+	now = now.Add(30 * time.Second)
+	is.NoErr(worker.Work(context.TODO(), now))
+	is.Equal(rc.count(), 2)
+
+	deliveries, err = store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveries[0].Attempts, 2)
+	is.Equal(deliveries[0].NextAttempt, now.Add(time.Minute).Unix())
+
+	now = now.Add(time.Minute)
+	is.NoErr(worker.Work(context.TODO(), now))
+	is.Equal(rc.count(), 3)
+
+	deliveries, err = store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveries[0].Status, webhooks.StatusSucceeded)
+	is.Equal(deliveries[0].Attempts, 3)
+	is.Equal(deliveries[0].LastError, "")
+}
+
+func TestWorkerDeadLetter(t *testing.T) {
+	rc := &receiver{codes: []int{
+		http.StatusInternalServerError, http.StatusInternalServerError, http.StatusBadRequest,
+	}}
+	worker, store, sub, _ := setup(t, rc, news.EventCreated)
+	is := is.New(t)
+
+	now := time.Unix(1_666_000_000, 0)
+	for i := 0; i < 3; i++ {
+		is.NoErr(worker.Work(context.TODO(), now))
+		now = now.Add(time.Hour)
+	}
+	is.Equal(rc.count(), 3)
+
+	deliveries, err := store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveries[0].Status, webhooks.StatusDead)
+	is.Equal(deliveries[0].Attempts, 3)
+	is.Equal(deliveries[0].LastStatusCode, http.StatusBadRequest)
+
+	// a dead delivery isn't sent again.
+	is.NoErr(worker.Work(context.TODO(), now.Add(24*time.Hour)))
+	is.Equal(rc.count(), 3)
+}
+
+func TestWorkerUnreachable(t *testing.T) {
+	rc := &receiver{}
+	worker, store, sub, _ := setup(t, rc, news.EventCreated)
+	is := is.New(t)
+
+	sub.URL = "http://127.0.0.1:1/hook"
+	is.NoErr(store.Update(context.TODO(), *sub))
+
+	is.NoErr(worker.Work(context.TODO(), time.Unix(1_666_000_000, 0)))
+
+	deliveries, err := store.GetDeliveries(context.TODO(), sub.ID, 10)
+	is.NoErr(err)
+	is.Equal(deliveries[0].Status, webhooks.StatusPending)
+	is.Equal(deliveries[0].LastStatusCode, 0)
+	is.True(deliveries[0].LastError != "")
+}
+
+func TestWorkerSkipsUnwantedEvents(t *testing.T) {
+	rc := &receiver{}
+	worker, _, _, _ := setup(t, rc, news.EventDeleted)
+	is := is.New(t)
+
+	is.NoErr(worker.Work(context.TODO(), time.Now()))
+	is.Equal(rc.count(), 0)
+}
+
+func TestWorkerCreatedAsPublished(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	store := memory.CreateStore()
+	newsStore := newsmemory.CreateStore().WithOutbox(store)
+	is := is.New(t)
+
+	sub := webhooks.Create(srv.URL, "0123456789abcdef", []string{news.EventPublished}, time.Now().Unix())
+	is.NoErr(store.Save(context.TODO(), *sub))
+
+	// a story that is created as published comes to a published-only
+	// subscriber, and a draft doesn't.
+	n := news.Create("news 1", "news body", bareknews.Publish, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *n))
+	draft := news.Create("news 2", "news body", bareknews.Draft, nil, time.Now().Unix())
+	is.NoErr(newsStore.Save(context.TODO(), *draft))
+
+	retry := webhooks.Retry{MaxAttempts: 3, BaseDelay: 30 * time.Second, MaxDelay: time.Minute}
+	worker, err := webhooks.CreateWorker(store, srv.Client(), retry, zap.NewNop().Sugar())
+	is.NoErr(err)
+
+	is.NoErr(worker.Work(context.TODO(), time.Now()))
+	is.Equal(rc.count(), 1)
+	is.Equal(rc.requests[0].Header.Get(webhooks.HeaderEvent), news.EventPublished)
+
+	var got struct {
+		News webhooks.NewsData `json:"news"`
+	}
+	is.NoErr(json.Unmarshal(rc.bodies[0], &got))
+	is.Equal(got.News.ID, n.Post.ID)
+	is.Equal(got.News.Status, bareknews.Publish.String())
+}
+
+func TestWorkerSlowReceiver(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+
+	fast := &receiver{}
+	worker, store, _, n := setup(t, fast, news.EventCreated)
+	is := is.New(t)
+
+	// the slow subscription is older, so its delivery comes first.
+	slowSub := webhooks.Create(slow.URL, "0123456789abcdef", []string{news.EventCreated}, time.Now().Unix()-1)
+	is.NoErr(store.Save(context.TODO(), *slowSub))
+	store.Enqueue(news.EventCreated, *n)
+
+	done := make(chan error)
+	go func() { done <- worker.Work(context.TODO(), time.Now()) }()
+
+	// a slow receiver doesn't hold up the other subscriptions.
+	deadline := time.Now().Add(2 * time.Second)
+	for fast.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	is.Equal(fast.count(), 2)
+
+	close(release)
+	is.NoErr(<-done)
+}
+
+func TestWorkerMaxPerWork(t *testing.T) {
+	rc := &receiver{}
+	worker, store, _, n := setup(t, rc, news.EventCreated)
+	is := is.New(t)
+
+	for i := 1; i < webhooks.DefaultMaxPerWork+20; i++ {
+		store.Enqueue(news.EventCreated, *n)
+	}
+
+	now := time.Now()
+
+	// a Work sends a bounded number of deliveries, and the next one the
+	// rest.
+	is.NoErr(worker.Work(context.TODO(), now))
+	is.Equal(rc.count(), webhooks.DefaultMaxPerWork)
+
+	is.NoErr(worker.Work(context.TODO(), now))
+	is.Equal(rc.count(), webhooks.DefaultMaxPerWork+20)
+}
+
+func TestRetryValidate(t *testing.T) {
+	store := memory.CreateStore()
+
+	for _, retry := range []webhooks.Retry{
+		{MaxAttempts: 8, BaseDelay: 0, MaxDelay: time.Hour},
+		{MaxAttempts: 8, BaseDelay: -time.Second, MaxDelay: time.Hour},
+		{MaxAttempts: 8, BaseDelay: time.Millisecond, MaxDelay: time.Hour},
+		{MaxAttempts: 0, BaseDelay: time.Second, MaxDelay: time.Hour},
+		{MaxAttempts: 8, BaseDelay: time.Minute, MaxDelay: time.Second},
+	} {
+		is := is.New(t)
+
+		_, err := webhooks.CreateWorker(store, http.DefaultClient, retry, zap.NewNop().Sugar())
+		is.True(err != nil)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	retry := webhooks.Retry{MaxAttempts: 8, BaseDelay: 30 * time.Second, MaxDelay: 6 * time.Hour}
+	is := is.New(t)
+
+	is.Equal(retry.Backoff(1), 30*time.Second)
+	is.Equal(retry.Backoff(2), time.Minute)
+	is.Equal(retry.Backoff(5), 8*time.Minute)
+	is.Equal(retry.Backoff(10), 256*time.Minute)
+	is.Equal(retry.Backoff(11), 6*time.Hour)
+	is.Equal(retry.Backoff(100), 6*time.Hour)
+}